@@ -26,7 +26,6 @@ package autogcd
 
 import (
 	"encoding/json"
-	"github.com/wirepair/gcd"
 	"github.com/wirepair/gcd/gcdapi"
 	"github.com/wirepair/gcd/gcdmessage"
 )
@@ -47,7 +46,7 @@ work.
 // userGesture - Whether execution should be treated as initiated by user in the UI.
 // awaitPromise - Whether execution should wait for promise to be resolved. If the result of evaluation is not a Promise, it's considered to be an error.
 // Returns -  result - Evaluation result. exceptionDetails - Exception details.
-func overridenRuntimeEvaluate(target *gcd.ChromeTarget, expression string, objectGroup string, includeCommandLineAPI bool, silent bool, contextId int, returnByValue bool, generatePreview bool, userGesture bool, awaitPromise bool) (*gcdapi.RuntimeRemoteObject, *gcdapi.RuntimeExceptionDetails, error) {
+func overridenRuntimeEvaluate(target gcdmessage.ChromeTargeter, expression string, objectGroup string, includeCommandLineAPI bool, silent bool, contextId int, returnByValue bool, generatePreview bool, userGesture bool, awaitPromise bool) (*gcdapi.RuntimeRemoteObject, *gcdapi.RuntimeExceptionDetails, error) {
 	paramRequest := make(map[string]interface{}, 9)
 	paramRequest["expression"] = expression
 	paramRequest["objectGroup"] = objectGroup
@@ -90,3 +89,38 @@ func overridenRuntimeEvaluate(target *gcd.ChromeTarget, expression string, objec
 
 	return chromeData.Result.Result, chromeData.Result.ExceptionDetails, nil
 }
+
+// returns the Storage domain, which gcd.ChromeTarget.Init does not create.
+func (t *Tab) storage() *gcdapi.Storage {
+	return gcdapi.NewStorage(t.targeter())
+}
+
+// sends a command the vendored gcdapi does not have, decoding the command's result into result unless it is nil.
+func sendCommand(target gcdmessage.ChromeTargeter, method string, params interface{}, result interface{}) error {
+	resp, err := gcdmessage.SendCustomReturn(target, target.GetSendCh(), &gcdmessage.ParamRequest{Id: target.GetId(), Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+
+	if resp == nil {
+		return &gcdmessage.ChromeEmptyResponseErr{}
+	}
+
+	// test if error first
+	cerr := &gcdmessage.ChromeErrorResponse{}
+	json.Unmarshal(resp.Data, cerr)
+	if cerr.Error != nil {
+		return &gcdmessage.ChromeRequestErr{Resp: cerr}
+	}
+
+	if result == nil {
+		return nil
+	}
+	var chromeData struct {
+		Result json.RawMessage
+	}
+	if err := json.Unmarshal(resp.Data, &chromeData); err != nil {
+		return err
+	}
+	return json.Unmarshal(chromeData.Result, result)
+}
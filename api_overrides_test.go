@@ -0,0 +1,63 @@
+package autogcd
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/wirepair/gcd/gcdmessage"
+)
+
+// answers every command with the response returned by replyFn
+type testTargeter struct {
+	id     int64
+	sendCh chan *gcdmessage.Message
+	doneCh chan struct{}
+}
+
+func newTestTargeter(replyFn func(method string) string) *testTargeter {
+	target := &testTargeter{sendCh: make(chan *gcdmessage.Message), doneCh: make(chan struct{})}
+	go func() {
+		for {
+			select {
+			case msg := <-target.sendCh:
+				request := &gcdmessage.ParamRequest{}
+				json.Unmarshal(msg.Data, request)
+				msg.ReplyCh <- &gcdmessage.Message{Id: msg.Id, Data: []byte(replyFn(request.Method))}
+			case <-target.doneCh:
+				return
+			}
+		}
+	}()
+	return target
+}
+
+func (target *testTargeter) GetId() int64                        { target.id++; return target.id }
+func (target *testTargeter) GetApiTimeout() time.Duration        { return time.Second }
+func (target *testTargeter) GetSendCh() chan *gcdmessage.Message { return target.sendCh }
+func (target *testTargeter) GetDoneCh() chan struct{}            { return target.doneCh }
+
+func TestSendCommand(t *testing.T) {
+	target := newTestTargeter(func(method string) string {
+		if method == "Storage.getTrustTokens" {
+			return `{"id":1,"result":{"tokens":[{"issuerOrigin":"https://issuer.example","count":3}]}}`
+		}
+		return `{"id":2,"error":{"code":-32601,"message":"'` + method + `' wasn't found"}}`
+	})
+	defer close(target.doneCh)
+
+	var result struct {
+		Tokens []*TrustTokens `json:"tokens"`
+	}
+	if err := sendCommand(target, "Storage.getTrustTokens", nil, &result); err != nil {
+		t.Fatalf("error sending command: %s\n", err)
+	}
+	if len(result.Tokens) != 1 || result.Tokens[0].IssuerOrigin != "https://issuer.example" || result.Tokens[0].Count != 3 {
+		t.Fatalf("unexpected result %#v\n", result.Tokens)
+	}
+
+	err := sendCommand(target, "Storage.unknown", nil, nil)
+	if _, ok := err.(*gcdmessage.ChromeRequestErr); !ok {
+		t.Fatalf("expected a ChromeRequestErr got %v\n", err)
+	}
+}
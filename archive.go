@@ -0,0 +1,130 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Returns the visible text of the document followed by the text of each frame it can reach, frames
+// from other origins can not be read and are listed by url only.
+const flattenedTextScript = `(function() {
+	var parts = [];
+	function walk(win, label) {
+		var doc;
+		try {
+			doc = win.document;
+			parts.push('==== ' + label + ' ' + doc.location.href + ' ====');
+			parts.push(doc.body ? doc.body.innerText : '');
+		} catch (e) {
+			parts.push('==== ' + label + ' (cross origin, not readable) ====');
+			return;
+		}
+		for (var i = 0; i < win.frames.length; i++) {
+			walk(win.frames[i], label + ' > frame ' + (i + 1));
+		}
+	}
+	walk(window, 'page');
+	return parts.join('\n\n');
+})()`
+
+// Names of the files written by ArchivePage
+const (
+	ArchiveHTMLFile       = "page.html"
+	ArchiveMHTMLFile      = "page.mhtml"
+	ArchiveScreenshotFile = "screenshot.png"
+	ArchiveTextFile       = "page.txt"
+	ArchiveManifestFile   = "manifest.json"
+)
+
+// ArchiveManifest describes a page saved by ArchivePage, it is written to manifest.json.
+type ArchiveManifest struct {
+	Url       string            `json:"url"`              // url of the top level document
+	Title     string            `json:"title"`            // document title
+	Timestamp time.Time         `json:"timestamp"`        // when the archive was started
+	Files     map[string]string `json:"files"`            // file name => hex sha256 of its contents
+	Errors    map[string]string `json:"errors,omitempty"` // file name => why it could not be captured
+}
+
+// ArchivePage saves evidence of the current page to dir, creating it if needed: the HTML source, an MHTML
+// snapshot with its resources, a full page screenshot, the page text with the text of its frames flattened
+// in, and a manifest with the url, title, time and sha256 of each file. Capturing is best effort, files
+// which could not be captured are listed in the manifest's Errors. An error is only returned if dir or
+// the manifest could not be written.
+func (t *Tab) ArchivePage(dir string) (*ArchiveManifest, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	manifest := &ArchiveManifest{Timestamp: time.Now().UTC(), Files: make(map[string]string), Errors: make(map[string]string)}
+	manifest.Url, _ = t.GetCurrentUrl()
+	manifest.Title, _ = t.GetTitle()
+
+	capture := func(name string, captureFn func() ([]byte, error)) {
+		data, err := captureFn()
+		if err == nil {
+			err = ioutil.WriteFile(filepath.Join(dir, name), data, 0644)
+		}
+		if err != nil {
+			manifest.Errors[name] = err.Error()
+			return
+		}
+		sum := sha256.Sum256(data)
+		manifest.Files[name] = hex.EncodeToString(sum[:])
+	}
+
+	capture(ArchiveHTMLFile, func() ([]byte, error) {
+		source, err := t.GetPageSource(0)
+		return []byte(source), err
+	})
+	capture(ArchiveMHTMLFile, func() ([]byte, error) {
+		snapshot, err := t.Page.CaptureSnapshot("mhtml")
+		return []byte(snapshot), err
+	})
+	capture(ArchiveScreenshotFile, t.GetFullPageScreenShot)
+	capture(ArchiveTextFile, func() ([]byte, error) {
+		rro, err := t.evaluateScript(flattenedTextScript, false)
+		if err != nil {
+			return nil, err
+		}
+		text, _ := rro.Value.(string)
+		return []byte(text), nil
+	})
+
+	encoded, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ArchiveManifestFile), encoded, 0644); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
@@ -0,0 +1,241 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"time"
+)
+
+// A simplified readability algorithm. Paragraph text is scored and credited to parents and grandparents,
+// adjusted by class/id hints and link density. The best candidate is cloned, cleaned of scripts, forms
+// and other boilerplate, and returned along with metadata from meta tags and JSON-LD.
+const extractArticleScript = `(function() {
+	var negative = /comment|meta|footer|footnote|foot|nav|sidebar|sponsor|ad-|ads|advert|promo|related|share|social|widget|masthead|menu|banner|cookie|popup|modal|subscribe|newsletter/i;
+	var positive = /article|body|content|entry|main|page|post|text|blog|story/i;
+	function meta(names) {
+		for (var i = 0; i < names.length; i++) {
+			var node = document.querySelector('meta[property="' + names[i] + '"], meta[name="' + names[i] + '"], meta[itemprop="' + names[i] + '"]');
+			if (node && node.content) {
+				return node.content.trim();
+			}
+		}
+		return '';
+	}
+	function jsonLD() {
+		var scripts = document.querySelectorAll('script[type="application/ld+json"]');
+		for (var i = 0; i < scripts.length; i++) {
+			try {
+				var data = JSON.parse(scripts[i].textContent);
+				var items = Array.isArray(data) ? data : (data['@graph'] || [data]);
+				for (var j = 0; j < items.length; j++) {
+					if (items[j] && /Article|Posting|Report/.test(items[j]['@type'])) {
+						return items[j];
+					}
+				}
+			} catch (e) {
+			}
+		}
+		return {};
+	}
+	function classWeight(node) {
+		var weight = 0;
+		var hints = (node.className && node.className.baseVal === undefined ? node.className : '') + ' ' + (node.id || '');
+		if (negative.test(hints)) {
+			weight -= 25;
+		}
+		if (positive.test(hints)) {
+			weight += 25;
+		}
+		return weight;
+	}
+	function linkDensity(node) {
+		var textLength = node.innerText.length;
+		if (textLength === 0) {
+			return 0;
+		}
+		var linkLength = 0;
+		var links = node.querySelectorAll('a');
+		for (var i = 0; i < links.length; i++) {
+			linkLength += links[i].innerText.length;
+		}
+		return linkLength / textLength;
+	}
+	var ld = jsonLD();
+	var author = ld.author;
+	if (Array.isArray(author)) {
+		author = author[0];
+	}
+	var byline = meta(['author', 'article:author', 'parsely-author', 'dc.creator']) || (author && (author.name || author)) || '';
+	if (!byline) {
+		var bylineNode = document.querySelector('[rel="author"], [itemprop="author"], .byline, .author');
+		byline = bylineNode ? bylineNode.innerText.trim() : '';
+	}
+	var title = meta(['og:title', 'twitter:title']) || ld.headline || '';
+	if (!title) {
+		var h1 = document.querySelector('h1');
+		title = h1 ? h1.innerText.trim() : document.title;
+	}
+	var published = meta(['article:published_time', 'datePublished', 'date', 'dc.date', 'pubdate']) || ld.datePublished || '';
+	if (!published) {
+		var timeNode = document.querySelector('time[datetime]');
+		published = timeNode ? timeNode.getAttribute('datetime') : '';
+	}
+
+	var scores = new Map();
+	var paragraphs = document.body ? document.body.querySelectorAll('p, pre, td, article div') : [];
+	for (var i = 0; i < paragraphs.length; i++) {
+		var p = paragraphs[i];
+		if (p.tagName === 'DIV' && p.querySelector('p, div, table, ul, ol')) {
+			continue;
+		}
+		var text = p.innerText || '';
+		if (text.length < 25) {
+			continue;
+		}
+		var score = 1 + text.split(/[,，]/).length + Math.min(Math.floor(text.length / 100), 3);
+		var ancestors = [p.parentElement, p.parentElement && p.parentElement.parentElement];
+		for (var j = 0; j < ancestors.length; j++) {
+			var ancestor = ancestors[j];
+			if (!ancestor || ancestor === document.documentElement) {
+				continue;
+			}
+			if (!scores.has(ancestor)) {
+				scores.set(ancestor, classWeight(ancestor) + (/ARTICLE|MAIN|SECTION/.test(ancestor.tagName) ? 10 : 0));
+			}
+			scores.set(ancestor, scores.get(ancestor) + (j === 0 ? score : score / 2));
+		}
+	}
+	var best = null;
+	var bestScore = 0;
+	scores.forEach(function(score, node) {
+		score = score * (1 - linkDensity(node));
+		if (score > bestScore) {
+			best = node;
+			bestScore = score;
+		}
+	});
+	if (!best) {
+		best = document.querySelector('article, main, [role="main"]') || document.body;
+	}
+	if (!best) {
+		return {title: title, byline: byline, published: published, html: '', text: '', siteName: meta(['og:site_name'])};
+	}
+
+	var clone = best.cloneNode(true);
+	var junk = clone.querySelectorAll('script, style, noscript, iframe, form, button, input, select, textarea, nav, aside, footer, header, svg, object, embed');
+	for (var i = 0; i < junk.length; i++) {
+		junk[i].remove();
+	}
+	var blocks = clone.querySelectorAll('div, section, ul, ol, table');
+	for (var i = blocks.length - 1; i >= 0; i--) {
+		var block = blocks[i];
+		if (!block.parentNode) {
+			continue;
+		}
+		var blockText = block.textContent.trim();
+		if (classWeight(block) < 0 || blockText.length === 0 && !block.querySelector('img')) {
+			block.remove();
+		}
+	}
+	var nodes = clone.querySelectorAll('*');
+	for (var i = 0; i < nodes.length; i++) {
+		var attrs = Array.prototype.slice.call(nodes[i].attributes);
+		for (var j = 0; j < attrs.length; j++) {
+			if (!/^(href|src|alt|title|datetime)$/.test(attrs[j].name)) {
+				nodes[i].removeAttribute(attrs[j].name);
+			}
+		}
+	}
+	// innerText requires layout, so render the cleaned clone off screen to read it
+	var holder = document.createElement('div');
+	holder.style.cssText = 'position:absolute;left:-99999px;top:0;width:800px;';
+	holder.appendChild(clone);
+	document.body.appendChild(holder);
+	var cleanText = clone.innerText.replace(/\n{3,}/g, '\n\n').trim();
+	holder.remove();
+	var excerpt = meta(['og:description', 'description', 'twitter:description']) || ld.description || '';
+	return {title: title, byline: byline, published: published, siteName: meta(['og:site_name']) || (ld.publisher && ld.publisher.name) || '', excerpt: excerpt, html: clone.innerHTML.trim(), text: cleanText};
+})()`
+
+// layouts tried, in order, when parsing an article's published time
+var articleTimeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"January 2, 2006",
+}
+
+// Article is the main content of a page as determined by ExtractArticle.
+type Article struct {
+	Title         string    // og:title, the JSON-LD headline, the first h1 or the document title
+	Byline        string    // the author if one could be found
+	Published     string    // the published time as it appeared in the page
+	PublishedTime time.Time // Published parsed, the zero time if it was missing or in an unknown format
+	SiteName      string    // og:site_name or the JSON-LD publisher
+	Excerpt       string    // the page's description
+	HTML          string    // the cleaned article html, only structural tags and href/src/alt/title attributes remain
+	Text          string    // the cleaned article as text
+}
+
+// ExtractArticle finds the main content of the top level document using a readability style algorithm,
+// returning it cleaned of navigation, ads, scripts and other boilerplate along with its metadata.
+// Works best on news and blog style pages, on other pages the result is the largest block of text.
+func (t *Tab) ExtractArticle() (*Article, error) {
+	rro, err := t.evaluateScript(extractArticleScript, false)
+	if err != nil {
+		return nil, err
+	}
+
+	article := &Article{}
+	values, ok := rro.Value.(map[string]interface{})
+	if !ok {
+		return article, nil
+	}
+
+	article.Title, _ = values["title"].(string)
+	article.Byline, _ = values["byline"].(string)
+	article.Published, _ = values["published"].(string)
+	article.SiteName, _ = values["siteName"].(string)
+	article.Excerpt, _ = values["excerpt"].(string)
+	article.HTML, _ = values["html"].(string)
+	article.Text, _ = values["text"].(string)
+	article.PublishedTime = parseArticleTime(article.Published)
+	return article, nil
+}
+
+// parses an article's published time using articleTimeLayouts
+func parseArticleTime(published string) time.Time {
+	for _, layout := range articleTimeLayouts {
+		if parsed, err := time.Parse(layout, published); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,97 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"sync"
+)
+
+// number of DOM.getAttributes requests GetAttributesBatch keeps in flight
+const attributeBatchConcurrency = 16
+
+// GetAttributesBatch reads the attributes of many nodes at once, returning a map of nodeId to the node's
+// name,value pairs and replacing the attributes of the tab's cached elements with the values read, so
+// attributes removed since they were cached are dropped. The requests are made concurrently, so scraping
+// hundreds of nodes is not bound by one debugger round trip per node. Nodes which could not be read,
+// because they were removed or are not elements, are left out of the map and the error for the first of
+// them is returned along with the attributes which were read.
+func (t *Tab) GetAttributesBatch(nodeIds []int) (map[int]map[string]string, error) {
+	type attributeResult struct {
+		nodeId     int
+		attributes []string
+		err        error
+	}
+
+	jobs := make(chan int)
+	results := make(chan *attributeResult, len(nodeIds))
+	workers := attributeBatchConcurrency
+	if len(nodeIds) < workers {
+		workers = len(nodeIds)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for nodeId := range jobs {
+				attributes, err := t.DOM.GetAttributes(nodeId)
+				results <- &attributeResult{nodeId: nodeId, attributes: attributes, err: err}
+			}
+		}()
+	}
+	for _, nodeId := range nodeIds {
+		jobs <- nodeId
+	}
+	close(jobs)
+	wg.Wait()
+	close(results)
+
+	batch := make(map[int]map[string]string, len(nodeIds))
+	failed := make(map[int]error)
+	for result := range results {
+		if result.err != nil {
+			failed[result.nodeId] = result.err
+			continue
+		}
+		attributes := make(map[string]string, len(result.attributes)/2)
+		for i := 0; i+1 < len(result.attributes); i += 2 {
+			attributes[result.attributes[i]] = result.attributes[i+1]
+		}
+		batch[result.nodeId] = attributes
+
+		if ele, ok := t.getElement(result.nodeId); ok {
+			ele.setAttributes(attributes)
+		}
+	}
+
+	// report the first failure in the order the nodes were given
+	for _, nodeId := range nodeIds {
+		if err, ok := failed[nodeId]; ok {
+			return batch, err
+		}
+	}
+	return batch, nil
+}
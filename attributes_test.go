@@ -0,0 +1,58 @@
+package autogcd
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+func TestElementAttributesSnapshot(t *testing.T) {
+	ele := newReadyElement(nil, &gcdapi.DOMNode{NodeId: 1, NodeName: "INPUT", Attributes: []string{"id", "attr", "x", "y"}})
+
+	snapshot := ele.AttributesSnapshot()
+	if len(snapshot) != 2 || snapshot["x"] != "y" {
+		t.Fatalf("unexpected snapshot %v\n", snapshot)
+	}
+
+	// a snapshot must not change, or race, as DOM change events update the element
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			ele.updateAttribute(fmt.Sprintf("data-%d", i), "1")
+			ele.removeAttribute("x")
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		for range snapshot {
+		}
+		ele.AttributesSnapshot()
+	}
+	wg.Wait()
+
+	if len(snapshot) != 2 || snapshot["x"] != "y" {
+		t.Fatalf("snapshot was modified %v\n", snapshot)
+	}
+	if current := ele.AttributesSnapshot(); len(current) != 1001 {
+		t.Fatalf("expected the updated attributes got %d\n", len(current))
+	}
+}
+
+func TestElementSetAttributes(t *testing.T) {
+	ele := newReadyElement(nil, &gcdapi.DOMNode{NodeId: 1, NodeName: "INPUT", Attributes: []string{"id", "attr", "x", "y"}})
+
+	read := map[string]string{"id": "attr", "z": "1"}
+	ele.setAttributes(read)
+	read["z"] = "2"
+
+	snapshot := ele.AttributesSnapshot()
+	if len(snapshot) != 2 || snapshot["z"] != "1" {
+		t.Fatalf("expected the attributes read to replace the cached ones got %v\n", snapshot)
+	}
+	if _, ok := snapshot["x"]; ok {
+		t.Fatalf("expected the removed attribute to be dropped got %v\n", snapshot)
+	}
+}
@@ -47,27 +47,42 @@ or rewriting links etc.
 package autogcd
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"runtime"
 	"sync"
+	"time"
 
 	"github.com/wirepair/gcd"
 )
 
 type AutoGcd struct {
-	debugger *gcd.Gcd
-	settings *Settings
-	tabLock  *sync.RWMutex
-	tabs     map[string]*Tab
-	shutdown bool
+	debugger        *gcd.Gcd
+	settings        *Settings
+	tabLock         *sync.RWMutex
+	tabs            map[string]*Tab
+	shutdown        bool
+	userDir         string              // user data dir chrome was started with
+	tempDir         bool                // userDir is a temporary copy of a profile, remove it on shutdown
+	hookLock        *sync.Mutex         // protects shutdownHooks
+	shutdownHooks   []ShutdownFunc      // called by Shutdown, see OnShutdown
+	startTabs       map[string]struct{} // ids of the tabs opened by Start, for leak detection
+	browserContexts map[string]struct{} // ids of the browser contexts created by NewIsolatedTab
+	goroutines      int                 // go routines running when Start was called, for leak detection
 }
 
 // Creates a new AutoGcd based off the provided settings.
 func NewAutoGcd(settings *Settings) *AutoGcd {
-	auto := &AutoGcd{settings: settings}
+	auto := &AutoGcd{settings: settings, userDir: settings.userDir}
 	auto.tabLock = &sync.RWMutex{}
 	auto.tabs = make(map[string]*Tab)
+	auto.hookLock = &sync.Mutex{}
+	auto.startTabs = make(map[string]struct{})
+	auto.browserContexts = make(map[string]struct{})
 	auto.debugger = gcd.NewChromeDebugger()
 	auto.debugger.SetTerminationHandler(auto.defaultTerminationHandler)
 	if len(settings.extensions) > 0 {
@@ -78,6 +93,10 @@ func NewAutoGcd(settings *Settings) *AutoGcd {
 		auto.debugger.AddFlags(settings.flags)
 	}
 
+	if flags := settings.launchFlags(); len(flags) > 0 {
+		auto.debugger.AddFlags(flags)
+	}
+
 	if settings.timeout > 0 {
 		auto.debugger.SetTimeout(settings.timeout)
 	}
@@ -100,52 +119,92 @@ func (auto *AutoGcd) SetTerminationHandler(handler gcd.TerminatedHandler) {
 
 // Starts Google Chrome with debugging enabled.
 func (auto *AutoGcd) Start() error {
+	auto.goroutines = runtime.NumGoroutine()
 	if auto.settings.connectToInstance {
 		auto.debugger.ConnectToInstance(auto.settings.chromeHost, auto.settings.chromePort)
 	} else {
-		auto.debugger.StartProcess(auto.settings.chromePath, auto.settings.userDir, auto.settings.chromePort)
+		userDir, tempDir, err := auto.settings.prepareUserDir()
+		if err != nil {
+			return err
+		}
+		auto.userDir = userDir
+		auto.tempDir = tempDir
+		if len(auto.settings.preferences) > 0 && userDir != "" {
+			if err := writePreferences(userDir, auto.settings.preferences); err != nil {
+				return err
+			}
+		}
+		auto.debugger.StartProcess(auto.settings.chromePath, auto.userDir, auto.settings.chromePort)
 	}
 
-	tabs, err := auto.debugger.GetTargets()
+	targets, err := auto.listTargets()
 	if err != nil {
 		return err
 	}
 	auto.tabLock.Lock()
-	for _, tab := range tabs {
-		t, err := open(tab)
+	defer auto.tabLock.Unlock()
+	for _, info := range targets {
+		target, err := dialTarget(auto.debuggerAddr(), info)
+		if err != nil {
+			return err
+		}
+		t, err := auto.openTab(target)
 		if err != nil {
 			return err
 		}
-		auto.tabs[tab.Target.Id] = t
+		auto.tabs[info.Id] = t
+		auto.startTabs[info.Id] = struct{}{}
 	}
-	auto.tabLock.Unlock()
 	return nil
 }
 
-// Closes all tabs and shuts down the browser.
+// Runs the OnShutdown hooks, disposes the browser contexts of isolated tabs, closes all tabs and shuts down
+// the browser.
 func (auto *AutoGcd) Shutdown() error {
 	if auto.shutdown {
-		return errors.New("AutoGcd already shut down.")
+		return ErrShutdown
+	}
+
+	hookErr := auto.runShutdownHooks()
+	var report *ResourceLeakReport
+	if auto.settings.detectLeaks {
+		report = auto.leakedResources()
+	}
+
+	for _, contextId := range auto.BrowserContexts() {
+		if err := auto.DisposeBrowserContext(contextId); err != nil && hookErr == nil {
+			hookErr = err
+		}
 	}
 
 	auto.tabLock.Lock()
 	for _, tab := range auto.tabs {
 		tab.close() // exit go routines
-		auto.debugger.CloseTab(tab.ChromeTarget)
+		auto.closeTarget(tab)
+		if err := tab.removeTempDir(); err != nil {
+			tab.debugf("error removing temp dir: %s\n", err)
+		}
 
 	}
 	auto.tabLock.Unlock()
 
+	var err error
 	if !auto.settings.connectToInstance {
-		err := auto.debugger.ExitProcess()
-		if auto.settings.removeUserDir == true {
-			return os.RemoveAll(auto.settings.userDir)
+		err = auto.debugger.ExitProcess()
+		if auto.settings.removeUserDir == true || auto.tempDir {
+			err = os.RemoveAll(auto.userDir)
 		}
-		return err
+	} else {
+		auto.shutdown = true
 	}
 
-	auto.shutdown = true
-	return nil
+	if report != nil {
+		auto.reportLeaks(report)
+	}
+	if err == nil {
+		err = hookErr
+	}
+	return err
 }
 
 // Refreshs our internal list of tabs and return all tabs
@@ -156,18 +215,27 @@ func (auto *AutoGcd) RefreshTabList() (map[string]*Tab, error) {
 	for _, v := range knownTabs {
 		knownIds[v.Target.Id] = struct{}{}
 	}
-	newTabs, err := auto.debugger.GetNewTargets(knownIds)
+	targets, err := auto.listTargets()
 	if err != nil {
 		return nil, err
 	}
 
 	auto.tabLock.Lock()
-	for _, newTab := range newTabs {
-		t, err := open(newTab)
+	for _, info := range targets {
+		if _, ok := knownIds[info.Id]; ok {
+			continue
+		}
+		target, err := dialTarget(auto.debuggerAddr(), info)
 		if err != nil {
+			auto.tabLock.Unlock()
 			return nil, err
 		}
-		auto.tabs[newTab.Target.Id] = t
+		t, err := auto.openTab(target)
+		if err != nil {
+			auto.tabLock.Unlock()
+			return nil, err
+		}
+		auto.tabs[info.Id] = t
 	}
 	auto.tabLock.Unlock()
 	return auto.GetAllTabs(), nil
@@ -198,7 +266,7 @@ func (auto *AutoGcd) GetAllTabs() map[string]*Tab {
 
 // Activate the tab in the chrome UI
 func (auto *AutoGcd) ActivateTab(tab *Tab) error {
-	return auto.debugger.ActivateTab(tab.ChromeTarget)
+	return auto.debuggerRequest("/json/activate/"+tab.Target.Id, nil)
 }
 
 // Activate the tab in the chrome UI, by tab id
@@ -212,33 +280,179 @@ func (auto *AutoGcd) ActivateTabById(id string) error {
 
 // Creates a new tab
 func (auto *AutoGcd) NewTab() (*Tab, error) {
-	target, err := auto.debugger.NewTab()
+	return auto.NewTabWithOptions()
+}
+
+// NewTabWithOptions creates a new tab configured by opts, for example:
+//
+//	tab, err := auto.NewTabWithOptions(autogcd.WithNavigationTimeout(time.Minute), autogcd.WithoutConsoleDomain())
+//
+// Options are applied before the tab enables any debugger domains.
+func (auto *AutoGcd) NewTabWithOptions(opts ...TabOption) (*Tab, error) {
+	info := &gcd.TargetInfo{}
+	if err := auto.debuggerRequest("/json/new", info); err != nil {
+		return nil, &InvalidTabErr{Message: "unable to create tab: " + err.Error()}
+	}
+	target, err := dialTarget(auto.debuggerAddr(), info)
 	if err != nil {
 		return nil, &InvalidTabErr{Message: "unable to create tab: " + err.Error()}
 	}
 	auto.tabLock.Lock()
 	defer auto.tabLock.Unlock()
 
-	tab, err := open(target)
+	tab, err := auto.openTab(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	auto.tabs[info.Id] = tab
+	return tab, nil
+}
+
+// Opens the target and applies any tab related settings.
+func (auto *AutoGcd) openTab(target *targetConnection, opts ...TabOption) (*Tab, error) {
+	tab, err := open(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	tab.SetRateLimiter(auto.settings.rateLimiter)
+	tab.SetRobotsCache(auto.settings.robots)
+	tab.tempRoot = auto.settings.tabTempRoot
+	tab.retainOnFailure = auto.settings.retainFailedTabs
+	targetId := target.info.Id
+	tab.reattacher = func() (*targetConnection, error) {
+		return auto.reconnectTarget(targetId)
+	}
+	tab.findTab = auto.tabById
+	// pages opened by a tab belong to its browser context
+	tab.attachTab = func(targetId string) (*Tab, error) {
+		return auto.attachTarget(targetId, tab.browserContextId)
+	}
+	return tab, nil
+}
+
+// returns the tab for targetId, connecting to the target and adding it to the known tabs if it is new.
+func (auto *AutoGcd) attachTarget(targetId, browserContextId string) (*Tab, error) {
+	auto.tabLock.Lock()
+	defer auto.tabLock.Unlock()
+	if tab, ok := auto.tabs[targetId]; ok {
+		return tab, nil
+	}
+
+	tab, err := auto.openTargetId(targetId)
 	if err != nil {
 		return nil, err
 	}
-	auto.tabs[target.Target.Id] = tab
+	tab.browserContextId = browserContextId
+	auto.tabs[targetId] = tab
 	return tab, nil
 }
 
+// connects to an existing target and opens it as a tab, caller must hold tabLock.
+func (auto *AutoGcd) openTargetId(targetId string, opts ...TabOption) (*Tab, error) {
+	target, err := auto.reconnectTarget(targetId)
+	if err != nil {
+		return nil, err
+	}
+	return auto.openTab(target, opts...)
+}
+
+// calls the browser's /json endpoints to list, create, activate and close targets
+var targetListClient = &http.Client{Timeout: 10 * time.Second}
+
+// returns the host:port of the browser's debugger.
+func (auto *AutoGcd) debuggerAddr() string {
+	host := auto.settings.chromeHost
+	if host == "" {
+		host = "localhost"
+	}
+	return host + ":" + auto.settings.chromePort
+}
+
+// requests path from the browser's debugger, decoding the json response into v unless it is nil.
+func (auto *AutoGcd) debuggerRequest(path string, v interface{}) error {
+	resp, err := targetListClient.Get("http://" + auto.debuggerAddr() + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if v == nil {
+		_, err = io.Copy(ioutil.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// lists the targets the browser allows connecting to.
+func (auto *AutoGcd) listTargets() ([]*gcd.TargetInfo, error) {
+	targets := make([]*gcd.TargetInfo, 0)
+	if err := auto.debuggerRequest("/json", &targets); err != nil {
+		return nil, err
+	}
+
+	connectable := make([]*gcd.TargetInfo, 0, len(targets))
+	for _, info := range targets {
+		if info.WebSocketDebuggerUrl != "" {
+			connectable = append(connectable, info)
+		}
+	}
+	return connectable, nil
+}
+
+// opens a new connection to an existing target, used to re-attach tabs after the debugger detached and to
+// attach to popups. Only targetId is connected to, other targets are left alone.
+func (auto *AutoGcd) reconnectTarget(targetId string) (*targetConnection, error) {
+	targets, err := auto.listTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, info := range targets {
+		if info.Id == targetId {
+			return dialTarget(auto.debuggerAddr(), info)
+		}
+	}
+	return nil, &InvalidTabErr{Message: "target " + targetId + " is not available to attach to"}
+}
+
+// closes the tab's connection then the target itself.
+func (auto *AutoGcd) closeTarget(tab *Tab) error {
+	tab.target().Close()
+	return auto.debuggerRequest("/json/close/"+tab.Target.Id, nil)
+}
+
 // Closes the provided tab.
 func (auto *AutoGcd) CloseTab(tab *Tab) error {
 	tab.close() // kill listening go routines
 
-	if err := auto.debugger.CloseTab(tab.ChromeTarget); err != nil {
+	if err := auto.closeTarget(tab); err != nil {
+		return err
+	}
+	if err := tab.removeTempDir(); err != nil {
 		return err
 	}
 
 	auto.tabLock.Lock()
-	defer auto.tabLock.Unlock()
-
 	delete(auto.tabs, tab.Target.Id)
+	contextId := tab.browserContextId
+	_, isolated := auto.browserContexts[contextId]
+	if isolated {
+		// popups of the tab may still be open in the context
+		for _, other := range auto.tabs {
+			if other.browserContextId == contextId {
+				isolated = false
+				break
+			}
+		}
+	}
+	if isolated {
+		delete(auto.browserContexts, contextId)
+	}
+	auto.tabLock.Unlock()
+
+	if isolated {
+		return auto.disposeBrowserContext(contextId)
+	}
 	return nil
 }
 
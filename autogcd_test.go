@@ -13,6 +13,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/wirepair/gcd/gcdapi"
 )
 
 var (
@@ -295,3 +297,136 @@ func TestInstanceCloseTab(t *testing.T) {
 		t.Fatalf("error closed tab still in our map")
 	}
 }
+
+func TestCollectDiagnostics(t *testing.T) {
+	auto := testDefaultStartup(t)
+	defer auto.Shutdown()
+
+	dir, err := ioutil.TempDir("", "autogcd-diagnostics")
+	if err != nil {
+		t.Fatalf("error creating dir: %s\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	diagnostics, err := auto.CollectDiagnostics(dir)
+	if err != nil {
+		t.Fatalf("error collecting diagnostics: %s\n", err)
+	}
+
+	if diagnostics.Product == "" || diagnostics.Histograms == 0 {
+		t.Fatalf("expected version and histograms: %#v\n", diagnostics)
+	}
+
+	for _, name := range []string{DiagnosticsSummaryFile, DiagnosticsHistogramsFile} {
+		if _, err := os.Stat(dir + "/" + name); err != nil {
+			t.Fatalf("expected %s to be written: %s\n", name, err)
+		}
+	}
+}
+
+func TestShutdownDetectLeaks(t *testing.T) {
+	s := NewSettings(testPath, testRandomDir(t))
+	s.RemoveUserDir(true)
+	s.AddStartupFlags(testStartupFlags)
+	s.SetDebuggerPort(testRandomPort(t))
+	var report *ResourceLeakReport
+	s.DetectLeaks(func(r *ResourceLeakReport) {
+		report = r
+	})
+	auto := NewAutoGcd(s)
+	if err := auto.Start(); err != nil {
+		t.Fatalf("failed to start chrome: %s\n", err)
+	}
+	auto.SetTerminationHandler(nil)
+
+	hooked := false
+	auto.OnShutdown(func(auto *AutoGcd) error {
+		hooked = len(auto.GetAllTabs()) > 0
+		return nil
+	})
+
+	tab, err := auto.NewTab()
+	if err != nil {
+		t.Fatalf("error opening tab: %s\n", err)
+	}
+	tab.GetConsoleMessages(func(tab *Tab, message *gcdapi.ConsoleConsoleMessage) {})
+
+	if err := auto.Shutdown(); err != nil {
+		t.Fatalf("error shutting down: %s\n", err)
+	}
+	if !hooked {
+		t.Fatalf("expected shutdown hook to run before tabs were closed\n")
+	}
+	if report == nil || len(report.Tabs) != 1 || report.Tabs[0].Id != tab.Target.Id {
+		t.Fatalf("expected the new tab to be reported as leaked got %#v\n", report)
+	}
+	if len(report.Tabs[0].Subscriptions) != 1 || report.Tabs[0].Subscriptions[0] != "Console.messageAdded" {
+		t.Fatalf("expected Console.messageAdded to be reported got %v\n", report.Tabs[0].Subscriptions)
+	}
+}
+
+func TestCloseTabRemovesTempDir(t *testing.T) {
+	auto := testDefaultStartup(t)
+	defer auto.Shutdown()
+
+	tab, err := auto.NewTab()
+	if err != nil {
+		t.Fatalf("error opening tab: %s\n", err)
+	}
+	downloads, err := tab.EnableDownloads()
+	if err != nil {
+		t.Fatalf("error enabling downloads: %s\n", err)
+	}
+	dir, _ := tab.TempDir()
+	if !strings.HasPrefix(downloads, dir) {
+		t.Fatalf("expected downloads in %s got %s\n", dir, downloads)
+	}
+
+	if err := auto.CloseTab(tab); err != nil {
+		t.Fatalf("error closing tab: %s\n", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed\n", dir)
+	}
+}
+
+func TestNewIsolatedTab(t *testing.T) {
+	auto := testDefaultStartup(t)
+	defer auto.Shutdown()
+
+	shared, err := auto.NewTab()
+	if err != nil {
+		t.Fatalf("error opening tab: %s\n", err)
+	}
+	isolated, err := auto.NewIsolatedTab()
+	if err != nil {
+		t.Fatalf("error opening isolated tab: %s\n", err)
+	}
+	if len(auto.BrowserContexts()) != 1 {
+		t.Fatalf("expected one browser context got %v\n", auto.BrowserContexts())
+	}
+
+	if _, err := shared.Navigate(testServerAddr + "cookie1.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	if _, err := shared.EvaluateScript("document.cookie = 'shared=1'"); err != nil {
+		t.Fatalf("error setting cookie: %s\n", err)
+	}
+	if _, err := isolated.Navigate(testServerAddr + "cookie1.html"); err != nil {
+		t.Fatalf("Error navigating isolated tab: %s\n", err)
+	}
+	rro, err := isolated.EvaluateScript("document.cookie")
+	if err != nil {
+		t.Fatalf("error reading cookies: %s\n", err)
+	}
+	if cookies, _ := rro.Value.(string); strings.Contains(cookies, "shared=1") {
+		t.Fatalf("expected the isolated tab not to see the shared cookie got %s\n", cookies)
+	}
+
+	if err := auto.CloseTab(isolated); err != nil {
+		t.Fatalf("error closing isolated tab: %s\n", err)
+	}
+	if len(auto.BrowserContexts()) != 0 {
+		t.Fatalf("expected the browser context to be disposed got %v\n", auto.BrowserContexts())
+	}
+}
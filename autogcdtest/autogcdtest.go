@@ -0,0 +1,204 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+/*
+Package autogcdtest provides helpers for writing Go tests that drive Chrome with autogcd. A typical test:
+
+	func TestLogin(t *testing.T) {
+		browser := autogcdtest.NewTestBrowser(t)
+		tab := browser.NewTab(t)
+		if _, err := tab.Navigate(autogcdtest.FileServer(t, "testdata") + "login.html"); err != nil {
+			t.Fatalf("error navigating: %s\n", err)
+		}
+		...
+	}
+
+Chrome is found using the AUTOGCD_CHROME environment variable or well known install locations, tests are
+skipped if it is not found. Everything is cleaned up when the test finishes, and if it failed, a screenshot,
+the page source and url of every open test tab are written to AUTOGCD_ARTIFACTS (or the system temp dir).
+*/
+package autogcdtest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/wirepair/autogcd"
+)
+
+// StartupFlags used for every test browser, disabling first run ui and background network activity
+var StartupFlags = []string{"--test-type", "--ignore-certificate-errors", "--disable-new-tab-first-run", "--no-first-run", "--disable-translate", "--safebrowsing-disable-auto-update", "--disable-component-update", "--disable-background-networking"}
+
+// install locations checked, in order, when AUTOGCD_CHROME is not set
+var chromePaths = map[string][]string{
+	"windows": {`C:\Program Files (x86)\Google\Chrome\Application\chrome.exe`, `C:\Program Files\Google\Chrome\Application\chrome.exe`},
+	"darwin":  {"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome", "/Applications/Chromium.app/Contents/MacOS/Chromium"},
+	"linux":   {"chromium-browser", "chromium", "google-chrome", "google-chrome-stable"},
+}
+
+// ChromePath returns the path of the chrome executable from AUTOGCD_CHROME or well known install
+// locations, or an empty string if it could not be found.
+func ChromePath() string {
+	if path := os.Getenv("AUTOGCD_CHROME"); path != "" {
+		return path
+	}
+	for _, candidate := range chromePaths[runtime.GOOS] {
+		if path, err := exec.LookPath(candidate); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// SkipIfNoChrome skips the test if chrome can not be found, returning its path otherwise.
+func SkipIfNoChrome(t testing.TB) string {
+	path := ChromePath()
+	if path == "" {
+		t.Skip("chrome not found, set AUTOGCD_CHROME to its path")
+	}
+	return path
+}
+
+// Browser is a chrome instance started for a single test.
+type Browser struct {
+	*autogcd.AutoGcd
+	opts []autogcd.TabOption
+}
+
+// NewTestBrowser starts chrome (headless if AUTOGCD_HEADLESS is set) with its own profile and debugger port,
+// skipping the test if chrome is not found. Chrome is shut down and the profile removed when the test
+// finishes. Tabs created with Browser.NewTab are opened with opts.
+func NewTestBrowser(t testing.TB, opts ...autogcd.TabOption) *Browser {
+	path := SkipIfNoChrome(t)
+
+	userDir, err := ioutil.TempDir("", "autogcdtest")
+	if err != nil {
+		t.Fatalf("error creating profile dir: %s\n", err)
+	}
+
+	settings := autogcd.NewSettings(path, userDir)
+	settings.RemoveUserDir(true)
+	settings.AddStartupFlags(StartupFlags)
+	if os.Getenv("AUTOGCD_HEADLESS") != "" {
+		settings.AddStartupFlags([]string{"--headless", "--hide-scrollbars"})
+	}
+	settings.SetDebuggerPort(randomPort(t))
+
+	auto := autogcd.NewAutoGcd(settings)
+	if err := auto.Start(); err != nil {
+		os.RemoveAll(userDir)
+		t.Fatalf("failed to start chrome: %s\n", err)
+	}
+	auto.SetTerminationHandler(nil) // a test ending should not panic
+	t.Cleanup(func() {
+		auto.Shutdown()
+	})
+	return &Browser{AutoGcd: auto, opts: opts}
+}
+
+// NewTab opens a new tab for the test, closing it when the test finishes. If the test failed, the tab's
+// screenshot, page source and url are saved first, see ArtifactDir.
+func (b *Browser) NewTab(t testing.TB) *autogcd.Tab {
+	tab, err := b.NewTabWithOptions(b.opts...)
+	if err != nil {
+		t.Fatalf("error creating tab: %s\n", err)
+	}
+	t.Cleanup(func() {
+		if t.Failed() {
+			CaptureArtifacts(t, tab)
+		}
+		b.CloseTab(tab)
+	})
+	return tab
+}
+
+// ArtifactDir returns the directory artifacts of the test are written to, AUTOGCD_ARTIFACTS or the system
+// temp dir, followed by the test's name.
+func ArtifactDir(t testing.TB) string {
+	root := os.Getenv("AUTOGCD_ARTIFACTS")
+	if root == "" {
+		root = filepath.Join(os.TempDir(), "autogcd-artifacts")
+	}
+	return filepath.Join(root, safeName(t.Name()))
+}
+
+// CaptureArtifacts writes a screenshot, the page source and current url of tab to ArtifactDir, logging
+// where they were written. Failures to capture are logged rather than failing the test again.
+func CaptureArtifacts(t testing.TB, tab *autogcd.Tab) {
+	dir := ArtifactDir(t)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Logf("unable to create artifact dir: %s\n", err)
+		return
+	}
+	prefix := filepath.Join(dir, safeName(tab.Target.Id))
+
+	if img, err := tab.GetScreenShot(); err != nil {
+		t.Logf("unable to capture screenshot: %s\n", err)
+	} else if err := ioutil.WriteFile(prefix+".png", img, 0644); err != nil {
+		t.Logf("unable to write screenshot: %s\n", err)
+	}
+
+	if source, err := tab.GetPageSource(0); err != nil {
+		t.Logf("unable to capture page source: %s\n", err)
+	} else if err := ioutil.WriteFile(prefix+".html", []byte(source), 0644); err != nil {
+		t.Logf("unable to write page source: %s\n", err)
+	}
+
+	if url, err := tab.GetCurrentUrl(); err == nil {
+		ioutil.WriteFile(prefix+".url", []byte(url+"\n"), 0644)
+	}
+	t.Logf("artifacts for tab %s written to %s.*\n", tab.Target.Id, prefix)
+}
+
+// FileServer serves dir over http until the test finishes, returning its base url ending with a slash.
+func FileServer(t testing.TB, dir string) string {
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	t.Cleanup(server.Close)
+	return server.URL + "/"
+}
+
+func randomPort(t testing.TB) string {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("error finding a free port: %s\n", err)
+	}
+	defer l.Close()
+	return fmt.Sprintf("%d", l.Addr().(*net.TCPAddr).Port)
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// makes a test or tab name safe to use as a file name
+func safeName(name string) string {
+	return unsafeNameChars.ReplaceAllString(name, "_")
+}
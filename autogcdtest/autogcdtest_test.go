@@ -0,0 +1,26 @@
+package autogcdtest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChromePathFromEnv(t *testing.T) {
+	t.Setenv("AUTOGCD_CHROME", "/opt/chrome/chrome")
+	if path := ChromePath(); path != "/opt/chrome/chrome" {
+		t.Fatalf("expected AUTOGCD_CHROME to be used got %s\n", path)
+	}
+}
+
+func TestArtifactDir(t *testing.T) {
+	root := filepath.Join(os.TempDir(), "artifacts")
+	t.Setenv("AUTOGCD_ARTIFACTS", root)
+	t.Run("sub test/with spaces", func(t *testing.T) {
+		dir := ArtifactDir(t)
+		if !strings.HasPrefix(dir, root) || strings.ContainsAny(filepath.Base(dir), " /") {
+			t.Fatalf("expected a safe directory under %s got %s\n", root, dir)
+		}
+	})
+}
@@ -0,0 +1,192 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+	"time"
+)
+
+// Returns the document's scroll height and the number of elements matching the selector (all elements if empty).
+const measureScrollScript = `(function(selector) {
+	var root = document.documentElement;
+	return {height: root ? root.scrollHeight : 0, count: document.querySelectorAll(selector || '*').length};
+})(%s)`
+
+const scrollToBottomScript = `window.scrollTo(0, document.documentElement.scrollHeight)`
+
+// Why AutoScroll stopped
+type ScrollStopReason uint8
+
+const (
+	ScrollNoNewContent ScrollStopReason = 0x0 // no new content appeared after StopAfterNoChange steps
+	ScrollMaxSteps     ScrollStopReason = 0x1 // MaxSteps was reached
+	ScrollMaxHeight    ScrollStopReason = 0x2 // the page grew beyond MaxHeight
+	ScrollMaxTime      ScrollStopReason = 0x3 // MaxTime elapsed
+)
+
+var scrollStopReasonMap = map[ScrollStopReason]string{
+	ScrollNoNewContent: "ScrollNoNewContent",
+	ScrollMaxSteps:     "ScrollMaxSteps",
+	ScrollMaxHeight:    "ScrollMaxHeight",
+	ScrollMaxTime:      "ScrollMaxTime",
+}
+
+func (reason ScrollStopReason) String() string {
+	if s, ok := scrollStopReasonMap[reason]; ok {
+		return s
+	}
+	return ""
+}
+
+// ScrollOptions for AutoScroll, zero values use the defaults.
+type ScrollOptions struct {
+	ItemSelector      string        // count elements matching this selector to detect new content, defaults to all elements
+	MaxSteps          int           // maximum number of times to scroll, defaults to 50
+	MaxHeight         int           // stop once the document is taller than this many pixels, 0 for no limit
+	MaxTime           time.Duration // stop after this long, defaults to 2 minutes
+	StepTimeout       time.Duration // how long to wait for new content after each scroll, defaults to 3 seconds
+	IdleTime          time.Duration // once new content appears, wait for the network to be idle this long, defaults to 500ms
+	StopAfterNoChange int           // stop after this many consecutive scrolls produce no new content, defaults to 2
+}
+
+// ScrollStep is how much new content appeared after a single scroll.
+type ScrollStep struct {
+	Step        int           // 1 based step number
+	Height      int           // document scroll height after the step
+	NewItems    int           // increase in the number of elements matching ItemSelector
+	HeightDelta int           // increase in the document scroll height
+	Elapsed     time.Duration // how long the step took
+}
+
+// ScrollReport returned by AutoScroll
+type ScrollReport struct {
+	Steps      []*ScrollStep    // every scroll performed
+	Items      int              // final number of elements matching ItemSelector
+	Height     int              // final document scroll height
+	Duration   time.Duration    // total time spent scrolling
+	StopReason ScrollStopReason // why scrolling stopped
+}
+
+// AutoScroll harvests infinite scroll pages by repeatedly scrolling to the bottom of the top level document and
+// waiting for new content, measured by the number of elements matching ItemSelector and the document height.
+// Pass nil for the default options. Returns a report of how much content each step added and why it stopped.
+func (t *Tab) AutoScroll(opts *ScrollOptions) (*ScrollReport, error) {
+	if opts == nil {
+		opts = &ScrollOptions{}
+	}
+	maxSteps := opts.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = 50
+	}
+	maxTime := opts.MaxTime
+	if maxTime <= 0 {
+		maxTime = 2 * time.Minute
+	}
+	stepTimeout := opts.StepTimeout
+	if stepTimeout <= 0 {
+		stepTimeout = 3 * time.Second
+	}
+	idleTime := opts.IdleTime
+	if idleTime <= 0 {
+		idleTime = 500 * time.Millisecond
+	}
+	stopAfter := opts.StopAfterNoChange
+	if stopAfter <= 0 {
+		stopAfter = 2
+	}
+
+	if err := t.enableNetwork(); err != nil {
+		return nil, err
+	}
+
+	measureScript := fmt.Sprintf(measureScrollScript, jsQuote(opts.ItemSelector))
+	height, items, err := t.measureScroll(measureScript)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ScrollReport{Steps: make([]*ScrollStep, 0), StopReason: ScrollMaxSteps}
+	start := time.Now()
+	noChange := 0
+	for i := 1; i <= maxSteps; i++ {
+		stepStart := time.Now()
+		if _, err := t.evaluateScript(scrollToBottomScript, false); err != nil {
+			return nil, err
+		}
+
+		newHeight, newItems := height, items
+		changed := t.WaitFor(100*time.Millisecond, stepTimeout, func(tab *Tab) bool {
+			newHeight, newItems, err = tab.measureScroll(measureScript)
+			return err == nil && (newHeight > height || newItems > items)
+		}) == nil
+
+		if changed {
+			// let the rest of the batch load before measuring
+			if err := t.WaitNetworkIdle(idleTime, stepTimeout); err != nil {
+				t.debugf("network did not become idle after scroll: %s\n", err)
+			}
+			if newHeight, newItems, err = t.measureScroll(measureScript); err != nil {
+				return nil, err
+			}
+			noChange = 0
+		} else {
+			noChange++
+		}
+
+		report.Steps = append(report.Steps, &ScrollStep{Step: i, Height: newHeight, NewItems: newItems - items, HeightDelta: newHeight - height, Elapsed: time.Now().Sub(stepStart)})
+		height, items = newHeight, newItems
+
+		if noChange >= stopAfter {
+			report.StopReason = ScrollNoNewContent
+			break
+		}
+		if opts.MaxHeight > 0 && height > opts.MaxHeight {
+			report.StopReason = ScrollMaxHeight
+			break
+		}
+		if time.Now().Sub(start) >= maxTime {
+			report.StopReason = ScrollMaxTime
+			break
+		}
+	}
+
+	report.Height = height
+	report.Items = items
+	report.Duration = time.Now().Sub(start)
+	return report, nil
+}
+
+// returns the document height and item count using the measureScrollScript
+func (t *Tab) measureScroll(measureScript string) (int, int, error) {
+	rro, err := t.evaluateScript(measureScript, false)
+	if err != nil {
+		return 0, 0, err
+	}
+	values, _ := rro.Value.(map[string]interface{})
+	height, _ := values["height"].(float64)
+	count, _ := values["count"].(float64)
+	return int(height), int(count), nil
+}
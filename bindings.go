@@ -0,0 +1,90 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+// receives the string passed to a page binding, see addBinding
+type bindingFunc func(payload string)
+
+// adds a function named name to the global object of every frame (and every document loaded
+// later) which calls bindingFn with its single string argument. Used to report data from
+// instrumentation injected into the page back to Go.
+func (t *Tab) addBinding(name string, bindingFn bindingFunc) error {
+	if err := t.enableRuntime(); err != nil {
+		return err
+	}
+
+	t.bindingLock.Lock()
+	_, exists := t.bindings[name]
+	t.bindings[name] = bindingFn
+	t.bindingLock.Unlock()
+
+	if exists {
+		return nil
+	}
+
+	if _, err := t.Runtime.AddBinding(name, 0); err != nil {
+		t.bindingLock.Lock()
+		delete(t.bindings, name)
+		t.bindingLock.Unlock()
+		return err
+	}
+	return nil
+}
+
+// stops receiving calls for the binding, the function remains on the page's global objects.
+func (t *Tab) removeBinding(name string) error {
+	t.bindingLock.Lock()
+	delete(t.bindings, name)
+	t.bindingLock.Unlock()
+
+	_, err := t.Runtime.RemoveBinding(name)
+	return err
+}
+
+// called for every Runtime.bindingCalled event, see tab_subscribers.go
+func (t *Tab) handleBindingCalled(name, payload string) {
+	t.bindingLock.RLock()
+	bindingFn := t.bindings[name]
+	t.bindingLock.RUnlock()
+
+	if bindingFn != nil {
+		bindingFn(payload)
+	}
+}
+
+// enables the Runtime domain if it is not already enabled, required for binding events.
+func (t *Tab) enableRuntime() error {
+	t.bindingLock.Lock()
+	defer t.bindingLock.Unlock()
+
+	if t.runtimeEnabled {
+		return nil
+	}
+	if _, err := t.Runtime.Enable(); err != nil {
+		return err
+	}
+	t.runtimeEnabled = true
+	return nil
+}
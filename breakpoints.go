@@ -0,0 +1,117 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"github.com/wirepair/gcd"
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// DOMBreakpointType for Element.SetDOMBreakpoint
+type DOMBreakpointType string
+
+const (
+	BreakOnSubtreeModified   DOMBreakpointType = "subtree-modified"   // a child was added or removed
+	BreakOnAttributeModified DOMBreakpointType = "attribute-modified" // an attribute was changed
+	BreakOnNodeRemoved       DOMBreakpointType = "node-removed"       // the node was removed
+)
+
+// PauseInfo passed to the PausedHandlerFunc when a breakpoint is hit.
+type PauseInfo struct {
+	Reason         string                      // XHR, EventListener, DOM, other etc
+	Data           map[string]interface{}      // breakpoint specific data, such as the url for XHR breakpoints
+	CallFrames     []*gcdapi.DebuggerCallFrame // call stack at the time the page paused
+	HitBreakpoints []string                    // ids of script breakpoints that were hit, if any
+}
+
+// SetPausedHandler is called whenever the page pauses on a breakpoint. The page stays paused until
+// the handler returns and is then resumed automatically. While paused, scripts may only be evaluated
+// against the call frames with Debugger.EvaluateOnCallFrame, EvaluateScript will not return.
+// Pass nil to simply resume when a breakpoint is hit.
+func (t *Tab) SetPausedHandler(handlerFn PausedHandlerFunc) {
+	t.pauseLock.Lock()
+	t.pausedHandler = handlerFn
+	t.pauseLock.Unlock()
+	t.subscribePaused()
+}
+
+// SetXHRBreakpoint pauses the page when an XMLHttpRequest or fetch is made to a url containing urlSubstring.
+// Pass an empty string to break on every request.
+func (t *Tab) SetXHRBreakpoint(urlSubstring string) error {
+	t.subscribePaused()
+	_, err := t.DOMDebugger.SetXHRBreakpoint(urlSubstring)
+	return err
+}
+
+// RemoveXHRBreakpoint removes a breakpoint set by SetXHRBreakpoint.
+func (t *Tab) RemoveXHRBreakpoint(urlSubstring string) error {
+	_, err := t.DOMDebugger.RemoveXHRBreakpoint(urlSubstring)
+	return err
+}
+
+// SetEventListenerBreakpoint pauses the page before any listener for eventName (click, submit, message...) is run.
+func (t *Tab) SetEventListenerBreakpoint(eventName string) error {
+	t.subscribePaused()
+	_, err := t.DOMDebugger.SetEventListenerBreakpoint(eventName, "")
+	return err
+}
+
+// RemoveEventListenerBreakpoint removes a breakpoint set by SetEventListenerBreakpoint.
+func (t *Tab) RemoveEventListenerBreakpoint(eventName string) error {
+	_, err := t.DOMDebugger.RemoveEventListenerBreakpoint(eventName, "")
+	return err
+}
+
+// makes sure paused events are handled so the page does not stay paused forever, subscribing once
+// per connection.
+func (t *Tab) subscribePaused() {
+	t.pauseLock.Lock()
+	defer t.pauseLock.Unlock()
+	connection := t.target()
+	if t.pausedTarget == connection {
+		return
+	}
+	t.pausedTarget = connection
+
+	t.Subscribe("Debugger.paused", func(target *gcd.ChromeTarget, payload []byte) {
+		// resume even if the handler panics
+		defer func() {
+			if _, err := t.Debugger.Resume(); err != nil {
+				t.debugf("error resuming after pause: %s\n", err)
+			}
+		}()
+
+		t.pauseLock.Lock()
+		handlerFn := t.pausedHandler
+		t.pauseLock.Unlock()
+
+		message := &gcdapi.DebuggerPausedEvent{}
+		if err := json.Unmarshal(payload, message); err == nil && handlerFn != nil {
+			p := message.Params
+			handlerFn(t, &PauseInfo{Reason: p.Reason, Data: p.Data, CallFrames: p.CallFrames, HitBreakpoints: p.HitBreakpoints})
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package autogcd
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+func TestTabResumesAfterPausedHandlerPanics(t *testing.T) {
+	tab, _, closeServers := testConnectedTab(t)
+	defer closeServers()
+	tab.stats = newStatsRecorder()
+	tab.errorLock = &sync.Mutex{}
+	tab.pauseLock = &sync.Mutex{}
+
+	errCh := make(chan error, 1)
+	tab.errorHandler = func(tab *Tab, err error) { errCh <- err }
+	resumedCh := make(chan string, 1)
+	debugger := newTestTargeter(func(method string) string {
+		resumedCh <- method
+		return `{"id":1,"result":{}}`
+	})
+	defer close(debugger.doneCh)
+	tab.Debugger = gcdapi.NewDebugger(debugger)
+
+	tab.pausedHandler = func(tab *Tab, info *PauseInfo) { panic("handler failed") }
+	tab.subscribePaused()
+	tab.target().dispatch([]byte(`{"method":"Debugger.paused","params":{"reason":"other","callFrames":[]}}`))
+
+	select {
+	case method := <-resumedCh:
+		if method != "Debugger.resume" {
+			t.Fatalf("expected Debugger.resume got %s\n", method)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the page to be resumed")
+	}
+	if _, ok := (<-errCh).(*CallbackPanicErr); !ok {
+		t.Fatalf("expected the panic to be reported")
+	}
+	tab.target().Close()
+}
@@ -0,0 +1,78 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"runtime/debug"
+	"time"
+
+	"github.com/wirepair/gcd/gcdmessage"
+)
+
+// SetCallTimeout sets how long every debugger request made by this tab waits for chrome to respond
+// before failing, the default is 120 seconds. Lower it so a wedged renderer fails calls quickly
+// rather than hanging them.
+func (t *Tab) SetCallTimeout(timeout time.Duration) {
+	t.SetApiTimeout(timeout)
+}
+
+// CallWithTimeout runs fn, which should make debugger requests for this tab, failing with a TimeoutErr if
+// it does not return within timeout. If fn fails because a request went unanswered (the message was lost
+// on a websocket write, or chrome dropped it) it is retried once, so fn should be safe to repeat.
+// The call is abandoned rather than cancelled on timeout, it finishes in the background once the
+// tab's call timeout (see SetCallTimeout) expires. A panic in fn is returned as a CallbackPanicErr.
+func (t *Tab) CallWithTimeout(timeout time.Duration, fn func() error) error {
+	deadline := time.Now().Add(timeout)
+	err := t.callBefore(deadline, fn)
+	if _, ok := err.(*gcdmessage.ChromeApiTimeoutErr); ok && time.Now().Before(deadline) {
+		t.debugf("retrying call after unanswered request\n")
+		err = t.callBefore(deadline, fn)
+	}
+	return err
+}
+
+func (t *Tab) callBefore(deadline time.Time, fn func() error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errCh <- &CallbackPanicErr{Message: "call", Value: r, Stack: debug.Stack()}
+			}
+		}()
+		errCh <- fn()
+	}()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-timer.C:
+		return &TimeoutErr{Message: "waiting for debugger call to complete"}
+	case <-t.crashedNotifyCh:
+		return t.CrashErr()
+	}
+}
@@ -0,0 +1,50 @@
+package autogcd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wirepair/gcd/gcdmessage"
+)
+
+func TestTabCallWithTimeout(t *testing.T) {
+	tab := &Tab{}
+
+	calls := 0
+	err := tab.CallWithTimeout(time.Second, func() error {
+		calls++
+		if calls == 1 {
+			return &gcdmessage.ChromeApiTimeoutErr{}
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("expected unanswered call to be retried once, got %d calls: %v\n", calls, err)
+	}
+
+	calls = 0
+	expected := errors.New("protocol error")
+	err = tab.CallWithTimeout(time.Second, func() error {
+		calls++
+		return expected
+	})
+	if err != expected || calls != 1 {
+		t.Fatalf("expected other errors to not be retried, got %d calls: %v\n", calls, err)
+	}
+
+	err = tab.CallWithTimeout(50*time.Millisecond, func() error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected ErrTimeout got %v\n", err)
+	}
+
+	err = tab.CallWithTimeout(time.Second, func() error {
+		panic("boom")
+	})
+	if !errors.Is(err, ErrCallbackPanic) {
+		t.Fatalf("expected ErrCallbackPanic got %v\n", err)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+// Looks for known captcha widgets, challenge scripts and block pages, returning the
+// first match as {kind: ..., evidence: ...} or null.
+const detectCaptchaScript = `(function() {
+	var widgets = [
+		{kind: 'recaptcha', pattern: /google\.com\/recaptcha|recaptcha\.net\/recaptcha|gstatic\.com\/recaptcha/i, selector: '.g-recaptcha, #g-recaptcha-response'},
+		{kind: 'hcaptcha', pattern: /hcaptcha\.com/i, selector: '.h-captcha'},
+		{kind: 'turnstile', pattern: /challenges\.cloudflare\.com\/turnstile/i, selector: '.cf-turnstile'},
+		{kind: 'datadome', pattern: /captcha-delivery\.com/i, selector: ''},
+		{kind: 'perimeterx', pattern: /px-cdn\.net|perimeterx\.net|px-captcha/i, selector: '#px-captcha'}
+	];
+	var sources = [];
+	var frames = document.querySelectorAll('iframe[src]');
+	for (var i = 0; i < frames.length; i++) {
+		sources.push(frames[i].src);
+	}
+	var scripts = document.querySelectorAll('script[src]');
+	for (var i = 0; i < scripts.length; i++) {
+		sources.push(scripts[i].src);
+	}
+	for (var i = 0; i < widgets.length; i++) {
+		for (var j = 0; j < sources.length; j++) {
+			if (widgets[i].pattern.test(sources[j])) {
+				return {kind: widgets[i].kind, evidence: sources[j]};
+			}
+		}
+		if (widgets[i].selector && document.querySelector(widgets[i].selector)) {
+			return {kind: widgets[i].kind, evidence: widgets[i].selector};
+		}
+	}
+	var title = document.title || '';
+	var interstitials = [
+		{pattern: /^just a moment\.\.\.$|^attention required!? \| cloudflare$/i, selector: '#challenge-form, #cf-challenge-running, #challenge-running'},
+		{pattern: /^access denied$/i, selector: ''},
+		{pattern: /are you a (human|robot)|verify you are (a )?human|unusual traffic/i, selector: ''}
+	];
+	for (var i = 0; i < interstitials.length; i++) {
+		if (interstitials[i].pattern.test(title)) {
+			return {kind: 'interstitial', evidence: 'title: ' + title};
+		}
+		if (interstitials[i].selector && document.querySelector(interstitials[i].selector)) {
+			return {kind: 'interstitial', evidence: interstitials[i].selector};
+		}
+	}
+	var text = document.body ? document.body.innerText.substring(0, 2000) : '';
+	if (interstitials[2].pattern.test(text)) {
+		return {kind: 'interstitial', evidence: 'text: ' + text.match(interstitials[2].pattern)[0]};
+	}
+	return null;
+})()`
+
+// Types of captchas or block pages detected by DetectCaptcha
+type CaptchaKind uint8
+
+const (
+	NoCaptcha           CaptchaKind = 0x0
+	ReCaptcha           CaptchaKind = 0x1
+	HCaptcha            CaptchaKind = 0x2
+	TurnstileCaptcha    CaptchaKind = 0x3
+	DataDomeCaptcha     CaptchaKind = 0x4
+	PerimeterXCaptcha   CaptchaKind = 0x5
+	InterstitialCaptcha CaptchaKind = 0x6 // a challenge or block page, e.g. cloudflare's "Just a moment..."
+)
+
+var captchaScriptKinds = map[string]CaptchaKind{
+	"recaptcha":    ReCaptcha,
+	"hcaptcha":     HCaptcha,
+	"turnstile":    TurnstileCaptcha,
+	"datadome":     DataDomeCaptcha,
+	"perimeterx":   PerimeterXCaptcha,
+	"interstitial": InterstitialCaptcha,
+}
+
+var captchaKindMap = map[CaptchaKind]string{
+	NoCaptcha:           "NoCaptcha",
+	ReCaptcha:           "ReCaptcha",
+	HCaptcha:            "HCaptcha",
+	TurnstileCaptcha:    "TurnstileCaptcha",
+	DataDomeCaptcha:     "DataDomeCaptcha",
+	PerimeterXCaptcha:   "PerimeterXCaptcha",
+	InterstitialCaptcha: "InterstitialCaptcha",
+}
+
+func (kind CaptchaKind) String() string {
+	if s, ok := captchaKindMap[kind]; ok {
+		return s
+	}
+	return ""
+}
+
+// CaptchaResult returned from DetectCaptcha.
+type CaptchaResult struct {
+	Detected bool        // true if a captcha or block page was found
+	Kind     CaptchaKind // what was found
+	Evidence string      // the url, selector or text that matched
+}
+
+// DetectCaptcha checks the top level document for known captcha widgets (reCAPTCHA, hCaptcha, Turnstile etc),
+// their iframes or scripts, and for interstitial challenge/block pages. Crawlers can use this to route
+// pages to a manual queue instead of silently extracting nothing.
+func (t *Tab) DetectCaptcha() (*CaptchaResult, error) {
+	rro, err := t.evaluateScript(detectCaptchaScript, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CaptchaResult{Kind: NoCaptcha}
+	found, ok := rro.Value.(map[string]interface{})
+	if !ok {
+		return result, nil
+	}
+
+	kind, _ := found["kind"].(string)
+	result.Kind = captchaScriptKinds[kind]
+	result.Evidence, _ = found["evidence"].(string)
+	result.Detected = result.Kind != NoCaptcha
+	return result, nil
+}
@@ -0,0 +1,249 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Arms a one time capturing listener recording whether an event of type reached the element, so clicks
+// swallowed by an overlay can be told apart from clicks which had no visible effect.
+const armClickEventFunction = `function(type) {
+	var element = this;
+	element.__autogcdEventSeen = false;
+	element.addEventListener(type, function() { element.__autogcdEventSeen = true; }, {capture: true, once: true});
+}`
+
+const clickEventSeenFunction = `function() {
+	return this.__autogcdEventSeen === true;
+}`
+
+// Returns a point, relative to the element's own viewport, where hit testing finds the element or one of its
+// descendants, along with the element's bounding rectangle. Tries the center first then a grid across
+// the element so a partially covered element is still clicked on its visible part. Returns null if every
+// point is covered or outside the viewport.
+const clickablePointFunction = `function() {
+	var rect = this.getBoundingClientRect();
+	if (rect.width === 0 || rect.height === 0) {
+		return null;
+	}
+	var root = this.getRootNode();
+	if (!root.elementFromPoint) {
+		root = document;
+	}
+	var fractions = [[0.5, 0.5], [0.25, 0.25], [0.75, 0.25], [0.25, 0.75], [0.75, 0.75], [0.5, 0.1], [0.5, 0.9], [0.1, 0.5], [0.9, 0.5]];
+	for (var i = 0; i < fractions.length; i++) {
+		var x = rect.left + rect.width * fractions[i][0];
+		var y = rect.top + rect.height * fractions[i][1];
+		var hit = root.elementFromPoint(x, y);
+		if (hit !== null && (hit === this || this.contains(hit))) {
+			return {x: x, y: y, left: rect.left, top: rect.top};
+		}
+	}
+	return null;
+}`
+
+// default time ClickWithOptions waits for an effect after each click
+const defaultClickVerifyTimeout = 2 * time.Second
+
+// ClickVerificationErr is returned by ClickWithOptions when no click had the expected effect.
+type ClickVerificationErr struct {
+	Message string
+}
+
+func (e *ClickVerificationErr) Error() string {
+	return "click not verified: " + e.Message
+}
+
+// Unwrap returns ErrClickNotVerified so the error can be matched with errors.Is
+func (e *ClickVerificationErr) Unwrap() error {
+	return ErrClickNotVerified
+}
+
+// ClickOptions for Element.ClickWithOptions. Each Verify option set must be satisfied for a click to count,
+// if none are set the element is clicked once like Click.
+type ClickOptions struct {
+	VerifyNavigation bool          // the top frame must start navigating or change its url, including history changes
+	VerifyEvent      string        // a DOM event of this type, such as click or mousedown, must reach the element
+	VerifySelector   string        // an element matching this selector must exist after the click
+	VerifyConsole    string        // a console message containing this text must be logged
+	Timeout          time.Duration // time to wait for the effects after each click, 0 defaults to 2 seconds
+	Retries          int           // clicks to retry when nothing happened, 0 defaults to 2, negative disables retries
+}
+
+func (opts *ClickOptions) verifies() bool {
+	return opts.VerifyNavigation || opts.VerifyEvent != "" || opts.VerifySelector != "" || opts.VerifyConsole != ""
+}
+
+// ClickWithOptions clicks the element and confirms the click had the effects set in opts. When nothing
+// happened, which is usually a click swallowed by an overlay such as a cookie banner, the element is
+// scrolled into view and clicked again at a point hit testing shows is not covered, up to opts.Retries
+// times. Only use effects which can not happen without the click, otherwise a click which worked may be
+// repeated. Returns a ClickVerificationErr if no click was verified. Runs through the tab's middleware as
+// a single Click action, see Tab.Use.
+func (e *Element) ClickWithOptions(opts *ClickOptions) error {
+	if opts == nil || !opts.verifies() {
+		return e.Click()
+	}
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = defaultClickVerifyTimeout
+	}
+	retries := opts.Retries
+	if retries == 0 {
+		retries = 2
+	} else if retries < 0 {
+		retries = 0
+	}
+
+	return e.tab.runAction(&Action{Name: ActionClick, Tab: e.tab, Element: e}, func(action *Action) error {
+		var consoleSeen int32
+		if opts.VerifyConsole != "" {
+			if err := e.tab.enableRuntime(); err != nil {
+				return err
+			}
+			stop := e.tab.watchConsole(func(tab *Tab, entry *ConsoleEntry) {
+				if strings.Contains(entry.Text, opts.VerifyConsole) {
+					atomic.StoreInt32(&consoleSeen, 1)
+				}
+			})
+			defer stop()
+		}
+
+		var lastErr error
+		for attempt := 0; attempt <= retries; attempt++ {
+			requests, loaderId, url := e.tab.navigationState()
+			if opts.VerifyEvent != "" {
+				if _, err := e.callFunction(armClickEventFunction, opts.VerifyEvent); err != nil {
+					return err
+				}
+			}
+
+			if attempt == 0 {
+				lastErr = e.click()
+			} else {
+				e.tab.debugf("click on %d had no effect, retrying (attempt %d)\n", e.NodeId(), attempt+1)
+				lastErr = e.clickVisiblePoint()
+			}
+
+			verified := func(tab *Tab) bool {
+				if opts.VerifyNavigation {
+					currentRequests, currentLoaderId, currentUrl := tab.navigationState()
+					if currentRequests == requests && currentLoaderId == loaderId && currentUrl == url {
+						return false
+					}
+				}
+				if opts.VerifyConsole != "" && atomic.LoadInt32(&consoleSeen) == 0 {
+					return false
+				}
+				if opts.VerifySelector != "" {
+					rro, err := tab.evaluateScript("document.querySelector("+jsQuote(opts.VerifySelector)+") !== null", false)
+					if err != nil || rro.Value != true {
+						return false
+					}
+				}
+				if opts.VerifyEvent != "" {
+					// fails if the click removed the element or navigated away
+					rro, err := e.callFunction(clickEventSeenFunction)
+					if err != nil || rro.Value != true {
+						return false
+					}
+				}
+				return true
+			}
+			if lastErr == nil && e.tab.WaitFor(50*time.Millisecond, timeout, verified) == nil {
+				return nil
+			}
+		}
+
+		if lastErr != nil {
+			return &ClickVerificationErr{Message: lastErr.Error()}
+		}
+		return &ClickVerificationErr{Message: fmt.Sprintf("no effect after %d clicks on node %d", retries+1, e.NodeId())}
+	})
+}
+
+// scrolls the element into view and clicks a point hit testing finds the element at, falling back to the
+// center if every point is covered.
+func (e *Element) clickVisiblePoint() error {
+	if _, err := e.callFunction(`function() { this.scrollIntoView({block: 'center', inline: 'center'}); }`); err != nil {
+		return err
+	}
+
+	rro, err := e.callFunction(clickablePointFunction)
+	if err != nil {
+		return err
+	}
+	point, ok := rro.Value.(map[string]interface{})
+	if !ok {
+		e.tab.debugf("no uncovered point found for %d, clicking the center\n", e.NodeId())
+		return e.click()
+	}
+
+	// the script's coordinates are relative to the element's frame, the box model places that frame in the page
+	points, err := e.Dimensions()
+	if err != nil {
+		return err
+	}
+	bounds, err := quadBounds(points)
+	if err != nil {
+		return err
+	}
+	x, _ := point["x"].(float64)
+	y, _ := point["y"].(float64)
+	left, _ := point["left"].(float64)
+	top, _ := point["top"].(float64)
+	return e.tab.click(bounds.X+(x-left), bounds.Y+(y-top), 1)
+}
+
+// returns the top frame's navigation requests, loader and url, any of which changing means it navigated.
+func (t *Tab) navigationState() (int, string, string) {
+	t.redirectLoopLock.Lock()
+	requests := t.topNavigationRequests
+	t.redirectLoopLock.Unlock()
+
+	t.softNavLock.Lock()
+	url := t.topFrameUrl
+	t.softNavLock.Unlock()
+	return requests, t.LoaderId(), url
+}
+
+// calls fn with every Runtime console entry until the returned function is called, the Runtime domain
+// must be enabled.
+func (t *Tab) watchConsole(fn ConsoleEntryFunc) func() {
+	t.consoleLock.Lock()
+	defer t.consoleLock.Unlock()
+	t.consoleWatcherId++
+	id := t.consoleWatcherId
+	t.consoleWatchers[id] = fn
+	return func() {
+		t.consoleLock.Lock()
+		delete(t.consoleWatchers, id)
+		t.consoleLock.Unlock()
+	}
+}
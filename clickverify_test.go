@@ -0,0 +1,20 @@
+package autogcd
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClickOptionsVerifies(t *testing.T) {
+	if (&ClickOptions{Retries: 3}).verifies() {
+		t.Fatalf("options without a Verify field should not verify")
+	}
+	for _, opts := range []*ClickOptions{{VerifyNavigation: true}, {VerifyEvent: "click"}, {VerifySelector: "#done"}, {VerifyConsole: "done"}} {
+		if !opts.verifies() {
+			t.Fatalf("expected %#v to verify\n", opts)
+		}
+	}
+	if !errors.Is(&ClickVerificationErr{Message: "no effect"}, ErrClickNotVerified) {
+		t.Fatalf("expected ClickVerificationErr to unwrap to ErrClickNotVerified")
+	}
+}
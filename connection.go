@@ -0,0 +1,303 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wirepair/gcd"
+	"github.com/wirepair/gcd/gcdapi"
+	"github.com/wirepair/gcd/gcdmessage"
+	"golang.org/x/net/websocket"
+)
+
+// how long connecting to a target's websocket may take
+const targetDialTimeout = 10 * time.Second
+
+// targetConnection is a websocket connection to a single target. The tab uses its own connection rather than
+// the one gcd opens so it can connect to one existing target, and close the connection without closing the
+// target, when re-attaching.
+type targetConnection struct {
+	info       *gcd.TargetInfo
+	ws         *websocket.Conn
+	sendId     int64                              // last command id, accessed atomically
+	apiTimeout int64                              // how long commands wait for a response, accessed atomically
+	sendCh     chan *gcdmessage.Message           // commands from the domains
+	doneCh     chan struct{}                      // closed when the connection is closed
+	lock       *sync.Mutex                        // protects replies, events and closed
+	replies    map[int64]chan *gcdmessage.Message // commands waiting for their response
+	events     map[string]func([]byte)            // subscribed events
+	closed     bool
+}
+
+// connects to the target listed by the browser at addr, the host:port of its debugger.
+func dialTarget(addr string, info *gcd.TargetInfo) (*targetConnection, error) {
+	conn, err := net.DialTimeout("tcp", addr, targetDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	config, err := websocket.NewConfig(info.WebSocketDebuggerUrl, "http://localhost")
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	ws, err := websocket.NewClient(config, conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	c := &targetConnection{info: info, ws: ws}
+	c.apiTimeout = int64(120 * time.Second) // same default as gcd
+	c.sendCh = make(chan *gcdmessage.Message)
+	c.doneCh = make(chan struct{})
+	c.lock = &sync.Mutex{}
+	c.replies = make(map[int64]chan *gcdmessage.Message)
+	c.events = make(map[string]func([]byte))
+	go c.write()
+	go c.read()
+	return c, nil
+}
+
+// GetId returns the next command id.
+func (c *targetConnection) GetId() int64 {
+	return atomic.AddInt64(&c.sendId, 1)
+}
+
+// GetApiTimeout returns how long commands wait for chrome to respond.
+func (c *targetConnection) GetApiTimeout() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.apiTimeout))
+}
+
+// SetApiTimeout sets how long commands wait for chrome to respond.
+func (c *targetConnection) SetApiTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&c.apiTimeout, int64(timeout))
+}
+
+// GetSendCh returns the channel commands are sent on.
+func (c *targetConnection) GetSendCh() chan *gcdmessage.Message {
+	return c.sendCh
+}
+
+// GetDoneCh returns the channel closed when the connection is closed.
+func (c *targetConnection) GetDoneCh() chan struct{} {
+	return c.doneCh
+}
+
+// calls handler with the payload of every method event, replacing any handler already subscribed.
+func (c *targetConnection) subscribe(method string, handler func([]byte)) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.events[method] = handler
+}
+
+func (c *targetConnection) unsubscribe(method string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.events, method)
+}
+
+// closes the websocket, the target itself is left open. Commands waiting for a response return
+// gcdmessage.ChromeDoneErr.
+func (c *targetConnection) Close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	close(c.doneCh)
+	c.ws.Close()
+}
+
+// sends commands until the connection is closed.
+func (c *targetConnection) write() {
+	for {
+		select {
+		case msg := <-c.sendCh:
+			c.lock.Lock()
+			c.replies[msg.Id] = msg.ReplyCh
+			c.lock.Unlock()
+			if err := websocket.Message.Send(c.ws, string(msg.Data)); err != nil {
+				c.Close()
+				return
+			}
+		case <-c.doneCh:
+			return
+		}
+	}
+}
+
+// dispatches responses and events until the connection is closed.
+func (c *targetConnection) read() {
+	for {
+		var msg []byte
+		err := websocket.Message.Receive(c.ws, &msg)
+		if err == websocket.ErrFrameTooLarge {
+			// the frame is discarded, the command it answered times out
+			continue
+		}
+		if err != nil {
+			c.Close()
+			return
+		}
+		c.dispatch(msg)
+	}
+}
+
+// hands a response to the command waiting for it, or calls the handler subscribed to an event.
+func (c *targetConnection) dispatch(msg []byte) {
+	var header struct {
+		Id     int64  `json:"id"`
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(msg, &header); err != nil {
+		return
+	}
+
+	c.lock.Lock()
+	if header.Method == "" {
+		replyCh, ok := c.replies[header.Id]
+		delete(c.replies, header.Id)
+		c.lock.Unlock()
+		if ok {
+			// reply channels are buffered by gcdmessage and answered once
+			select {
+			case replyCh <- &gcdmessage.Message{Id: header.Id, Data: msg}:
+			default:
+			}
+		}
+		return
+	}
+
+	switch header.Method {
+	case "Inspector.targetCrashed", "Inspector.detached":
+		// no responses will arrive for the pending commands
+		for id, replyCh := range c.replies {
+			close(replyCh)
+			delete(c.replies, id)
+		}
+	}
+	handler := c.events[header.Method]
+	c.lock.Unlock()
+	if handler != nil {
+		go handler(msg)
+	}
+}
+
+// returns the current connection to the tab's target. The tab's domains (Page, DOM etc.) are created once
+// and bound to the Tab itself, which implements gcdmessage.ChromeTargeter by forwarding to the current
+// connection, so re-attaching can swap the connection while other goroutines use the domains.
+func (t *Tab) target() *targetConnection {
+	t.targetLock.RLock()
+	defer t.targetLock.RUnlock()
+	return t.connection
+}
+
+// closes the current connection and replaces it with target.
+func (t *Tab) setTarget(target *targetConnection) {
+	t.targetLock.Lock()
+	defer t.targetLock.Unlock()
+	if t.connection != nil {
+		t.connection.Close()
+	}
+	t.connection = target
+}
+
+// GetId returns the next command id of the current connection.
+func (t *Tab) GetId() int64 {
+	return t.target().GetId()
+}
+
+// GetApiTimeout returns how long commands wait for chrome to respond, see SetCallTimeout.
+func (t *Tab) GetApiTimeout() time.Duration {
+	return t.target().GetApiTimeout()
+}
+
+// SetApiTimeout sets how long commands wait for chrome to respond, same as SetCallTimeout.
+func (t *Tab) SetApiTimeout(timeout time.Duration) {
+	t.target().SetApiTimeout(timeout)
+}
+
+// GetSendCh returns the channel the tab's domains send commands on, which records them while
+// RecordSession is active.
+func (t *Tab) GetSendCh() chan *gcdmessage.Message {
+	if sendCh := t.recordingSendCh(); sendCh != nil {
+		return sendCh
+	}
+	return t.target().GetSendCh()
+}
+
+// GetDoneCh returns the channel closed when the current connection is closed.
+func (t *Tab) GetDoneCh() chan struct{} {
+	return t.target().GetDoneCh()
+}
+
+// points every domain of target at targeter, mirroring gcd.ChromeTarget.Init.
+func initDomains(target *gcd.ChromeTarget, targeter gcdmessage.ChromeTargeter) {
+	target.Accessibility = gcdapi.NewAccessibility(targeter)
+	target.Animation = gcdapi.NewAnimation(targeter)
+	target.ApplicationCache = gcdapi.NewApplicationCache(targeter)
+	target.Browser = gcdapi.NewBrowser(targeter)
+	target.CacheStorage = gcdapi.NewCacheStorage(targeter)
+	target.Console = gcdapi.NewConsole(targeter)
+	target.CSS = gcdapi.NewCSS(targeter)
+	target.Database = gcdapi.NewDatabase(targeter)
+	target.Debugger = gcdapi.NewDebugger(targeter)
+	target.DeviceOrientation = gcdapi.NewDeviceOrientation(targeter)
+	target.DOMDebugger = gcdapi.NewDOMDebugger(targeter)
+	target.DOM = gcdapi.NewDOM(targeter)
+	target.DOMSnapshot = gcdapi.NewDOMSnapshot(targeter)
+	target.DOMStorage = gcdapi.NewDOMStorage(targeter)
+	target.Emulation = gcdapi.NewEmulation(targeter)
+	target.HeapProfiler = gcdapi.NewHeapProfiler(targeter)
+	target.IndexedDB = gcdapi.NewIndexedDB(targeter)
+	target.Input = gcdapi.NewInput(targeter)
+	target.Inspector = gcdapi.NewInspector(targeter)
+	target.IO = gcdapi.NewIO(targeter)
+	target.LayerTree = gcdapi.NewLayerTree(targeter)
+	target.Memory = gcdapi.NewMemory(targeter)
+	target.Log = gcdapi.NewLog(targeter)
+	target.Network = gcdapi.NewNetwork(targeter)
+	target.Overlay = gcdapi.NewOverlay(targeter)
+	target.Page = gcdapi.NewPage(targeter)
+	target.Profiler = gcdapi.NewProfiler(targeter)
+	target.Runtime = gcdapi.NewRuntime(targeter)
+	target.Schema = gcdapi.NewSchema(targeter)
+	target.Security = gcdapi.NewSecurity(targeter)
+	target.SystemInfo = gcdapi.NewSystemInfo(targeter)
+	target.ServiceWorker = gcdapi.NewServiceWorker(targeter)
+	target.TargetApi = gcdapi.NewTarget(targeter)
+	target.Tracing = gcdapi.NewTracing(targeter)
+	target.Tethering = gcdapi.NewTethering(targeter)
+	target.HeadlessExperimental = gcdapi.NewHeadlessExperimental(targeter)
+	target.Performance = gcdapi.NewPerformance(targeter)
+	target.Testing = gcdapi.NewTesting(targeter)
+	target.Fetch = gcdapi.NewFetch(targeter)
+}
@@ -0,0 +1,133 @@
+package autogcd
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wirepair/gcd"
+	"github.com/wirepair/gcd/gcdapi"
+	"github.com/wirepair/gcd/gcdmessage"
+	"golang.org/x/net/websocket"
+)
+
+// starts a websocket server which answers Page.getFrameTree, sends an event for Page.enable, detaches for
+// Page.reload, answers other commands with an empty result and closes when the client does.
+func testTargetServer(closedCh chan struct{}) (*httptest.Server, *gcd.TargetInfo) {
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		defer close(closedCh)
+		for {
+			var request gcdmessage.ParamRequest
+			if err := websocket.JSON.Receive(ws, &request); err != nil {
+				return
+			}
+			switch request.Method {
+			case "Page.getFrameTree":
+				websocket.Message.Send(ws, `{"id":`+strconv.FormatInt(request.Id, 10)+`,"result":{"frameTree":{"frame":{"id":"main"}}}}`)
+			case "Page.enable":
+				websocket.Message.Send(ws, `{"method":"Page.loadEventFired","params":{"timestamp":1}}`)
+				websocket.Message.Send(ws, `{"id":`+strconv.FormatInt(request.Id, 10)+`,"result":{}}`)
+			case "Page.reload":
+				websocket.Message.Send(ws, `{"method":"Inspector.detached","params":{"reason":"replaced_with_devtools"}}`)
+			default:
+				websocket.Message.Send(ws, `{"id":`+strconv.FormatInt(request.Id, 10)+`,"result":{}}`)
+			}
+		}
+	}))
+	addr := strings.TrimPrefix(server.URL, "http://")
+	return server, &gcd.TargetInfo{Id: "target", Type: "page", WebSocketDebuggerUrl: "ws://" + addr + "/"}
+}
+
+func TestTargetConnection(t *testing.T) {
+	closedCh := make(chan struct{})
+	server, info := testTargetServer(closedCh)
+	defer server.Close()
+
+	target, err := dialTarget(strings.TrimPrefix(server.URL, "http://"), info)
+	if err != nil {
+		t.Fatalf("error connecting to target: %s\n", err)
+	}
+	target.SetApiTimeout(5 * time.Second)
+	page := gcdapi.NewPage(target)
+
+	frameTree, err := page.GetFrameTree()
+	if err != nil || frameTree.Frame.Id != "main" {
+		t.Fatalf("expected the frame tree got %v %v\n", frameTree, err)
+	}
+
+	eventCh := make(chan string, 1)
+	target.subscribe("Page.loadEventFired", func(payload []byte) {
+		eventCh <- string(payload)
+	})
+	if _, err := page.Enable(); err != nil {
+		t.Fatalf("error enabling page: %s\n", err)
+	}
+	select {
+	case <-eventCh:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the subscribed event")
+	}
+
+	// pending commands are answered once the target detaches
+	if _, err := page.Reload(false, ""); err == nil {
+		t.Fatalf("expected an error for a command pending when the target detached")
+	}
+
+	target.Close()
+	target.Close()
+	select {
+	case <-target.GetDoneCh():
+	default:
+		t.Fatalf("expected the done channel to be closed")
+	}
+	select {
+	case <-closedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the websocket to be closed")
+	}
+	if _, err := page.GetFrameTree(); err == nil {
+		t.Fatalf("expected an error using a closed connection")
+	}
+}
+
+// returns a tab connected to a test target server, a function connecting to another test target server and a
+// function closing the servers.
+func testConnectedTab(t *testing.T) (*Tab, func() *targetConnection, func()) {
+	servers := make([]*httptest.Server, 0)
+	connect := func() *targetConnection {
+		server, info := testTargetServer(make(chan struct{}))
+		servers = append(servers, server)
+		target, err := dialTarget(strings.TrimPrefix(server.URL, "http://"), info)
+		if err != nil {
+			t.Fatalf("error connecting to target: %s\n", err)
+		}
+		return target
+	}
+	closeServers := func() {
+		for _, server := range servers {
+			server.Close()
+		}
+	}
+
+	target := connect()
+	tab := &Tab{targetLock: &sync.RWMutex{}, connection: target, ChromeTarget: &gcd.ChromeTarget{Target: target.info}}
+	initDomains(tab.ChromeTarget, tab)
+	tab.subscriptionLock = &sync.Mutex{}
+	tab.subscriptions = make(map[string]struct{})
+	tab.recordLock = &sync.RWMutex{}
+	tab.interceptLock = &sync.RWMutex{}
+	tab.authAttempts = make(map[string]struct{})
+	tab.popupLock = &sync.RWMutex{}
+	return tab, connect, closeServers
+}
+
+// returns true if method is subscribed on target.
+func testSubscribed(target *targetConnection, method string) bool {
+	target.lock.Lock()
+	defer target.lock.Unlock()
+	_, ok := target.events[method]
+	return ok
+}
@@ -0,0 +1,199 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// Severity of a ConsoleEntry, levels are ordered so filters may use a minimum.
+type ConsoleLevel uint8
+
+const (
+	ConsoleVerbose ConsoleLevel = 0x0 // console.debug and verbose browser messages
+	ConsoleInfo    ConsoleLevel = 0x1 // console.log, console.info and similar calls
+	ConsoleWarning ConsoleLevel = 0x2 // console.warn and browser warnings
+	ConsoleError   ConsoleLevel = 0x3 // console.error, failed assertions and browser errors
+)
+
+var consoleLevelMap = map[ConsoleLevel]string{
+	ConsoleVerbose: "ConsoleVerbose",
+	ConsoleInfo:    "ConsoleInfo",
+	ConsoleWarning: "ConsoleWarning",
+	ConsoleError:   "ConsoleError",
+}
+
+func (level ConsoleLevel) String() string {
+	if s, ok := consoleLevelMap[level]; ok {
+		return s
+	}
+	return ""
+}
+
+// maps Console message levels and Runtime console call types to a ConsoleLevel.
+func consoleLevel(level string) ConsoleLevel {
+	switch level {
+	case "debug", "verbose":
+		return ConsoleVerbose
+	case "warning":
+		return ConsoleWarning
+	case "error", "assert":
+		return ConsoleError
+	default:
+		return ConsoleInfo
+	}
+}
+
+// the Console domain reports calls to the console api with this source, the same calls are reported
+// by Runtime.consoleAPICalled.
+const consoleApiSource = "console-api"
+
+// ConsoleEntry is a console message from either the Console domain or Runtime.consoleAPICalled.
+type ConsoleEntry struct {
+	Level     ConsoleLevel // normalized severity
+	Type      string       // the original level or console call type (log, warning, table, assert...)
+	Source    string       // where the message came from (console-api, javascript, network, security...)
+	Text      string       // message text, console call arguments are joined by spaces
+	Url       string       // url of the script or resource which generated the message, if known
+	Line      int          // line number in Url, if known
+	Column    int          // column number in Url, if known
+	Timestamp float64      // when the call was made, only set for Runtime entries
+	Runtime   bool         // true if the entry came from Runtime.consoleAPICalled
+}
+
+// ConsoleOptions filter the entries delivered by GetConsoleEntries, the zero value delivers everything.
+type ConsoleOptions struct {
+	MinLevel       ConsoleLevel   // only deliver entries of this level or higher
+	Sources        []string       // only deliver entries from these sources, empty for all
+	UrlPattern     *regexp.Regexp // only deliver entries whose Url matches, nil for all
+	IncludeRuntime bool           // also listen to Runtime.consoleAPICalled, which includes stack locations and object descriptions
+}
+
+// returns true if entry passes the filters.
+func (opts *ConsoleOptions) matches(entry *ConsoleEntry) bool {
+	if entry.Level < opts.MinLevel {
+		return false
+	}
+	if len(opts.Sources) > 0 {
+		found := false
+		for _, source := range opts.Sources {
+			if source == entry.Source {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if opts.UrlPattern != nil && !opts.UrlPattern.MatchString(entry.Url) {
+		return false
+	}
+	return true
+}
+
+// GetConsoleEntries is GetConsoleMessages with filtering, delivering messages as ConsoleEntry values. If
+// opts.IncludeRuntime is set, console api calls are taken from Runtime.consoleAPICalled rather than the Console
+// domain so each call is only delivered once. Replaces any handler set by GetConsoleMessages, stop with
+// StopConsoleMessages.
+func (t *Tab) GetConsoleEntries(opts *ConsoleOptions, entryHandler ConsoleEntryFunc) error {
+	if opts == nil {
+		opts = &ConsoleOptions{}
+	}
+
+	t.consoleLock.Lock()
+	t.consoleOptions = opts
+	t.consoleEntryHandler = entryHandler
+	t.consoleLock.Unlock()
+
+	t.GetConsoleMessages(func(tab *Tab, message *gcdapi.ConsoleConsoleMessage) {
+		if opts.IncludeRuntime && message.Source == consoleApiSource {
+			return
+		}
+		entry := &ConsoleEntry{
+			Level:  consoleLevel(message.Level),
+			Type:   message.Level,
+			Source: message.Source,
+			Text:   message.Text,
+			Url:    message.Url,
+			Line:   message.Line,
+			Column: message.Column,
+		}
+		if opts.matches(entry) {
+			entryHandler(t, entry)
+		}
+	})
+
+	if !opts.IncludeRuntime {
+		return nil
+	}
+	return t.enableRuntime()
+}
+
+// parses a Runtime.consoleAPICalled event into a ConsoleEntry.
+func parseConsoleAPICalled(payload []byte) (*ConsoleEntry, error) {
+	message := &gcdapi.RuntimeConsoleAPICalledEvent{}
+	if err := json.Unmarshal(payload, message); err != nil {
+		return nil, err
+	}
+	p := message.Params
+	entry := &ConsoleEntry{Level: consoleLevel(p.Type), Type: p.Type, Source: consoleApiSource, Text: remoteObjectsText(p.Args), Timestamp: p.Timestamp, Runtime: true}
+	if p.StackTrace != nil && len(p.StackTrace.CallFrames) > 0 {
+		frame := p.StackTrace.CallFrames[0]
+		entry.Url = frame.Url
+		entry.Line = frame.LineNumber + 1 // zero based, match the Console domain
+		entry.Column = frame.ColumnNumber + 1
+	}
+	return entry, nil
+}
+
+// called for every Runtime.consoleAPICalled event, which are only sent once the Runtime domain is enabled.
+// Errors are recorded for CollectErrors and the entry is passed to the GetConsoleEntries handler.
+func (t *Tab) handleConsoleAPICalled(entry *ConsoleEntry) {
+	if entry.Level == ConsoleError {
+		t.recordPageError(func(errors *PageErrors) {
+			errors.ConsoleErrors = append(errors.ConsoleErrors, entry)
+		})
+	}
+
+	t.consoleLock.RLock()
+	opts := t.consoleOptions
+	handlerFn := t.consoleEntryHandler
+	watchers := make([]ConsoleEntryFunc, 0, len(t.consoleWatchers))
+	for _, watcher := range t.consoleWatchers {
+		watchers = append(watchers, watcher)
+	}
+	t.consoleLock.RUnlock()
+
+	for _, watcher := range watchers {
+		watcher(t, entry)
+	}
+	if handlerFn != nil && opts.IncludeRuntime && opts.matches(entry) {
+		handlerFn(t, entry)
+	}
+}
@@ -0,0 +1,52 @@
+package autogcd
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestConsoleLevelMapping(t *testing.T) {
+	levels := map[string]ConsoleLevel{
+		"debug":   ConsoleVerbose,
+		"verbose": ConsoleVerbose,
+		"log":     ConsoleInfo,
+		"table":   ConsoleInfo,
+		"warning": ConsoleWarning,
+		"assert":  ConsoleError,
+		"error":   ConsoleError,
+	}
+	for level, expected := range levels {
+		if got := consoleLevel(level); got != expected {
+			t.Fatalf("expected %s for %s got %s\n", expected, level, got)
+		}
+	}
+}
+
+func TestConsoleOptionsMatches(t *testing.T) {
+	entry := &ConsoleEntry{Level: ConsoleWarning, Source: "javascript", Url: "http://localhost/app.js"}
+
+	opts := &ConsoleOptions{}
+	if !opts.matches(entry) {
+		t.Fatalf("expected zero options to match")
+	}
+
+	opts = &ConsoleOptions{MinLevel: ConsoleError}
+	if opts.matches(entry) {
+		t.Fatalf("expected warning to be filtered by MinLevel error")
+	}
+
+	opts = &ConsoleOptions{Sources: []string{"network", "security"}}
+	if opts.matches(entry) {
+		t.Fatalf("expected javascript source to be filtered")
+	}
+
+	opts = &ConsoleOptions{Sources: []string{"javascript"}, UrlPattern: regexp.MustCompile(`app\.js$`)}
+	if !opts.matches(entry) {
+		t.Fatalf("expected entry to match source and url")
+	}
+
+	opts.UrlPattern = regexp.MustCompile(`vendor\.js$`)
+	if opts.matches(entry) {
+		t.Fatalf("expected url pattern to filter entry")
+	}
+}
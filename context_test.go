@@ -0,0 +1,34 @@
+package autogcd
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTabWaitCtxCancelled(t *testing.T) {
+	tab := &Tab{navigationTimeout: 5 * time.Second, stabilityTimeout: 5 * time.Second, stableAfter: time.Second}
+	tab.lastNodeChangeTimeVal.Store(time.Now().Add(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := tab.WaitForCtx(ctx, 10*time.Millisecond, 5*time.Second, func(tab *Tab) bool { return false })
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected WaitForCtx to return DeadlineExceeded got %v\n", err)
+	}
+	if err := tab.WaitStableCtx(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected WaitStableCtx to return DeadlineExceeded got %v\n", err)
+	}
+	if err := tab.readyWait(ctx, "http://localhost/", ""); err != context.DeadlineExceeded {
+		t.Fatalf("expected readyWait to return DeadlineExceeded got %v\n", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("waits did not stop when the context was done")
+	}
+
+	if err := tab.WaitForCtx(context.Background(), 10*time.Millisecond, time.Second, func(tab *Tab) bool { return true }); err != nil {
+		t.Fatalf("expected condition to succeed got %v\n", err)
+	}
+}
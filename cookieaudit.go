@@ -0,0 +1,131 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// Cookie audit rules reported in CookieViolation.Rule
+const (
+	CookieRuleSecure     = "secure"               // the cookie is not Secure
+	CookieRuleHttpOnly   = "httponly"             // the cookie is not HttpOnly
+	CookieRuleSameSite   = "samesite"             // the cookie's SameSite value is not allowed by the policy
+	CookieRuleNoneSecure = "samesite-none-secure" // SameSite=None without Secure, rejected by modern browsers
+	CookieRuleLifetime   = "lifetime"             // the cookie expires later than the policy allows
+)
+
+// CookiePolicy for AuditCookies, the zero value only checks that SameSite=None cookies are Secure.
+type CookiePolicy struct {
+	RequireSecure   bool          // every cookie must be Secure
+	RequireHttpOnly bool          // every cookie must be HttpOnly
+	AllowedSameSite []string      // allowed SameSite values (Strict, Lax, None), a missing value is reported as None, empty allows any
+	MaxLifetime     time.Duration // maximum time until a persistent cookie expires, 0 for no limit
+	Exempt          []string      // cookie names not audited, such as ones set by third party scripts that need to be readable
+}
+
+// CookieViolation is a cookie breaking a rule of the CookiePolicy
+type CookieViolation struct {
+	Cookie  *gcdapi.NetworkCookie // the offending cookie
+	Rule    string                // one of the CookieRule constants
+	Message string                // human readable description
+}
+
+func (v *CookieViolation) String() string {
+	return fmt.Sprintf("%s (%s%s): %s", v.Cookie.Name, v.Cookie.Domain, v.Cookie.Path, v.Message)
+}
+
+// AuditCookies checks the tab's cookies against policy, returning a violation for every rule each cookie
+// breaks. Pass nil to only check that SameSite=None cookies are Secure.
+func (t *Tab) AuditCookies(policy *CookiePolicy) ([]*CookieViolation, error) {
+	cookies, err := t.GetCookies()
+	if err != nil {
+		return nil, err
+	}
+	return auditCookies(cookies, policy, time.Now()), nil
+}
+
+func auditCookies(cookies []*gcdapi.NetworkCookie, policy *CookiePolicy, now time.Time) []*CookieViolation {
+	if policy == nil {
+		policy = &CookiePolicy{}
+	}
+	violations := make([]*CookieViolation, 0)
+	add := func(cookie *gcdapi.NetworkCookie, rule, format string, args ...interface{}) {
+		violations = append(violations, &CookieViolation{Cookie: cookie, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, cookie := range cookies {
+		if exempt(policy.Exempt, cookie.Name) {
+			continue
+		}
+
+		sameSite := cookie.SameSite
+		if sameSite == "" {
+			sameSite = "None"
+		}
+
+		if policy.RequireSecure && !cookie.Secure {
+			add(cookie, CookieRuleSecure, "missing Secure attribute")
+		}
+		if policy.RequireHttpOnly && !cookie.HttpOnly {
+			add(cookie, CookieRuleHttpOnly, "missing HttpOnly attribute")
+		}
+		if len(policy.AllowedSameSite) > 0 && !sameSiteAllowed(policy.AllowedSameSite, sameSite) {
+			add(cookie, CookieRuleSameSite, "SameSite=%s is not allowed", sameSite)
+		}
+		if cookie.SameSite == "None" && !cookie.Secure {
+			add(cookie, CookieRuleNoneSecure, "SameSite=None requires the Secure attribute")
+		}
+		if policy.MaxLifetime > 0 && !cookie.Session && cookie.Expires > 0 {
+			expires := time.Unix(0, int64(cookie.Expires*float64(time.Second)))
+			if lifetime := expires.Sub(now); lifetime > policy.MaxLifetime {
+				add(cookie, CookieRuleLifetime, "expires in %s, more than the allowed %s", lifetime.Round(time.Second), policy.MaxLifetime)
+			}
+		}
+	}
+	return violations
+}
+
+func exempt(names []string, name string) bool {
+	for _, exempt := range names {
+		if exempt == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sameSiteAllowed(allowed []string, sameSite string) bool {
+	for _, value := range allowed {
+		if strings.EqualFold(value, sameSite) {
+			return true
+		}
+	}
+	return false
+}
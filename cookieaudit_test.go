@@ -0,0 +1,42 @@
+package autogcd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+func TestAuditCookies(t *testing.T) {
+	now := time.Unix(1000000, 0)
+	cookies := []*gcdapi.NetworkCookie{
+		{Name: "good", Secure: true, HttpOnly: true, SameSite: "Strict", Session: true},
+		{Name: "insecure", HttpOnly: true, SameSite: "Lax", Session: true},
+		{Name: "none", HttpOnly: true, SameSite: "None", Session: true},
+		{Name: "longlived", Secure: true, HttpOnly: true, SameSite: "Lax", Expires: float64(now.Add(48 * time.Hour).Unix())},
+		{Name: "tracking"},
+	}
+
+	if violations := auditCookies(cookies, nil, now); len(violations) != 1 || violations[0].Rule != CookieRuleNoneSecure {
+		t.Fatalf("expected only the SameSite=None violation with no policy got %v\n", violations)
+	}
+
+	policy := &CookiePolicy{RequireSecure: true, RequireHttpOnly: true, AllowedSameSite: []string{"strict", "lax"}, MaxLifetime: 24 * time.Hour, Exempt: []string{"tracking"}}
+	violations := auditCookies(cookies, policy, now)
+	rules := make(map[string][]string)
+	for _, violation := range violations {
+		rules[violation.Cookie.Name] = append(rules[violation.Cookie.Name], violation.Rule)
+	}
+	if len(rules["good"]) != 0 || len(rules["tracking"]) != 0 {
+		t.Fatalf("unexpected violations %v\n", rules)
+	}
+	if len(rules["insecure"]) != 1 || rules["insecure"][0] != CookieRuleSecure {
+		t.Fatalf("expected a secure violation got %v\n", rules["insecure"])
+	}
+	if len(rules["none"]) != 3 {
+		t.Fatalf("expected secure, samesite and samesite-none-secure violations got %v\n", rules["none"])
+	}
+	if len(rules["longlived"]) != 1 || rules["longlived"][0] != CookieRuleLifetime {
+		t.Fatalf("expected a lifetime violation got %v\n", rules["longlived"])
+	}
+}
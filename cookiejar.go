@@ -0,0 +1,127 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// BrowserCookieJar is an http.CookieJar backed by the browser's cookie store, so an http.Client using it shares
+// session state with the browser. Cookies set by either side are visible to the other. See Tab.CookieJar.
+type BrowserCookieJar struct {
+	tab *Tab
+}
+
+// CookieJar returns an http.CookieJar backed by the browser's cookies, for example:
+//
+//	client := &http.Client{Jar: tab.CookieJar()}
+//
+// The browser's cookie store is shared by every tab (unless using separate browser contexts).
+func (t *Tab) CookieJar() *BrowserCookieJar {
+	return &BrowserCookieJar{tab: t}
+}
+
+// SetCookies stores cookies received in a response for u in the browser. Errors are only reported by debug
+// printing since the http.CookieJar interface does not return them.
+func (j *BrowserCookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	for _, cookie := range cookies {
+		if err := j.tab.setHTTPCookie(u, cookie); err != nil {
+			j.tab.debugf("error setting cookie %s for %s: %s\n", cookie.Name, u, err)
+		}
+	}
+}
+
+// Cookies returns the cookies the browser would send to u.
+func (j *BrowserCookieJar) Cookies(u *url.URL) []*http.Cookie {
+	browserCookies, err := j.tab.Network.GetCookies([]string{u.String()})
+	if err != nil {
+		j.tab.debugf("error getting cookies for %s: %s\n", u, err)
+		return nil
+	}
+
+	cookies := make([]*http.Cookie, 0, len(browserCookies))
+	for _, c := range browserCookies {
+		cookie := &http.Cookie{Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path, Secure: c.Secure, HttpOnly: c.HttpOnly}
+		if !c.Session && c.Expires > 0 {
+			cookie.Expires = time.Unix(int64(c.Expires), 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies
+}
+
+// SyncFromJar copies the cookies jar holds for each of urls into the browser, for example after logging in
+// with an http.Client. An http.CookieJar can not list its contents, so the urls the cookies apply to must
+// be provided.
+func (t *Tab) SyncFromJar(jar http.CookieJar, urls ...string) error {
+	for _, rawurl := range urls {
+		u, err := url.Parse(rawurl)
+		if err != nil {
+			return err
+		}
+		for _, cookie := range jar.Cookies(u) {
+			if err := t.setHTTPCookie(u, cookie); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sets cookie in the browser as though it was received from u.
+func (t *Tab) setHTTPCookie(u *url.URL, cookie *http.Cookie) error {
+	params := &gcdapi.NetworkSetCookieParams{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Url:      u.String(),
+		Domain:   cookie.Domain,
+		Path:     cookie.Path,
+		Secure:   cookie.Secure,
+		HttpOnly: cookie.HttpOnly,
+	}
+	switch cookie.SameSite {
+	case http.SameSiteStrictMode:
+		params.SameSite = "Strict"
+	case http.SameSiteLaxMode:
+		params.SameSite = "Lax"
+	}
+	if cookie.MaxAge < 0 {
+		// expired, the browser removes it
+		params.Expires = 1
+	} else if cookie.MaxAge > 0 {
+		params.Expires = float64(time.Now().Add(time.Duration(cookie.MaxAge) * time.Second).Unix())
+	} else if !cookie.Expires.IsZero() {
+		params.Expires = float64(cookie.Expires.Unix())
+	}
+	_, err := t.Network.SetCookieWithParams(params)
+	return err
+}
+
+// compile time check that BrowserCookieJar implements http.CookieJar
+var _ http.CookieJar = (*BrowserCookieJar)(nil)
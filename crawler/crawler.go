@@ -0,0 +1,242 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+/*
+Package crawler drives an autogcd Tab through a queue of urls. Urls are deduplicated by the
+Frontier using the urlutil package, and since every page is loaded with Tab.Navigate any
+RateLimiter or robots.Cache set on the Tab (or in Settings) applies to the crawl as well.
+Crawl state is kept in a Store, use a FileStore to be able to stop and resume large crawls.
+*/
+package crawler
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/wirepair/autogcd"
+	"github.com/wirepair/autogcd/urlutil"
+)
+
+// script to return the absolute urls of all links in the top document
+const linksScript = `Array.prototype.map.call(document.querySelectorAll('a[href]'), function(a) { return a.href; })`
+
+// PageFunc is called once a page has loaded. Use Crawler.Enqueue or Crawler.EnqueueLinks to
+// add more urls. Returning an error stops the crawl.
+type PageFunc func(c *Crawler, tab *autogcd.Tab, entry *Entry) error
+
+// ErrorFunc is called when navigating to an entry failed, the crawl continues.
+type ErrorFunc func(c *Crawler, entry *Entry, err error)
+
+// Crawler visits urls from its Frontier one at a time in a single Tab.
+type Crawler struct {
+	tab          *autogcd.Tab
+	frontier     *Frontier
+	client       *http.Client // used for fetching sitemaps
+	pageHandler  PageFunc
+	errorHandler ErrorFunc
+	maxDepth     int    // maximum link depth to follow, 0 for no limit
+	maxPages     int    // maximum pages to visit, 0 for no limit
+	scope        string // if set, only urls of this origin are enqueued
+	visited      int    // number of pages visited
+}
+
+// New creates a crawler for the tab, calling pageHandler for each loaded page.
+func New(tab *autogcd.Tab, pageHandler PageFunc) *Crawler {
+	c := &Crawler{tab: tab, pageHandler: pageHandler}
+	c.client = &http.Client{Timeout: 30 * time.Second}
+	c.frontier = NewFrontier(&urlutil.Options{RemoveParams: urlutil.TrackingParams})
+	return c
+}
+
+// SetFrontier replaces the default frontier, for example to use custom canonicalization options.
+func (c *Crawler) SetFrontier(frontier *Frontier) {
+	c.frontier = frontier
+}
+
+// SetStore keeps the crawl state (pending urls, seen urls, visited pages and results) in store, keeping the
+// canonicalization options of the current frontier. If store already holds state, for example a FileStore
+// from an interrupted crawl, Run continues where it stopped.
+func (c *Crawler) SetStore(store Store) error {
+	visited, err := store.Visited()
+	if err != nil {
+		return err
+	}
+	c.frontier = NewFrontierWithStore(c.frontier.options, store)
+	c.visited = visited
+	return nil
+}
+
+// SaveResult stores data extracted from entry's page in the frontier's store, see Store.PutResult.
+func (c *Crawler) SaveResult(entry *Entry, result []byte) error {
+	return c.frontier.store.PutResult(entry.Url, result)
+}
+
+// Frontier returns the queue of urls to be crawled.
+func (c *Crawler) Frontier() *Frontier {
+	return c.frontier
+}
+
+// SetHTTPClient used for fetching sitemaps outside of the browser.
+func (c *Crawler) SetHTTPClient(client *http.Client) {
+	c.client = client
+}
+
+// SetErrorHandler to be notified of failed navigations.
+func (c *Crawler) SetErrorHandler(errorHandler ErrorFunc) {
+	c.errorHandler = errorHandler
+}
+
+// SetMaxDepth of links to follow from the seed urls, 0 (the default) means no limit.
+func (c *Crawler) SetMaxDepth(depth int) {
+	c.maxDepth = depth
+}
+
+// SetMaxPages to visit before Run returns, 0 (the default) means no limit.
+func (c *Crawler) SetMaxPages(pages int) {
+	c.maxPages = pages
+}
+
+// SetScope restricts the crawl to urls sharing the origin of scopeUrl, pass an empty string to crawl anywhere.
+func (c *Crawler) SetScope(scopeUrl string) error {
+	if scopeUrl == "" {
+		c.scope = ""
+		return nil
+	}
+	origin, err := urlutil.Origin(scopeUrl)
+	if err != nil {
+		return err
+	}
+	c.scope = origin
+	return nil
+}
+
+// Visited returns the number of pages visited so far.
+func (c *Crawler) Visited() int {
+	return c.visited
+}
+
+// Enqueue adds rawurl, found on the from entry (nil for seeds), to the frontier. Returns false if the url
+// was already seen, is out of scope or exceeds the max depth.
+func (c *Crawler) Enqueue(rawurl string, from *Entry) bool {
+	entry := &Entry{Url: rawurl}
+	if from != nil {
+		resolved, err := urlutil.Resolve(from.Url, rawurl)
+		if err != nil {
+			return false
+		}
+		entry.Url = resolved
+		entry.Depth = from.Depth + 1
+		entry.Referrer = from.Url
+	}
+	return c.push(entry)
+}
+
+// applies the depth and scope checks before pushing on to the frontier.
+func (c *Crawler) push(entry *Entry) bool {
+	if c.maxDepth > 0 && entry.Depth > c.maxDepth {
+		return false
+	}
+	if c.scope != "" {
+		if origin, err := urlutil.Origin(entry.Url); err != nil || origin != c.scope {
+			return false
+		}
+	}
+	return c.frontier.Push(entry)
+}
+
+// SeedFromSitemap fetches the sitemaps of origin (see FetchSitemaps) and enqueues every url with
+// its lastmod and priority, highest priority first. Returns the number of urls added.
+func (c *Crawler) SeedFromSitemap(origin string) (int, error) {
+	sitemapEntries, err := FetchSitemaps(c.client, origin)
+	if err != nil {
+		return 0, err
+	}
+
+	sort.SliceStable(sitemapEntries, func(i, j int) bool {
+		return sitemapEntries[i].Priority > sitemapEntries[j].Priority
+	})
+
+	added := 0
+	for _, s := range sitemapEntries {
+		if c.push(&Entry{Url: s.Url, LastMod: s.LastMod, Priority: s.Priority}) {
+			added++
+		}
+	}
+	return added, nil
+}
+
+// EnqueueLinks adds every a[href] link of the current page, returning how many were new.
+func (c *Crawler) EnqueueLinks(from *Entry) (int, error) {
+	rro, err := c.tab.EvaluateScript(linksScript)
+	if err != nil {
+		return 0, err
+	}
+	links, ok := rro.Value.([]interface{})
+	if !ok {
+		return 0, nil
+	}
+
+	added := 0
+	for _, link := range links {
+		if href, ok := link.(string); ok && c.Enqueue(href, from) {
+			added++
+		}
+	}
+	return added, nil
+}
+
+// Run enqueues the seeds and visits urls until the frontier is empty, the max pages is reached
+// or the page handler returns an error. Returns the frontier's error if its store failed.
+func (c *Crawler) Run(seeds ...string) error {
+	for _, seed := range seeds {
+		c.Enqueue(seed, nil)
+	}
+
+	for c.maxPages == 0 || c.visited < c.maxPages {
+		entry, ok := c.frontier.Pop()
+		if !ok {
+			return c.frontier.Err()
+		}
+
+		if _, err := c.tab.Navigate(entry.Url); err != nil {
+			if c.errorHandler != nil {
+				c.errorHandler(c, entry, err)
+			}
+			continue
+		}
+		c.visited++
+		if err := c.frontier.store.AddVisited(entry); err != nil {
+			return err
+		}
+
+		if c.pageHandler == nil {
+			continue
+		}
+		if err := c.pageHandler(c, c.tab, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
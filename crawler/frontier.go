@@ -0,0 +1,143 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package crawler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/wirepair/autogcd/urlutil"
+)
+
+// Entry is a url waiting to be (or that has been) crawled.
+type Entry struct {
+	Url      string // canonicalized url
+	Depth    int    // number of links followed from a seed url
+	Referrer string // url of the page this entry was found on, empty for seeds
+
+	// metadata for entries seeded from a sitemap
+	LastMod  time.Time // when the page was last modified, zero if unknown
+	Priority float64   // priority relative to other urls of the site (0.0 - 1.0), zero if unknown
+}
+
+// Frontier is a FIFO queue of urls to crawl. Urls are canonicalized with urlutil so the same
+// page is only ever queued once. The queue and seen urls are kept in a Store, in memory by default.
+// It is safe for concurrent use.
+type Frontier struct {
+	lock    *sync.Mutex
+	options *urlutil.Options
+	store   Store
+	err     error // the last error returned by the store
+}
+
+// NewFrontier creates a new in memory frontier canonicalizing urls with opts, which may be nil.
+func NewFrontier(opts *urlutil.Options) *Frontier {
+	return NewFrontierWithStore(opts, NewMemoryStore())
+}
+
+// NewFrontierWithStore creates a frontier keeping its state in store, for example a FileStore so the
+// crawl can be resumed. Urls are canonicalized with opts, which may be nil.
+func NewFrontierWithStore(opts *urlutil.Options, store Store) *Frontier {
+	f := &Frontier{}
+	f.lock = &sync.Mutex{}
+	f.options = opts
+	f.store = store
+	return f
+}
+
+// Push adds the entry to the queue, returning false if it was invalid, already seen or could not be stored.
+// The entry's Url is replaced with its canonical form.
+func (f *Frontier) Push(entry *Entry) bool {
+	canonical, err := urlutil.Canonicalize(entry.Url, f.options)
+	if err != nil {
+		return false
+	}
+
+	added, err := f.store.AddSeen(canonical)
+	if err != nil {
+		f.setErr(err)
+		return false
+	}
+	if !added {
+		return false
+	}
+	entry.Url = canonical
+
+	if err := f.store.Enqueue(entry); err != nil {
+		f.setErr(err)
+		return false
+	}
+	return true
+}
+
+// Pop removes the next entry from the queue, returns false if the queue is empty or the store failed.
+func (f *Frontier) Pop() (*Entry, bool) {
+	entry, ok, err := f.store.Dequeue()
+	if err != nil {
+		f.setErr(err)
+		return nil, false
+	}
+	return entry, ok
+}
+
+// Len returns the number of entries waiting to be crawled.
+func (f *Frontier) Len() int {
+	pending, err := f.store.Pending()
+	if err != nil {
+		f.setErr(err)
+	}
+	return pending
+}
+
+// Seen returns true if the url (or an equivalent url) was ever pushed.
+func (f *Frontier) Seen(rawurl string) bool {
+	canonical, err := urlutil.Canonicalize(rawurl, f.options)
+	if err != nil {
+		return false
+	}
+	seen, err := f.store.Seen(canonical)
+	if err != nil {
+		f.setErr(err)
+	}
+	return seen
+}
+
+// Store returns the store holding the frontier's state.
+func (f *Frontier) Store() Store {
+	return f.store
+}
+
+// Err returns the last error returned by the store, nil if it has not failed.
+func (f *Frontier) Err() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.err
+}
+
+func (f *Frontier) setErr(err error) {
+	f.lock.Lock()
+	f.err = err
+	f.lock.Unlock()
+}
@@ -0,0 +1,52 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/wirepair/autogcd/urlutil"
+)
+
+func TestFrontierDedupe(t *testing.T) {
+	f := NewFrontier(&urlutil.Options{RemoveParams: urlutil.TrackingParams})
+	if !f.Push(&Entry{Url: "http://localhost/page?utm_source=test#top"}) {
+		t.Fatalf("expected first entry to be pushed")
+	}
+	if f.Push(&Entry{Url: "http://LOCALHOST:80/page"}) {
+		t.Fatalf("expected equivalent url to be rejected")
+	}
+	if f.Len() != 1 {
+		t.Fatalf("expected 1 entry got %d\n", f.Len())
+	}
+
+	entry, ok := f.Pop()
+	if !ok || entry.Url != "http://localhost/page" {
+		t.Fatalf("expected canonical url got %#v\n", entry)
+	}
+	if _, ok := f.Pop(); ok {
+		t.Fatalf("expected frontier to be empty")
+	}
+}
+
+func TestCrawlerEnqueueScope(t *testing.T) {
+	c := New(nil, nil)
+	c.SetMaxDepth(1)
+	if err := c.SetScope("http://localhost/"); err != nil {
+		t.Fatalf("error setting scope: %s\n", err)
+	}
+
+	seed := &Entry{Url: "http://localhost/", Depth: 0}
+	if !c.Enqueue("/relative", seed) {
+		t.Fatalf("expected relative link to be enqueued")
+	}
+	if c.Enqueue("http://example.com/", seed) {
+		t.Fatalf("expected out of scope link to be rejected")
+	}
+	if c.Enqueue("/too/deep", &Entry{Url: "http://localhost/relative", Depth: 1}) {
+		t.Fatalf("expected link past max depth to be rejected")
+	}
+
+	entry, _ := c.Frontier().Pop()
+	if entry.Url != "http://localhost/relative" || entry.Referrer != "http://localhost/" || entry.Depth != 1 {
+		t.Fatalf("unexpected entry %#v\n", entry)
+	}
+}
@@ -0,0 +1,199 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/wirepair/autogcd"
+	"github.com/wirepair/autogcd/urlutil"
+)
+
+// RelNext can be passed to FollowPagination for pages that declare their next page with rel="next".
+const RelNext = `a[rel~="next"], link[rel~="next"]`
+
+// Finds the first element matching the selector that is not disabled, returning its absolute href
+// if following it is a plain navigation, or an empty href if it must be clicked. The element is marked
+// with the nextMarker attribute so the same element can be clicked.
+const findNextScript = `(function(selector) {
+	var marked = document.querySelectorAll('[data-autogcd-next]');
+	for (var i = 0; i < marked.length; i++) {
+		marked[i].removeAttribute('data-autogcd-next');
+	}
+	var nodes = document.querySelectorAll(selector);
+	for (var i = 0; i < nodes.length; i++) {
+		var node = nodes[i];
+		if (node.disabled || node.getAttribute('aria-disabled') === 'true' || /(^|\s)disabled(\s|$)/.test(node.className)) {
+			continue;
+		}
+		var href = node.getAttribute('href');
+		if (href && !/^\s*(javascript:|#)/i.test(href) && !node.hasAttribute('onclick')) {
+			return {found: true, href: node.href};
+		}
+		node.setAttribute('data-autogcd-next', '');
+		return {found: true, href: ''};
+	}
+	return {found: false, href: ''};
+})(%s)`
+
+// A cheap signature of the page's content used to detect that clicking a next button loaded a new page.
+const pageSignatureScript = `(function() {
+	var text = document.body ? document.body.innerText : '';
+	var hash = 0;
+	for (var i = 0; i < text.length; i++) {
+		hash = (hash * 31 + text.charCodeAt(i)) | 0;
+	}
+	return location.href + '|' + text.length + '|' + hash;
+})()`
+
+// selects the element found by findNextScript
+const nextMarker = "[data-autogcd-next]"
+
+// how long to wait for content to change after clicking a next button
+const paginationClickTimeout = 10 * time.Second
+
+// PaginationErr returned by FollowPagination when it is called without a PaginationFunc.
+type PaginationErr struct {
+	Message string
+}
+
+func (e *PaginationErr) Error() string {
+	return "pagination: " + e.Message
+}
+
+// PaginationFunc is called for every page of a paginated listing, page starts at 1. Returning an error
+// stops FollowPagination.
+type PaginationFunc func(tab *autogcd.Tab, page int) error
+
+// FollowPagination calls perPageFn for the tab's current page and then repeatedly moves to the next page using
+// the first enabled element matching nextSelector (RelNext if empty), until there is no next page, maxPages have
+// been processed (0 for no limit) or perPageFn returns an error. Links are followed by navigating to their
+// href, buttons and script links are clicked and the page is considered changed once its content differs.
+// Stops without error if a link leads to an already visited page or a click does not change the content.
+// Returns the number of pages processed, or a PaginationErr if perPageFn is nil.
+func FollowPagination(tab *autogcd.Tab, nextSelector string, maxPages int, perPageFn PaginationFunc) (int, error) {
+	if perPageFn == nil {
+		return 0, &PaginationErr{Message: "perPageFn must not be nil"}
+	}
+	if nextSelector == "" {
+		nextSelector = RelNext
+	}
+
+	visited := urlutil.NewDeduper(nil)
+	if current, err := tab.GetCurrentUrl(); err == nil {
+		visited.Add(current)
+	}
+
+	page := 1
+	for {
+		if err := perPageFn(tab, page); err != nil {
+			return page, err
+		}
+		if maxPages > 0 && page >= maxPages {
+			return page, nil
+		}
+
+		rro, err := tab.EvaluateScript(fmt.Sprintf(findNextScript, jsQuote(nextSelector)))
+		if err != nil {
+			return page, err
+		}
+		next, _ := rro.Value.(map[string]interface{})
+		if found, _ := next["found"].(bool); !found {
+			return page, nil
+		}
+
+		if href, _ := next["href"].(string); href != "" {
+			if _, isNew := visited.Add(href); !isNew {
+				return page, nil
+			}
+			if _, err := tab.Navigate(href); err != nil {
+				return page, err
+			}
+		} else {
+			changed, err := clickNext(tab)
+			if err != nil {
+				return page, err
+			}
+			if !changed {
+				return page, nil
+			}
+			if current, err := tab.GetCurrentUrl(); err == nil {
+				visited.Add(current)
+			}
+		}
+		page++
+	}
+}
+
+// clicks the element marked by findNextScript and waits for the page content to change.
+// Returns false if it did not change within paginationClickTimeout.
+func clickNext(tab *autogcd.Tab) (bool, error) {
+	before, err := pageSignature(tab)
+	if err != nil {
+		return false, err
+	}
+
+	elements, err := tab.GetElementsBySelector(nextMarker)
+	if err != nil {
+		return false, err
+	}
+	if len(elements) == 0 {
+		return false, nil
+	}
+	if err := elements[0].WaitForReady(); err != nil {
+		return false, err
+	}
+	if err := elements[0].Click(); err != nil {
+		return false, err
+	}
+
+	err = tab.WaitFor(100*time.Millisecond, paginationClickTimeout, func(tab *autogcd.Tab) bool {
+		after, err := pageSignature(tab)
+		return err == nil && after != before
+	})
+	if err != nil {
+		return false, nil
+	}
+	// let the rest of the new page render
+	tab.WaitStable()
+	return true, nil
+}
+
+func pageSignature(tab *autogcd.Tab) (string, error) {
+	rro, err := tab.EvaluateScript(pageSignatureScript)
+	if err != nil {
+		return "", err
+	}
+	signature, _ := rro.Value.(string)
+	return signature, nil
+}
+
+// quotes a string for use as a javascript string literal.
+func jsQuote(value string) string {
+	quoted, _ := json.Marshal(value)
+	return string(quoted)
+}
@@ -0,0 +1,69 @@
+package crawler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wirepair/autogcd"
+	"github.com/wirepair/autogcd/autogcdtest"
+)
+
+func TestFollowPaginationNilFunc(t *testing.T) {
+	if _, err := FollowPagination(nil, "", 0, nil); err == nil {
+		t.Fatalf("expected an error without a PaginationFunc")
+	}
+}
+
+func TestFollowPagination(t *testing.T) {
+	browser := autogcdtest.NewTestBrowser(t)
+	base := autogcdtest.FileServer(t, "../testdata")
+	tab := browser.NewTab(t)
+
+	contents := make([]string, 0)
+	collect := func(tab *autogcd.Tab, page int) error {
+		rro, err := tab.EvaluateScript("document.getElementById('content').textContent")
+		if err != nil {
+			return err
+		}
+		content, _ := rro.Value.(string)
+		contents = append(contents, content)
+		return nil
+	}
+
+	// the last page links back to the first, which has already been visited
+	if _, err := tab.Navigate(base + "paginate_link1.html"); err != nil {
+		t.Fatalf("error navigating: %s\n", err)
+	}
+	pages, err := FollowPagination(tab, "", 0, collect)
+	if err != nil {
+		t.Fatalf("error following links: %s\n", err)
+	}
+	if pages != 3 || strings.Join(contents, ",") != "page 1,page 2,page 3" {
+		t.Fatalf("expected 3 linked pages got %d %v\n", pages, contents)
+	}
+
+	contents = contents[:0]
+	if _, err := tab.Navigate(base + "paginate_link1.html"); err != nil {
+		t.Fatalf("error navigating: %s\n", err)
+	}
+	pages, err = FollowPagination(tab, RelNext, 2, collect)
+	if err != nil {
+		t.Fatalf("error following links: %s\n", err)
+	}
+	if pages != 2 || strings.Join(contents, ",") != "page 1,page 2" {
+		t.Fatalf("expected maxPages to stop after 2 pages got %d %v\n", pages, contents)
+	}
+
+	// clicking the button on the last page changes nothing
+	contents = contents[:0]
+	if _, err := tab.Navigate(base + "paginate_button.html"); err != nil {
+		t.Fatalf("error navigating: %s\n", err)
+	}
+	pages, err = FollowPagination(tab, "#next", 0, collect)
+	if err != nil {
+		t.Fatalf("error clicking next: %s\n", err)
+	}
+	if pages != 3 || strings.Join(contents, ",") != "page 1,page 2,page 3" {
+		t.Fatalf("expected 3 clicked pages got %d %v\n", pages, contents)
+	}
+}
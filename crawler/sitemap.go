@@ -0,0 +1,193 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package crawler
+
+import (
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wirepair/autogcd/robots"
+)
+
+// maximum number of sitemap files fetched for a single origin, guards against huge or cyclic indexes.
+const maxSitemapFiles = 50
+
+// formats allowed by the W3C datetime spec used for <lastmod>
+var lastModFormats = []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02"}
+
+// SitemapErr returned when a sitemap could not be fetched or parsed.
+type SitemapErr struct {
+	Url     string
+	Message string
+}
+
+func (e *SitemapErr) Error() string {
+	return "sitemap " + e.Url + ": " + e.Message
+}
+
+// SitemapEntry is a single <url> from a sitemap.
+type SitemapEntry struct {
+	Url        string
+	LastMod    time.Time // zero if not specified or invalid
+	ChangeFreq string
+	Priority   float64 // defaults to 0.5 as per the sitemap protocol
+}
+
+type xmlSitemapUrl struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod"`
+	ChangeFreq string `xml:"changefreq"`
+	Priority   string `xml:"priority"`
+}
+
+type xmlSitemap struct {
+	XMLName  xml.Name
+	Urls     []xmlSitemapUrl `xml:"url"`
+	Sitemaps []xmlSitemapUrl `xml:"sitemap"`
+}
+
+// ParseSitemap reads a sitemap (<urlset>) or sitemap index (<sitemapindex>) document. Entries of a
+// urlset are returned as SitemapEntry values, the locations of an index are returned as child sitemaps.
+func ParseSitemap(r io.Reader) ([]*SitemapEntry, []string, error) {
+	doc := &xmlSitemap{}
+	if err := xml.NewDecoder(r).Decode(doc); err != nil {
+		return nil, nil, err
+	}
+
+	entries := make([]*SitemapEntry, 0, len(doc.Urls))
+	for _, u := range doc.Urls {
+		loc := strings.TrimSpace(u.Loc)
+		if loc == "" {
+			continue
+		}
+		entry := &SitemapEntry{Url: loc, ChangeFreq: strings.TrimSpace(u.ChangeFreq), Priority: 0.5}
+		entry.LastMod = parseLastMod(strings.TrimSpace(u.LastMod))
+		if priority, err := strconv.ParseFloat(strings.TrimSpace(u.Priority), 64); err == nil && priority >= 0 && priority <= 1 {
+			entry.Priority = priority
+		}
+		entries = append(entries, entry)
+	}
+
+	children := make([]string, 0, len(doc.Sitemaps))
+	for _, s := range doc.Sitemaps {
+		if loc := strings.TrimSpace(s.Loc); loc != "" {
+			children = append(children, loc)
+		}
+	}
+	return entries, children, nil
+}
+
+func parseLastMod(value string) time.Time {
+	for _, format := range lastModFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// FetchSitemaps retrieves all sitemap entries for origin (scheme://host). Sitemap locations are
+// taken from robots.txt, falling back to /sitemap.xml. Sitemap index files are followed and
+// gzipped sitemaps are supported.
+func FetchSitemaps(client *http.Client, origin string) ([]*SitemapEntry, error) {
+	origin = strings.TrimSuffix(origin, "/")
+	queue := sitemapLocations(client, origin)
+
+	entries := make([]*SitemapEntry, 0)
+	fetched := make(map[string]struct{})
+	var lastErr error
+
+	for len(queue) > 0 && len(fetched) < maxSitemapFiles {
+		loc := queue[0]
+		queue = queue[1:]
+		if _, ok := fetched[loc]; ok {
+			continue
+		}
+		fetched[loc] = struct{}{}
+
+		found, children, err := fetchSitemap(client, loc)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		entries = append(entries, found...)
+		queue = append(queue, children...)
+	}
+
+	// only report an error if nothing could be retrieved
+	if len(entries) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return entries, nil
+}
+
+// returns the sitemaps listed in robots.txt, or the default location.
+func sitemapLocations(client *http.Client, origin string) []string {
+	resp, err := client.Get(origin + "/robots.txt")
+	if err == nil {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			if rules, err := robots.Parse(resp.Body); err == nil && len(rules.Sitemaps) > 0 {
+				return rules.Sitemaps
+			}
+		}
+	}
+	return []string{origin + "/sitemap.xml"}
+}
+
+func fetchSitemap(client *http.Client, loc string) ([]*SitemapEntry, []string, error) {
+	resp, err := client.Get(loc)
+	if err != nil {
+		return nil, nil, &SitemapErr{Url: loc, Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, &SitemapErr{Url: loc, Message: fmt.Sprintf("unexpected status code %d", resp.StatusCode)}
+	}
+
+	var body io.Reader = resp.Body
+	// net/http transparently decompresses Content-Encoding: gzip, but not .gz files
+	if strings.HasSuffix(resp.Request.URL.Path, ".gz") || resp.Header.Get("Content-Type") == "application/x-gzip" {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, nil, &SitemapErr{Url: loc, Message: err.Error()}
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	entries, children, err := ParseSitemap(body)
+	if err != nil {
+		return nil, nil, &SitemapErr{Url: loc, Message: err.Error()}
+	}
+	return entries, children, nil
+}
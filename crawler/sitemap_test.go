@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testSitemapIndex = `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%s/sitemap-pages.xml</loc></sitemap>
+</sitemapindex>`
+
+const testSitemap = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>%[1]s/low</loc><lastmod>2018-12-08</lastmod><priority>0.1</priority></url>
+  <url><loc>%[1]s/high</loc><lastmod>2018-12-08T10:00:00+00:00</lastmod><priority>0.9</priority></url>
+  <url><loc>%[1]s/default</loc></url>
+</urlset>`
+
+func TestParseSitemap(t *testing.T) {
+	entries, children, err := ParseSitemap(strings.NewReader(fmt.Sprintf(testSitemap, "http://localhost")))
+	if err != nil {
+		t.Fatalf("error parsing sitemap: %s\n", err)
+	}
+	if len(children) != 0 || len(entries) != 3 {
+		t.Fatalf("expected 3 entries and no children got %d %d\n", len(entries), len(children))
+	}
+	if entries[0].LastMod.Year() != 2018 || entries[0].Priority != 0.1 {
+		t.Fatalf("unexpected metadata %#v\n", entries[0])
+	}
+	if entries[1].LastMod.Hour() != 10 {
+		t.Fatalf("expected lastmod with time got %s\n", entries[1].LastMod)
+	}
+	if entries[2].Priority != 0.5 || !entries[2].LastMod.IsZero() {
+		t.Fatalf("expected default priority and no lastmod got %#v\n", entries[2])
+	}
+}
+
+func TestSeedFromSitemap(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			fmt.Fprintf(w, "User-agent: *\nSitemap: %s/sitemap-index.xml\n", server.URL)
+		case "/sitemap-index.xml":
+			fmt.Fprintf(w, testSitemapIndex, server.URL)
+		case "/sitemap-pages.xml":
+			fmt.Fprintf(w, testSitemap, server.URL)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := New(nil, nil)
+	added, err := c.SeedFromSitemap(server.URL)
+	if err != nil {
+		t.Fatalf("error seeding from sitemap: %s\n", err)
+	}
+	if added != 3 {
+		t.Fatalf("expected 3 urls to be added got %d\n", added)
+	}
+
+	entry, _ := c.Frontier().Pop()
+	if entry.Url != server.URL+"/high" || entry.Priority != 0.9 {
+		t.Fatalf("expected highest priority entry first got %#v\n", entry)
+	}
+}
@@ -0,0 +1,344 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package crawler
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// Store persists the state of a crawl: the queue of pending entries, the set of seen urls, the visited
+// pages and any results saved by the page handler. Urls passed to a Store are already canonicalized.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	Enqueue(entry *Entry) error                // add an entry to the end of the pending queue
+	Dequeue() (*Entry, bool, error)            // remove the next pending entry, false if there are none
+	Pending() (int, error)                     // number of pending entries
+	AddSeen(url string) (bool, error)          // mark url as seen, true if it was not seen before
+	Seen(url string) (bool, error)             // has url been seen
+	AddVisited(entry *Entry) error             // record that entry's page was visited
+	Visited() (int, error)                     // number of visited pages
+	PutResult(url string, result []byte) error // save the result extracted from url, replacing any previous result
+	Result(url string) ([]byte, bool, error)   // the result saved for url, false if there is none
+	Close() error                              // flush and release the store
+}
+
+// MemoryStore keeps crawl state in memory, it is the default store of a Frontier.
+type MemoryStore struct {
+	lock    *sync.Mutex
+	queue   []*Entry
+	seen    map[string]struct{}
+	visited int
+	results map[string][]byte
+}
+
+// NewMemoryStore creates an empty in memory store.
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{}
+	s.lock = &sync.Mutex{}
+	s.queue = make([]*Entry, 0)
+	s.seen = make(map[string]struct{})
+	s.results = make(map[string][]byte)
+	return s
+}
+
+func (s *MemoryStore) Enqueue(entry *Entry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.queue = append(s.queue, entry)
+	return nil
+}
+
+func (s *MemoryStore) Dequeue() (*Entry, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.queue) == 0 {
+		return nil, false, nil
+	}
+	entry := s.queue[0]
+	s.queue[0] = nil
+	s.queue = s.queue[1:]
+	return entry, true, nil
+}
+
+func (s *MemoryStore) Pending() (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.queue), nil
+}
+
+func (s *MemoryStore) AddSeen(url string) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.seen[url]; ok {
+		return false, nil
+	}
+	s.seen[url] = struct{}{}
+	return true, nil
+}
+
+func (s *MemoryStore) Seen(url string) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, ok := s.seen[url]
+	return ok, nil
+}
+
+func (s *MemoryStore) AddVisited(entry *Entry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.visited++
+	return nil
+}
+
+func (s *MemoryStore) Visited() (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.visited, nil
+}
+
+func (s *MemoryStore) PutResult(url string, result []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.results[url] = append([]byte(nil), result...)
+	return nil
+}
+
+func (s *MemoryStore) Result(url string) ([]byte, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	result, ok := s.results[url]
+	return result, ok, nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// a single operation in a FileStore journal
+type journalRecord struct {
+	Op     string `json:"op"`
+	Entry  *Entry `json:"entry,omitempty"`
+	Url    string `json:"url,omitempty"`
+	Result []byte `json:"result,omitempty"`
+}
+
+// FileStore persists crawl state to an append only journal file so a crawl can be stopped and resumed by
+// opening the same file again. The queue and seen set are kept in memory, results are read back from the
+// journal when requested. Entries that were dequeued but not recorded as visited when the process stopped,
+// such as the page being crawled or one that failed to load, are requeued ahead of the other pending entries.
+type FileStore struct {
+	*MemoryStore
+	file    *os.File
+	writer  *bufio.Writer
+	offsets map[string]int64 // url => offset of its latest result record
+	size    int64            // current length of the journal
+}
+
+// OpenFileStore opens (or creates) the journal at path and replays it to restore the crawl state.
+func OpenFileStore(path string) (*FileStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileStore{MemoryStore: NewMemoryStore(), file: file}
+	s.offsets = make(map[string]int64)
+	if err := s.replay(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(s.size, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	s.writer = bufio.NewWriter(file)
+	return s, nil
+}
+
+// rebuilds the in memory state from the journal, a partially written last record is discarded.
+func (s *FileStore) replay() error {
+	reader := bufio.NewReader(s.file)
+	var offset int64
+	inflight := make([]*Entry, 0) // dequeued entries without a visited record, in the order they were dequeued
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		record := &journalRecord{}
+		if err := json.Unmarshal(line, record); err != nil {
+			return err
+		}
+		switch record.Op {
+		case "enqueue":
+			s.MemoryStore.Enqueue(record.Entry)
+		case "dequeue":
+			if entry, ok, _ := s.MemoryStore.Dequeue(); ok {
+				inflight = append(inflight, entry)
+			}
+		case "seen":
+			s.MemoryStore.AddSeen(record.Url)
+		case "visited":
+			s.MemoryStore.AddVisited(record.Entry)
+			inflight = removeEntry(inflight, record.Entry)
+		case "result":
+			s.offsets[record.Url] = offset
+		}
+		offset += int64(len(line))
+	}
+	s.size = offset
+	// requeued in the order they were dequeued, which is also the order a later replay dequeues them in
+	s.queue = append(inflight, s.queue...)
+	return s.file.Truncate(offset)
+}
+
+// removes the first entry with the same url as visited.
+func removeEntry(entries []*Entry, visited *Entry) []*Entry {
+	if visited == nil {
+		return entries
+	}
+	for i, entry := range entries {
+		if entry.Url == visited.Url {
+			return append(entries[:i], entries[i+1:]...)
+		}
+	}
+	return entries
+}
+
+// appends a record to the journal, caller must hold the lock. Records are flushed before returning so
+// an interrupted crawl loses at most the record being written.
+func (s *FileStore) append(record *journalRecord) (int64, error) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return 0, err
+	}
+	line = append(line, '\n')
+	offset := s.size
+	if _, err := s.writer.Write(line); err != nil {
+		return 0, err
+	}
+	if err := s.writer.Flush(); err != nil {
+		return 0, err
+	}
+	s.size += int64(len(line))
+	return offset, nil
+}
+
+func (s *FileStore) Enqueue(entry *Entry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, err := s.append(&journalRecord{Op: "enqueue", Entry: entry}); err != nil {
+		return err
+	}
+	s.queue = append(s.queue, entry)
+	return nil
+}
+
+func (s *FileStore) Dequeue() (*Entry, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.queue) == 0 {
+		return nil, false, nil
+	}
+	if _, err := s.append(&journalRecord{Op: "dequeue"}); err != nil {
+		return nil, false, err
+	}
+	entry := s.queue[0]
+	s.queue[0] = nil
+	s.queue = s.queue[1:]
+	return entry, true, nil
+}
+
+func (s *FileStore) AddSeen(url string) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.seen[url]; ok {
+		return false, nil
+	}
+	if _, err := s.append(&journalRecord{Op: "seen", Url: url}); err != nil {
+		return false, err
+	}
+	s.seen[url] = struct{}{}
+	return true, nil
+}
+
+func (s *FileStore) AddVisited(entry *Entry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, err := s.append(&journalRecord{Op: "visited", Entry: entry}); err != nil {
+		return err
+	}
+	s.visited++
+	return nil
+}
+
+func (s *FileStore) PutResult(url string, result []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	offset, err := s.append(&journalRecord{Op: "result", Url: url, Result: result})
+	if err != nil {
+		return err
+	}
+	s.offsets[url] = offset
+	return nil
+}
+
+func (s *FileStore) Result(url string) ([]byte, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	offset, ok := s.offsets[url]
+	if !ok {
+		return nil, false, nil
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(s.file, offset, s.size-offset))
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return nil, false, err
+	}
+	record := &journalRecord{}
+	if err := json.Unmarshal(line, record); err != nil {
+		return nil, false, err
+	}
+	return record.Result, true, nil
+}
+
+func (s *FileStore) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.writer.Flush(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
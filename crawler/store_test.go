@@ -0,0 +1,111 @@
+package crawler
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStoreResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crawlstore")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %s\n", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "crawl.journal")
+
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("error opening store: %s\n", err)
+	}
+
+	f := NewFrontierWithStore(nil, store)
+	f.Push(&Entry{Url: "http://localhost/a"})
+	f.Push(&Entry{Url: "http://localhost/b", Depth: 1})
+	entry, _ := f.Pop()
+	store.AddVisited(entry)
+	if err := store.PutResult(entry.Url, []byte("first")); err != nil {
+		t.Fatalf("error saving result: %s\n", err)
+	}
+	store.PutResult(entry.Url, []byte("second"))
+	if err := store.Close(); err != nil {
+		t.Fatalf("error closing store: %s\n", err)
+	}
+
+	store, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("error reopening store: %s\n", err)
+	}
+	defer store.Close()
+
+	f = NewFrontierWithStore(nil, store)
+	if f.Len() != 1 || !f.Seen("http://localhost/a") {
+		t.Fatalf("expected one pending entry and a seen url after resuming, got %d pending\n", f.Len())
+	}
+	if f.Push(&Entry{Url: "http://localhost/a"}) {
+		t.Fatalf("expected visited url to be rejected after resuming\n")
+	}
+
+	entry, ok := f.Pop()
+	if !ok || entry.Url != "http://localhost/b" || entry.Depth != 1 {
+		t.Fatalf("unexpected entry after resuming %#v\n", entry)
+	}
+
+	if visited, _ := store.Visited(); visited != 1 {
+		t.Fatalf("expected 1 visited page got %d\n", visited)
+	}
+
+	result, ok, err := store.Result("http://localhost/a")
+	if err != nil || !ok || string(result) != "second" {
+		t.Fatalf("expected latest result to be restored got %q %v %v\n", result, ok, err)
+	}
+}
+
+func TestFileStoreRequeuesUnvisited(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crawlstore")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %s\n", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "crawl.journal")
+
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("error opening store: %s\n", err)
+	}
+	f := NewFrontierWithStore(nil, store)
+	f.Push(&Entry{Url: "http://localhost/a"})
+	f.Push(&Entry{Url: "http://localhost/b"})
+	f.Push(&Entry{Url: "http://localhost/c"})
+	visited, _ := f.Pop()
+	store.AddVisited(visited)
+	f.Pop() // stopped while b was being crawled
+	store.Close()
+
+	store, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("error reopening store: %s\n", err)
+	}
+	f = NewFrontierWithStore(nil, store)
+	entry, ok := f.Pop()
+	if !ok || entry.Url != "http://localhost/b" {
+		t.Fatalf("expected the in flight entry to be requeued first got %#v\n", entry)
+	}
+	store.AddVisited(entry)
+	store.Close()
+
+	// the requeued entry was visited, only c is left
+	store, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("error reopening store: %s\n", err)
+	}
+	defer store.Close()
+	if pending, _ := store.Pending(); pending != 1 {
+		t.Fatalf("expected 1 pending entry got %d\n", pending)
+	}
+	entry, ok, _ = store.Dequeue()
+	if !ok || entry.Url != "http://localhost/c" {
+		t.Fatalf("expected c to be pending got %#v\n", entry)
+	}
+}
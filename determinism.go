@@ -0,0 +1,96 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+	"time"
+)
+
+// Replaces Date so that new Date() and Date.now() always return the frozen time, dates created from
+// explicit values are unaffected.
+const freezeTimeScript = `(function(now) {
+	var NativeDate = Date;
+	var FrozenDate = function() {
+		if (!new.target) {
+			return new NativeDate(now).toString();
+		}
+		var args = arguments.length === 0 ? [now] : Array.prototype.slice.call(arguments);
+		return Reflect.construct(NativeDate, args, new.target);
+	};
+	FrozenDate.prototype = NativeDate.prototype;
+	FrozenDate.now = function() {
+		return now;
+	};
+	FrozenDate.parse = NativeDate.parse;
+	FrozenDate.UTC = NativeDate.UTC;
+	window.Date = FrozenDate;
+})(%d)`
+
+// Replaces Math.random with a seeded mulberry32 generator.
+const seedRandomScript = `(function(seed) {
+	var state = seed >>> 0;
+	Math.random = function() {
+		state = (state + 0x6D2B79F5) >>> 0;
+		var t = state;
+		t = Math.imul(t ^ (t >>> 15), t | 1);
+		t ^= t + Math.imul(t ^ (t >>> 7), t | 61);
+		return ((t ^ (t >>> 14)) >>> 0) / 4294967296;
+	};
+})(%d)`
+
+// FreezeTime makes new Date() and Date.now() return start in every document loaded after the call, so
+// pages which display or compute with the current time behave the same on every run. The current document
+// is not modified, call it before Navigate or Reload. Calling it again replaces the frozen time.
+func (t *Tab) FreezeTime(start time.Time) error {
+	return t.setNewDocumentScript("time", fmt.Sprintf(freezeTimeScript, start.UnixNano()/int64(time.Millisecond)))
+}
+
+// SeedRandom replaces Math.random with a generator seeded by seed in every document loaded after the call,
+// so the same seed produces the same sequence of random numbers. Only the low 32 bits of seed are used. The
+// current document is not modified, call it before Navigate or Reload. Calling it again replaces the seed.
+func (t *Tab) SeedRandom(seed int64) error {
+	return t.setNewDocumentScript("random", fmt.Sprintf(seedRandomScript, uint32(seed)))
+}
+
+// adds script to be evaluated on every new document, replacing the previous script added for the same purpose.
+func (t *Tab) setNewDocumentScript(purpose, script string) error {
+	scriptId, err := t.Page.AddScriptToEvaluateOnNewDocument(script, "")
+	if err != nil {
+		return err
+	}
+
+	t.bindingLock.Lock()
+	previousId, exists := t.newDocScripts[purpose]
+	t.newDocScripts[purpose] = scriptId
+	t.bindingLock.Unlock()
+
+	if exists {
+		if _, err := t.Page.RemoveScriptToEvaluateOnNewDocument(previousId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
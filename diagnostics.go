@@ -0,0 +1,161 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Names of the files written by CollectDiagnostics
+const (
+	DiagnosticsHistogramsFile  = "histograms.json"
+	DiagnosticsCommandLineFile = "commandline.txt"
+	DiagnosticsCrashDir        = "crashes"
+	DiagnosticsSummaryFile     = "diagnostics.json"
+)
+
+// directories of the user data dir crashpad writes minidumps to
+var crashDumpDirs = []string{"Crashpad", "Crash Reports"}
+
+// Diagnostics describes the browser state saved by CollectDiagnostics, it is written to diagnostics.json.
+type Diagnostics struct {
+	Timestamp       time.Time         `json:"timestamp"`             // when collection started
+	Revision        string            `json:"revision"`              // revision of the protocol gcd was generated from
+	ProtocolVersion string            `json:"protocolVersion"`       // protocol version reported by the browser
+	Product         string            `json:"product"`               // browser product and version
+	UserAgent       string            `json:"userAgent"`             // browser user agent
+	JsVersion       string            `json:"jsVersion"`             // V8 version
+	CommandLine     []string          `json:"commandLine,omitempty"` // the browser's command line, only available if started with --enable-automation
+	Histograms      int               `json:"histograms"`            // number of histograms written to histograms.json
+	CrashDumps      []string          `json:"crashDumps,omitempty"`  // crash dumps copied to the crashes directory, relative to the user data dir
+	Errors          map[string]string `json:"errors,omitempty"`      // item => why it could not be collected
+}
+
+// CollectDiagnostics saves information useful for reporting browser problems to dir, creating it if needed:
+// the browser's histograms, version, command line and any crashpad minidumps found in the user data dir.
+// Collection is best effort, items which could not be collected are listed in the summary's Errors. Only
+// crash dumps are collected if Chrome is no longer responding. An error is only returned if dir or the summary
+// could not be written.
+func (auto *AutoGcd) CollectDiagnostics(dir string) (*Diagnostics, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	diagnostics := &Diagnostics{Timestamp: time.Now().UTC(), Revision: auto.GetChromeRevision(), Errors: make(map[string]string)}
+
+	if tab, err := auto.GetTab(); err != nil {
+		diagnostics.Errors["browser"] = err.Error()
+	} else {
+		auto.collectBrowserDiagnostics(tab, dir, diagnostics)
+	}
+
+	if auto.userDir != "" {
+		dumps, err := copyCrashDumps(auto.userDir, filepath.Join(dir, DiagnosticsCrashDir))
+		diagnostics.CrashDumps = dumps
+		if err != nil {
+			diagnostics.Errors[DiagnosticsCrashDir] = err.Error()
+		}
+	}
+
+	encoded, err := json.MarshalIndent(diagnostics, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, DiagnosticsSummaryFile), encoded, 0644); err != nil {
+		return nil, err
+	}
+	return diagnostics, nil
+}
+
+// queries the browser domain through tab for the version, command line and histograms.
+func (auto *AutoGcd) collectBrowserDiagnostics(tab *Tab, dir string, diagnostics *Diagnostics) {
+	var err error
+	diagnostics.ProtocolVersion, diagnostics.Product, _, diagnostics.UserAgent, diagnostics.JsVersion, err = tab.Browser.GetVersion()
+	if err != nil {
+		diagnostics.Errors["version"] = err.Error()
+	}
+
+	if diagnostics.CommandLine, err = tab.Browser.GetBrowserCommandLine(); err == nil {
+		err = ioutil.WriteFile(filepath.Join(dir, DiagnosticsCommandLineFile), []byte(strings.Join(diagnostics.CommandLine, "\n")), 0644)
+	}
+	if err != nil {
+		diagnostics.Errors[DiagnosticsCommandLineFile] = err.Error()
+	}
+
+	histograms, err := tab.Browser.GetHistograms("", false)
+	if err == nil {
+		diagnostics.Histograms = len(histograms)
+		var encoded []byte
+		if encoded, err = json.MarshalIndent(histograms, "", "  "); err == nil {
+			err = ioutil.WriteFile(filepath.Join(dir, DiagnosticsHistogramsFile), encoded, 0644)
+		}
+	}
+	if err != nil {
+		diagnostics.Errors[DiagnosticsHistogramsFile] = err.Error()
+	}
+}
+
+// copies every minidump under the crash directories of userDir to dst, keeping their relative paths.
+// Returns the paths copied relative to userDir.
+func copyCrashDumps(userDir, dst string) ([]string, error) {
+	copied := make([]string, 0)
+	for _, crashDir := range crashDumpDirs {
+		root := filepath.Join(userDir, crashDir)
+		if _, err := os.Stat(root); err != nil {
+			continue
+		}
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || filepath.Ext(path) != ".dmp" {
+				return err
+			}
+			rel, err := filepath.Rel(userDir, path)
+			if err != nil {
+				return err
+			}
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			target := filepath.Join(dst, rel)
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(target, data, 0644); err != nil {
+				return err
+			}
+			copied = append(copied, rel)
+			return nil
+		})
+		if err != nil {
+			return copied, err
+		}
+	}
+	return copied, nil
+}
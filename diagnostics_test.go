@@ -0,0 +1,49 @@
+package autogcd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyCrashDumps(t *testing.T) {
+	userDir, err := ioutil.TempDir("", "autogcd-userdir")
+	if err != nil {
+		t.Fatalf("error creating user dir: %s\n", err)
+	}
+	defer os.RemoveAll(userDir)
+
+	files := map[string]string{
+		"Crashpad/completed/one.dmp": "dump one",
+		"Crashpad/pending/two.dmp":   "dump two",
+		"Crashpad/settings.dat":      "not a dump",
+		"Default/Preferences":        "{}",
+		"Crash Reports/three.dmp":    "dump three",
+	}
+	for name, contents := range files {
+		path := filepath.Join(userDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("error writing %s: %s\n", name, err)
+		}
+	}
+
+	dst := filepath.Join(userDir, "out")
+	copied, err := copyCrashDumps(userDir, dst)
+	if err != nil {
+		t.Fatalf("error copying crash dumps: %s\n", err)
+	}
+	if len(copied) != 3 {
+		t.Fatalf("expected 3 crash dumps got %v\n", copied)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dst, "Crashpad", "pending", "two.dmp"))
+	if err != nil || string(data) != "dump two" {
+		t.Fatalf("expected dump to be copied with its relative path: %s %v\n", data, err)
+	}
+
+	if copied, err := copyCrashDumps(filepath.Join(userDir, "missing"), dst); err != nil || len(copied) != 0 {
+		t.Fatalf("expected no dumps for a missing user dir got %v %v\n", copied, err)
+	}
+}
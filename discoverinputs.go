@@ -0,0 +1,202 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+)
+
+// Describes every form, and the inputs outside of forms, in the document and each frame it can reach.
+// Frames from other origins can not be read and are skipped.
+const discoverInputsScript = `(function() {
+	var results = [];
+	function intAttr(el, name) {
+		var value = el.getAttribute(name);
+		return value === null || isNaN(parseInt(value, 10)) ? -1 : parseInt(value, 10);
+	}
+	function describeInput(el) {
+		var tag = el.tagName.toLowerCase();
+		var input = {
+			tag: tag,
+			type: tag === 'input' ? (el.getAttribute('type') || 'text').toLowerCase() : (tag === 'select' || tag === 'textarea' ? tag : (el.getAttribute('type') || 'submit').toLowerCase()),
+			name: el.getAttribute('name') || '',
+			id: el.id || '',
+			value: tag === 'select' ? '' : (el.getAttribute('value') || (tag === 'textarea' ? el.defaultValue : '') || ''),
+			required: el.hasAttribute('required'),
+			pattern: el.getAttribute('pattern') || '',
+			minLength: intAttr(el, 'minlength'),
+			maxLength: intAttr(el, 'maxlength'),
+			min: el.getAttribute('min') || '',
+			max: el.getAttribute('max') || '',
+			step: el.getAttribute('step') || '',
+			accept: el.getAttribute('accept') || '',
+			autocomplete: el.getAttribute('autocomplete') || '',
+			multiple: el.hasAttribute('multiple'),
+			disabled: el.disabled === true,
+			readOnly: el.hasAttribute('readonly'),
+			options: []
+		};
+		if (tag === 'select') {
+			for (var i = 0; i < el.options.length; i++) {
+				input.options.push(el.options[i].value);
+			}
+		}
+		return input;
+	}
+	function isField(el) {
+		var tag = el.tagName.toLowerCase();
+		return tag === 'input' || tag === 'select' || tag === 'textarea' || tag === 'button';
+	}
+	function walk(win) {
+		var doc;
+		try {
+			doc = win.document;
+			doc.location.href;
+		} catch (e) {
+			return;
+		}
+		var forms = doc.querySelectorAll('form');
+		for (var i = 0; i < forms.length; i++) {
+			var form = forms[i];
+			var described = {
+				frameUrl: doc.location.href,
+				id: form.id || '',
+				name: form.getAttribute('name') || '',
+				method: (form.getAttribute('method') || 'get').toUpperCase(),
+				action: form.action || doc.location.href,
+				enctype: form.enctype || '',
+				target: form.getAttribute('target') || '',
+				noValidate: form.noValidate === true,
+				formless: false,
+				inputs: []
+			};
+			for (var j = 0; j < form.elements.length; j++) {
+				if (isField(form.elements[j])) {
+					described.inputs.push(describeInput(form.elements[j]));
+				}
+			}
+			results.push(described);
+		}
+		var loose = {frameUrl: doc.location.href, id: '', name: '', method: '', action: '', enctype: '', target: '', noValidate: false, formless: true, inputs: []};
+		var fields = doc.querySelectorAll('input, select, textarea, button');
+		for (var k = 0; k < fields.length; k++) {
+			if (!fields[k].form) {
+				loose.inputs.push(describeInput(fields[k]));
+			}
+		}
+		if (loose.inputs.length > 0) {
+			results.push(loose);
+		}
+		for (var f = 0; f < win.frames.length; f++) {
+			walk(win.frames[f]);
+		}
+	}
+	walk(window);
+	return results;
+})()`
+
+// FormInfo describes a form found by DiscoverInputs.
+type FormInfo struct {
+	FrameUrl   string       `json:"frameUrl"`   // url of the document containing the form
+	Id         string       `json:"id"`         // id attribute
+	Name       string       `json:"name"`       // name attribute
+	Method     string       `json:"method"`     // GET, POST or DIALOG, empty for Formless
+	Action     string       `json:"action"`     // absolute url the form submits to, the document url if the form has no action
+	Enctype    string       `json:"enctype"`    // encoding of the submitted data
+	Target     string       `json:"target"`     // target attribute
+	NoValidate bool         `json:"noValidate"` // the form disables client side validation
+	Formless   bool         `json:"formless"`   // holds the inputs of FrameUrl that do not belong to any form, usually submitted by script
+	Inputs     []*FormInput `json:"inputs"`     // fields in document order
+}
+
+// FormInput describes a field of a form found by DiscoverInputs, MinLength and MaxLength are -1 if not set.
+type FormInput struct {
+	Tag          string   `json:"tag"`          // input, select, textarea or button
+	Type         string   `json:"type"`         // input or button type, select or textarea for those elements
+	Name         string   `json:"name"`         // name the value is submitted as
+	Id           string   `json:"id"`           // id attribute
+	Value        string   `json:"value"`        // default value
+	Required     bool     `json:"required"`     // required attribute
+	Pattern      string   `json:"pattern"`      // pattern the value must match
+	MinLength    int      `json:"minLength"`    // minlength attribute
+	MaxLength    int      `json:"maxLength"`    // maxlength attribute
+	Min          string   `json:"min"`          // min attribute of number, date and range inputs
+	Max          string   `json:"max"`          // max attribute of number, date and range inputs
+	Step         string   `json:"step"`         // step attribute
+	Accept       string   `json:"accept"`       // accepted file types of file inputs
+	Autocomplete string   `json:"autocomplete"` // autocomplete attribute
+	Multiple     bool     `json:"multiple"`     // multiple values (select, file and email inputs)
+	Disabled     bool     `json:"disabled"`     // disabled fields are not submitted
+	ReadOnly     bool     `json:"readOnly"`     // readonly attribute
+	Options      []string `json:"options"`      // values of a select's options
+}
+
+// DiscoverInputs returns every form in the document and the frames it can reach, with their fields and
+// client side validation attributes, followed by those of out of process iframes attached with
+// AttachFrameTargets. Fields outside of any form are grouped per document into a FormInfo with Formless set.
+// Same process frames from other origins can not be read and are skipped.
+func (t *Tab) DiscoverInputs() ([]*FormInfo, error) {
+	rro, err := t.evaluateScript(discoverInputsScript, false)
+	if err != nil {
+		return nil, err
+	}
+	forms, err := decodeForms(rro.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range t.ChildSessions() {
+		if session.Type != "iframe" || session.IsDetached() {
+			continue
+		}
+		value, err := session.EvaluateScript(discoverInputsScript)
+		if err != nil {
+			t.debugf("unable to discover inputs of frame %s: %s\n", session.Url, err)
+			continue
+		}
+		frameForms, err := decodeForms(value)
+		if err != nil {
+			return nil, err
+		}
+		forms = append(forms, frameForms...)
+	}
+	return forms, nil
+}
+
+// converts the value returned by discoverInputsScript to FormInfos.
+func decodeForms(value interface{}) ([]*FormInfo, error) {
+	forms := make([]*FormInfo, 0)
+	if value == nil {
+		return forms, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(encoded, &forms); err != nil {
+		return nil, err
+	}
+	return forms, nil
+}
@@ -0,0 +1,28 @@
+package autogcd
+
+import (
+	"testing"
+)
+
+func TestDecodeForms(t *testing.T) {
+	value := []interface{}{
+		map[string]interface{}{"frameUrl": "http://localhost/", "method": "POST", "action": "http://localhost/login", "inputs": []interface{}{
+			map[string]interface{}{"tag": "input", "type": "password", "name": "pass", "required": true, "minLength": 8.0, "maxLength": -1.0},
+		}},
+	}
+
+	forms, err := decodeForms(value)
+	if err != nil {
+		t.Fatalf("error decoding forms: %s\n", err)
+	}
+	if len(forms) != 1 || forms[0].Method != "POST" || len(forms[0].Inputs) != 1 {
+		t.Fatalf("unexpected forms %#v\n", forms)
+	}
+	if input := forms[0].Inputs[0]; input.Name != "pass" || !input.Required || input.MinLength != 8 || input.MaxLength != -1 {
+		t.Fatalf("unexpected input %#v\n", input)
+	}
+
+	if forms, err := decodeForms(nil); err != nil || len(forms) != 0 {
+		t.Fatalf("expected no forms got %v %v\n", forms, err)
+	}
+}
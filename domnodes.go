@@ -0,0 +1,151 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+// Counts every node (elements, text, comments) in the top level document.
+const countDOMNodesScript = `(function() {
+	var count = 1;
+	var walker = document.createTreeWalker(document, NodeFilter.SHOW_ALL, null, false);
+	while (walker.nextNode()) {
+		count++;
+	}
+	return count;
+})()`
+
+// Called on the array returned from Runtime.queryObjects(Node.prototype). Returns the number of nodes that
+// belong to this document but are not connected to it, and a description of each detached subtree root.
+const findDetachedNodesFunction = `function() {
+	function describe(node) {
+		var description = node.nodeName.toLowerCase();
+		if (node.id) {
+			description += '#' + node.id;
+		}
+		if (typeof node.className === 'string' && node.className.trim() !== '') {
+			description += '.' + node.className.trim().split(/\s+/).join('.');
+		}
+		return description;
+	}
+	function size(node) {
+		var count = 0;
+		var walker = document.createTreeWalker(node, NodeFilter.SHOW_ALL, null, false);
+		do { count++; } while (walker.nextNode());
+		return count;
+	}
+	var result = {count: 0, roots: []};
+	for (var i = 0; i < this.length; i++) {
+		var node = this[i];
+		if (node.ownerDocument !== document || node.isConnected || node.nodeType === Node.DOCUMENT_FRAGMENT_NODE) {
+			continue;
+		}
+		result.count++;
+		if (node.parentNode === null) {
+			result.roots.push({nodeName: node.nodeName, description: describe(node), size: size(node)});
+		}
+	}
+	return result;
+}`
+
+// object group for remote objects created while finding detached nodes
+const detachedNodesObjectGroup = "autogcdDetached"
+
+// DetachedNode is the root of a subtree that has been removed from the document but is still referenced
+// from JavaScript, and so can not be garbage collected.
+type DetachedNode struct {
+	NodeName    string // node name of the root, e.g. DIV
+	Description string // tag, id and classes of the root, e.g. div#menu.open
+	Size        int    // number of nodes in the detached subtree, including the root
+}
+
+// DetachedNodesReport returned from FindDetachedNodes.
+type DetachedNodesReport struct {
+	Count int             // number of detached nodes, including the children of detached roots
+	Roots []*DetachedNode // the root of each detached subtree
+}
+
+// CountDOMNodes returns the number of nodes (including text and comment nodes) in the top level
+// document. Record it before and after an interaction to catch DOM growth regressions.
+func (t *Tab) CountDOMNodes() (int, error) {
+	rro, err := t.evaluateScript(countDOMNodesScript, false)
+	if err != nil {
+		return 0, err
+	}
+	count, _ := rro.Value.(float64)
+	return int(count), nil
+}
+
+// FindDetachedNodes forces garbage collection and then searches the JavaScript heap for nodes of the top
+// level document that are no longer attached to it. Nodes that survive garbage collection are being kept
+// alive by a reference from JavaScript, usually a sign of a DOM leak.
+func (t *Tab) FindDetachedNodes() (*DetachedNodesReport, error) {
+	if err := t.CollectGarbage(); err != nil {
+		return nil, err
+	}
+	defer t.Runtime.ReleaseObjectGroup(detachedNodesObjectGroup)
+
+	prototype, exception, err := overridenRuntimeEvaluate(t.targeter(), "Node.prototype", detachedNodesObjectGroup, false, true, 0, false, false, false, false)
+	if err != nil {
+		return nil, err
+	}
+	if exception != nil {
+		return nil, &ScriptEvaluationErr{Message: "error getting Node prototype: ", ExceptionText: exception.Text, ExceptionDetails: exception}
+	}
+
+	nodes, err := t.Runtime.QueryObjects(prototype.ObjectId, detachedNodesObjectGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	rro, exception, err := t.Runtime.CallFunctionOn(findDetachedNodesFunction, nodes.ObjectId, nil, true, true, false, false, false, 0, detachedNodesObjectGroup)
+	if err != nil {
+		return nil, err
+	}
+	if exception != nil {
+		return nil, &ScriptEvaluationErr{Message: "error finding detached nodes: ", ExceptionText: exception.Text, ExceptionDetails: exception}
+	}
+
+	report := &DetachedNodesReport{Roots: make([]*DetachedNode, 0)}
+	result, ok := rro.Value.(map[string]interface{})
+	if !ok {
+		return report, nil
+	}
+
+	count, _ := result["count"].(float64)
+	report.Count = int(count)
+
+	roots, _ := result["roots"].([]interface{})
+	for _, root := range roots {
+		values, ok := root.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		detached := &DetachedNode{}
+		detached.NodeName, _ = values["nodeName"].(string)
+		detached.Description, _ = values["description"].(string)
+		size, _ := values["size"].(float64)
+		detached.Size = int(size)
+		report.Roots = append(report.Roots, detached)
+	}
+	return report, nil
+}
@@ -0,0 +1,186 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// name of the binding instrumented DOM sinks report calls to
+const domSinkBindingName = "__autogcdSink"
+
+// Wraps the setters and functions commonly used as DOM XSS sinks so string values written to them are
+// reported to the binding with a stack trace. Wrappers pass through once __autogcdSinks.disabled is set.
+const instrumentDOMSinksScript = `(function(bindingName) {
+	if (window.__autogcdSinks) {
+		window.__autogcdSinks.disabled = false;
+		return true;
+	}
+	var state = window.__autogcdSinks = {disabled: false, reporting: false};
+	function report(sink, value) {
+		if (state.disabled || state.reporting || typeof value !== 'string') {
+			return;
+		}
+		state.reporting = true;
+		try {
+			window[bindingName](JSON.stringify({sink: sink, value: value, url: location.href, stack: new Error().stack || ''}));
+		} catch (e) {
+		} finally {
+			state.reporting = false;
+		}
+	}
+	function hookSetter(proto, property, sink) {
+		var descriptor = proto && Object.getOwnPropertyDescriptor(proto, property);
+		if (!descriptor || !descriptor.set || !descriptor.configurable) {
+			return;
+		}
+		var set = descriptor.set;
+		descriptor.set = function(value) {
+			report(sink, value);
+			return set.call(this, value);
+		};
+		Object.defineProperty(proto, property, descriptor);
+	}
+	function hookMethod(owner, name, sink, argument) {
+		var original = owner && owner[name];
+		if (typeof original !== 'function') {
+			return;
+		}
+		var wrapper = function() {
+			report(sink, arguments[argument]);
+			if (new.target) {
+				return Reflect.construct(original, arguments, new.target);
+			}
+			return original.apply(this, arguments);
+		};
+		wrapper.prototype = original.prototype;
+		owner[name] = wrapper;
+	}
+	hookSetter(Element.prototype, 'innerHTML', 'Element.innerHTML');
+	hookSetter(Element.prototype, 'outerHTML', 'Element.outerHTML');
+	hookSetter(window.ShadowRoot && ShadowRoot.prototype, 'innerHTML', 'ShadowRoot.innerHTML');
+	hookSetter(HTMLIFrameElement.prototype, 'srcdoc', 'HTMLIFrameElement.srcdoc');
+	hookSetter(HTMLScriptElement.prototype, 'src', 'HTMLScriptElement.src');
+	hookSetter(HTMLScriptElement.prototype, 'text', 'HTMLScriptElement.text');
+	hookSetter(HTMLAnchorElement.prototype, 'href', 'HTMLAnchorElement.href');
+	hookMethod(Element.prototype, 'insertAdjacentHTML', 'Element.insertAdjacentHTML', 1);
+	hookMethod(Element.prototype, 'setHTMLUnsafe', 'Element.setHTMLUnsafe', 0);
+	hookMethod(Document.prototype, 'write', 'document.write', 0);
+	hookMethod(Document.prototype, 'writeln', 'document.writeln', 0);
+	hookMethod(Range.prototype, 'createContextualFragment', 'Range.createContextualFragment', 0);
+	hookMethod(DOMParser.prototype, 'parseFromString', 'DOMParser.parseFromString', 0);
+	hookMethod(window, 'eval', 'eval', 0);
+	hookMethod(window, 'Function', 'Function', 0);
+	hookMethod(window, 'setTimeout', 'setTimeout', 0);
+	hookMethod(window, 'setInterval', 'setInterval', 0);
+	return true;
+})(%s)`
+
+// Lets the instrumented sinks in the current document pass values through without reporting.
+const disableDOMSinksScript = `if (window.__autogcdSinks) { window.__autogcdSinks.disabled = true; }`
+
+// DOMSinkCall is a string written to a DOM XSS sink, see InstrumentDOMSinks.
+type DOMSinkCall struct {
+	Sink  string `json:"sink"`  // the sink written to, such as Element.innerHTML, document.write or eval
+	Value string `json:"value"` // the string passed to the sink
+	Url   string `json:"url"`   // location of the document the sink was called in
+	Stack string `json:"stack"` // javascript stack trace at the time of the call
+}
+
+// Contains returns true if the value written to the sink contains canary, for checking whether input
+// injected by a scanner reached a sink.
+func (c *DOMSinkCall) Contains(canary string) bool {
+	return strings.Contains(c.Value, canary)
+}
+
+// InstrumentDOMSinks wraps the setters and functions commonly abused for DOM based XSS (innerHTML, outerHTML,
+// insertAdjacentHTML, document.write, eval, Function, string setTimeout/setInterval, iframe srcdoc, script
+// src and text, Range.createContextualFragment and DOMParser) in the current document and every document
+// loaded after, calling handlerFn with each string written to them. Only the top level document and same
+// process frames are instrumented. eval is replaced by a wrapper, so direct eval calls run in the global
+// scope while instrumented. Calling it again replaces the handler.
+func (t *Tab) InstrumentDOMSinks(handlerFn DOMSinkFunc) error {
+	if err := t.addBinding(domSinkBindingName, t.handleDOMSinkCall); err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf(instrumentDOMSinksScript, jsQuote(domSinkBindingName))
+
+	t.bindingLock.Lock()
+	t.sinkHandler = handlerFn
+	installed := t.sinkScriptId != ""
+	t.bindingLock.Unlock()
+
+	if !installed {
+		scriptId, err := t.Page.AddScriptToEvaluateOnNewDocument(script, "")
+		if err != nil {
+			return err
+		}
+		t.bindingLock.Lock()
+		t.sinkScriptId = scriptId
+		t.bindingLock.Unlock()
+	}
+
+	_, err := t.evaluateScript(script, false)
+	return err
+}
+
+// StopInstrumentingDOMSinks stops reporting sink calls in the current document and stops instrumenting new ones.
+func (t *Tab) StopInstrumentingDOMSinks() error {
+	t.bindingLock.Lock()
+	scriptId := t.sinkScriptId
+	t.sinkScriptId = ""
+	t.sinkHandler = nil
+	t.bindingLock.Unlock()
+
+	if scriptId == "" {
+		return nil
+	}
+
+	if _, err := t.Page.RemoveScriptToEvaluateOnNewDocument(scriptId); err != nil {
+		return err
+	}
+	_, err := t.evaluateScript(disableDOMSinksScript, false)
+	return err
+}
+
+// decodes calls reported by the sink binding and passes them to the handler.
+func (t *Tab) handleDOMSinkCall(payload string) {
+	call := &DOMSinkCall{}
+	if err := json.Unmarshal([]byte(payload), call); err != nil {
+		t.debugf("invalid dom sink payload: %s\n", err)
+		return
+	}
+
+	t.bindingLock.RLock()
+	handlerFn := t.sinkHandler
+	t.bindingLock.RUnlock()
+
+	if handlerFn != nil {
+		handlerFn(t, call)
+	}
+}
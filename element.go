@@ -26,6 +26,8 @@ package autogcd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -43,6 +45,11 @@ func (e *IncorrectElementTypeErr) Error() string {
 	return "incorrect element type, expected " + e.ExpectedName + " but this type is " + e.NodeName
 }
 
+// Unwrap returns ErrIncorrectElementType so the error can be matched with errors.Is
+func (e *IncorrectElementTypeErr) Unwrap() error {
+	return ErrIncorrectElementType
+}
+
 // The element has been removed from the DOM
 type InvalidElementErr struct {
 }
@@ -51,6 +58,11 @@ func (e *InvalidElementErr) Error() string {
 	return "this element has been invalidated"
 }
 
+// Unwrap returns ErrInvalidated so the error can be matched with errors.Is
+func (e *InvalidElementErr) Unwrap() error {
+	return ErrInvalidated
+}
+
 // The element has no children
 type ElementHasNoChildrenErr struct {
 }
@@ -59,6 +71,11 @@ func (e *ElementHasNoChildrenErr) Error() string {
 	return "this element has no child elements"
 }
 
+// Unwrap returns ErrElementHasNoChildren so the error can be matched with errors.Is
+func (e *ElementHasNoChildrenErr) Unwrap() error {
+	return ErrElementHasNoChildren
+}
+
 // When we have an element that has not been populated
 // with data yet.
 type ElementNotReadyErr struct {
@@ -68,6 +85,11 @@ func (e *ElementNotReadyErr) Error() string {
 	return "this element is not ready"
 }
 
+// Unwrap returns ErrElementNotReady so the error can be matched with errors.Is
+func (e *ElementNotReadyErr) Unwrap() error {
+	return ErrElementNotReady
+}
+
 // When the dimensions of an element are incorrect to calculate the centroid
 type InvalidDimensionsErr struct {
 	Message string
@@ -77,6 +99,11 @@ func (e *InvalidDimensionsErr) Error() string {
 	return "invalid dimensions " + e.Message
 }
 
+// Unwrap returns ErrInvalidDimensions so the error can be matched with errors.Is
+func (e *InvalidDimensionsErr) Unwrap() error {
+	return ErrInvalidDimensions
+}
+
 // An abstraction over a DOM element, it can be in three modes
 // NotReady - it's data has not been returned to us by the debugger yet.
 // Ready - the debugger has given us the DOMNode reference.
@@ -137,12 +164,12 @@ func (e *Element) populateElement(node *gcdapi.DOMNode) {
 		e.updateAttribute(node.Attributes[i], node.Attributes[i+1])
 	}
 
-	// close it
+	// close it, under the lock as the node may be populated from more than one source
+	e.lock.Lock()
+	defer e.lock.Unlock()
 	if !e.ready {
 		close(e.readyGate)
 	}
-	e.lock.Lock()
-	defer e.lock.Unlock()
 	e.ready = true
 }
 
@@ -177,6 +204,10 @@ func (e *Element) setInvalidated(invalid bool) {
 // If we are ready, just return, if we are not, wait for the readyGate
 // to be closed or for the timeout timer to fired.
 func (e *Element) WaitForReady() error {
+	return e.waitForReady(e.tab.elementTimeout)
+}
+
+func (e *Element) waitForReady(wait time.Duration) error {
 	e.lock.RLock()
 	ready := e.ready
 	e.lock.RUnlock()
@@ -185,7 +216,7 @@ func (e *Element) WaitForReady() error {
 		return nil
 	}
 
-	timeout := time.NewTimer(e.tab.elementTimeout)
+	timeout := time.NewTimer(wait)
 	defer timeout.Stop()
 
 	select {
@@ -262,6 +293,47 @@ func (e *Element) NodeId() int {
 	return e.id
 }
 
+// BackendNodeId returns chrome's backend id for the node. Unlike the NodeId, which changes when the
+// document is requested again, it remains the same for the life of the node.
+func (e *Element) BackendNodeId() (int, error) {
+	e.lock.RLock()
+	if e.node != nil && e.node.BackendNodeId != 0 {
+		defer e.lock.RUnlock()
+		return e.node.BackendNodeId, nil
+	}
+	id := e.id
+	e.lock.RUnlock()
+
+	node, err := e.tab.DOM.DescribeNode(id, 0, "", 0, false)
+	if err != nil {
+		return 0, err
+	}
+	return node.BackendNodeId, nil
+}
+
+// Equals returns true if other refers to the same underlying node in the same tab as this element,
+// even if it was obtained from a different query or after the DOM has been updated.
+func (e *Element) Equals(other *Element) bool {
+	if other == nil {
+		return false
+	}
+	if e == other {
+		return true
+	}
+	if e.tab != other.tab {
+		return false
+	}
+	id, err := e.BackendNodeId()
+	if err != nil {
+		return false
+	}
+	otherId, err := other.BackendNodeId()
+	if err != nil {
+		return false
+	}
+	return id == otherId
+}
+
 // Returns event listeners for the element, both static and dynamically bound.
 func (e *Element) GetEventListeners() ([]*gcdapi.DOMDebuggerEventListener, error) {
 	e.lock.RLock()
@@ -283,6 +355,55 @@ func (e *Element) GetEventListeners() ([]*gcdapi.DOMDebuggerEventListener, error
 	return eventListeners, nil
 }
 
+// resolves the element to a remote object and calls functionDeclaration on it (as this) with args, returning the result by value.
+func (e *Element) callFunction(functionDeclaration string, args ...interface{}) (*gcdapi.RuntimeRemoteObject, error) {
+	e.lock.RLock()
+	id := e.id
+	e.lock.RUnlock()
+
+	obj, err := e.tab.DOM.ResolveNodeWithParams(&gcdapi.DOMResolveNodeParams{NodeId: id})
+	if err != nil {
+		return nil, err
+	}
+	defer e.tab.Runtime.ReleaseObject(obj.ObjectId)
+
+	callArgs := make([]*gcdapi.RuntimeCallArgument, len(args))
+	for i, arg := range args {
+		callArgs[i] = &gcdapi.RuntimeCallArgument{Value: arg}
+	}
+
+	rro, exception, err := e.tab.Runtime.CallFunctionOn(functionDeclaration, obj.ObjectId, callArgs, true, true, false, false, true, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	if exception != nil {
+		return nil, &ScriptEvaluationErr{Message: "error calling function on element: ", ExceptionText: exception.Text, ExceptionDetails: exception}
+	}
+	return rro, nil
+}
+
+// SetDOMBreakpoint pauses the page when this element is modified in the way described by breakType,
+// see Tab.SetPausedHandler.
+func (e *Element) SetDOMBreakpoint(breakType DOMBreakpointType) error {
+	e.lock.RLock()
+	id := e.id
+	e.lock.RUnlock()
+
+	e.tab.subscribePaused()
+	_, err := e.tab.DOMDebugger.SetDOMBreakpoint(id, string(breakType))
+	return err
+}
+
+// RemoveDOMBreakpoint removes a breakpoint set by SetDOMBreakpoint.
+func (e *Element) RemoveDOMBreakpoint(breakType DOMBreakpointType) error {
+	e.lock.RLock()
+	id := e.id
+	e.lock.RUnlock()
+
+	_, err := e.tab.DOMDebugger.RemoveDOMBreakpoint(id, string(breakType))
+	return err
+}
+
 // Returns the underlying DOMNode for this element. Note this is potentially
 // unsafe to access as we give up the ability to lock.
 func (e *Element) GetDebuggerDOMNode() (*gcdapi.DOMNode, error) {
@@ -306,6 +427,17 @@ func (e *Element) updateAttribute(name, value string) {
 	e.attributes[name] = value
 }
 
+// replaces our attributes list with the attributes read from the browser.
+func (e *Element) setAttributes(attributes map[string]string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.attributes = make(map[string]string, len(attributes))
+	for name, value := range attributes {
+		e.attributes[name] = value
+	}
+}
+
 // removes the attribute from our attributes list.
 func (e *Element) removeAttribute(name string) {
 	e.lock.Lock()
@@ -495,7 +627,8 @@ func (e *Element) GetComputedCssStyle() (map[string]string, error) {
 	return styleMap, nil
 }
 
-// Get attributes of the node returning a map of name,value pairs.
+// Get attributes of the node returning a map of name,value pairs. The map is a copy, so it
+// is safe to use while DOM change events update the element.
 func (e *Element) GetAttributes() (map[string]string, error) {
 	e.lock.RLock()
 	attr, err := e.tab.DOM.GetAttributes(e.id)
@@ -508,7 +641,20 @@ func (e *Element) GetAttributes() (map[string]string, error) {
 		e.updateAttribute(attr[i], attr[i+1])
 	}
 
-	return e.attributes, nil
+	return e.AttributesSnapshot(), nil
+}
+
+// AttributesSnapshot returns a copy of the attributes last known for the element, as kept up to date by
+// DOM change events, without asking the browser.
+func (e *Element) AttributesSnapshot() map[string]string {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	attributes := make(map[string]string, len(e.attributes))
+	for name, value := range e.attributes {
+		attributes[name] = value
+	}
+	return attributes
 }
 
 // Gets a single attribute by name, returns empty string if it does not exist
@@ -571,8 +717,56 @@ func (e *Element) Clear() error {
 	return err
 }
 
-// Clicks the center of the element.
+// SetInputFiles selects the files at paths in an <input type=file> element, as if the user had chosen them,
+// firing the input and change events. Relative paths are resolved against the working directory and each
+// file must exist. Passing no paths clears the selection. Returns an IncorrectElementTypeErr if the element
+// is not a file input, or if several paths are given and it does not have the multiple attribute.
+func (e *Element) SetInputFiles(paths ...string) error {
+	e.lock.RLock()
+	ready := e.ready
+	id := e.id
+	nodeName := e.nodeName
+	inputType := strings.ToLower(e.attributes["type"])
+	_, multiple := e.attributes["multiple"]
+	e.lock.RUnlock()
+
+	if !ready {
+		return &ElementNotReadyErr{}
+	}
+	if nodeName != "input" || inputType != "file" {
+		if nodeName == "input" {
+			nodeName += "[type=" + inputType + "]"
+		}
+		return &IncorrectElementTypeErr{ExpectedName: "input[type=file]", NodeName: nodeName}
+	}
+	if len(paths) > 1 && !multiple {
+		return &IncorrectElementTypeErr{ExpectedName: "input[type=file][multiple]", NodeName: "input[type=file]"}
+	}
+
+	files := make([]string, len(paths))
+	for i, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return err
+		}
+		files[i] = abs
+	}
+
+	_, err := e.tab.DOM.SetFileInputFilesWithParams(&gcdapi.DOMSetFileInputFilesParams{Files: files, NodeId: id})
+	return err
+}
+
+// Clicks the center of the element. Runs through the tab's middleware, see Tab.Use.
 func (e *Element) Click() error {
+	return e.tab.runAction(&Action{Name: ActionClick, Tab: e.tab, Element: e}, func(action *Action) error {
+		return e.click()
+	})
+}
+
+func (e *Element) click() error {
 	x, y, err := e.getCenter()
 	if err != nil {
 		return err
@@ -582,6 +776,52 @@ func (e *Element) Click() error {
 	return e.tab.Click(float64(x), float64(y))
 }
 
+// Makes a click on the element navigate the current window: the target of the element's link or form (and a
+// button's formtarget) is set to _self, and window.open is replaced until restoreWindowOpenScript is run so
+// scripted popups navigate the window instead.
+const forceSameTabFunction = `function() {
+	var owner = this.closest('a[target], area[target]') || this.form || this.closest('form');
+	if (owner && owner.hasAttribute('target')) {
+		owner.setAttribute('target', '_self');
+	}
+	if (this.hasAttribute('formtarget')) {
+		this.setAttribute('formtarget', '_self');
+	}
+	if (!window.__autogcdOpen) {
+		window.__autogcdOpen = window.open;
+		window.open = function(url) {
+			if (url) {
+				window.location.assign(url);
+			}
+			return window;
+		};
+	}
+}`
+
+const restoreWindowOpenScript = `(function() {
+	if (window.__autogcdOpen) {
+		window.open = window.__autogcdOpen;
+		delete window.__autogcdOpen;
+	}
+})()`
+
+// ClickForceSameTab clicks the element like Click, but a target=_blank link, a form with a target or a
+// window.open call made by the page's click handler navigates this tab instead of opening a new one, so
+// crawlers can follow links without managing popups. The element's target attribute is left rewritten.
+func (e *Element) ClickForceSameTab() error {
+	return e.tab.runAction(&Action{Name: ActionClick, Tab: e.tab, Element: e}, func(action *Action) error {
+		if _, err := e.callFunction(forceSameTabFunction); err != nil {
+			return err
+		}
+		err := e.click()
+		// the page may have navigated, in which case window.open is already restored
+		if _, restoreErr := e.tab.evaluateScript(restoreWindowOpenScript, false); restoreErr != nil {
+			e.tab.debugf("unable to restore window.open: %s\n", restoreErr)
+		}
+		return err
+	})
+}
+
 // Double clicks the center of the element.
 func (e *Element) DoubleClick() error {
 	x, y, err := e.getCenter()
@@ -648,13 +888,15 @@ func (e *Element) getCenter() (int, int, error) {
 
 // SendKeys - sends each individual character after focusing (clicking) on the element.
 // Extremely basic, doesn't take into account most/all system keys except enter, tab or backspace.
+// Runs through the tab's middleware as a single SendKeys action, see Tab.Use.
 func (e *Element) SendKeys(text string) error {
-	e.Focus()
-	err := e.Click()
-	if err != nil {
-		return err
-	}
-	return e.tab.SendKeys(text)
+	return e.tab.runAction(&Action{Name: ActionSendKeys, Tab: e.tab, Element: e, Input: text}, func(action *Action) error {
+		e.Focus()
+		if err := e.click(); err != nil {
+			return err
+		}
+		return e.tab.sendKeys(action.Input)
+	})
 }
 
 // Gnarly output mode activated
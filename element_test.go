@@ -1,6 +1,7 @@
 package autogcd
 
 import (
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -22,8 +23,8 @@ func TestElementDimensions(t *testing.T) {
 		t.Fatalf("error getting tab")
 	}
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementsBySelectorNotEmpty(tab, "button"))
@@ -66,8 +67,8 @@ func TestElementClick(t *testing.T) {
 	//tab.Debug(true)
 	//tab.DebugEvents(true)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	msgHandler := func(callerTab *Tab, message *gcdapi.ConsoleConsoleMessage) {
@@ -126,8 +127,8 @@ func TestElementMouseOver(t *testing.T) {
 	}
 	tab.GetConsoleMessages(msgHandler)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "mouseover.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "mouseover.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementByIdReady(tab, "button"))
@@ -177,8 +178,8 @@ func TestElementDoubleClick(t *testing.T) {
 	}
 	tab.GetConsoleMessages(msgHandler)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "dblclick.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "dblclick.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementByIdReady(tab, "doubleclick"))
@@ -220,8 +221,8 @@ func TestElementGetSource(t *testing.T) {
 	}
 	//tab.Debug(true)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementsBySelectorNotEmpty(tab, "button"))
@@ -257,8 +258,8 @@ func TestElementGetAttributes(t *testing.T) {
 	}
 	//tab.Debug(true)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "attributes.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "attributes.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementByIdReady(tab, "attr"))
@@ -314,8 +315,8 @@ func TestElementSetAttributeValue(t *testing.T) {
 	}
 	//tab.Debug(true)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "attributes.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "attributes.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementByIdReady(tab, "attr"))
@@ -389,8 +390,8 @@ func TestElementSendKeys(t *testing.T) {
 	}
 	tab.GetConsoleMessages(msgHandler)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "input.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "input.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementByIdReady(tab, "attr"))
@@ -423,8 +424,8 @@ func TestElementGetTag(t *testing.T) {
 	}
 	//tab.Debug(true)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "attributes.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "attributes.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementByIdReady(tab, "attr"))
@@ -459,8 +460,8 @@ func TestElementGetEventListeners(t *testing.T) {
 	}
 	//tab.Debug(true)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "events.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "events.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementByIdReady(tab, "divvie"))
@@ -497,8 +498,8 @@ func TestElementFrameGetTag(t *testing.T) {
 		t.Fatalf("error getting tab")
 	}
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "iframe.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "iframe.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 	//tab.Debug(true)
 
@@ -545,8 +546,8 @@ func TestElementFrameSet(t *testing.T) {
 
 	//tab.Debug(true)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "frameset.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "frameset.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 	if err := tab.WaitStable(); err != nil {
 		t.Fatalf("error waiting for stable: %s\n", err)
@@ -587,8 +588,8 @@ func TestElementInvalidated(t *testing.T) {
 	}
 	//tab.Debug(true)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "invalidated.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "invalidated.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementByIdReady(tab, "child"))
@@ -612,3 +613,134 @@ func TestElementInvalidated(t *testing.T) {
 		t.Fatalf("error child is not invalid after it was removed!")
 	}
 }
+
+func TestElementDOMBreakpoint(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementsBySelectorNotEmpty(tab, "button"))
+	if err != nil {
+		t.Fatalf("error finding buttons, timed out waiting: %s\n", err)
+	}
+
+	buttons, err := tab.GetElementsBySelector("button")
+	if err != nil {
+		t.Fatalf("error finding buttons: %s\n", err)
+	}
+
+	pausedCh := make(chan *PauseInfo, 1)
+	tab.SetPausedHandler(func(tab *Tab, paused *PauseInfo) {
+		pausedCh <- paused
+	})
+
+	if err := buttons[0].SetDOMBreakpoint(BreakOnAttributeModified); err != nil {
+		t.Fatalf("error setting dom breakpoint: %s\n", err)
+	}
+
+	if _, err := tab.EvaluateScript("document.querySelector('button').setAttribute('data-x', '1')"); err != nil {
+		t.Fatalf("error modifying attribute: %s\n", err)
+	}
+
+	select {
+	case paused := <-pausedCh:
+		if paused.Reason != "DOM" {
+			t.Fatalf("expected DOM pause reason got: %s\n", paused.Reason)
+		}
+	case <-time.After(testWaitTimeout):
+		t.Fatalf("timed out waiting for dom breakpoint\n")
+	}
+
+	if err := buttons[0].RemoveDOMBreakpoint(BreakOnAttributeModified); err != nil {
+		t.Fatalf("error removing dom breakpoint: %s\n", err)
+	}
+}
+
+func TestElementSetInputFiles(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "upload.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementByIdReady(tab, "text"))
+	if err != nil {
+		t.Fatalf("error finding inputs, timed out waiting: %s\n", err)
+	}
+
+	single, _, _ := tab.GetElementById("single")
+	multiple, _, _ := tab.GetElementById("multiple")
+	text, _, _ := tab.GetElementById("text")
+
+	if err := single.SetInputFiles("testdata/red.png"); err != nil {
+		t.Fatalf("error setting input files: %s\n", err)
+	}
+	rro, err := tab.EvaluateScript("document.getElementById('single').files[0].name")
+	if err != nil || rro.Value != "red.png" {
+		t.Fatalf("expected red.png to be selected got %v %v\n", rro, err)
+	}
+
+	if err := multiple.SetInputFiles("testdata/red.png", "testdata/index.html"); err != nil {
+		t.Fatalf("error setting multiple input files: %s\n", err)
+	}
+	rro, err = tab.EvaluateScript("document.getElementById('multiple').files.length")
+	if err != nil || rro.Value != float64(2) {
+		t.Fatalf("expected 2 files to be selected got %v %v\n", rro, err)
+	}
+
+	if err := single.SetInputFiles("testdata/red.png", "testdata/index.html"); !errors.Is(err, ErrIncorrectElementType) {
+		t.Fatalf("expected ErrIncorrectElementType for several files got %v\n", err)
+	}
+	if err := text.SetInputFiles("testdata/red.png"); !errors.Is(err, ErrIncorrectElementType) {
+		t.Fatalf("expected ErrIncorrectElementType for a text input got %v\n", err)
+	}
+	if err := single.SetInputFiles("testdata/missing.png"); err == nil {
+		t.Fatalf("expected an error for a missing file\n")
+	}
+}
+
+func TestElementClickWithOptions(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "overlay.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementByIdReady(tab, "inert"))
+	if err != nil {
+		t.Fatalf("error finding buttons, timed out waiting: %s\n", err)
+	}
+	target, _, _ := tab.GetElementById("target")
+	inert, _, _ := tab.GetElementById("inert")
+
+	// the center of target is covered, the retry must click an uncovered point
+	opts := &ClickOptions{VerifyEvent: "click", VerifySelector: "#done", VerifyConsole: "target clicked", Timeout: 500 * time.Millisecond}
+	if err := target.ClickWithOptions(opts); err != nil {
+		t.Fatalf("error clicking covered element: %s\n", err)
+	}
+
+	opts = &ClickOptions{VerifyNavigation: true, Timeout: 200 * time.Millisecond, Retries: -1}
+	if err := inert.ClickWithOptions(opts); !errors.Is(err, ErrClickNotVerified) {
+		t.Fatalf("expected ErrClickNotVerified got %v\n", err)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// OnElementAppear calls handler with a ready Element whenever a node matching the CSS selector is inserted
+// into the document, either directly or as part of an inserted subtree. Only nodes inserted after this is called
+// are matched, use GetElementsBySelector for elements already present. Pass a nil handler to stop watching selector.
+func (t *Tab) OnElementAppear(selector string, handler ElementAppearFunc) {
+	t.watchLock.Lock()
+	defer t.watchLock.Unlock()
+
+	if handler == nil {
+		delete(t.watchers, selector)
+		return
+	}
+	t.watchers[selector] = handler
+}
+
+// returns a copy of the registered element watchers so matching does not hold the lock while calling the debugger
+func (t *Tab) elementWatchers() map[string]ElementAppearFunc {
+	t.watchLock.RLock()
+	defer t.watchLock.RUnlock()
+
+	if len(t.watchers) == 0 {
+		return nil
+	}
+	watchers := make(map[string]ElementAppearFunc, len(t.watchers))
+	for selector, handler := range t.watchers {
+		watchers[selector] = handler
+	}
+	return watchers
+}
+
+// checks an inserted node and its subtree against the watched selectors. Called in its own go routine
+// since querying the DOM causes more node change events to be dispatched.
+func (t *Tab) matchInsertedNode(parentNodeId int, node *gcdapi.DOMNode, watchers map[string]ElementAppearFunc) {
+	defer t.recoverCallback("element watcher")
+
+	for selector, handler := range watchers {
+		matched := make([]int, 0)
+		// querying the parent is the only way to test the inserted node itself against the selector
+		siblings, err := t.DOM.QuerySelectorAll(parentNodeId, selector)
+		if err != nil {
+			t.debugf("error matching %s against inserted node: %s\n", selector, err)
+			continue
+		}
+		for _, nodeId := range siblings {
+			if nodeId == node.NodeId {
+				matched = append(matched, nodeId)
+				break
+			}
+		}
+
+		descendants, err := t.DOM.QuerySelectorAll(node.NodeId, selector)
+		if err != nil {
+			t.debugf("error matching %s against inserted subtree: %s\n", selector, err)
+		}
+		matched = append(matched, descendants...)
+
+		for _, nodeId := range matched {
+			ele, _ := t.GetElementByNodeId(nodeId)
+			if err := ele.WaitForReady(); err != nil {
+				t.debugf("matched element %d for %s never became ready: %s\n", nodeId, selector, err)
+				continue
+			}
+			handler(t, ele)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"errors"
+)
+
+// Sentinel errors, every error type returned by autogcd unwraps to one of these so callers can use
+// errors.Is(err, ErrTimeout) instead of type switches. Use errors.As to access the typed error's fields.
+var (
+	ErrTimeout              = errors.New("timed out")
+	ErrElementNotFound      = errors.New("element not found")
+	ErrInvalidated          = errors.New("element invalidated")
+	ErrTabCrashed           = errors.New("tab crashed")
+	ErrElementNotReady      = errors.New("element not ready")
+	ErrElementHasNoChildren = errors.New("element has no children")
+	ErrIncorrectElementType = errors.New("incorrect element type")
+	ErrInvalidDimensions    = errors.New("invalid dimensions")
+	ErrInvalidTab           = errors.New("invalid tab")
+	ErrInvalidNavigation    = errors.New("invalid navigation")
+	ErrNavigationFailed     = errors.New("navigation failed")
+	ErrScriptEvaluation     = errors.New("script evaluation failed")
+	ErrRobotsDisallowed     = errors.New("disallowed by robots.txt")
+	ErrFPSMeter             = errors.New("fps meter error")
+	ErrChildSession         = errors.New("child session error")
+	ErrShutdown             = errors.New("AutoGcd already shut down.")
+	ErrProfileInUse         = errors.New("profile in use")
+	ErrSessionRecord        = errors.New("session record error")
+	ErrCallbackPanic        = errors.New("callback panic")
+	ErrInterception         = errors.New("interception error")
+	ErrRedirectLoop         = errors.New("redirect loop")
+	ErrClickNotVerified     = errors.New("click not verified")
+	ErrHeapSample           = errors.New("heap sample error")
+	ErrHAR                  = errors.New("har error")
+)
@@ -0,0 +1,35 @@
+package autogcd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorsIsAs(t *testing.T) {
+	err := fmt.Errorf("waiting for element: %w", &TimeoutErr{Message: "#id"})
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected wrapped TimeoutErr to match ErrTimeout\n")
+	}
+
+	var timeoutErr *TimeoutErr
+	if !errors.As(err, &timeoutErr) || timeoutErr.Message != "#id" {
+		t.Fatalf("expected errors.As to return the TimeoutErr got: %v\n", timeoutErr)
+	}
+
+	if !errors.Is(&InvalidElementErr{}, ErrInvalidated) {
+		t.Fatalf("expected InvalidElementErr to match ErrInvalidated\n")
+	}
+
+	if !errors.Is(&CrashedErr{Reason: "oom"}, ErrTabCrashed) {
+		t.Fatalf("expected CrashedErr to match ErrTabCrashed\n")
+	}
+
+	if !errors.Is(&NavigationFailedErr{Url: "http://x", ErrorText: "net::ERR_BLOCKED_BY_CLIENT"}, ErrNavigationFailed) {
+		t.Fatalf("expected NavigationFailedErr to match ErrNavigationFailed\n")
+	}
+
+	if errors.Is(&ElementNotFoundErr{}, ErrTimeout) {
+		t.Fatalf("ElementNotFoundErr should not match ErrTimeout\n")
+	}
+}
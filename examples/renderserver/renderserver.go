@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"github.com/wirepair/autogcd"
+	"github.com/wirepair/autogcd/server"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"runtime"
+	"time"
+)
+
+var (
+	chromePath string
+	userDir    string
+	listen     string
+	tabs       int
+)
+
+var startupFlags = []string{"--headless", "--disable-gpu", "--disable-new-tab-first-run", "--no-first-run", "--disable-translate", "--hide-scrollbars"}
+
+func init() {
+	switch runtime.GOOS {
+	case "windows":
+		flag.StringVar(&chromePath, "chrome", "C:\\Program Files (x86)\\Google\\Chrome\\Application\\chrome.exe", "path to chrome")
+		flag.StringVar(&userDir, "dir", "C:\\temp\\", "user directory")
+	case "darwin":
+		flag.StringVar(&chromePath, "chrome", "/Applications/Google Chrome.app/Contents/MacOS/Google Chrome", "path to chrome")
+		flag.StringVar(&userDir, "dir", "/tmp/", "user directory")
+	case "linux":
+		flag.StringVar(&chromePath, "chrome", "/usr/bin/chromium-browser", "path to chrome")
+		flag.StringVar(&userDir, "dir", "/tmp/", "user directory")
+	}
+	flag.StringVar(&listen, "listen", "localhost:8080", "address to serve on")
+	flag.IntVar(&tabs, "tabs", 4, "number of pages to render concurrently")
+}
+
+// Serves screenshots, pdfs and rendered html, e.g. curl -o page.png 'http://localhost:8080/screenshot?url=https://github.com/'
+func main() {
+	flag.Parse()
+	settings := autogcd.NewSettings(chromePath, randUserDir())
+	settings.RemoveUserDir(true)
+	settings.AddStartupFlags(startupFlags)
+
+	auto := autogcd.NewAutoGcd(settings)
+	if err := auto.Start(); err != nil {
+		log.Fatalf("error starting chrome: %s\n", err)
+	}
+	defer auto.Shutdown()
+
+	pool, err := server.NewTabPool(auto, tabs, autogcd.WithoutConsoleDomain())
+	if err != nil {
+		log.Fatalf("error creating tab pool: %s\n", err)
+	}
+	defer pool.Close()
+
+	handler := server.New(pool, &server.Options{RequestTimeout: 20 * time.Second})
+	log.Printf("rendering on http://%s/\n", listen)
+	log.Fatal(http.ListenAndServe(listen, handler))
+}
+
+func randUserDir() string {
+	dir, err := ioutil.TempDir(userDir, "autogcd")
+	if err != nil {
+		log.Fatalf("error getting temp dir: %s\n", err)
+	}
+	return dir
+}
@@ -0,0 +1,94 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// For every container, queries each field's selector relative to it and returns the trimmed text, or
+// the attribute value if the selector ends with @attribute. An empty selector refers to the container.
+const extractScript = `(function(schema, containerSelector) {
+	var results = [];
+	var containers = document.querySelectorAll(containerSelector);
+	for (var i = 0; i < containers.length; i++) {
+		var row = {};
+		for (var field in schema) {
+			var selector = schema[field];
+			var attribute = '';
+			var at = selector.lastIndexOf('@');
+			if (at !== -1) {
+				attribute = selector.substring(at + 1);
+				selector = selector.substring(0, at).trim();
+			}
+			var node = selector === '' ? containers[i] : containers[i].querySelector(selector);
+			if (!node) {
+				row[field] = '';
+			} else if (attribute !== '') {
+				row[field] = node.getAttribute(attribute) || '';
+			} else {
+				row[field] = (node.innerText || node.textContent || '').trim();
+			}
+		}
+		results.push(row);
+	}
+	return results;
+})(%s, %s)`
+
+// Schema maps output field names to CSS selectors relative to each container. A selector may end
+// with @attribute to extract an attribute instead of text (e.g. "h2 a@href"), and "@attribute"
+// alone reads the attribute from the container itself.
+type Schema map[string]string
+
+// Extract evaluates schema against every element matching containerSelector in the top level document,
+// returning one map per container. Fields whose selector does not match are set to the empty string.
+// The extraction runs entirely in the page so it is much faster than walking Elements.
+func (t *Tab) Extract(schema Schema, containerSelector string) ([]map[string]string, error) {
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	rro, err := t.evaluateScript(fmt.Sprintf(extractScript, string(encoded), jsQuote(containerSelector)), false)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, _ := rro.Value.([]interface{})
+	results := make([]map[string]string, 0, len(rows))
+	for _, row := range rows {
+		fields, ok := row.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		result := make(map[string]string, len(fields))
+		for field, value := range fields {
+			result[field], _ = value.(string)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
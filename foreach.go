@@ -0,0 +1,56 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+)
+
+// Calls the function with each matching element and its index, waiting on any promises returned.
+// The body is placed in its own function so a syntax error in it is reported as an exception.
+const forEachElementScript = `(function(selector, body) {
+	var fn = new Function('element', 'index', body);
+	return Promise.all(Array.prototype.map.call(document.querySelectorAll(selector), function(element, index) {
+		return fn(element, index);
+	}));
+})(%s, %s)`
+
+// ForEachElement calls a JavaScript function with the body jsFnBody for every element matching selector
+// in the top level document. The function is passed the arguments element and index, and whatever it
+// returns (or resolves, for promises) is serialized as JSON, giving one result per element in document
+// order. For example ForEachElement("a", "return element.href;") returns every link in a single call
+// rather than one per element.
+func (t *Tab) ForEachElement(selector, jsFnBody string) ([]interface{}, error) {
+	rro, err := t.evaluateScript(fmt.Sprintf(forEachElementScript, jsQuote(selector), jsQuote(jsFnBody)), true)
+	if err != nil {
+		return nil, err
+	}
+
+	results, _ := rro.Value.([]interface{})
+	if results == nil {
+		results = make([]interface{}, 0)
+	}
+	return results, nil
+}
@@ -0,0 +1,162 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/wirepair/gcd"
+)
+
+// trace category containing the compositor's frame events
+const fpsTraceCategories = "-*,disabled-by-default-devtools.timeline.frame"
+
+// how long StopFPSMeter waits for chrome to flush the remaining trace events
+const fpsFlushTimeout = 10 * time.Second
+
+// FPSMeterErr is returned when the FPS meter is started twice, or stopped before being started.
+type FPSMeterErr struct {
+	Message string
+}
+
+func (e *FPSMeterErr) Error() string {
+	return "fps meter error: " + e.Message
+}
+
+// Unwrap returns ErrFPSMeter so the error can be matched with errors.Is
+func (e *FPSMeterErr) Unwrap() error {
+	return ErrFPSMeter
+}
+
+// FPSReport of the frames drawn between StartFPSMeter and StopFPSMeter.
+type FPSReport struct {
+	Duration      time.Duration // how long the meter ran
+	Frames        int           // number of frames drawn
+	DroppedFrames int           // number of frames the compositor dropped (jank)
+	AverageFPS    float64       // Frames / Duration
+}
+
+// collects frame events from Tracing.dataCollected
+type fpsMeter struct {
+	lock       *sync.Mutex
+	started    time.Time
+	frames     int
+	dropped    int
+	completeCh chan struct{}
+	complete   *sync.Once // Tracing.tracingComplete may be sent again by another Tracing.End
+}
+
+type traceDataCollectedEvent struct {
+	Params struct {
+		Value []struct {
+			Name string `json:"name"`
+		} `json:"value"`
+	} `json:"params"`
+}
+
+// StartFPSMeter starts tracing compositor frames, perform the interaction to measure and then call
+// StopFPSMeter to get the report. Only one meter may run per tab, and it can not be used while
+// another Tracing session is active.
+func (t *Tab) StartFPSMeter() error {
+	t.fpsLock.Lock()
+	defer t.fpsLock.Unlock()
+	if t.fpsMeter != nil {
+		return &FPSMeterErr{Message: "already started"}
+	}
+
+	meter := &fpsMeter{lock: &sync.Mutex{}, completeCh: make(chan struct{}), complete: &sync.Once{}}
+	t.Subscribe("Tracing.dataCollected", func(target *gcd.ChromeTarget, payload []byte) {
+		event := &traceDataCollectedEvent{}
+		if err := json.Unmarshal(payload, event); err != nil {
+			return
+		}
+		meter.lock.Lock()
+		for _, traceEvent := range event.Params.Value {
+			switch traceEvent.Name {
+			case "DrawFrame":
+				meter.frames++
+			case "DroppedFrame":
+				meter.dropped++
+			}
+		}
+		meter.lock.Unlock()
+	})
+	t.Subscribe("Tracing.tracingComplete", func(target *gcd.ChromeTarget, payload []byte) {
+		meter.complete.Do(func() { close(meter.completeCh) })
+	})
+
+	if _, err := t.Tracing.Start(fpsTraceCategories, "", 0, "ReportEvents", "", nil); err != nil {
+		t.unsubscribeFPSMeter()
+		return err
+	}
+	meter.started = time.Now()
+	t.fpsMeter = meter
+	return nil
+}
+
+// StopFPSMeter stops tracing and returns the frame statistics collected since StartFPSMeter.
+func (t *Tab) StopFPSMeter() (*FPSReport, error) {
+	t.fpsLock.Lock()
+	meter := t.fpsMeter
+	t.fpsMeter = nil
+	t.fpsLock.Unlock()
+	if meter == nil {
+		return nil, &FPSMeterErr{Message: "not started"}
+	}
+	defer t.unsubscribeFPSMeter()
+
+	report := &FPSReport{Duration: time.Now().Sub(meter.started)}
+	if _, err := t.Tracing.End(); err != nil {
+		return nil, err
+	}
+
+	timeoutTimer := time.NewTimer(fpsFlushTimeout)
+	defer timeoutTimer.Stop()
+
+	select {
+	case <-meter.completeCh:
+	case <-timeoutTimer.C:
+		return nil, &TimeoutErr{Message: "waiting for trace data to be flushed"}
+	case <-t.exitCh:
+		return nil, &InvalidTabErr{Message: "tab closed while waiting for trace data"}
+	}
+
+	meter.lock.Lock()
+	report.Frames = meter.frames
+	report.DroppedFrames = meter.dropped
+	meter.lock.Unlock()
+
+	if seconds := report.Duration.Seconds(); seconds > 0 {
+		report.AverageFPS = float64(report.Frames) / seconds
+	}
+	return report, nil
+}
+
+func (t *Tab) unsubscribeFPSMeter() {
+	t.Unsubscribe("Tracing.dataCollected")
+	t.Unsubscribe("Tracing.tracingComplete")
+}
@@ -0,0 +1,542 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// default limit of response bodies kept by RecordHAR
+const defaultHARMaxBodySize = 1024 * 1024
+
+// HAROptions for RecordHAR, the zero value records traffic without bodies.
+type HAROptions struct {
+	Bodies      bool // retrieve response bodies once they finish loading
+	MaxBodySize int  // bodies larger than this are omitted, 0 defaults to 1MB
+}
+
+// HAR is an HTTP Archive 1.2 document, see http://www.softwareishard.com/blog/har-12-spec/
+type HAR struct {
+	Log *HARLog `json:"log"`
+}
+
+// HARLog is the root of an HTTP Archive.
+type HARLog struct {
+	Version string      `json:"version"`
+	Creator *HARCreator `json:"creator"`
+	Pages   []*HARPage  `json:"pages"`
+	Entries []*HAREntry `json:"entries"`
+}
+
+// HARCreator identifies the application that created the archive.
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HARPage is a top level document load, entries refer to it by Id.
+type HARPage struct {
+	StartedDateTime string          `json:"startedDateTime"`
+	Id              string          `json:"id"`
+	Title           string          `json:"title"`
+	PageTimings     *HARPageTimings `json:"pageTimings"`
+}
+
+// HARPageTimings of a page in milliseconds since the page's document was requested, taken from the
+// DOMContentLoaded and load events of the main frame. -1 when not known, such as for a page that was replaced
+// before it finished loading.
+type HARPageTimings struct {
+	OnContentLoad float64 `json:"onContentLoad"`
+	OnLoad        float64 `json:"onLoad"`
+}
+
+// HAREntry is a single request and its response.
+type HAREntry struct {
+	Pageref         string       `json:"pageref,omitempty"`
+	StartedDateTime string       `json:"startedDateTime"`
+	Time            float64      `json:"time"`
+	Request         *HARRequest  `json:"request"`
+	Response        *HARResponse `json:"response"`
+	Cache           struct{}     `json:"cache"`
+	Timings         *HARTimings  `json:"timings"`
+	ServerIPAddress string       `json:"serverIPAddress,omitempty"`
+	Connection      string       `json:"connection,omitempty"`
+}
+
+// HARRequest describes the request sent.
+type HARRequest struct {
+	Method      string          `json:"method"`
+	Url         string          `json:"url"`
+	HttpVersion string          `json:"httpVersion"`
+	Cookies     []*HARNameValue `json:"cookies"`
+	Headers     []*HARNameValue `json:"headers"`
+	QueryString []*HARNameValue `json:"queryString"`
+	PostData    *HARPostData    `json:"postData,omitempty"`
+	HeadersSize int             `json:"headersSize"`
+	BodySize    int             `json:"bodySize"`
+}
+
+// HARResponse describes the response received, Status is 0 if the request failed.
+type HARResponse struct {
+	Status      int             `json:"status"`
+	StatusText  string          `json:"statusText"`
+	HttpVersion string          `json:"httpVersion"`
+	Cookies     []*HARNameValue `json:"cookies"`
+	Headers     []*HARNameValue `json:"headers"`
+	Content     *HARContent     `json:"content"`
+	RedirectURL string          `json:"redirectURL"`
+	HeadersSize int             `json:"headersSize"`
+	BodySize    int             `json:"bodySize"`
+	Error       string          `json:"_error,omitempty"` // network error text for failed requests, a custom field
+}
+
+// HARNameValue is a header, cookie or query string parameter.
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData is the body of a request.
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent is the body of a response, Text is only set if bodies were recorded.
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings in milliseconds, -1 for phases that did not apply.
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// HARErr is returned by ExportHAR when nothing has been recorded.
+type HARErr struct {
+	Message string
+}
+
+func (e *HARErr) Error() string {
+	return "har error: " + e.Message
+}
+
+// Unwrap returns ErrHAR so the error can be matched with errors.Is
+func (e *HARErr) Unwrap() error {
+	return ErrHAR
+}
+
+// an entry being recorded, keyed by requestId until it finishes or fails.
+type harEntry struct {
+	entry     *HAREntry
+	timestamp float64                       // monotonic time the request was sent, seconds
+	timing    *gcdapi.NetworkResourceTiming // raw timing from the response
+}
+
+// collects network events for ExportHAR, all methods are called with the tab's networkLock held.
+type harRecorder struct {
+	options   *HAROptions
+	entries   []*harEntry
+	pending   map[string]*harEntry // requestId => entry waiting for its response to finish
+	pages     []*HARPage
+	pageIds   map[string]string // loaderId => page id
+	page      *HARPage          // latest page, the target of page load events
+	pageStart float64           // monotonic time the latest page's document was requested, seconds
+}
+
+func newHARRecorder(options *HAROptions) *harRecorder {
+	if options.MaxBodySize == 0 {
+		options.MaxBodySize = defaultHARMaxBodySize
+	}
+	return &harRecorder{options: options, entries: make([]*harEntry, 0), pending: make(map[string]*harEntry), pages: make([]*HARPage, 0), pageIds: make(map[string]string)}
+}
+
+// RecordHAR starts collecting the tab's network traffic for ExportHAR, replacing anything recorded so far.
+// Pass nil for the default options. Requests sent before it was called are not recorded.
+func (t *Tab) RecordHAR(options *HAROptions) error {
+	if options == nil {
+		options = &HAROptions{}
+	}
+	if err := t.enableNetwork(); err != nil {
+		return err
+	}
+	t.networkLock.Lock()
+	t.har = newHARRecorder(options)
+	t.harStopped = nil
+	t.networkLock.Unlock()
+	return nil
+}
+
+// StopRecordHAR stops collecting traffic, what was recorded can still be exported until RecordHAR is called again.
+func (t *Tab) StopRecordHAR() {
+	t.networkLock.Lock()
+	if t.har != nil {
+		t.harStopped = t.har
+	}
+	t.har = nil
+	t.networkLock.Unlock()
+}
+
+// ExportHAR writes the traffic recorded by RecordHAR to w as an HTTP Archive 1.2 JSON document. Requests that
+// have not finished are included without timings for the body. Returns a HARErr if nothing was recorded.
+func (t *Tab) ExportHAR(w io.Writer) error {
+	// entries are still updated while recording, encode them under the lock
+	t.networkLock.RLock()
+	defer t.networkLock.RUnlock()
+	recorder := t.har
+	if recorder == nil {
+		recorder = t.harStopped
+	}
+	if recorder == nil {
+		return &HARErr{Message: "no HAR recorded, call RecordHAR first"}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(recorder.archive())
+}
+
+// builds the archive from the entries recorded so far, in the order the requests were sent.
+func (r *harRecorder) archive() *HAR {
+	harLog := &HARLog{Version: "1.2", Creator: &HARCreator{Name: "autogcd", Version: "1.0"}}
+	harLog.Pages = append(make([]*HARPage, 0, len(r.pages)), r.pages...)
+	harLog.Entries = make([]*HAREntry, 0, len(r.entries))
+	for _, recorded := range r.entries {
+		harLog.Entries = append(harLog.Entries, recorded.entry)
+	}
+	return &HAR{Log: harLog}
+}
+
+// records a new request, a redirect completes the previous entry for the requestId first.
+func (r *harRecorder) request(request *NetworkRequest) {
+	if request.Request == nil {
+		return
+	}
+	if previous, ok := r.pending[request.RequestId]; ok && request.RedirectResponse != nil {
+		r.setResponse(previous, request.RedirectResponse)
+		r.finish(previous, request.Timestamp, request.RedirectResponse.EncodedDataLength)
+		delete(r.pending, request.RequestId)
+	}
+
+	started := time.Now()
+	if request.WallTime > 0 {
+		started = time.Unix(0, int64(request.WallTime*float64(time.Second)))
+	}
+	startedDateTime := started.UTC().Format(time.RFC3339Nano)
+
+	if request.Type == "Document" && request.LoaderId == request.RequestId {
+		if _, exists := r.pageIds[request.LoaderId]; !exists {
+			page := &HARPage{StartedDateTime: startedDateTime, Id: fmt.Sprintf("page_%d", len(r.pages)+1), Title: request.Request.Url, PageTimings: &HARPageTimings{OnContentLoad: -1, OnLoad: -1}}
+			r.pages = append(r.pages, page)
+			r.pageIds[request.LoaderId] = page.Id
+			r.page = page
+			r.pageStart = request.Timestamp
+		}
+	}
+
+	entry := &HAREntry{Pageref: r.pageIds[request.LoaderId], StartedDateTime: startedDateTime}
+	entry.Request = &HARRequest{Method: request.Request.Method, Url: request.Request.Url + request.Request.UrlFragment, HttpVersion: "HTTP/1.1", HeadersSize: -1}
+	entry.Request.Headers = harHeaders(request.Request.Headers)
+	entry.Request.Cookies = harRequestCookies(request.Request.Headers)
+	entry.Request.QueryString = harQueryString(request.Request.Url)
+	if request.HasPostData {
+		entry.Request.PostData = &HARPostData{MimeType: harHeader(request.Request.Headers, "Content-Type"), Text: request.PostData}
+		entry.Request.BodySize = len(request.PostData)
+	}
+	entry.Response = &HARResponse{Cookies: []*HARNameValue{}, Headers: []*HARNameValue{}, Content: &HARContent{}, HeadersSize: -1, BodySize: -1}
+	entry.Timings = &HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+
+	recorded := &harEntry{entry: entry, timestamp: request.Timestamp}
+	r.entries = append(r.entries, recorded)
+	r.pending[request.RequestId] = recorded
+}
+
+// sets the DOMContentLoaded or load timing of the latest page from the event's timestamp, only the first
+// event of each kind after the page's document was requested is used.
+func (r *harRecorder) pageEvent(timestamp float64, load bool) {
+	if r.page == nil || timestamp < r.pageStart {
+		return
+	}
+	timing := &r.page.PageTimings.OnContentLoad
+	if load {
+		timing = &r.page.PageTimings.OnLoad
+	}
+	if *timing == -1 {
+		*timing = (timestamp - r.pageStart) * 1000
+	}
+}
+
+func (r *harRecorder) response(response *NetworkResponse) {
+	if recorded, ok := r.pending[response.RequestId]; ok && response.Response != nil {
+		r.setResponse(recorded, response.Response)
+	}
+}
+
+// completes the entry, returns it so the body can be attached.
+func (r *harRecorder) finished(requestId string, timestamp, dataLength float64) *harEntry {
+	recorded, ok := r.pending[requestId]
+	if !ok {
+		return nil
+	}
+	delete(r.pending, requestId)
+	r.finish(recorded, timestamp, dataLength)
+	return recorded
+}
+
+func (r *harRecorder) failed(failure *FailedRequest) {
+	recorded, ok := r.pending[failure.RequestId]
+	if !ok {
+		return
+	}
+	delete(r.pending, failure.RequestId)
+	recorded.entry.Response.Error = failure.ErrorText
+}
+
+func (r *harRecorder) setResponse(recorded *harEntry, response *gcdapi.NetworkResponse) {
+	entry := recorded.entry
+	entry.Response.Status = response.Status
+	entry.Response.StatusText = response.StatusText
+	entry.Response.HttpVersion = harHttpVersion(response.Protocol)
+	entry.Response.Headers = harHeaders(response.Headers)
+	entry.Response.Cookies = harResponseCookies(response.Headers)
+	entry.Response.RedirectURL = harHeader(response.Headers, "Location")
+	entry.Response.Content.MimeType = response.MimeType
+	entry.Request.HttpVersion = entry.Response.HttpVersion
+	if len(response.RequestHeaders) > 0 {
+		entry.Request.Headers = harHeaders(response.RequestHeaders)
+		entry.Request.Cookies = harRequestCookies(response.RequestHeaders)
+	}
+	entry.ServerIPAddress = strings.Trim(response.RemoteIPAddress, "[]")
+	if response.ConnectionId > 0 {
+		entry.Connection = fmt.Sprintf("%.0f", response.ConnectionId)
+	}
+	recorded.timing = response.Timing
+}
+
+// computes the timings once the response has been received in full.
+func (r *harRecorder) finish(recorded *harEntry, timestamp, dataLength float64) {
+	entry := recorded.entry
+	entry.Response.BodySize = int(dataLength)
+	entry.Timings = harTimings(recorded.timing, recorded.timestamp, timestamp)
+	entry.Time = 0
+	for _, phase := range []float64{entry.Timings.Blocked, entry.Timings.DNS, entry.Timings.Connect, entry.Timings.Send, entry.Timings.Wait, entry.Timings.Receive} {
+		if phase > 0 {
+			entry.Time += phase
+		}
+	}
+}
+
+// sets the response body of an entry, bodies over the size limit are left out.
+func (r *harRecorder) setBody(recorded *harEntry, body []byte) {
+	content := recorded.entry.Response.Content
+	content.Size = len(body)
+	if len(body) > r.options.MaxBodySize {
+		return
+	}
+	if isTextMimeType(content.MimeType) {
+		content.Text = string(body)
+		return
+	}
+	content.Text = base64.StdEncoding.EncodeToString(body)
+	content.Encoding = "base64"
+}
+
+// records a page load event of the top frame for the HAR being recorded.
+func (t *Tab) recordHARPageEvent(timestamp float64, load bool) {
+	t.networkLock.Lock()
+	if t.har != nil {
+		t.har.pageEvent(timestamp, load)
+	}
+	t.networkLock.Unlock()
+}
+
+// retrieves the body of a finished request for the HAR being recorded.
+func (t *Tab) recordHARBody(recorder *harRecorder, recorded *harEntry, requestId string) {
+	body, err := t.GetResponseBody(requestId)
+	if err != nil {
+		t.debugf("unable to get body of %s for HAR: %s\n", requestId, err)
+		return
+	}
+	t.networkLock.Lock()
+	recorder.setBody(recorded, body)
+	t.networkLock.Unlock()
+}
+
+// converts chrome's timing, milliseconds relative to timing.RequestTime, to HAR timings. Responses without
+// timing such as cache hits only have the time from the request to the end of the body.
+func harTimings(timing *gcdapi.NetworkResourceTiming, requestTimestamp, finishedTimestamp float64) *HARTimings {
+	timings := &HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+	if timing == nil {
+		if finishedTimestamp > requestTimestamp {
+			timings.Receive = (finishedTimestamp - requestTimestamp) * 1000
+		}
+		return timings
+	}
+
+	phase := func(start, end float64) float64 {
+		if start < 0 || end < 0 || end < start {
+			return -1
+		}
+		return end - start
+	}
+	timings.DNS = phase(timing.DnsStart, timing.DnsEnd)
+	timings.Connect = phase(timing.ConnectStart, timing.ConnectEnd)
+	timings.SSL = phase(timing.SslStart, timing.SslEnd)
+
+	firstPhase := timing.SendStart
+	for _, start := range []float64{timing.ConnectStart, timing.DnsStart} {
+		if start >= 0 {
+			firstPhase = start
+		}
+	}
+	if firstPhase > 0 {
+		timings.Blocked = firstPhase
+	}
+	if send := phase(timing.SendStart, timing.SendEnd); send > 0 {
+		timings.Send = send
+	}
+	if wait := phase(timing.SendEnd, timing.ReceiveHeadersEnd); wait > 0 {
+		timings.Wait = wait
+	}
+	if receive := (finishedTimestamp-timing.RequestTime)*1000 - timing.ReceiveHeadersEnd; finishedTimestamp > 0 && receive > 0 {
+		timings.Receive = receive
+	}
+	return timings
+}
+
+// converts chrome's header map to sorted name value pairs, chrome joins repeated headers with newlines.
+func harHeaders(headers map[string]interface{}) []*HARNameValue {
+	pairs := make([]*HARNameValue, 0, len(headers))
+	for name, value := range headers {
+		for _, line := range strings.Split(fmt.Sprintf("%v", value), "\n") {
+			pairs = append(pairs, &HARNameValue{Name: name, Value: line})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Name < pairs[j].Name })
+	return pairs
+}
+
+// returns the first value of the header name, matched case insensitively.
+func harHeader(headers map[string]interface{}, name string) string {
+	for header, value := range headers {
+		if strings.EqualFold(header, name) {
+			return strings.SplitN(fmt.Sprintf("%v", value), "\n", 2)[0]
+		}
+	}
+	return ""
+}
+
+func harRequestCookies(headers map[string]interface{}) []*HARNameValue {
+	cookies := make([]*HARNameValue, 0)
+	for _, cookie := range strings.Split(harHeader(headers, "Cookie"), ";") {
+		parts := strings.SplitN(strings.TrimSpace(cookie), "=", 2)
+		if len(parts) == 2 {
+			cookies = append(cookies, &HARNameValue{Name: parts[0], Value: parts[1]})
+		}
+	}
+	return cookies
+}
+
+func harResponseCookies(headers map[string]interface{}) []*HARNameValue {
+	cookies := make([]*HARNameValue, 0)
+	for header, value := range headers {
+		if !strings.EqualFold(header, "Set-Cookie") {
+			continue
+		}
+		for _, line := range strings.Split(fmt.Sprintf("%v", value), "\n") {
+			parts := strings.SplitN(strings.SplitN(line, ";", 2)[0], "=", 2)
+			if len(parts) == 2 {
+				cookies = append(cookies, &HARNameValue{Name: strings.TrimSpace(parts[0]), Value: parts[1]})
+			}
+		}
+	}
+	return cookies
+}
+
+func harQueryString(rawurl string) []*HARNameValue {
+	pairs := make([]*HARNameValue, 0)
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return pairs
+	}
+	for _, param := range strings.Split(u.RawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		parts := strings.SplitN(param, "=", 2)
+		name, _ := url.QueryUnescape(parts[0])
+		value := ""
+		if len(parts) == 2 {
+			value, _ = url.QueryUnescape(parts[1])
+		}
+		pairs = append(pairs, &HARNameValue{Name: name, Value: value})
+	}
+	return pairs
+}
+
+// maps chrome's protocol names (http/1.1, h2, h3) to HAR http versions.
+func harHttpVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "h2":
+		return "HTTP/2.0"
+	case "h3", "h3-29", "quic":
+		return "HTTP/3.0"
+	case "":
+		return "HTTP/1.1"
+	}
+	return strings.ToUpper(protocol)
+}
+
+// are bodies of this mime type stored as text rather than base64.
+func isTextMimeType(mimeType string) bool {
+	mimeType = strings.ToLower(mimeType)
+	if strings.HasPrefix(mimeType, "text/") {
+		return true
+	}
+	for _, textType := range []string{"json", "javascript", "xml", "svg", "x-www-form-urlencoded"} {
+		if strings.Contains(mimeType, textType) {
+			return true
+		}
+	}
+	return false
+}
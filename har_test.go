@@ -0,0 +1,97 @@
+package autogcd
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+func TestHARRecorder(t *testing.T) {
+	recorder := newHARRecorder(&HAROptions{})
+
+	document := &gcdapi.NetworkRequest{Url: "http://localhost/start?a=1&b=two%20words", Method: "GET", Headers: map[string]interface{}{"Cookie": "session=abc; theme=dark"}}
+	recorder.request(&NetworkRequest{RequestId: "1", LoaderId: "1", Type: "Document", Request: document, Timestamp: 10, WallTime: 1500000000})
+
+	redirect := &gcdapi.NetworkResponse{Url: "http://localhost/start", Status: 302, StatusText: "Found", Headers: map[string]interface{}{"Location": "/landing"}, EncodedDataLength: 100}
+	landing := &gcdapi.NetworkRequest{Url: "http://localhost/landing", Method: "GET", Headers: map[string]interface{}{}}
+	recorder.request(&NetworkRequest{RequestId: "1", LoaderId: "1", Type: "Document", Request: landing, Timestamp: 10.1, WallTime: 1500000000.1, RedirectResponse: redirect})
+
+	timing := &gcdapi.NetworkResourceTiming{RequestTime: 10.1, DnsStart: -1, DnsEnd: -1, ConnectStart: -1, ConnectEnd: -1, SslStart: -1, SslEnd: -1, SendStart: 1, SendEnd: 2, ReceiveHeadersEnd: 50}
+	response := &gcdapi.NetworkResponse{Url: "http://localhost/landing", Status: 200, StatusText: "OK", MimeType: "text/html", Protocol: "h2", Headers: map[string]interface{}{"Set-Cookie": "a=1; Path=/\nb=2"}, Timing: timing}
+	recorder.response(&NetworkResponse{RequestId: "1", Response: response})
+	recorded := recorder.finished("1", 10.2, 500)
+	recorder.setBody(recorded, []byte("<html></html>"))
+
+	recorder.request(&NetworkRequest{RequestId: "2", LoaderId: "1", Type: "Image", Request: &gcdapi.NetworkRequest{Url: "http://localhost/missing.png", Method: "GET"}, Timestamp: 10.3})
+	recorder.failed(&FailedRequest{RequestId: "2", ErrorText: "net::ERR_FAILED"})
+
+	archive := recorder.archive()
+	if len(archive.Log.Pages) != 1 || len(archive.Log.Entries) != 3 {
+		t.Fatalf("expected 1 page and 3 entries got %d %d\n", len(archive.Log.Pages), len(archive.Log.Entries))
+	}
+
+	first, second, failed := archive.Log.Entries[0], archive.Log.Entries[1], archive.Log.Entries[2]
+	if first.Response.Status != 302 || first.Response.RedirectURL != "/landing" || first.Pageref != "page_1" {
+		t.Fatalf("unexpected redirect entry %#v\n", first.Response)
+	}
+	if len(first.Request.QueryString) != 2 || first.Request.QueryString[1].Value != "two words" || len(first.Request.Cookies) != 2 {
+		t.Fatalf("unexpected request %#v\n", first.Request)
+	}
+	if second.Response.Status != 200 || second.Response.HttpVersion != "HTTP/2.0" || second.Response.Content.Text != "<html></html>" || len(second.Response.Cookies) != 2 {
+		t.Fatalf("unexpected response %#v\n", second.Response)
+	}
+	if second.Timings.Wait != 48 || second.Timings.DNS != -1 || second.Time <= 0 {
+		t.Fatalf("unexpected timings %#v\n", second.Timings)
+	}
+	if failed.Response.Error != "net::ERR_FAILED" || failed.Response.Status != 0 {
+		t.Fatalf("unexpected failed entry %#v\n", failed.Response)
+	}
+}
+
+func TestHARBodyEncoding(t *testing.T) {
+	recorder := newHARRecorder(&HAROptions{MaxBodySize: 4})
+	recorded := &harEntry{entry: &HAREntry{Response: &HARResponse{Content: &HARContent{MimeType: "image/png"}}}}
+	recorder.setBody(recorded, []byte{1, 2, 3})
+	if recorded.entry.Response.Content.Encoding != "base64" || recorded.entry.Response.Content.Text != "AQID" {
+		t.Fatalf("expected a base64 body got %#v\n", recorded.entry.Response.Content)
+	}
+
+	recorded.entry.Response.Content = &HARContent{MimeType: "text/plain"}
+	recorder.setBody(recorded, []byte("too large"))
+	if recorded.entry.Response.Content.Text != "" || recorded.entry.Response.Content.Size != 9 {
+		t.Fatalf("expected the body to be omitted got %#v\n", recorded.entry.Response.Content)
+	}
+}
+
+func TestHARPageTimings(t *testing.T) {
+	recorder := newHARRecorder(&HAROptions{})
+	recorder.pageEvent(9, true)
+	document := &gcdapi.NetworkRequest{Url: "http://localhost/", Method: "GET"}
+	recorder.request(&NetworkRequest{RequestId: "1", LoaderId: "1", Type: "Document", Request: document, Timestamp: 10})
+	recorder.pageEvent(9.5, false)
+	recorder.pageEvent(10.25, false)
+	recorder.pageEvent(10.5, true)
+	recorder.pageEvent(11, true)
+
+	timings := recorder.archive().Log.Pages[0].PageTimings
+	if timings.OnContentLoad != 250 || timings.OnLoad != 500 {
+		t.Fatalf("expected page timings of 250 and 500 got %#v\n", timings)
+	}
+
+	recorder.request(&NetworkRequest{RequestId: "2", LoaderId: "2", Type: "Document", Request: document, Timestamp: 12})
+	recorder.pageEvent(12.1, false)
+	pages := recorder.archive().Log.Pages
+	if pages[0].PageTimings.OnContentLoad != 250 || pages[1].PageTimings.OnContentLoad < 99 || pages[1].PageTimings.OnLoad != -1 {
+		t.Fatalf("unexpected timings for the second page %#v\n", pages[1].PageTimings)
+	}
+}
+
+func TestTabExportHARNotRecorded(t *testing.T) {
+	tab := &Tab{networkLock: &sync.RWMutex{}}
+	err := tab.ExportHAR(nil)
+	if _, ok := err.(*HARErr); !ok || !errors.Is(err, ErrHAR) {
+		t.Fatalf("expected a HARErr got %v\n", err)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Severity of a HeaderFinding
+const (
+	FindingHigh   = "high"
+	FindingMedium = "medium"
+	FindingLow    = "low"
+	FindingInfo   = "info"
+)
+
+// minimum Strict-Transport-Security max-age, 180 days
+const minHSTSMaxAge = 15552000
+
+// headers revealing server software and versions
+var disclosureHeaders = []string{"Server", "X-Powered-By", "X-AspNet-Version", "X-AspNetMvc-Version", "X-Generator"}
+
+// HeaderFinding is a missing or weak security header found by AuditResponseHeaders.
+type HeaderFinding struct {
+	Url          string // url of the response
+	ResourceType string // Document, Script, Stylesheet etc
+	Header       string // the header the finding is about
+	Severity     string // one of the Finding constants
+	Message      string // description of the problem
+}
+
+func (f *HeaderFinding) String() string {
+	return fmt.Sprintf("[%s] %s %s: %s", f.Severity, f.Url, f.Header, f.Message)
+}
+
+// AuditResponseHeaders passively checks the responses received since the last Navigate (or ClearResources)
+// for missing or weak security headers. Responses are only tracked while the Network domain is enabled,
+// which Navigate does automatically. Documents, including frames, are checked for Content-Security-Policy,
+// Strict-Transport-Security, clickjacking protection, X-Content-Type-Options, Referrer-Policy and
+// Permissions-Policy. Every response is checked for X-Content-Type-Options on scripts and stylesheets,
+// credentialed wildcard CORS and headers disclosing server software. Identical findings for the same origin
+// are reported once.
+func (t *Tab) AuditResponseHeaders() []*HeaderFinding {
+	type response struct {
+		url, resourceType string
+		headers           map[string]interface{}
+	}
+	responses := make([]*response, 0)
+	t.networkLock.RLock()
+	for _, requestId := range t.resourceOrder {
+		tracked := t.resources[requestId]
+		if tracked.headers != nil {
+			responses = append(responses, &response{url: tracked.Url, resourceType: tracked.Type, headers: tracked.headers})
+		}
+	}
+	t.networkLock.RUnlock()
+
+	findings := make([]*HeaderFinding, 0)
+	seen := make(map[string]struct{})
+	for _, r := range responses {
+		for _, finding := range auditHeaders(r.url, r.resourceType, r.headers) {
+			key := scriptOrigin(finding.Url) + "|" + finding.ResourceType + "|" + finding.Header + "|" + finding.Message
+			if finding.ResourceType == "Document" {
+				key = finding.Url + "|" + key
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// checks the headers of a single response.
+func auditHeaders(url, resourceType string, headers map[string]interface{}) []*HeaderFinding {
+	findings := make([]*HeaderFinding, 0)
+	add := func(header, severity, message string) {
+		findings = append(findings, &HeaderFinding{Url: url, ResourceType: resourceType, Header: header, Severity: severity, Message: message})
+	}
+
+	if resourceType == "Document" {
+		csp := harHeader(headers, "Content-Security-Policy")
+		if csp == "" {
+			add("Content-Security-Policy", FindingMedium, "missing, scripts from any source may run")
+		} else if directive := cspDirective(csp, "script-src", "default-src"); strings.Contains(directive, "'unsafe-inline'") && !strings.Contains(directive, "'nonce-") && !strings.Contains(directive, "'sha") && !strings.Contains(directive, "'strict-dynamic'") {
+			add("Content-Security-Policy", FindingLow, "allows 'unsafe-inline' scripts")
+		}
+
+		if strings.HasPrefix(strings.ToLower(url), "https:") {
+			hsts := harHeader(headers, "Strict-Transport-Security")
+			if hsts == "" {
+				add("Strict-Transport-Security", FindingMedium, "missing on an https document")
+			} else if maxAge := hstsMaxAge(hsts); maxAge < minHSTSMaxAge {
+				add("Strict-Transport-Security", FindingLow, fmt.Sprintf("max-age %d is less than 180 days", maxAge))
+			}
+		}
+
+		if harHeader(headers, "X-Frame-Options") == "" && cspDirective(csp, "frame-ancestors") == "" {
+			add("X-Frame-Options", FindingMedium, "missing and no CSP frame-ancestors, the page may be framed (clickjacking)")
+		}
+		if harHeader(headers, "Referrer-Policy") == "" {
+			add("Referrer-Policy", FindingLow, "missing, the browser default is used")
+		}
+		if harHeader(headers, "Permissions-Policy") == "" {
+			add("Permissions-Policy", FindingInfo, "missing")
+		}
+	}
+
+	if resourceType == "Document" || resourceType == "Script" || resourceType == "Stylesheet" {
+		if !strings.EqualFold(strings.TrimSpace(harHeader(headers, "X-Content-Type-Options")), "nosniff") {
+			add("X-Content-Type-Options", FindingLow, "not set to nosniff, the response may be MIME sniffed")
+		}
+	}
+
+	if harHeader(headers, "Access-Control-Allow-Origin") == "*" && strings.EqualFold(harHeader(headers, "Access-Control-Allow-Credentials"), "true") {
+		add("Access-Control-Allow-Origin", FindingHigh, "wildcard origin with credentials allowed")
+	}
+
+	for _, header := range disclosureHeaders {
+		if value := harHeader(headers, header); value != "" && strings.ContainsAny(value, "0123456789") {
+			add(header, FindingInfo, "discloses version information: "+value)
+		}
+	}
+	return findings
+}
+
+// returns the value of the first directive of policy found, in the order of names.
+func cspDirective(policy string, names ...string) string {
+	directives := make(map[string]string)
+	for _, directive := range strings.Split(policy, ";") {
+		fields := strings.Fields(strings.TrimSpace(directive))
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if _, exists := directives[name]; !exists {
+			directives[name] = strings.Join(fields[1:], " ")
+		}
+	}
+	for _, name := range names {
+		if value, ok := directives[name]; ok {
+			if value == "" {
+				return "'none'"
+			}
+			return value
+		}
+	}
+	return ""
+}
+
+// returns the max-age of a Strict-Transport-Security header, 0 if missing or invalid.
+func hstsMaxAge(hsts string) int {
+	for _, directive := range strings.Split(hsts, ";") {
+		parts := strings.SplitN(strings.TrimSpace(directive), "=", 2)
+		if len(parts) == 2 && strings.EqualFold(parts[0], "max-age") {
+			maxAge, _ := strconv.Atoi(strings.Trim(parts[1], `"`))
+			return maxAge
+		}
+	}
+	return 0
+}
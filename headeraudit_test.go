@@ -0,0 +1,93 @@
+package autogcd
+
+import (
+	"testing"
+)
+
+func findingHeaders(findings []*HeaderFinding) map[string]string {
+	headers := make(map[string]string)
+	for _, finding := range findings {
+		headers[finding.Header] = finding.Severity
+	}
+	return headers
+}
+
+func TestAuditHeadersMissing(t *testing.T) {
+	findings := findingHeaders(auditHeaders("https://example.com/", "Document", map[string]interface{}{"Server": "nginx/1.18.0"}))
+	expected := map[string]string{
+		"Content-Security-Policy":   FindingMedium,
+		"Strict-Transport-Security": FindingMedium,
+		"X-Frame-Options":           FindingMedium,
+		"X-Content-Type-Options":    FindingLow,
+		"Referrer-Policy":           FindingLow,
+		"Permissions-Policy":        FindingInfo,
+		"Server":                    FindingInfo,
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("expected %d findings got %v\n", len(expected), findings)
+	}
+	for header, severity := range expected {
+		if findings[header] != severity {
+			t.Fatalf("expected %s finding for %s got %v\n", severity, header, findings)
+		}
+	}
+}
+
+func TestAuditHeadersSecure(t *testing.T) {
+	headers := map[string]interface{}{
+		"content-security-policy":   "default-src 'self'; frame-ancestors 'none'",
+		"strict-transport-security": "max-age=31536000; includeSubDomains",
+		"x-content-type-options":    "nosniff",
+		"referrer-policy":           "no-referrer",
+		"permissions-policy":        "camera=()",
+		"server":                    "nginx",
+	}
+	if findings := auditHeaders("https://example.com/", "Document", headers); len(findings) != 0 {
+		t.Fatalf("expected no findings got %v\n", findingHeaders(findings))
+	}
+}
+
+func TestAuditHeadersWeak(t *testing.T) {
+	headers := map[string]interface{}{
+		"Content-Security-Policy":   "script-src 'self' 'unsafe-inline'",
+		"Strict-Transport-Security": "max-age=3600",
+		"X-Frame-Options":           "DENY",
+		"X-Content-Type-Options":    "nosniff",
+		"Referrer-Policy":           "no-referrer",
+		"Permissions-Policy":        "camera=()",
+	}
+	findings := findingHeaders(auditHeaders("https://example.com/", "Document", headers))
+	if len(findings) != 2 || findings["Content-Security-Policy"] != FindingLow || findings["Strict-Transport-Security"] != FindingLow {
+		t.Fatalf("expected weak CSP and HSTS findings got %v\n", findings)
+	}
+
+	headers["Content-Security-Policy"] = "script-src 'self' 'unsafe-inline' 'nonce-abc'"
+	headers["Strict-Transport-Security"] = "max-age=31536000"
+	if findings := auditHeaders("https://example.com/", "Document", headers); len(findings) != 0 {
+		t.Fatalf("expected nonce to allow unsafe-inline got %v\n", findingHeaders(findings))
+	}
+}
+
+func TestAuditHeadersSubresources(t *testing.T) {
+	findings := findingHeaders(auditHeaders("http://example.com/app.js", "Script", map[string]interface{}{}))
+	if len(findings) != 1 || findings["X-Content-Type-Options"] != FindingLow {
+		t.Fatalf("expected only nosniff finding for script got %v\n", findings)
+	}
+
+	if findings := auditHeaders("http://example.com/logo.png", "Image", map[string]interface{}{}); len(findings) != 0 {
+		t.Fatalf("expected no findings for image got %v\n", findingHeaders(findings))
+	}
+
+	cors := map[string]interface{}{"Access-Control-Allow-Origin": "*", "Access-Control-Allow-Credentials": "true"}
+	findings = findingHeaders(auditHeaders("http://example.com/api", "XHR", cors))
+	if findings["Access-Control-Allow-Origin"] != FindingHigh {
+		t.Fatalf("expected credentialed wildcard CORS finding got %v\n", findings)
+	}
+}
+
+func TestAuditHeadersHttpSkipsHSTS(t *testing.T) {
+	findings := findingHeaders(auditHeaders("http://example.com/", "Document", map[string]interface{}{}))
+	if _, ok := findings["Strict-Transport-Security"]; ok {
+		t.Fatalf("HSTS should not be required on http documents\n")
+	}
+}
@@ -0,0 +1,90 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"time"
+)
+
+// how long IsHealthy waits for the renderer to answer
+const healthPingTimeout = 2 * time.Second
+
+// Health describes the state of a tab, see Tab.Health.
+type Health struct {
+	Healthy      bool          // the tab has not crashed or closed and answered the ping
+	Crashed      bool          // the renderer crashed or the target was detached
+	Closed       bool          // the tab has been closed
+	Latency      time.Duration // round trip time of the ping
+	PingErr      error         // why the ping failed, nil if it succeeded
+	LastActivity time.Time     // when a DOM change or network event was last seen, zero if never
+}
+
+// Ping evaluates a trivial script in the page, returning the round trip time. A renderer which is
+// wedged (for example by a busy script) fails with a TimeoutErr after timeout.
+func (t *Tab) Ping(timeout time.Duration) (time.Duration, error) {
+	start := time.Now()
+	err := t.CallWithTimeout(timeout, func() error {
+		rro, exception, err := overridenRuntimeEvaluate(t.targeter(), "1+1", "", false, true, 0, true, false, false, false)
+		if err != nil {
+			return err
+		}
+		if exception != nil {
+			return &ScriptEvaluationErr{Message: "error pinging tab: ", ExceptionText: exception.Text, ExceptionDetails: exception}
+		}
+		if value, ok := rro.Value.(float64); !ok || value != 2 {
+			return &ScriptEvaluationErr{Message: "unexpected ping result"}
+		}
+		return nil
+	})
+	return time.Since(start), err
+}
+
+// Health checks the crash and closed state of the tab, then pings it with timeout. Pool managers can use it
+// to decide whether to recycle a tab.
+func (t *Tab) Health(timeout time.Duration) *Health {
+	health := &Health{Crashed: t.CrashErr() != nil, Closed: t.IsShuttingDown()}
+
+	if changeTime, ok := t.lastNodeChangeTimeVal.Load().(time.Time); ok {
+		health.LastActivity = changeTime
+	}
+	t.networkLock.RLock()
+	if t.lastNetworkActivity.After(health.LastActivity) {
+		health.LastActivity = t.lastNetworkActivity
+	}
+	t.networkLock.RUnlock()
+
+	if health.Crashed || health.Closed {
+		return health
+	}
+
+	health.Latency, health.PingErr = t.Ping(timeout)
+	health.Healthy = health.PingErr == nil
+	return health
+}
+
+// IsHealthy returns true if the tab has not crashed or closed and answers a ping within two seconds.
+func (t *Tab) IsHealthy() bool {
+	return t.Health(healthPingTimeout).Healthy
+}
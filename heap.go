@@ -0,0 +1,152 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"time"
+)
+
+// HeapSampleErr is returned by SampleHeap when the interval or duration is not positive.
+type HeapSampleErr struct {
+	Message string
+}
+
+func (e *HeapSampleErr) Error() string {
+	return "heap sample error: " + e.Message
+}
+
+// Unwrap returns ErrHeapSample so the error can be matched with errors.Is
+func (e *HeapSampleErr) Unwrap() error {
+	return ErrHeapSample
+}
+
+// HeapSample is the size of the JavaScript heap at a point in time.
+type HeapSample struct {
+	Time  time.Time // when the sample was taken
+	Used  float64   // used heap size in bytes
+	Total float64   // allocated heap size in bytes
+}
+
+// LeakReport returned from DetectLeak. Heap sizes are measured after forcing garbage collection.
+type LeakReport struct {
+	Iterations         int     // number of times the action was run (not counting the warm up run)
+	Before             float64 // used heap size in bytes before running the action
+	After              float64 // used heap size in bytes after running the action
+	Growth             float64 // After - Before
+	GrowthPerIteration float64 // Growth / Iterations
+}
+
+// GetHeapUsage returns the current used and total JavaScript heap size in bytes.
+func (t *Tab) GetHeapUsage() (*HeapSample, error) {
+	used, total, err := t.Runtime.GetHeapUsage()
+	if err != nil {
+		return nil, err
+	}
+	return &HeapSample{Time: time.Now(), Used: used, Total: total}, nil
+}
+
+// CollectGarbage forces a full garbage collection of the JavaScript heap.
+func (t *Tab) CollectGarbage() error {
+	_, err := t.HeapProfiler.CollectGarbage()
+	return err
+}
+
+// SampleHeap samples the JavaScript heap size every interval until duration has elapsed,
+// returning the time series. The first sample is taken immediately. Returns a HeapSampleErr if
+// interval or duration is not positive.
+func (t *Tab) SampleHeap(interval, duration time.Duration) ([]*HeapSample, error) {
+	if interval <= 0 || duration <= 0 {
+		return nil, &HeapSampleErr{Message: "interval and duration must be positive"}
+	}
+	samples := make([]*HeapSample, 0)
+	deadline := time.Now().Add(duration)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		sample, err := t.GetHeapUsage()
+		if err != nil {
+			return samples, err
+		}
+		samples = append(samples, sample)
+
+		if !time.Now().Before(deadline) {
+			return samples, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-t.exitCh:
+			return samples, &InvalidTabErr{Message: "tab closed while sampling heap"}
+		}
+	}
+}
+
+// DetectLeak runs actionFn once to warm up caches, forces garbage collection and measures the heap,
+// then runs actionFn iterations more times and measures again. Consistent growth per iteration
+// indicates the action leaks memory.
+func (t *Tab) DetectLeak(iterations int, actionFn TabActionFunc) (*LeakReport, error) {
+	report := &LeakReport{Iterations: iterations}
+
+	if err := actionFn(t); err != nil {
+		return nil, err
+	}
+
+	before, err := t.collectAndMeasure()
+	if err != nil {
+		return nil, err
+	}
+	report.Before = before
+
+	for i := 0; i < iterations; i++ {
+		if err := actionFn(t); err != nil {
+			return nil, err
+		}
+	}
+
+	after, err := t.collectAndMeasure()
+	if err != nil {
+		return nil, err
+	}
+	report.After = after
+	report.Growth = report.After - report.Before
+	if iterations > 0 {
+		report.GrowthPerIteration = report.Growth / float64(iterations)
+	}
+	return report, nil
+}
+
+// forces garbage collection and returns the used heap size.
+func (t *Tab) collectAndMeasure() (float64, error) {
+	if err := t.CollectGarbage(); err != nil {
+		return 0, err
+	}
+	sample, err := t.GetHeapUsage()
+	if err != nil {
+		return 0, err
+	}
+	return sample.Used, nil
+}
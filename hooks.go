@@ -0,0 +1,202 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// name of the binding instrumented functions report calls to
+const hookBindingName = "__autogcdHook"
+
+// Wraps the function at path so every call is reported to the binding before the original is called.
+// Constructors called with new are supported. Returns false if path is not a function.
+const hookFunctionScript = `(function(path, bindingName) {
+	var hooks = window.__autogcdHooks = window.__autogcdHooks || {};
+	if (hooks[path]) {
+		return true;
+	}
+	var parts = path.split('.');
+	if (parts[0] === 'window') {
+		parts.shift();
+	}
+	var name = parts.pop();
+	var owner = window;
+	for (var i = 0; i < parts.length; i++) {
+		owner = owner[parts[i]];
+		if (owner === null || owner === undefined) {
+			return false;
+		}
+	}
+	var original = owner[name];
+	if (typeof original !== 'function') {
+		return false;
+	}
+	function serialize(arg) {
+		if (typeof arg === 'function') {
+			return 'function ' + (arg.name || 'anonymous');
+		}
+		try {
+			var value = JSON.stringify(arg);
+			return value === undefined ? String(arg) : JSON.parse(value);
+		} catch (e) {
+			return String(arg);
+		}
+	}
+	var wrapper = function() {
+		// reporting calls JSON functions, which may themselves be hooked
+		if (!window.__autogcdHookReporting) {
+			window.__autogcdHookReporting = true;
+			try {
+				var args = [];
+				for (var i = 0; i < arguments.length; i++) {
+					args.push(serialize(arguments[i]));
+				}
+				window[bindingName](JSON.stringify({path: path, args: args, stack: new Error().stack || ''}));
+			} catch (e) {
+			} finally {
+				window.__autogcdHookReporting = false;
+			}
+		}
+		if (new.target) {
+			return Reflect.construct(original, arguments, new.target);
+		}
+		return original.apply(this, arguments);
+	};
+	wrapper.prototype = original.prototype;
+	hooks[path] = {owner: owner, name: name, original: original};
+	owner[name] = wrapper;
+	return true;
+})(%s, %s)`
+
+// Restores the original function at path.
+const unhookFunctionScript = `(function(path) {
+	var hooks = window.__autogcdHooks || {};
+	var hook = hooks[path];
+	if (hook) {
+		hook.owner[hook.name] = hook.original;
+		delete hooks[path];
+	}
+})(%s)`
+
+// HookCall describes a call to a function instrumented by HookFunction.
+type HookCall struct {
+	Path      string        `json:"path"`  // the path passed to HookFunction
+	Arguments []interface{} `json:"args"`  // arguments as JSON values, values that can not be serialized are converted to strings
+	Stack     string        `json:"stack"` // javascript stack trace at the time of the call
+}
+
+// a hooked function's handler and new document script.
+type hook struct {
+	handler  HookFunc
+	scriptId string
+}
+
+// HookFunction wraps the page function at jsPath (window.fetch, JSON.parse, document.write etc) so that every
+// call reports its arguments to handlerFn before calling the original. The hook is installed in the current
+// document and every document loaded after. Functions defined by the page's own scripts can only be hooked
+// in the current document, since new documents are instrumented before any of their scripts run.
+func (t *Tab) HookFunction(jsPath string, handlerFn HookFunc) error {
+	if err := t.addBinding(hookBindingName, t.handleHookCall); err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf(hookFunctionScript, jsQuote(jsPath), jsQuote(hookBindingName))
+
+	t.bindingLock.Lock()
+	existing, exists := t.hooks[jsPath]
+	t.hooks[jsPath] = &hook{handler: handlerFn}
+	if exists {
+		t.hooks[jsPath].scriptId = existing.scriptId
+	}
+	t.bindingLock.Unlock()
+
+	if !exists {
+		scriptId, err := t.Page.AddScriptToEvaluateOnNewDocument(script, "")
+		if err != nil {
+			t.bindingLock.Lock()
+			delete(t.hooks, jsPath)
+			t.bindingLock.Unlock()
+			return err
+		}
+		t.bindingLock.Lock()
+		current, ok := t.hooks[jsPath]
+		if ok {
+			current.scriptId = scriptId
+		}
+		t.bindingLock.Unlock()
+		if !ok {
+			// unhooked while the script was being added
+			_, err := t.Page.RemoveScriptToEvaluateOnNewDocument(scriptId)
+			return err
+		}
+	}
+
+	_, err := t.evaluateScript(script, false)
+	return err
+}
+
+// UnhookFunction restores the original function at jsPath in the current document and stops instrumenting
+// new documents.
+func (t *Tab) UnhookFunction(jsPath string) error {
+	t.bindingLock.Lock()
+	existing, exists := t.hooks[jsPath]
+	delete(t.hooks, jsPath)
+	t.bindingLock.Unlock()
+
+	if !exists {
+		return nil
+	}
+
+	if _, err := t.Page.RemoveScriptToEvaluateOnNewDocument(existing.scriptId); err != nil {
+		return err
+	}
+	_, err := t.evaluateScript(fmt.Sprintf(unhookFunctionScript, jsQuote(jsPath)), false)
+	return err
+}
+
+// dispatches calls reported by the hook binding to the handler of the hooked path.
+func (t *Tab) handleHookCall(payload string) {
+	call := &HookCall{}
+	if err := json.Unmarshal([]byte(payload), call); err != nil {
+		t.debugf("invalid hook payload: %s\n", err)
+		return
+	}
+
+	t.bindingLock.RLock()
+	existing, ok := t.hooks[call.Path]
+	t.bindingLock.RUnlock()
+
+	if ok && existing.handler != nil {
+		existing.handler(t, call)
+	}
+}
+
+// quotes a string for use as a javascript string literal.
+func jsQuote(value string) string {
+	quoted, _ := json.Marshal(value)
+	return string(quoted)
+}
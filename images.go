@@ -0,0 +1,175 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+	"time"
+)
+
+// Scrolls the page a viewport at a time, pausing between steps so lazy loaded content is requested,
+// until the bottom is reached or maxSteps. Resolves to the number of steps taken.
+const scrollThroughScript = `(function(maxSteps, pause) {
+	return new Promise(function(resolve) {
+		var steps = 0;
+		function step() {
+			var before = window.scrollY;
+			window.scrollBy(0, window.innerHeight);
+			steps++;
+			var atBottom = window.scrollY === before || window.innerHeight + window.scrollY >= document.documentElement.scrollHeight;
+			if (atBottom || steps >= maxSteps) {
+				resolve(steps);
+				return;
+			}
+			setTimeout(step, pause);
+		}
+		step();
+	});
+})(%d, %d)`
+
+// Collects every img (using currentSrc so srcset and picture are resolved) with its natural size and alt text.
+const collectImagesScript = `(function() {
+	var images = [];
+	var seen = {};
+	for (var i = 0; i < document.images.length; i++) {
+		var img = document.images[i];
+		var src = img.currentSrc || img.src;
+		if (!src || seen[src]) {
+			continue;
+		}
+		seen[src] = true;
+		images.push({url: src, alt: img.alt || '', width: img.naturalWidth, height: img.naturalHeight, loaded: img.complete && img.naturalWidth > 0});
+	}
+	return images;
+})()`
+
+// ImageOptions for CollectImages, the zero value scrolls up to 20 viewports and does not download.
+type ImageOptions struct {
+	MaxScrolls  int           // maximum viewports to scroll through to trigger lazy loading, 0 defaults to 20, negative disables scrolling
+	ScrollPause time.Duration // time to wait between scrolls, 0 defaults to 250ms
+	IdleTime    time.Duration // how long the network must be idle after scrolling, 0 defaults to 500ms
+	Timeout     time.Duration // maximum time to wait for network idle, 0 defaults to the navigation timeout
+	Download    bool          // retrieve each image's bytes from the browser cache via GetResponseBody
+}
+
+// Image found by CollectImages
+type Image struct {
+	Url    string // the image's current source
+	Alt    string // alt text
+	Width  int    // natural width in pixels, 0 if the image did not load
+	Height int    // natural height in pixels, 0 if the image did not load
+	Loaded bool   // the image finished loading
+	Data   []byte // the image bytes if ImageOptions.Download was set and the response is still available
+}
+
+// CollectImages scrolls through the page to trigger lazy loading, waits for the network to become idle and
+// returns every image in the top level document. Pass nil for the default options. If the network does not
+// become idle before the timeout, the images loaded so far are returned.
+func (t *Tab) CollectImages(opts *ImageOptions) ([]*Image, error) {
+	if opts == nil {
+		opts = &ImageOptions{}
+	}
+	maxScrolls := opts.MaxScrolls
+	if maxScrolls == 0 {
+		maxScrolls = 20
+	}
+	pause := opts.ScrollPause
+	if pause == 0 {
+		pause = 250 * time.Millisecond
+	}
+	idleTime := opts.IdleTime
+	if idleTime == 0 {
+		idleTime = 500 * time.Millisecond
+	}
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = t.navigationTimeout
+	}
+
+	if err := t.enableNetwork(); err != nil {
+		return nil, err
+	}
+
+	if maxScrolls > 0 {
+		if _, err := t.evaluateScript(fmt.Sprintf(scrollThroughScript, maxScrolls, int(pause/time.Millisecond)), true); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := t.WaitNetworkIdle(idleTime, timeout); err != nil {
+		t.debugf("network did not become idle collecting images: %s\n", err)
+	}
+
+	rro, err := t.evaluateScript(collectImagesScript, false)
+	if err != nil {
+		return nil, err
+	}
+
+	found, _ := rro.Value.([]interface{})
+	images := make([]*Image, 0, len(found))
+	for _, value := range found {
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		image := &Image{}
+		image.Url, _ = values["url"].(string)
+		image.Alt, _ = values["alt"].(string)
+		width, _ := values["width"].(float64)
+		height, _ := values["height"].(float64)
+		image.Width = int(width)
+		image.Height = int(height)
+		image.Loaded, _ = values["loaded"].(bool)
+
+		if opts.Download && image.Loaded {
+			image.Data, err = t.downloadResource(image.Url)
+			if err != nil {
+				t.debugf("unable to download %s: %s\n", image.Url, err)
+			}
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
+
+// returns the body of the most recent finished request for url using GetResponseBody.
+func (t *Tab) downloadResource(url string) ([]byte, error) {
+	requestId := ""
+	t.networkLock.RLock()
+	for i := len(t.resourceOrder) - 1; i >= 0; i-- {
+		tracked, ok := t.resources[t.resourceOrder[i]]
+		if ok && tracked.finished && tracked.Url == url {
+			requestId = t.resourceOrder[i]
+			break
+		}
+	}
+	t.networkLock.RUnlock()
+
+	if requestId == "" {
+		return nil, fmt.Errorf("no finished request for %s", url)
+	}
+
+	return t.GetResponseBody(requestId)
+}
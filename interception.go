@@ -0,0 +1,332 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/wirepair/gcd"
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// InterceptionErr is returned when deciding the fate of an intercepted request fails.
+type InterceptionErr struct {
+	Message string
+}
+
+func (e *InterceptionErr) Error() string {
+	return "interception error: " + e.Message
+}
+
+// Unwrap returns ErrInterception so the error can be matched with errors.Is
+func (e *InterceptionErr) Unwrap() error {
+	return ErrInterception
+}
+
+// RequestOverrides for InterceptedRequest.ContinueWith, empty fields are left unchanged. Headers replaces
+// all of the request's headers, start from InterceptedRequest.Headers to modify them.
+type RequestOverrides struct {
+	Url      string            // the new url, the change is not observable by the page
+	Method   string            // the new http method
+	Headers  map[string]string // replaces the request headers
+	PostData string            // the new request body
+}
+
+// InterceptedRequest is a request paused by InterceptRequests. The handler decides what happens to it by
+// calling one of Continue, ContinueWith, Fulfill or Abort. If the handler returns without a decision the
+// request is continued unmodified, unless Hold was called in which case it stays paused until decided.
+type InterceptedRequest struct {
+	RequestId       string            // the Fetch domain's id for the paused request
+	FrameId         string            // frame that issued the request
+	ResourceType    string            // Document, Stylesheet, Image, Media, Font, Script, XHR, Fetch etc
+	Url             string            // request url, including the fragment
+	Method          string            // http method
+	Headers         map[string]string // request headers
+	PostData        string            // request body if sent inline
+	ResponseStatus  int               // status code if paused at the response stage, see RequestPattern
+	ResponseError   string            // network error if paused at the response stage
+	ResponseHeaders map[string]string // response headers if paused at the response stage
+	tab             *Tab
+	lock            *sync.Mutex
+	held            bool
+	decided         bool
+}
+
+// IsResponseStage returns true if the request was paused after the response was received.
+func (r *InterceptedRequest) IsResponseStage() bool {
+	return r.ResponseStatus != 0 || r.ResponseError != ""
+}
+
+// Hold keeps the request paused after the handler returns so it can be decided later from another goroutine.
+// The page's request will not complete until Continue, ContinueWith, Fulfill or Abort is called.
+func (r *InterceptedRequest) Hold() {
+	r.lock.Lock()
+	r.held = true
+	r.lock.Unlock()
+}
+
+// Continue resumes the request unmodified.
+func (r *InterceptedRequest) Continue() error {
+	return r.ContinueWith(nil)
+}
+
+// ContinueWith resumes the request, replacing any non empty fields of overrides.
+func (r *InterceptedRequest) ContinueWith(overrides *RequestOverrides) error {
+	params := &gcdapi.FetchContinueRequestParams{RequestId: r.RequestId}
+	if overrides != nil {
+		params.Url = overrides.Url
+		params.Method = overrides.Method
+		if overrides.PostData != "" {
+			params.PostData = base64.StdEncoding.EncodeToString([]byte(overrides.PostData))
+		}
+		params.Headers = headerEntries(overrides.Headers)
+	}
+	return r.decide(func() error {
+		_, err := r.tab.Fetch.ContinueRequestWithParams(params)
+		return err
+	})
+}
+
+// Fulfill answers the request with a synthetic response, it is never sent to the server.
+func (r *InterceptedRequest) Fulfill(status int, headers map[string]string, body []byte) error {
+	params := &gcdapi.FetchFulfillRequestParams{RequestId: r.RequestId, ResponseCode: status}
+	params.ResponseHeaders = headerEntries(headers)
+	if params.ResponseHeaders == nil {
+		params.ResponseHeaders = make([]*gcdapi.FetchHeaderEntry, 0)
+	}
+	if len(body) > 0 {
+		params.Body = base64.StdEncoding.EncodeToString(body)
+	}
+	return r.decide(func() error {
+		_, err := r.tab.Fetch.FulfillRequestWithParams(params)
+		return err
+	})
+}
+
+// Abort fails the request with the network error reason such as Failed, Aborted, TimedOut, AccessDenied,
+// ConnectionRefused or BlockedByClient. An empty reason defaults to Aborted.
+func (r *InterceptedRequest) Abort(reason string) error {
+	if reason == "" {
+		reason = "Aborted"
+	}
+	return r.decide(func() error {
+		_, err := r.tab.Fetch.FailRequest(r.RequestId, reason)
+		return err
+	})
+}
+
+// runs fn if the request has not been decided yet.
+func (r *InterceptedRequest) decide(fn func() error) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.decided {
+		return &InterceptionErr{Message: "request " + r.RequestId + " already decided"}
+	}
+	r.decided = true
+	if err := fn(); err != nil {
+		return &InterceptionErr{Message: "request " + r.RequestId + ": " + err.Error()}
+	}
+	return nil
+}
+
+// returns true if the handler neither decided nor held the request.
+func (r *InterceptedRequest) undecided() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return !r.decided && !r.held
+}
+
+// RequestPattern selects the requests to intercept, the zero value matches every request at the request stage.
+type RequestPattern struct {
+	UrlPattern   string // wildcards '*' (zero or more) and '?' (exactly one) are allowed, empty matches all
+	ResourceType string // only intercept this resource type, Document, Script, XHR etc
+	Response     bool   // pause after the response headers are received instead of before sending
+}
+
+// InterceptRequests enables the Fetch domain and calls handler for every request matching one of patterns, or
+// every request if none are given. Requests stay paused until the handler decides them, see InterceptedRequest.
+// Authentication challenges are answered with the credentials from SetInterceptCredentials, or deferred to
+// Chrome's default behaviour if none were set. Calling it again replaces the patterns and handler. After the
+// tab re-attaches, call it again from the OnReattach handler to intercept on the new connection.
+func (t *Tab) InterceptRequests(handler RequestInterceptFunc, patterns ...*RequestPattern) error {
+	if handler == nil {
+		return &InterceptionErr{Message: "handler must not be nil"}
+	}
+
+	fetchPatterns := make([]*gcdapi.FetchRequestPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		fetchPattern := &gcdapi.FetchRequestPattern{UrlPattern: pattern.UrlPattern, ResourceType: pattern.ResourceType}
+		if fetchPattern.UrlPattern == "" {
+			fetchPattern.UrlPattern = "*"
+		}
+		if pattern.Response {
+			fetchPattern.RequestStage = "Response"
+		}
+		fetchPatterns = append(fetchPatterns, fetchPattern)
+	}
+	if len(fetchPatterns) == 0 {
+		fetchPatterns = append(fetchPatterns, &gcdapi.FetchRequestPattern{UrlPattern: "*"})
+	}
+
+	t.interceptLock.Lock()
+	defer t.interceptLock.Unlock()
+	// subscriptions belong to the connection, subscribe again after re-attaching
+	if connection := t.target(); t.interceptTarget != connection {
+		t.interceptTarget = connection
+		t.Subscribe("Fetch.requestPaused", func(target *gcd.ChromeTarget, payload []byte) {
+			message := &gcdapi.FetchRequestPausedEvent{}
+			if err := json.Unmarshal(payload, message); err == nil && message.Params.Request != nil {
+				t.handleRequestPaused(message)
+			}
+		})
+		t.Subscribe("Fetch.authRequired", func(target *gcd.ChromeTarget, payload []byte) {
+			message := &gcdapi.FetchAuthRequiredEvent{}
+			if err := json.Unmarshal(payload, message); err == nil {
+				t.handleAuthRequired(message.Params.RequestId)
+			}
+		})
+	}
+	t.interceptHandler = handler
+
+	if _, err := t.Fetch.Enable(fetchPatterns, true); err != nil {
+		t.interceptHandler = nil
+		t.interceptTarget = nil
+		t.Unsubscribe("Fetch.requestPaused")
+		t.Unsubscribe("Fetch.authRequired")
+		return &InterceptionErr{Message: err.Error()}
+	}
+	return nil
+}
+
+// StopInterceptingRequests disables the Fetch domain, requests still paused are continued by Chrome.
+func (t *Tab) StopInterceptingRequests() error {
+	t.interceptLock.Lock()
+	defer t.interceptLock.Unlock()
+	if t.interceptHandler == nil {
+		return nil
+	}
+	t.interceptHandler = nil
+	t.interceptTarget = nil
+	t.authAttempts = make(map[string]struct{})
+	t.Unsubscribe("Fetch.requestPaused")
+	t.Unsubscribe("Fetch.authRequired")
+	_, err := t.Fetch.Disable()
+	return err
+}
+
+// SetInterceptCredentials sets the username and password given to http authentication challenges while
+// intercepting requests. Pass empty strings to defer to Chrome's default behaviour.
+func (t *Tab) SetInterceptCredentials(username, password string) {
+	t.interceptLock.Lock()
+	t.interceptUser = username
+	t.interceptPassword = password
+	t.interceptLock.Unlock()
+}
+
+// builds the InterceptedRequest and passes it to the handler, continuing it if the handler did not decide.
+func (t *Tab) handleRequestPaused(message *gcdapi.FetchRequestPausedEvent) {
+	t.interceptLock.RLock()
+	handler := t.interceptHandler
+	t.interceptLock.RUnlock()
+
+	request := newInterceptedRequest(t, message)
+	if handler == nil {
+		request.Continue()
+		return
+	}
+
+	// also runs if handler panics, so the page is not left waiting on the request
+	defer func() {
+		if request.undecided() {
+			if err := request.Continue(); err != nil {
+				t.debugf("unable to continue intercepted request: %s\n", err)
+			}
+		}
+	}()
+	handler(t, request)
+}
+
+// answers an authentication challenge once with the configured credentials, cancelling repeated challenges
+// for the same request so bad credentials do not loop.
+func (t *Tab) handleAuthRequired(requestId string) {
+	response := &gcdapi.FetchAuthChallengeResponse{Response: "Default"}
+	t.interceptLock.Lock()
+	if t.interceptUser != "" || t.interceptPassword != "" {
+		if _, attempted := t.authAttempts[requestId]; attempted {
+			response.Response = "CancelAuth"
+		} else {
+			t.authAttempts[requestId] = struct{}{}
+			response.Response = "ProvideCredentials"
+			response.Username = t.interceptUser
+			response.Password = t.interceptPassword
+		}
+	}
+	t.interceptLock.Unlock()
+
+	if _, err := t.Fetch.ContinueWithAuth(requestId, response); err != nil {
+		t.debugf("unable to continue with auth: %s\n", err)
+	}
+}
+
+func newInterceptedRequest(t *Tab, message *gcdapi.FetchRequestPausedEvent) *InterceptedRequest {
+	params := message.Params
+	request := &InterceptedRequest{tab: t, lock: &sync.Mutex{}}
+	request.RequestId = params.RequestId
+	request.FrameId = params.FrameId
+	request.ResourceType = params.ResourceType
+	request.Url = params.Request.Url + params.Request.UrlFragment
+	request.Method = params.Request.Method
+	request.PostData = params.Request.PostData
+	request.Headers = make(map[string]string, len(params.Request.Headers))
+	for name, value := range params.Request.Headers {
+		request.Headers[name] = fmt.Sprintf("%v", value)
+	}
+	request.ResponseStatus = params.ResponseStatusCode
+	request.ResponseError = params.ResponseErrorReason
+	if len(params.ResponseHeaders) > 0 {
+		request.ResponseHeaders = make(map[string]string, len(params.ResponseHeaders))
+		for _, entry := range params.ResponseHeaders {
+			request.ResponseHeaders[entry.Name] = entry.Value
+		}
+	}
+	return request
+}
+
+// converts headers to Fetch header entries sorted by name, nil if there are none.
+func headerEntries(headers map[string]string) []*gcdapi.FetchHeaderEntry {
+	if len(headers) == 0 {
+		return nil
+	}
+	entries := make([]*gcdapi.FetchHeaderEntry, 0, len(headers))
+	for name, value := range headers {
+		entries = append(entries, &gcdapi.FetchHeaderEntry{Name: name, Value: value})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
@@ -0,0 +1,111 @@
+package autogcd
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/wirepair/gcd"
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// returns a tab whose Fetch commands are answered by a testTargeter, and the methods it received
+func testInterceptTab() (*Tab, *testTargeter, func() []string) {
+	lock := &sync.Mutex{}
+	methods := make([]string, 0)
+	target := newTestTargeter(func(method string) string {
+		lock.Lock()
+		methods = append(methods, method)
+		lock.Unlock()
+		return `{"id":1,"result":{}}`
+	})
+	chromeTarget := &gcd.ChromeTarget{}
+	chromeTarget.Fetch = gcdapi.NewFetch(target)
+	tab := &Tab{ChromeTarget: chromeTarget, interceptLock: &sync.RWMutex{}, authAttempts: make(map[string]struct{})}
+	return tab, target, func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]string{}, methods...)
+	}
+}
+
+func testPausedEvent(requestId string) *gcdapi.FetchRequestPausedEvent {
+	message := &gcdapi.FetchRequestPausedEvent{}
+	message.Params.RequestId = requestId
+	message.Params.ResourceType = "XHR"
+	message.Params.Request = &gcdapi.NetworkRequest{Url: "http://localhost/api", UrlFragment: "#x", Method: "POST", Headers: map[string]interface{}{"Content-Length": 4.0}}
+	return message
+}
+
+func TestInterceptedRequestDecisions(t *testing.T) {
+	tab, target, methods := testInterceptTab()
+	defer close(target.doneCh)
+
+	tab.interceptHandler = func(tab *Tab, request *InterceptedRequest) {
+		if request.Url != "http://localhost/api#x" || request.Headers["Content-Length"] != "4" || request.IsResponseStage() {
+			t.Fatalf("unexpected request %#v\n", request)
+		}
+	}
+	tab.handleRequestPaused(testPausedEvent("1"))
+
+	var decideErr error
+	tab.interceptHandler = func(tab *Tab, request *InterceptedRequest) {
+		if err := request.Fulfill(200, map[string]string{"Content-Type": "text/plain"}, []byte("mocked")); err != nil {
+			t.Fatalf("error fulfilling request: %s\n", err)
+		}
+		decideErr = request.Abort("")
+	}
+	tab.handleRequestPaused(testPausedEvent("2"))
+	if !errors.Is(decideErr, ErrInterception) {
+		t.Fatalf("expected an InterceptionErr deciding twice got %v\n", decideErr)
+	}
+
+	var held *InterceptedRequest
+	tab.interceptHandler = func(tab *Tab, request *InterceptedRequest) {
+		request.Hold()
+		held = request
+	}
+	tab.handleRequestPaused(testPausedEvent("3"))
+	if got := methods(); len(got) != 2 || got[0] != "Fetch.continueRequest" || got[1] != "Fetch.fulfillRequest" {
+		t.Fatalf("held request should not be continued, got %v\n", got)
+	}
+	if err := held.ContinueWith(&RequestOverrides{Headers: map[string]string{"X-Test": "1"}}); err != nil {
+		t.Fatalf("error continuing held request: %s\n", err)
+	}
+
+	tab.interceptHandler = func(tab *Tab, request *InterceptedRequest) {
+		panic("boom")
+	}
+	func() {
+		defer func() { recover() }()
+		tab.handleRequestPaused(testPausedEvent("4"))
+	}()
+	if got := methods(); len(got) != 4 || got[3] != "Fetch.continueRequest" {
+		t.Fatalf("expected a panicking handler's request to be continued, got %v\n", got)
+	}
+}
+
+func TestInterceptAuthRequired(t *testing.T) {
+	tab, target, methods := testInterceptTab()
+	defer close(target.doneCh)
+
+	tab.SetInterceptCredentials("user", "pass")
+	tab.handleAuthRequired("1")
+	if _, ok := tab.authAttempts["1"]; !ok {
+		t.Fatalf("expected credentials to be provided once")
+	}
+	tab.handleAuthRequired("1")
+	if got := methods(); len(got) != 2 || got[1] != "Fetch.continueWithAuth" {
+		t.Fatalf("unexpected methods %v\n", got)
+	}
+}
+
+func TestHeaderEntries(t *testing.T) {
+	if headerEntries(nil) != nil {
+		t.Fatalf("expected nil entries for no headers")
+	}
+	entries := headerEntries(map[string]string{"b": "2", "a": "1"})
+	if len(entries) != 2 || entries[0].Name != "a" || entries[1].Value != "2" {
+		t.Fatalf("unexpected entries %#v\n", entries)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// schemes of pages served by the browser itself, these have no network response and may not emit the frame
+// and document events navigation normally waits for.
+var internalSchemes = []string{"about:", "chrome:", "chrome-untrusted:", "chrome-error:", "devtools:"}
+
+// returns the url of the document once it has loaded, empty while it is loading.
+const internalReadyScript = `document.readyState === 'complete' ? location.href : ''`
+
+// IsInternalUrl returns true for urls of pages served by the browser itself, such as about:blank or
+// chrome://version.
+func IsInternalUrl(url string) bool {
+	lower := strings.ToLower(url)
+	for _, scheme := range internalSchemes {
+		if strings.HasPrefix(lower, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// does the loaded document's href belong to the navigated url, chrome adds a trailing slash to chrome:// urls.
+func isInternalPage(href, url string) bool {
+	return strings.HasPrefix(strings.ToLower(href), strings.TrimSuffix(strings.ToLower(url), "/"))
+}
+
+// waits for an internal page to load. The document update event is used if it arrives, otherwise the
+// document is polled until it has loaded and the element tree is refreshed. Returns the document's url.
+func (t *Tab) internalReadyWait(ctx context.Context, url, loaderId string) (string, error) {
+	var docUpdated, refreshed bool
+	timeoutTimer := time.NewTimer(t.navigationTimeout)
+	defer timeoutTimer.Stop()
+	pollTicker := time.NewTicker(50 * time.Millisecond)
+	defer pollTicker.Stop()
+
+	for {
+		select {
+		case <-t.navigationCh:
+		case <-t.docUpdateCh:
+			docUpdated = true
+		case <-pollTicker.C:
+		case <-t.crashedNotifyCh:
+			return "", t.CrashErr()
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-timeoutTimer.C:
+			return "", &TimeoutErr{Message: "waiting for internal page to load: " + url}
+		}
+
+		rro, err := t.evaluateScript(internalReadyScript, false)
+		if err != nil {
+			continue
+		}
+		href, _ := rro.Value.(string)
+		if href == "" || !isInternalPage(href, url) {
+			continue
+		}
+		if docUpdated && (loaderId == "" || refreshed || t.hasNavigatedLoader(loaderId)) {
+			return href, nil
+		}
+		if !refreshed {
+			// the document update event never arrived, refresh the elements so they belong to the new document
+			refreshed = true
+			t.dispatchNodeChange(&NodeChangeEvent{EventType: DocumentUpdatedEvent})
+		}
+	}
+}
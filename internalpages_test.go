@@ -0,0 +1,25 @@
+package autogcd
+
+import "testing"
+
+func TestIsInternalUrl(t *testing.T) {
+	for url, expected := range map[string]bool{
+		"about:blank":            true,
+		"chrome://version":       true,
+		"CHROME://settings":      true,
+		"http://localhost/":      false,
+		"data:text/html,<p>":     false,
+		"chrome-extension://abc": false,
+	} {
+		if IsInternalUrl(url) != expected {
+			t.Fatalf("expected IsInternalUrl(%s) to be %v\n", url, expected)
+		}
+	}
+
+	if !isInternalPage("chrome://version/", "chrome://version") || !isInternalPage("about:blank", "about:blank") {
+		t.Fatalf("expected loaded internal pages to match their urls\n")
+	}
+	if isInternalPage("http://localhost/", "about:blank") {
+		t.Fatalf("expected the previous document to not match\n")
+	}
+}
@@ -0,0 +1,160 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"sync"
+	"time"
+)
+
+// LatencyStats summarises the durations of Count calls.
+type LatencyStats struct {
+	Count int64         // number of calls measured
+	Total time.Duration // sum of the durations
+	Max   time.Duration // the longest call
+}
+
+// Average returns the mean duration of the calls, 0 if there were none.
+func (s LatencyStats) Average() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+func (s *LatencyStats) add(d time.Duration) {
+	s.Count++
+	s.Total += d
+	if d > s.Max {
+		s.Max = d
+	}
+}
+
+// InternalStats are measurements of the tab's event handling, returned by Tab.InternalStats. DOM events are
+// handled one at a time, a growing queue or long queue waits with short handling times mean the page is
+// producing events faster than the debugger connection delivers them, long handling times mean the library
+// (or a slow DOMChangeHandler) is the bottleneck.
+type InternalStats struct {
+	NodeChangeQueueDepth    int                     // DOM events waiting to be handled
+	NodeChangeQueueMaxDepth int                     // the most DOM events that have been waiting at once
+	NodeChangeWait          LatencyStats            // time DOM events waited to be handled
+	NodeChangeHandling      LatencyStats            // time spent updating the tab's elements for DOM events
+	DOMChangeHandler        LatencyStats            // time spent in the handler set by GetDOMChanges
+	Callbacks               map[string]LatencyStats // event method => time spent in its subscription callback
+}
+
+// collects InternalStats
+type statsRecorder struct {
+	lock          *sync.Mutex
+	queueDepth    int
+	maxQueueDepth int
+	wait          LatencyStats
+	handling      LatencyStats
+	domHandler    LatencyStats
+	callbacks     map[string]*LatencyStats
+}
+
+func newStatsRecorder() *statsRecorder {
+	return &statsRecorder{lock: &sync.Mutex{}, callbacks: make(map[string]*LatencyStats)}
+}
+
+// InternalStats returns measurements of how long the tab spends handling debugger events, for diagnosing
+// whether slow automation is caused by the library or the page. Measurements accumulate from when the tab
+// was opened, or ResetInternalStats was last called.
+func (t *Tab) InternalStats() *InternalStats {
+	return t.stats.snapshot()
+}
+
+// ResetInternalStats clears the measurements returned by InternalStats, the queue depth is kept.
+func (t *Tab) ResetInternalStats() {
+	t.stats.reset()
+}
+
+func (s *statsRecorder) snapshot() *InternalStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	stats := &InternalStats{
+		NodeChangeQueueDepth:    s.queueDepth,
+		NodeChangeQueueMaxDepth: s.maxQueueDepth,
+		NodeChangeWait:          s.wait,
+		NodeChangeHandling:      s.handling,
+		DOMChangeHandler:        s.domHandler,
+		Callbacks:               make(map[string]LatencyStats, len(s.callbacks)),
+	}
+	for method, callback := range s.callbacks {
+		stats.Callbacks[method] = *callback
+	}
+	return stats
+}
+
+func (s *statsRecorder) reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.maxQueueDepth = s.queueDepth
+	s.wait = LatencyStats{}
+	s.handling = LatencyStats{}
+	s.domHandler = LatencyStats{}
+	s.callbacks = make(map[string]*LatencyStats)
+}
+
+// a DOM event is waiting to be handled
+func (s *statsRecorder) queued() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.queueDepth++
+	if s.queueDepth > s.maxQueueDepth {
+		s.maxQueueDepth = s.queueDepth
+	}
+}
+
+// a DOM event was taken off the queue after waiting for wait, or abandoned on exit
+func (s *statsRecorder) dequeued(wait time.Duration, delivered bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.queueDepth--
+	if delivered {
+		s.wait.add(wait)
+	}
+}
+
+func (s *statsRecorder) nodeChangeHandled(handling, domHandler time.Duration, calledHandler bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.handling.add(handling)
+	if calledHandler {
+		s.domHandler.add(domHandler)
+	}
+}
+
+func (s *statsRecorder) callbackReturned(method string, d time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	callback, ok := s.callbacks[method]
+	if !ok {
+		callback = &LatencyStats{}
+		s.callbacks[method] = callback
+	}
+	callback.add(d)
+}
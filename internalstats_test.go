@@ -0,0 +1,44 @@
+package autogcd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsRecorder(t *testing.T) {
+	stats := newStatsRecorder()
+	stats.queued()
+	stats.queued()
+	stats.dequeued(10*time.Millisecond, true)
+	stats.nodeChangeHandled(2*time.Millisecond, 0, false)
+	stats.nodeChangeHandled(4*time.Millisecond, time.Millisecond, true)
+	stats.callbackReturned("DOM.setChildNodes", time.Millisecond)
+	stats.callbackReturned("DOM.setChildNodes", 3*time.Millisecond)
+
+	snapshot := stats.snapshot()
+	if snapshot.NodeChangeQueueDepth != 1 || snapshot.NodeChangeQueueMaxDepth != 2 {
+		t.Fatalf("unexpected queue depth %d max %d\n", snapshot.NodeChangeQueueDepth, snapshot.NodeChangeQueueMaxDepth)
+	}
+	if snapshot.NodeChangeWait.Count != 1 || snapshot.NodeChangeWait.Max != 10*time.Millisecond {
+		t.Fatalf("unexpected wait %#v\n", snapshot.NodeChangeWait)
+	}
+	if snapshot.NodeChangeHandling.Count != 2 || snapshot.NodeChangeHandling.Average() != 3*time.Millisecond {
+		t.Fatalf("unexpected handling %#v\n", snapshot.NodeChangeHandling)
+	}
+	if snapshot.DOMChangeHandler.Count != 1 {
+		t.Fatalf("unexpected dom change handler %#v\n", snapshot.DOMChangeHandler)
+	}
+	callback := snapshot.Callbacks["DOM.setChildNodes"]
+	if callback.Count != 2 || callback.Average() != 2*time.Millisecond || callback.Max != 3*time.Millisecond {
+		t.Fatalf("unexpected callback stats %#v\n", callback)
+	}
+
+	stats.reset()
+	snapshot = stats.snapshot()
+	if snapshot.NodeChangeQueueDepth != 1 || snapshot.NodeChangeQueueMaxDepth != 1 || snapshot.NodeChangeHandling.Count != 0 || len(snapshot.Callbacks) != 0 {
+		t.Fatalf("reset did not clear the measurements %#v\n", snapshot)
+	}
+	if (LatencyStats{}).Average() != 0 {
+		t.Fatalf("average of no calls should be 0")
+	}
+}
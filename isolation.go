@@ -0,0 +1,118 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+// NewIsolatedTab creates a tab in a new browser context, which like an incognito window shares no cookies,
+// storage or cache with other tabs. The context is disposed when the tab is closed with CloseTab, contexts
+// still open are disposed on Shutdown. Tabs the isolated tab opens, such as popups, belong to the same context.
+func (auto *AutoGcd) NewIsolatedTab(opts ...TabOption) (*Tab, error) {
+	controller, err := auto.controllerTab()
+	if err != nil {
+		return nil, err
+	}
+
+	contextId, err := controller.TargetApi.CreateBrowserContext()
+	if err != nil {
+		return nil, &InvalidTabErr{Message: "unable to create browser context: " + err.Error()}
+	}
+	targetId, err := controller.TargetApi.CreateTarget("about:blank", 0, 0, contextId, false)
+	if err != nil {
+		controller.TargetApi.DisposeBrowserContext(contextId)
+		return nil, &InvalidTabErr{Message: "unable to create tab in browser context: " + err.Error()}
+	}
+
+	auto.tabLock.Lock()
+	defer auto.tabLock.Unlock()
+	tab, err := auto.openTargetId(targetId, opts...)
+	if err != nil {
+		controller.TargetApi.DisposeBrowserContext(contextId)
+		return nil, err
+	}
+	tab.browserContextId = contextId
+	auto.tabs[targetId] = tab
+	auto.browserContexts[contextId] = struct{}{}
+	return tab, nil
+}
+
+// BrowserContexts returns the ids of the browser contexts created by NewIsolatedTab that have not been disposed.
+func (auto *AutoGcd) BrowserContexts() []string {
+	auto.tabLock.RLock()
+	defer auto.tabLock.RUnlock()
+	contextIds := make([]string, 0, len(auto.browserContexts))
+	for contextId := range auto.browserContexts {
+		contextIds = append(contextIds, contextId)
+	}
+	return contextIds
+}
+
+// DisposeBrowserContext closes the tabs of the browser context and deletes it along with its cookies, storage
+// and cache.
+func (auto *AutoGcd) DisposeBrowserContext(contextId string) error {
+	auto.tabLock.Lock()
+	_, known := auto.browserContexts[contextId]
+	delete(auto.browserContexts, contextId)
+	contextTabs := make([]*Tab, 0)
+	for id, tab := range auto.tabs {
+		if tab.browserContextId == contextId {
+			contextTabs = append(contextTabs, tab)
+			delete(auto.tabs, id)
+		}
+	}
+	auto.tabLock.Unlock()
+
+	if !known {
+		return &InvalidTabErr{Message: "unknown browser context " + contextId}
+	}
+
+	for _, tab := range contextTabs {
+		tab.close()
+		if err := tab.removeTempDir(); err != nil {
+			tab.debugf("error removing temp dir: %s\n", err)
+		}
+	}
+	return auto.disposeBrowserContext(contextId)
+}
+
+// deletes the browser context in chrome, which closes its pages.
+func (auto *AutoGcd) disposeBrowserContext(contextId string) error {
+	controller, err := auto.controllerTab()
+	if err != nil {
+		return err
+	}
+	_, err = controller.TargetApi.DisposeBrowserContext(contextId)
+	return err
+}
+
+// returns a tab in the default browser context to send browser wide Target commands through.
+func (auto *AutoGcd) controllerTab() (*Tab, error) {
+	auto.tabLock.RLock()
+	defer auto.tabLock.RUnlock()
+	for _, tab := range auto.tabs {
+		if tab.browserContextId == "" && tab.CrashErr() == nil {
+			return tab, nil
+		}
+	}
+	return nil, &InvalidTabErr{Message: "no tab in the default browser context to create browser contexts with"}
+}
@@ -0,0 +1,254 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// KeyModifier bit flags held down by SendKeyCombo, combine them with |
+type KeyModifier int
+
+// Modifier keys, the values match Input.dispatchKeyEvent's modifiers
+const (
+	ModifierNone  KeyModifier = 0
+	ModifierAlt   KeyModifier = 1
+	ModifierCtrl  KeyModifier = 2
+	ModifierMeta  KeyModifier = 4 // Command on macOS
+	ModifierShift KeyModifier = 8
+)
+
+// Key pressed by SendKeyCombo, one of the named keys below or a single printable character of a US
+// keyboard such as "a", "7" or "/".
+type Key string
+
+// Named keys for SendKeyCombo, the values are DOM KeyboardEvent.key values
+const (
+	KeyEnter      Key = "Enter"
+	KeyTab        Key = "Tab"
+	KeyBackspace  Key = "Backspace"
+	KeyEscape     Key = "Escape"
+	KeySpace      Key = " "
+	KeyDelete     Key = "Delete"
+	KeyInsert     Key = "Insert"
+	KeyHome       Key = "Home"
+	KeyEnd        Key = "End"
+	KeyPageUp     Key = "PageUp"
+	KeyPageDown   Key = "PageDown"
+	KeyArrowUp    Key = "ArrowUp"
+	KeyArrowDown  Key = "ArrowDown"
+	KeyArrowLeft  Key = "ArrowLeft"
+	KeyArrowRight Key = "ArrowRight"
+	KeyF1         Key = "F1"
+	KeyF2         Key = "F2"
+	KeyF3         Key = "F3"
+	KeyF4         Key = "F4"
+	KeyF5         Key = "F5"
+	KeyF6         Key = "F6"
+	KeyF7         Key = "F7"
+	KeyF8         Key = "F8"
+	KeyF9         Key = "F9"
+	KeyF10        Key = "F10"
+	KeyF11        Key = "F11"
+	KeyF12        Key = "F12"
+)
+
+// the physical key, virtual key code and text of a key on a US keyboard
+type keyDefinition struct {
+	code      string // DOM KeyboardEvent.code
+	keyCode   int    // windows virtual key code
+	text      string // text typed by the key, empty for keys which do not type
+	shiftText string // text typed with shift held, empty if the same as text
+}
+
+// modifier keys in the order they are pressed
+var modifierKeys = []struct {
+	modifier KeyModifier
+	key      string
+	code     string
+	keyCode  int
+}{
+	{ModifierCtrl, "Control", "ControlLeft", 17},
+	{ModifierShift, "Shift", "ShiftLeft", 16},
+	{ModifierAlt, "Alt", "AltLeft", 18},
+	{ModifierMeta, "Meta", "MetaLeft", 91},
+}
+
+var namedKeys = map[Key]keyDefinition{
+	KeyEnter:      {code: "Enter", keyCode: 13, text: "\r"},
+	KeyTab:        {code: "Tab", keyCode: 9},
+	KeyBackspace:  {code: "Backspace", keyCode: 8},
+	KeyEscape:     {code: "Escape", keyCode: 27},
+	KeySpace:      {code: "Space", keyCode: 32, text: " "},
+	KeyDelete:     {code: "Delete", keyCode: 46},
+	KeyInsert:     {code: "Insert", keyCode: 45},
+	KeyHome:       {code: "Home", keyCode: 36},
+	KeyEnd:        {code: "End", keyCode: 35},
+	KeyPageUp:     {code: "PageUp", keyCode: 33},
+	KeyPageDown:   {code: "PageDown", keyCode: 34},
+	KeyArrowLeft:  {code: "ArrowLeft", keyCode: 37},
+	KeyArrowUp:    {code: "ArrowUp", keyCode: 38},
+	KeyArrowRight: {code: "ArrowRight", keyCode: 39},
+	KeyArrowDown:  {code: "ArrowDown", keyCode: 40},
+}
+
+// punctuation of a US keyboard, code, virtual key code and shifted character
+var punctuationKeys = map[rune]keyDefinition{
+	'-':  {code: "Minus", keyCode: 189, shiftText: "_"},
+	'=':  {code: "Equal", keyCode: 187, shiftText: "+"},
+	'[':  {code: "BracketLeft", keyCode: 219, shiftText: "{"},
+	']':  {code: "BracketRight", keyCode: 221, shiftText: "}"},
+	'\\': {code: "Backslash", keyCode: 220, shiftText: "|"},
+	';':  {code: "Semicolon", keyCode: 186, shiftText: ":"},
+	'\'': {code: "Quote", keyCode: 222, shiftText: "\""},
+	',':  {code: "Comma", keyCode: 188, shiftText: "<"},
+	'.':  {code: "Period", keyCode: 190, shiftText: ">"},
+	'/':  {code: "Slash", keyCode: 191, shiftText: "?"},
+	'`':  {code: "Backquote", keyCode: 192, shiftText: "~"},
+}
+
+// characters typed by shift and the digits 0 to 9
+const shiftedDigits = ")!@#$%^&*("
+
+func init() {
+	for i := 1; i <= 12; i++ {
+		namedKeys[Key(fmt.Sprintf("F%d", i))] = keyDefinition{code: fmt.Sprintf("F%d", i), keyCode: 111 + i}
+	}
+}
+
+// returns the definition of key, an error if it is not a named key or printable US keyboard character.
+func lookupKey(key Key) (keyDefinition, error) {
+	if def, ok := namedKeys[key]; ok {
+		return def, nil
+	}
+
+	runes := []rune(string(key))
+	if len(runes) != 1 {
+		return keyDefinition{}, fmt.Errorf("unknown key %q", string(key))
+	}
+	r := runes[0]
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		upper := strings.ToUpper(string(r))
+		return keyDefinition{code: "Key" + upper, keyCode: int(upper[0]), text: string(r), shiftText: upper}, nil
+	case r >= '0' && r <= '9':
+		return keyDefinition{code: "Digit" + string(r), keyCode: int(r), text: string(r), shiftText: string(shiftedDigits[r-'0'])}, nil
+	}
+	if def, ok := punctuationKeys[r]; ok {
+		def.text = string(r)
+		return def, nil
+	}
+	return keyDefinition{}, fmt.Errorf("unknown key %q", string(key))
+}
+
+// the key events pressing modifiers then key and releasing them in reverse order. Keys only type text
+// when no modifier other than shift is held, so Ctrl+A selects rather than typing an a.
+func keyComboEvents(modifiers KeyModifier, key Key) ([]*gcdapi.InputDispatchKeyEventParams, error) {
+	def, err := lookupKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*gcdapi.InputDispatchKeyEventParams, 0)
+	held := ModifierNone
+	for _, modifier := range modifierKeys {
+		if modifiers&modifier.modifier == 0 {
+			continue
+		}
+		held |= modifier.modifier
+		events = append(events, &gcdapi.InputDispatchKeyEventParams{TheType: "rawKeyDown", Modifiers: int(held), Key: modifier.key, Code: modifier.code, WindowsVirtualKeyCode: modifier.keyCode, NativeVirtualKeyCode: modifier.keyCode, Location: 1})
+	}
+
+	text := def.text
+	if modifiers&ModifierShift != 0 && def.shiftText != "" {
+		text = def.shiftText
+	}
+	keyName := string(key)
+	if text != "" && text != "\r" {
+		keyName = text
+	}
+	if modifiers&^ModifierShift != 0 {
+		text = ""
+	}
+
+	down := &gcdapi.InputDispatchKeyEventParams{TheType: "rawKeyDown", Modifiers: int(modifiers), Key: keyName, Code: def.code, WindowsVirtualKeyCode: def.keyCode, NativeVirtualKeyCode: def.keyCode}
+	if text != "" {
+		down.TheType = "keyDown"
+		down.Text = text
+		down.UnmodifiedText = text
+	}
+	up := *down
+	up.TheType = "keyUp"
+	up.Text = ""
+	up.UnmodifiedText = ""
+	events = append(events, down, &up)
+
+	for i := len(modifierKeys) - 1; i >= 0; i-- {
+		modifier := modifierKeys[i]
+		if modifiers&modifier.modifier == 0 {
+			continue
+		}
+		held &^= modifier.modifier
+		events = append(events, &gcdapi.InputDispatchKeyEventParams{TheType: "keyUp", Modifiers: int(held), Key: modifier.key, Code: modifier.code, WindowsVirtualKeyCode: modifier.keyCode, NativeVirtualKeyCode: modifier.keyCode, Location: 1})
+	}
+	return events, nil
+}
+
+// describes a key combination such as Ctrl+Shift+Tab
+func keyComboString(modifiers KeyModifier, key Key) string {
+	parts := make([]string, 0, len(modifierKeys)+1)
+	for _, modifier := range modifierKeys {
+		if modifiers&modifier.modifier != 0 {
+			parts = append(parts, modifier.key)
+		}
+	}
+	if key == KeySpace {
+		return strings.Join(append(parts, "Space"), "+")
+	}
+	return strings.Join(append(parts, string(key)), "+")
+}
+
+// SendKeyCombo presses key while holding modifiers, for example SendKeyCombo(ModifierCtrl, "a") to select
+// all or SendKeyCombo(ModifierShift, KeyTab) to move focus backwards, sending the events with the key codes a
+// US keyboard would. Key is one of the named Key constants or a single printable character. Keys are sent
+// to whatever is focused, see Element.Focus. Runs through the tab's middleware as a SendKeyCombo action
+// with the Input describing the combination, such as "Control+a".
+func (t *Tab) SendKeyCombo(modifiers KeyModifier, key Key) error {
+	events, err := keyComboEvents(modifiers, key)
+	if err != nil {
+		return err
+	}
+	return t.runAction(&Action{Name: ActionSendKeyCombo, Tab: t, Input: keyComboString(modifiers, key)}, func(action *Action) error {
+		for _, event := range events {
+			if _, err := t.Input.DispatchKeyEventWithParams(event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
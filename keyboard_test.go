@@ -0,0 +1,88 @@
+package autogcd
+
+import (
+	"testing"
+)
+
+func TestLookupKey(t *testing.T) {
+	cases := []struct {
+		key     Key
+		code    string
+		keyCode int
+		text    string
+	}{
+		{"a", "KeyA", 65, "a"},
+		{"Z", "KeyZ", 90, "Z"},
+		{"7", "Digit7", 55, "7"},
+		{"/", "Slash", 191, "/"},
+		{KeyArrowDown, "ArrowDown", 40, ""},
+		{KeyEscape, "Escape", 27, ""},
+		{KeyF1, "F1", 112, ""},
+		{KeyF12, "F12", 123, ""},
+		{KeyEnter, "Enter", 13, "\r"},
+	}
+	for _, c := range cases {
+		def, err := lookupKey(c.key)
+		if err != nil {
+			t.Fatalf("error looking up %q: %s\n", c.key, err)
+		}
+		if def.code != c.code || def.keyCode != c.keyCode || def.text != c.text {
+			t.Fatalf("unexpected definition for %q: %#v\n", c.key, def)
+		}
+	}
+
+	for _, key := range []Key{"", "ab", "é", "F13"} {
+		if _, err := lookupKey(key); err == nil {
+			t.Fatalf("expected an error for %q\n", key)
+		}
+	}
+}
+
+func TestKeyComboEvents(t *testing.T) {
+	events, err := keyComboEvents(ModifierCtrl|ModifierShift, "a")
+	if err != nil {
+		t.Fatalf("error building events: %s\n", err)
+	}
+	expected := []struct {
+		theType   string
+		key       string
+		modifiers int
+	}{
+		{"rawKeyDown", "Control", 2},
+		{"rawKeyDown", "Shift", 10},
+		{"rawKeyDown", "A", 10},
+		{"keyUp", "A", 10},
+		{"keyUp", "Shift", 2},
+		{"keyUp", "Control", 0},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events got %d\n", len(expected), len(events))
+	}
+	for i, e := range expected {
+		if events[i].TheType != e.theType || events[i].Key != e.key || events[i].Modifiers != e.modifiers {
+			t.Fatalf("unexpected event %d: %#v\n", i, events[i])
+		}
+	}
+	if events[2].Text != "" || events[2].WindowsVirtualKeyCode != 65 {
+		t.Fatalf("ctrl combinations should not type text: %#v\n", events[2])
+	}
+
+	events, _ = keyComboEvents(ModifierShift, "1")
+	if len(events) != 4 || events[1].TheType != "keyDown" || events[1].Text != "!" || events[1].Key != "!" || events[1].Code != "Digit1" {
+		t.Fatalf("expected shift+1 to type ! got %#v\n", events[1])
+	}
+
+	events, _ = keyComboEvents(ModifierNone, KeyTab)
+	if len(events) != 2 || events[0].TheType != "rawKeyDown" || events[0].Key != "Tab" || events[0].WindowsVirtualKeyCode != 9 {
+		t.Fatalf("unexpected tab events %#v\n", events)
+	}
+}
+
+func TestKeyComboString(t *testing.T) {
+	if combo := keyComboString(ModifierShift|ModifierCtrl, KeyTab); combo != "Control+Shift+Tab" {
+		t.Fatalf("unexpected combo %s\n", combo)
+	}
+	if combo := keyComboString(ModifierNone, KeySpace); combo != "Space" {
+		t.Fatalf("unexpected combo %s\n", combo)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+)
+
+// Finds the form control labelled by text. label.control resolves both <label for=id> and controls nested
+// inside the label, aria-labelledby is checked for controls labelled by other elements. Exact matches
+// (ignoring case, whitespace and a trailing colon or asterisk) are preferred over labels containing text.
+const fieldByLabelScript = `(function(text) {
+	function normalize(value) {
+		return (value || '').replace(/\s+/g, ' ').trim().replace(/\s*[:*]+$/, '').toLowerCase();
+	}
+	var wanted = normalize(text);
+	var partial = null;
+	var labels = document.querySelectorAll('label');
+	for (var i = 0; i < labels.length; i++) {
+		var control = labels[i].control;
+		if (!control) {
+			continue;
+		}
+		var value = normalize(labels[i].textContent);
+		if (value === wanted) {
+			return control;
+		}
+		if (!partial && value.indexOf(wanted) !== -1) {
+			partial = control;
+		}
+	}
+	var labelled = document.querySelectorAll('[aria-labelledby]');
+	for (var j = 0; j < labelled.length; j++) {
+		var ids = labelled[j].getAttribute('aria-labelledby').split(/\s+/);
+		var label = ids.map(function(id) {
+			var node = document.getElementById(id);
+			return node ? node.textContent : '';
+		}).join(' ');
+		if (normalize(label) === wanted) {
+			return labelled[j];
+		}
+		if (!partial && normalize(label).indexOf(wanted) !== -1) {
+			partial = labelled[j];
+		}
+	}
+	return partial;
+})(%s)`
+
+// GetFieldByLabel returns the input, select or textarea in the top level document labelled by labelText,
+// following <label for="..."> references, controls wrapped in their label and aria-labelledby. Matching
+// ignores case, extra whitespace and trailing colons or asterisks, an exact match is preferred over a
+// label which only contains labelText.
+func (t *Tab) GetFieldByLabel(labelText string) (*Element, error) {
+	elements, err := t.evaluateElements(fmt.Sprintf(fieldByLabelScript, jsQuote(labelText)))
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) == 0 || elements[0] == nil {
+		return nil, &ElementNotFoundErr{Message: fmt.Sprintf("labelled %q", labelText)}
+	}
+	return elements[0], nil
+}
@@ -0,0 +1,231 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The attribute GetByTestId uses unless changed with SetTestIdAttribute or WithTestIdAttribute
+const DefaultTestIdAttribute = "data-testid"
+
+// LocatorFunc finds the elements matching query using a particular strategy.
+type LocatorFunc func(t *Tab, query string) ([]*Element, error)
+
+// LocatorRegistry maps strategy names to LocatorFuncs so the way elements are located can be standardized
+// across a codebase. Locators are written as "strategy=query", for example "testid=login-button",
+// "text=Sign in" or "role=button:Sign in". A locator without a registered strategy prefix is a CSS selector.
+// Tab.Locate, Tab.FillForm and Tab.ClickAndWait resolve their locators with the tab's registry.
+type LocatorRegistry struct {
+	lock     *sync.RWMutex
+	locators map[string]LocatorFunc
+}
+
+// DefaultLocators is used by tabs which have not been given their own registry, registering a strategy
+// here makes it available to every such tab.
+var DefaultLocators = NewLocatorRegistry()
+
+// NewLocatorRegistry returns a registry with the built in strategies:
+//
+//	css - CSS selector against the top level document
+//	xpath - XPath expression, see Tab.GetElementsBySearch
+//	text - innermost visible elements containing the text, see Tab.GetElementsContainingText
+//	label - the form field with the label, see Tab.GetFieldByLabel
+//	role - "role" or "role:accessible name", see Tab.GetAllByRole
+//	testid - elements with the tab's test id attribute, see Tab.GetByTestId
+func NewLocatorRegistry() *LocatorRegistry {
+	r := &LocatorRegistry{lock: &sync.RWMutex{}, locators: make(map[string]LocatorFunc)}
+	r.Register("css", func(t *Tab, query string) ([]*Element, error) {
+		return t.GetElementsBySelector(query)
+	})
+	r.Register("xpath", func(t *Tab, query string) ([]*Element, error) {
+		return t.GetElementsBySearch(query, false)
+	})
+	r.Register("text", func(t *Tab, query string) ([]*Element, error) {
+		return t.GetElementsContainingText(query)
+	})
+	r.Register("label", func(t *Tab, query string) ([]*Element, error) {
+		return singleLocated(t.GetFieldByLabel(query))
+	})
+	r.Register("role", func(t *Tab, query string) ([]*Element, error) {
+		role, name := query, ""
+		if idx := strings.Index(query, ":"); idx != -1 {
+			role, name = query[:idx], query[idx+1:]
+		}
+		return t.GetAllByRole(role, name)
+	})
+	r.Register("testid", func(t *Tab, query string) ([]*Element, error) {
+		return t.GetElementsBySelector(fmt.Sprintf("[%s=%s]", t.testIdAttribute, jsQuote(query)))
+	})
+	return r
+}
+
+// Register adds, or replaces, the strategy called name. Passing a nil fn removes it.
+func (r *LocatorRegistry) Register(name string, fn LocatorFunc) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if fn == nil {
+		delete(r.locators, name)
+		return
+	}
+	r.locators[name] = fn
+}
+
+// Strategies returns the sorted names of the registered strategies.
+func (r *LocatorRegistry) Strategies() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	names := make([]string, 0, len(r.locators))
+	for name := range r.locators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Locate returns every element matching locator in the tab.
+func (r *LocatorRegistry) Locate(t *Tab, locator string) ([]*Element, error) {
+	strategy, query := "css", locator
+	if idx := strings.Index(locator, "="); idx != -1 {
+		r.lock.RLock()
+		_, ok := r.locators[locator[:idx]]
+		r.lock.RUnlock()
+		if ok {
+			strategy, query = locator[:idx], locator[idx+1:]
+		}
+	}
+
+	r.lock.RLock()
+	fn, ok := r.locators[strategy]
+	r.lock.RUnlock()
+	if !ok {
+		return nil, &ElementNotFoundErr{Message: fmt.Sprintf("no locator strategy %s registered", strategy)}
+	}
+	return fn(t, query)
+}
+
+// converts the result of a single element lookup into a LocatorFunc result
+func singleLocated(ele *Element, err error) ([]*Element, error) {
+	if err != nil {
+		if _, ok := err.(*ElementNotFoundErr); ok {
+			return make([]*Element, 0), nil
+		}
+		return nil, err
+	}
+	return []*Element{ele}, nil
+}
+
+// SetTestIdAttribute changes the attribute GetByTestId and the testid locator match on, the default is data-testid.
+func (t *Tab) SetTestIdAttribute(name string) {
+	t.testIdAttribute = name
+}
+
+// SetLocatorRegistry sets the registry used to resolve locators for this tab, the default is DefaultLocators.
+func (t *Tab) SetLocatorRegistry(registry *LocatorRegistry) {
+	t.locators = registry
+}
+
+// GetByTestId returns the element whose test id attribute (data-testid unless changed with SetTestIdAttribute)
+// equals id, the convention for marking elements for automation independently of styling and structure.
+func (t *Tab) GetByTestId(id string) (*Element, error) {
+	return t.Locate("testid=" + id)
+}
+
+// Locate returns the first element matching locator, see LocatorRegistry for the locator syntax.
+func (t *Tab) Locate(locator string) (*Element, error) {
+	elements, err := t.LocateAll(locator)
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) == 0 || elements[0] == nil {
+		return nil, &ElementNotFoundErr{Message: locator}
+	}
+	return elements[0], nil
+}
+
+// LocateAll returns every element matching locator, see LocatorRegistry for the locator syntax.
+func (t *Tab) LocateAll(locator string) ([]*Element, error) {
+	return t.locators.Locate(t, locator)
+}
+
+// FillForm locates each field by its locator and replaces its value, selects have the option with the
+// value chosen and checkboxes or radios are checked for "true" or "on" and unchecked otherwise. Input
+// and change events are dispatched so page scripts see the new values.
+func (t *Tab) FillForm(fields map[string]string) error {
+	locators := make([]string, 0, len(fields))
+	for locator := range fields {
+		locators = append(locators, locator)
+	}
+	sort.Strings(locators)
+
+	for _, locator := range locators {
+		ele, err := t.Locate(locator)
+		if err != nil {
+			return err
+		}
+		if err := ele.WaitForReady(); err != nil {
+			return err
+		}
+		if _, err := ele.callFunction(fillFieldFunction, fields[locator]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sets the value of an input, select or textarea the same way user input would, firing input and change
+const fillFieldFunction = `function(value) {
+	var type = (this.type || '').toLowerCase();
+	this.focus();
+	if (type === 'checkbox' || type === 'radio') {
+		this.checked = value === 'true' || value === 'on';
+	} else if ('value' in this) {
+		this.value = value;
+	} else {
+		this.textContent = value;
+	}
+	this.dispatchEvent(new Event('input', {bubbles: true}));
+	this.dispatchEvent(new Event('change', {bubbles: true}));
+}`
+
+// ClickAndWait locates the element, clicks it and waits up to timeout for conditionFn to return true,
+// for example UrlContains after clicking a link.
+func (t *Tab) ClickAndWait(locator string, conditionFn ConditionalFunc, timeout time.Duration) error {
+	ele, err := t.Locate(locator)
+	if err != nil {
+		return err
+	}
+	if err := ele.WaitForReady(); err != nil {
+		return err
+	}
+	if err := ele.Click(); err != nil {
+		return err
+	}
+	return t.WaitFor(100*time.Millisecond, timeout, conditionFn)
+}
@@ -0,0 +1,39 @@
+package autogcd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLocatorRegistry(t *testing.T) {
+	r := NewLocatorRegistry()
+	var gotQuery string
+	r.Register("custom", func(t *Tab, query string) ([]*Element, error) {
+		gotQuery = query
+		return []*Element{}, nil
+	})
+
+	expected := []string{"css", "custom", "label", "role", "testid", "text", "xpath"}
+	if !reflect.DeepEqual(r.Strategies(), expected) {
+		t.Fatalf("expected strategies %v got %v\n", expected, r.Strategies())
+	}
+
+	if _, err := r.Locate(nil, "custom=a=b"); err != nil {
+		t.Fatalf("error locating: %s\n", err)
+	}
+	if gotQuery != "a=b" {
+		t.Fatalf("expected query a=b got %s\n", gotQuery)
+	}
+
+	r.Register("custom", nil)
+	r.Register("css", func(t *Tab, query string) ([]*Element, error) {
+		gotQuery = query
+		return []*Element{}, nil
+	})
+	if _, err := r.Locate(nil, "input[name=custom]"); err != nil {
+		t.Fatalf("error locating: %s\n", err)
+	}
+	if gotQuery != "input[name=custom]" {
+		t.Fatalf("expected unprefixed locator to be a css selector got %s\n", gotQuery)
+	}
+}
@@ -0,0 +1,173 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+// Finds the most likely login form. Returns [form, username, password, submit] where
+// any entry other than password may be null, or an empty array if no visible password
+// field exists. Usernames are scored by autocomplete, type, name/id and label text and
+// must precede the password field.
+const detectLoginFormScript = `(function() {
+	function visible(el) {
+		var rect = el.getBoundingClientRect();
+		var style = window.getComputedStyle(el);
+		return rect.width > 0 && rect.height > 0 && style.visibility !== 'hidden' && style.display !== 'none';
+	}
+	function labelText(el) {
+		var text = (el.getAttribute('aria-label') || '') + ' ' + (el.getAttribute('placeholder') || '');
+		if (el.labels) {
+			for (var i = 0; i < el.labels.length; i++) {
+				text += ' ' + el.labels[i].textContent;
+			}
+		}
+		return text;
+	}
+	var passwords = Array.prototype.filter.call(document.querySelectorAll('input[type=password]'), visible);
+	if (passwords.length === 0) {
+		return [];
+	}
+	// prefer a form with a single password field, registration forms usually have two.
+	var password = passwords[0];
+	for (var i = 0; i < passwords.length; i++) {
+		var form = passwords[i].form;
+		if (form && form.querySelectorAll('input[type=password]').length === 1) {
+			password = passwords[i];
+			break;
+		}
+	}
+	var scope = password.form || document;
+	var userPattern = /user|login|email|e-mail|account|ident/i;
+	var username = null;
+	var bestScore = 0;
+	var inputs = scope.querySelectorAll('input');
+	for (var i = 0; i < inputs.length && inputs[i] !== password; i++) {
+		var input = inputs[i];
+		var type = (input.getAttribute('type') || 'text').toLowerCase();
+		if (['text', 'email', 'tel'].indexOf(type) === -1 || !visible(input)) {
+			continue;
+		}
+		var score = 1;
+		var autocomplete = (input.getAttribute('autocomplete') || '').toLowerCase();
+		if (autocomplete === 'username' || autocomplete === 'email') {
+			score += 4;
+		}
+		if (type === 'email') {
+			score += 2;
+		}
+		if (userPattern.test((input.name || '') + ' ' + (input.id || ''))) {
+			score += 2;
+		}
+		if (userPattern.test(labelText(input))) {
+			score += 1;
+		}
+		// ties go to the field closest to the password
+		if (score >= bestScore) {
+			username = input;
+			bestScore = score;
+		}
+	}
+	var submit = scope.querySelector('button[type=submit], input[type=submit], input[type=image]');
+	if (!submit && password.form) {
+		submit = password.form.querySelector('button:not([type])');
+	}
+	if (!submit) {
+		var buttons = scope.querySelectorAll('button, input[type=button], [role=button], a');
+		for (var i = 0; i < buttons.length; i++) {
+			if (visible(buttons[i]) && /log ?in|sign ?in|submit|continue/i.test(buttons[i].textContent || buttons[i].value)) {
+				submit = buttons[i];
+				break;
+			}
+		}
+	}
+	return [password.form || null, username, password, submit || null];
+})()`
+
+// LoginForm contains the elements of a login form found by DetectLoginForm.
+type LoginForm struct {
+	Form     *Element // the containing form, nil if the fields are not inside a <form>
+	Username *Element // nil if no username field was found (e.g. multi step logins)
+	Password *Element // the password field
+	Submit   *Element // nil if no submit control was found
+}
+
+// DetectLoginForm uses heuristics (input types, autocomplete attributes, names and labels) to find
+// the username, password and submit elements of a login form in the top level document.
+// Returns ElementNotFoundErr if the page has no visible password field.
+func (t *Tab) DetectLoginForm() (*LoginForm, error) {
+	elements, err := t.evaluateElements(detectLoginFormScript)
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) != 4 || elements[2] == nil {
+		return nil, &ElementNotFoundErr{Message: "login form, no visible password field"}
+	}
+
+	form := &LoginForm{Form: elements[0], Username: elements[1], Password: elements[2], Submit: elements[3]}
+	for _, ele := range elements {
+		if ele == nil {
+			continue
+		}
+		if err := ele.WaitForReady(); err != nil {
+			return nil, err
+		}
+	}
+	return form, nil
+}
+
+// Login detects the login form, fills in the username and password and submits it by clicking the
+// submit control (or pressing enter in the password field if there is none). Login does not wait for
+// the resulting navigation, use WaitFor or WaitStable afterwards. If username is empty only the
+// password is filled in.
+func (t *Tab) Login(username, password string) error {
+	form, err := t.DetectLoginForm()
+	if err != nil {
+		return err
+	}
+
+	if username != "" {
+		if form.Username == nil {
+			return &ElementNotFoundErr{Message: "login form, no username field"}
+		}
+		if err := fillField(form.Username, username); err != nil {
+			return err
+		}
+	}
+
+	if err := fillField(form.Password, password); err != nil {
+		return err
+	}
+
+	if form.Submit == nil {
+		return form.Password.SendKeys("\n")
+	}
+	return form.Submit.Click()
+}
+
+// clears the field and types the value in to it.
+func fillField(ele *Element, value string) error {
+	if err := ele.Clear(); err != nil {
+		return err
+	}
+	return ele.SendKeys(value)
+}
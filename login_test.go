@@ -0,0 +1,71 @@
+package autogcd
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+func TestTabDetectLoginForm(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "login.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	form, err := tab.DetectLoginForm()
+	if err != nil {
+		t.Fatalf("error detecting login form: %s\n", err)
+	}
+
+	if form.Username == nil || form.Username.GetAttribute("id") != "user" {
+		t.Fatalf("expected username field to be #user got: %s\n", form.Username)
+	}
+
+	if form.Password.GetAttribute("id") != "pass" {
+		t.Fatalf("expected password field to be #pass got: %s\n", form.Password)
+	}
+
+	if form.Submit == nil || form.Submit.GetAttribute("id") != "submit" {
+		t.Fatalf("expected submit to be #submit got: %s\n", form.Submit)
+	}
+}
+
+func TestTabLogin(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	msgHandler := func(callerTab *Tab, message *gcdapi.ConsoleConsoleMessage) {
+		if strings.Contains(message.Text, "login ") {
+			if message.Text != "login user@localhost:secret" {
+				t.Errorf("expected credentials to be submitted got: %s\n", message.Text)
+			}
+			wg.Done()
+		}
+	}
+	tab.GetConsoleMessages(msgHandler)
+
+	if _, err := tab.Navigate(testServerAddr + "login.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	if err := tab.Login("user@localhost", "secret"); err != nil {
+		t.Fatalf("error logging in: %s\n", err)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,41 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+// MemoryPressureLevel for SimulateMemoryPressure
+type MemoryPressureLevel string
+
+const (
+	ModerateMemoryPressure MemoryPressureLevel = "moderate"
+	CriticalMemoryPressure MemoryPressureLevel = "critical"
+)
+
+// SimulateMemoryPressure sends a memory pressure notification to all of chrome's processes, causing
+// caches to be purged and pages listening for memory pressure to react. If the renderer runs out of
+// memory, Navigate will return and CrashErr will report a CrashedErr with OOM set.
+func (t *Tab) SimulateMemoryPressure(level MemoryPressureLevel) error {
+	_, err := t.Memory.SimulatePressureNotification(string(level))
+	return err
+}
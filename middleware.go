@@ -0,0 +1,82 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+// Names of the actions passed through middleware
+const (
+	ActionNavigate              = "Navigate"
+	ActionClick                 = "Click"
+	ActionSendKeys              = "SendKeys"
+	ActionSendKeyCombo          = "SendKeyCombo"
+	ActionEvaluateScript        = "EvaluateScript"
+	ActionEvaluatePromiseScript = "EvaluatePromiseScript"
+)
+
+// Action is an operation passed through a tab's middleware.
+type Action struct {
+	Name    string      // one of the Action constants
+	Tab     *Tab        // tab the action is performed on
+	Element *Element    // element clicked or typed into, nil for tab level actions
+	Input   string      // the url for Navigate, text for SendKeys, keys for SendKeyCombo or the script for evaluations
+	Result  interface{} // set once the action ran, a *NavigationResult for Navigate or *gcdapi.RuntimeRemoteObject for evaluations
+}
+
+// ActionFunc performs an action, middleware calls next to continue the chain.
+type ActionFunc func(action *Action) error
+
+// Middleware wraps an ActionFunc, for example to log every action:
+//
+//	tab.Use(func(next autogcd.ActionFunc) autogcd.ActionFunc {
+//		return func(action *autogcd.Action) error {
+//			start := time.Now()
+//			err := next(action)
+//			log.Printf("%s %q took %s: %v", action.Name, action.Input, time.Since(start), err)
+//			return err
+//		}
+//	})
+//
+// Middleware may call next more than once to retry, or not at all to skip the action.
+type Middleware func(next ActionFunc) ActionFunc
+
+// Use adds middleware wrapping Navigate, Element.Click, SendKeys (of both Tab and Element), EvaluateScript
+// and EvaluatePromiseScript. The first middleware added is the outermost. Scripts evaluated internally by
+// autogcd helpers do not pass through middleware.
+func (t *Tab) Use(middleware ...Middleware) {
+	t.middlewareLock.Lock()
+	defer t.middlewareLock.Unlock()
+	t.middleware = append(t.middleware, middleware...)
+}
+
+// runs actionFn wrapped by the tab's middleware.
+func (t *Tab) runAction(action *Action, actionFn ActionFunc) error {
+	t.middlewareLock.RLock()
+	chain := t.middleware
+	t.middlewareLock.RUnlock()
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		actionFn = chain[i](actionFn)
+	}
+	return actionFn(action)
+}
@@ -0,0 +1,65 @@
+package autogcd
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestTabMiddlewareChain(t *testing.T) {
+	tab := &Tab{middlewareLock: &sync.RWMutex{}}
+	order := make([]string, 0)
+	named := func(name string) Middleware {
+		return func(next ActionFunc) ActionFunc {
+			return func(action *Action) error {
+				order = append(order, name+" before")
+				err := next(action)
+				order = append(order, name+" after")
+				return err
+			}
+		}
+	}
+	tab.Use(named("outer"), named("inner"))
+
+	err := tab.runAction(&Action{Name: ActionClick}, func(action *Action) error {
+		order = append(order, "action")
+		action.Result = "done"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err)
+	}
+	expected := []string{"outer before", "inner before", "action", "inner after", "outer after"}
+	if len(order) != len(expected) {
+		t.Fatalf("expected %v got %v\n", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected %v got %v\n", expected, order)
+		}
+	}
+}
+
+func TestTabMiddlewareRetry(t *testing.T) {
+	tab := &Tab{middlewareLock: &sync.RWMutex{}}
+	tab.Use(func(next ActionFunc) ActionFunc {
+		return func(action *Action) error {
+			if err := next(action); err == nil {
+				return nil
+			}
+			return next(action)
+		}
+	})
+
+	attempts := 0
+	err := tab.runAction(&Action{Name: ActionNavigate}, func(action *Action) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("first attempt fails")
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Fatalf("expected middleware to retry once got %d attempts: %v\n", attempts, err)
+	}
+}
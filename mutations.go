@@ -0,0 +1,206 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// name of the binding the injected MutationObserver reports batches to
+const mutationBindingName = "__autogcdMutations"
+
+// maximum number of records reported in a single batch, the remainder are counted as dropped
+const mutationBatchLimit = 500
+
+// Observes the entire document and reports summaries of mutations to the binding, batched every interval ms.
+const observeMutationsScript = `(function(bindingName, interval, limit) {
+	if (window.__autogcdMutationObserver) {
+		return;
+	}
+	function describe(node) {
+		if (!node || node.nodeType !== 1) {
+			return node ? node.nodeName.toLowerCase() : '';
+		}
+		var parts = [];
+		while (node && node.nodeType === 1 && parts.length < 5) {
+			var part = node.nodeName.toLowerCase();
+			if (node.id) {
+				parts.unshift(part + '#' + node.id);
+				break;
+			}
+			if (node.classList && node.classList.length) {
+				part += '.' + Array.prototype.join.call(node.classList, '.');
+			}
+			parts.unshift(part);
+			node = node.parentNode;
+		}
+		return parts.join(' > ');
+	}
+	function tags(nodes) {
+		var names = [];
+		for (var i = 0; i < nodes.length && names.length < 10; i++) {
+			names.push(nodes[i].nodeName.toLowerCase());
+		}
+		return names;
+	}
+	var pending = [];
+	var dropped = 0;
+	var timer = null;
+	function flush() {
+		timer = null;
+		if (pending.length === 0 && dropped === 0) {
+			return;
+		}
+		var batch = {url: location.href, records: pending, dropped: dropped};
+		pending = [];
+		dropped = 0;
+		try {
+			window[bindingName](JSON.stringify(batch));
+		} catch (e) {
+		}
+	}
+	var observer = new MutationObserver(function(mutations) {
+		for (var i = 0; i < mutations.length; i++) {
+			if (pending.length >= limit) {
+				dropped += mutations.length - i;
+				break;
+			}
+			var m = mutations[i];
+			var record = {type: m.type, target: describe(m.target)};
+			if (m.type === 'childList') {
+				record.added = m.addedNodes.length;
+				record.removed = m.removedNodes.length;
+				record.addedTags = tags(m.addedNodes);
+			} else if (m.type === 'attributes') {
+				record.attribute = m.attributeName;
+				record.oldValue = m.oldValue;
+				record.value = m.target.getAttribute ? m.target.getAttribute(m.attributeName) : null;
+			} else {
+				record.oldValue = m.oldValue;
+				record.value = m.target.data;
+			}
+			pending.push(record);
+		}
+		if (!timer) {
+			timer = setTimeout(flush, interval);
+		}
+	});
+	observer.observe(document, {childList: true, subtree: true, attributes: true, attributeOldValue: true, characterData: true, characterDataOldValue: true});
+	window.__autogcdMutationObserver = observer;
+})(%s, %d, %d)`
+
+// Disconnects the injected MutationObserver.
+const disconnectMutationsScript = `(function() {
+	if (window.__autogcdMutationObserver) {
+		window.__autogcdMutationObserver.disconnect();
+		delete window.__autogcdMutationObserver;
+	}
+})()`
+
+// MutationRecord summarizes a single MutationObserver record.
+type MutationRecord struct {
+	Type          string   `json:"type"`      // childList, attributes or characterData
+	Target        string   `json:"target"`    // short css path of the mutated node
+	AddedNodes    int      `json:"added"`     // number of nodes added for childList mutations
+	RemovedNodes  int      `json:"removed"`   // number of nodes removed for childList mutations
+	AddedTags     []string `json:"addedTags"` // tag names of the first 10 added nodes
+	AttributeName string   `json:"attribute"` // changed attribute for attributes mutations
+	OldValue      *string  `json:"oldValue"`  // previous attribute value or text, nil if it did not exist
+	Value         *string  `json:"value"`     // current attribute value or text, nil if removed
+}
+
+// MutationBatch is a group of mutations reported together by ObserveMutations.
+type MutationBatch struct {
+	Url     string            `json:"url"`     // url of the document (or frame) the mutations occurred in
+	Records []*MutationRecord `json:"records"` // the mutations in the order they occurred
+	Dropped int               `json:"dropped"` // number of mutations not reported since the batch was full
+}
+
+// ObserveMutations injects a MutationObserver into the current document and every document loaded after, calling
+// handlerFn with batched summaries of mutations roughly every 100ms. This is an alternative to GetDOMChanges for
+// pages where the DOM domain's node events are too slow or incomplete, it does not depend on the tab having
+// requested the changed nodes. Calling it again replaces the handler.
+func (t *Tab) ObserveMutations(handlerFn MutationBatchFunc) error {
+	if err := t.addBinding(mutationBindingName, t.handleMutationBatch); err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf(observeMutationsScript, jsQuote(mutationBindingName), 100, mutationBatchLimit)
+
+	t.bindingLock.Lock()
+	t.mutationHandler = handlerFn
+	installed := t.mutationScriptId != ""
+	t.bindingLock.Unlock()
+
+	if !installed {
+		scriptId, err := t.Page.AddScriptToEvaluateOnNewDocument(script, "")
+		if err != nil {
+			return err
+		}
+		t.bindingLock.Lock()
+		t.mutationScriptId = scriptId
+		t.bindingLock.Unlock()
+	}
+
+	_, err := t.evaluateScript(script, false)
+	return err
+}
+
+// StopObservingMutations disconnects the observer in the current document and stops injecting it into new ones.
+func (t *Tab) StopObservingMutations() error {
+	t.bindingLock.Lock()
+	scriptId := t.mutationScriptId
+	t.mutationScriptId = ""
+	t.mutationHandler = nil
+	t.bindingLock.Unlock()
+
+	if scriptId == "" {
+		return nil
+	}
+
+	if _, err := t.Page.RemoveScriptToEvaluateOnNewDocument(scriptId); err != nil {
+		return err
+	}
+	_, err := t.evaluateScript(disconnectMutationsScript, false)
+	return err
+}
+
+// decodes batches reported by the mutation binding and passes them to the handler.
+func (t *Tab) handleMutationBatch(payload string) {
+	batch := &MutationBatch{}
+	if err := json.Unmarshal([]byte(payload), batch); err != nil {
+		t.debugf("invalid mutation payload: %s\n", err)
+		return
+	}
+
+	t.bindingLock.RLock()
+	handlerFn := t.mutationHandler
+	t.bindingLock.RUnlock()
+
+	if handlerFn != nil {
+		handlerFn(t, batch)
+	}
+}
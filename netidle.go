@@ -0,0 +1,59 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"time"
+)
+
+// number of requests allowed to remain in flight for the network to be considered idle, this
+// tolerates long polling and beacons which may never finish.
+const networkIdleMaxInflight = 2
+
+// WaitNetworkIdle enables the Network domain if required and waits until no more than two requests have
+// been in flight for idleFor, or returns a TimeoutErr after timeout. Requests sent before the Network domain
+// was enabled are not known about, so call it after Navigate or after GetNetworkTraffic.
+func (t *Tab) WaitNetworkIdle(idleFor, timeout time.Duration) error {
+	if err := t.enableNetwork(); err != nil {
+		return err
+	}
+
+	t.networkLock.Lock()
+	if t.lastNetworkActivity.IsZero() {
+		t.lastNetworkActivity = time.Now()
+	}
+	t.networkLock.Unlock()
+
+	rate := idleFor / 4
+	if rate < 10*time.Millisecond {
+		rate = 10 * time.Millisecond
+	}
+
+	return t.WaitFor(rate, timeout, func(tab *Tab) bool {
+		tab.networkLock.RLock()
+		defer tab.networkLock.RUnlock()
+		return len(tab.inflight) <= networkIdleMaxInflight && time.Now().Sub(tab.lastNetworkActivity) >= idleFor
+	})
+}
@@ -0,0 +1,190 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"time"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// maximum number of errors kept between navigations.
+const maxPageErrors = 500
+
+// PageException is an uncaught javascript exception.
+type PageException struct {
+	Text   string // the exception description, including the message
+	Url    string // script url where the exception was thrown, if known
+	Line   int    // line number in Url (1-based)
+	Column int    // column number in Url (1-based)
+	Stack  string // the call stack, one frame per line
+}
+
+// FailedRequest is a request which failed to load or returned an HTTP error status.
+type FailedRequest struct {
+	RequestId     string // internal chrome request id
+	Url           string // url of the request, if known
+	Type          string // resource type (Document, Script, XHR...)
+	Status        int    // HTTP status for error responses, 0 if the request failed to load
+	ErrorText     string // the status text or why loading failed (net::ERR_NAME_NOT_RESOLVED...)
+	BlockedReason string // why the browser blocked the request (csp, mixed-content...), if it did
+	Canceled      bool   // the request was canceled, these are not reported by CollectErrors
+}
+
+// SecurityIssue is a security related message logged by the browser, such as mixed content or a
+// content security policy violation.
+type SecurityIssue struct {
+	Level string // browser log level, warning or error
+	Text  string // the message
+	Url   string // url of the resource involved, if known
+}
+
+// PageErrors is the report returned by CollectErrors.
+type PageErrors struct {
+	ConsoleErrors  []*ConsoleEntry  // console.error and console.assert calls
+	Exceptions     []*PageException // uncaught exceptions and unhandled promise rejections
+	FailedRequests []*FailedRequest // requests which failed or returned a 4xx or 5xx status
+	SecurityIssues []*SecurityIssue // security messages from the browser log
+}
+
+// Count returns the total number of errors in the report.
+func (p *PageErrors) Count() int {
+	return len(p.ConsoleErrors) + len(p.Exceptions) + len(p.FailedRequests) + len(p.SecurityIssues)
+}
+
+// HasErrors returns true if any error was observed.
+func (p *PageErrors) HasErrors() bool {
+	return p.Count() > 0
+}
+
+// CollectErrors waits for window then returns the console errors, uncaught exceptions, failed requests and
+// security issues observed since the last Navigate (or ClearErrors). The first call enables the domains
+// required, errors raised before it are not known about, so call it once before navigating:
+//
+//	tab.CollectErrors(0)
+//	tab.Navigate(url)
+//	errors, err := tab.CollectErrors(time.Second)
+//	if errors.HasErrors() { ... }
+//
+// Pass a window of 0 to return what was observed so far without waiting.
+func (t *Tab) CollectErrors(window time.Duration) (*PageErrors, error) {
+	if err := t.enableErrorCollection(); err != nil {
+		return nil, err
+	}
+
+	if window > 0 {
+		timer := time.NewTimer(window)
+		select {
+		case <-timer.C:
+		case <-t.exitCh:
+			timer.Stop()
+		}
+	}
+
+	t.errorLock.Lock()
+	defer t.errorLock.Unlock()
+	return &PageErrors{
+		ConsoleErrors:  append([]*ConsoleEntry{}, t.pageErrors.ConsoleErrors...),
+		Exceptions:     append([]*PageException{}, t.pageErrors.Exceptions...),
+		FailedRequests: append([]*FailedRequest{}, t.pageErrors.FailedRequests...),
+		SecurityIssues: append([]*SecurityIssue{}, t.pageErrors.SecurityIssues...),
+	}, nil
+}
+
+// ClearErrors forgets the errors observed so far, Navigate calls it automatically.
+func (t *Tab) ClearErrors() {
+	t.errorLock.Lock()
+	t.pageErrors = &PageErrors{}
+	t.errorLock.Unlock()
+}
+
+// enables the Network, Runtime and Log domains so errors are reported.
+func (t *Tab) enableErrorCollection() error {
+	t.errorLock.Lock()
+	enabled := t.errorsEnabled
+	t.errorLock.Unlock()
+	if enabled {
+		return nil
+	}
+
+	if err := t.enableNetwork(); err != nil {
+		return err
+	}
+	if err := t.enableRuntime(); err != nil {
+		return err
+	}
+	if _, err := t.Log.Enable(); err != nil {
+		return err
+	}
+
+	t.errorLock.Lock()
+	t.errorsEnabled = true
+	t.errorLock.Unlock()
+	return nil
+}
+
+// calls addFn with the current errors if collection is enabled and the limit has not been reached.
+func (t *Tab) recordPageError(addFn func(errors *PageErrors)) {
+	t.errorLock.Lock()
+	defer t.errorLock.Unlock()
+	if !t.errorsEnabled || t.pageErrors.Count() >= maxPageErrors {
+		return
+	}
+	addFn(t.pageErrors)
+}
+
+// converts a Network.loadingFailed event, shared with child sessions.
+func parseLoadingFailed(message *gcdapi.NetworkLoadingFailedEvent) *FailedRequest {
+	p := message.Params
+	return &FailedRequest{RequestId: p.RequestId, Type: p.Type, ErrorText: p.ErrorText, BlockedReason: p.BlockedReason, Canceled: p.Canceled}
+}
+
+// called for every Runtime.exceptionThrown event.
+func (t *Tab) handleExceptionThrown(details *gcdapi.RuntimeExceptionDetails) {
+	exception := &PageException{Text: details.Text, Url: details.Url, Line: details.LineNumber + 1, Column: details.ColumnNumber + 1}
+	if details.Exception != nil && details.Exception.Description != "" {
+		exception.Text = details.Exception.Description
+	}
+	if details.StackTrace != nil {
+		for _, frame := range details.StackTrace.CallFrames {
+			exception.Stack += frame.FunctionName + " (" + frame.Url + ")\n"
+		}
+	}
+	t.recordPageError(func(errors *PageErrors) {
+		errors.Exceptions = append(errors.Exceptions, exception)
+	})
+}
+
+// called for every Log.entryAdded event, only security messages are kept since network and javascript
+// errors are reported by their own domains.
+func (t *Tab) handleLogEntry(entry *gcdapi.LogLogEntry) {
+	if entry.Source != "security" || (entry.Level != "warning" && entry.Level != "error") {
+		return
+	}
+	issue := &SecurityIssue{Level: entry.Level, Text: entry.Text, Url: entry.Url}
+	t.recordPageError(func(errors *PageErrors) {
+		errors.SecurityIssues = append(errors.SecurityIssues, issue)
+	})
+}
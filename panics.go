@@ -0,0 +1,80 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+)
+
+// CallbackPanicErr is reported to the tab's ErrorHandlerFunc when a callback panics.
+type CallbackPanicErr struct {
+	Message string      // what was being handled, such as the event name
+	Value   interface{} // the value passed to panic
+	Stack   []byte      // stack trace of the panicking go routine
+}
+
+func (e *CallbackPanicErr) Error() string {
+	return fmt.Sprintf("callback panic handling %s: %v", e.Message, e.Value)
+}
+
+// Unwrap returns ErrCallbackPanic so the error can be matched with errors.Is
+func (e *CallbackPanicErr) Unwrap() error {
+	return ErrCallbackPanic
+}
+
+// SetErrorHandler is called with errors that can not be returned to a caller, such as a panic in an event
+// callback. Callbacks that panic are recovered so the tab keeps processing events, by default the panic is
+// logged. Pass nil to restore the default.
+func (t *Tab) SetErrorHandler(handler ErrorHandlerFunc) {
+	t.errorLock.Lock()
+	defer t.errorLock.Unlock()
+	t.errorHandler = handler
+}
+
+// reports err to the error handler, or logs it.
+func (t *Tab) handleError(err error) {
+	t.errorLock.Lock()
+	handler := t.errorHandler
+	t.errorLock.Unlock()
+
+	if handler == nil {
+		if panicErr, ok := err.(*CallbackPanicErr); ok {
+			log.Printf("autogcd: %s\n%s", panicErr, panicErr.Stack)
+			return
+		}
+		log.Printf("autogcd: %s\n", err)
+		return
+	}
+	handler(t, err)
+}
+
+// recovers a panic in the calling go routine and reports it to the error handler, must be deferred directly.
+func (t *Tab) recoverCallback(name string) {
+	if r := recover(); r != nil {
+		t.handleError(&CallbackPanicErr{Message: name, Value: r, Stack: debug.Stack()})
+	}
+}
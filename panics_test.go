@@ -0,0 +1,36 @@
+package autogcd
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestTabRecoverCallback(t *testing.T) {
+	tab := &Tab{errorLock: &sync.Mutex{}}
+	var reported error
+	tab.SetErrorHandler(func(tab *Tab, err error) {
+		reported = err
+	})
+
+	func() {
+		defer tab.recoverCallback("Page.loadEventFired")
+		panic("boom")
+	}()
+
+	panicErr := &CallbackPanicErr{}
+	if !errors.As(reported, &panicErr) || !errors.Is(reported, ErrCallbackPanic) {
+		t.Fatalf("expected a CallbackPanicErr got %v\n", reported)
+	}
+	if panicErr.Message != "Page.loadEventFired" || panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+		t.Fatalf("unexpected panic error %#v\n", panicErr)
+	}
+
+	reported = nil
+	func() {
+		defer tab.recoverCallback("Page.loadEventFired")
+	}()
+	if reported != nil {
+		t.Fatalf("expected nothing to be reported without a panic got %v\n", reported)
+	}
+}
@@ -0,0 +1,164 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/base64"
+	"io"
+)
+
+// size of the chunks read from a PDF stream
+const pdfChunkSize = 1024 * 1024
+
+// PaperSize in inches
+type PaperSize struct {
+	Width  float64
+	Height float64
+}
+
+// Common paper sizes for PDFOptions.Paper
+var (
+	PaperLetter = PaperSize{Width: 8.5, Height: 11}
+	PaperLegal  = PaperSize{Width: 8.5, Height: 14}
+	PaperA3     = PaperSize{Width: 11.69, Height: 16.54}
+	PaperA4     = PaperSize{Width: 8.27, Height: 11.69}
+)
+
+// PDFMargins in inches
+type PDFMargins struct {
+	Top    float64
+	Bottom float64
+	Left   float64
+	Right  float64
+}
+
+// PDFOptions for PrintToPDF, the zero value prints every page on letter paper with 1cm margins and
+// no header or footer.
+type PDFOptions struct {
+	Paper             PaperSize   // the paper size, zero defaults to PaperLetter
+	Landscape         bool        // print in landscape orientation
+	Margins           *PDFMargins // page margins, nil defaults to 1cm on every side
+	Scale             float64     // scale of the page rendering between 0.1 and 2, 0 defaults to 1
+	PrintBackground   bool        // print background colours and images
+	PreferCSSPageSize bool        // use the page size defined by the page's @page css rule rather than Paper
+	PageRanges        string      // pages to print such as "1-5, 8, 11-13", empty prints every page
+	// HeaderTemplate and FooterTemplate are html printed on each page, elements with the classes date, title,
+	// url, pageNumber and totalPages have the values inserted into them, e.g. <span class=pageNumber></span>.
+	// The header and footer are only printed if either is set, an empty one is left blank.
+	HeaderTemplate string
+	FooterTemplate string
+}
+
+// Page.printToPDF parameters, sent directly as the vendored gcdapi omits zero margins and the transfer mode
+func (o *PDFOptions) params(transferMode string) map[string]interface{} {
+	params := map[string]interface{}{
+		"landscape":         o.Landscape,
+		"printBackground":   o.PrintBackground,
+		"preferCSSPageSize": o.PreferCSSPageSize,
+		"transferMode":      transferMode,
+	}
+	if o.Paper.Width > 0 && o.Paper.Height > 0 {
+		params["paperWidth"] = o.Paper.Width
+		params["paperHeight"] = o.Paper.Height
+	}
+	if o.Margins != nil {
+		params["marginTop"] = o.Margins.Top
+		params["marginBottom"] = o.Margins.Bottom
+		params["marginLeft"] = o.Margins.Left
+		params["marginRight"] = o.Margins.Right
+	}
+	if o.Scale != 0 {
+		params["scale"] = o.Scale
+	}
+	if o.PageRanges != "" {
+		params["pageRanges"] = o.PageRanges
+	}
+	if o.HeaderTemplate != "" || o.FooterTemplate != "" {
+		// chrome prints its own template in place of an empty one
+		blank := "<span></span>"
+		params["displayHeaderFooter"] = true
+		params["headerTemplate"] = blank
+		params["footerTemplate"] = blank
+		if o.HeaderTemplate != "" {
+			params["headerTemplate"] = o.HeaderTemplate
+		}
+		if o.FooterTemplate != "" {
+			params["footerTemplate"] = o.FooterTemplate
+		}
+	}
+	return params
+}
+
+// PrintToPDF prints the page as it would be printed with print media styles, returning the PDF. Pass nil
+// for the default options. Chrome only supports printing when running headless, see Settings.SetHeadless.
+func (t *Tab) PrintToPDF(opts *PDFOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &PDFOptions{}
+	}
+
+	var result struct {
+		Data string `json:"data"`
+	}
+	if err := sendCommand(t.targeter(), "Page.printToPDF", opts.params("ReturnAsBase64"), &result); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(result.Data)
+}
+
+// PrintToPDFWriter is PrintToPDF which streams the PDF from the browser to w in chunks, so large documents
+// are never held in memory in full.
+func (t *Tab) PrintToPDFWriter(w io.Writer, opts *PDFOptions) error {
+	if opts == nil {
+		opts = &PDFOptions{}
+	}
+
+	var result struct {
+		Stream string `json:"stream"`
+	}
+	if err := sendCommand(t.targeter(), "Page.printToPDF", opts.params("ReturnAsStream"), &result); err != nil {
+		return err
+	}
+	defer t.IO.Close(result.Stream)
+
+	// an offset of 0 is omitted so each read continues from the last
+	for {
+		base64Encoded, data, eof, err := t.IO.Read(result.Stream, 0, pdfChunkSize)
+		if err != nil {
+			return err
+		}
+		chunk := []byte(data)
+		if base64Encoded {
+			if chunk, err = base64.StdEncoding.DecodeString(data); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+		if eof {
+			return nil
+		}
+	}
+}
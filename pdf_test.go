@@ -0,0 +1,32 @@
+package autogcd
+
+import (
+	"testing"
+)
+
+func TestPDFOptionsParams(t *testing.T) {
+	params := (&PDFOptions{}).params("ReturnAsBase64")
+	for _, name := range []string{"paperWidth", "marginTop", "scale", "pageRanges", "displayHeaderFooter"} {
+		if _, ok := params[name]; ok {
+			t.Fatalf("default options should not set %s got %v\n", name, params)
+		}
+	}
+	if params["transferMode"] != "ReturnAsBase64" {
+		t.Fatalf("expected transfer mode to be set got %v\n", params)
+	}
+
+	opts := &PDFOptions{Paper: PaperA4, Margins: &PDFMargins{Top: 0.5}, HeaderTemplate: "<span class=title></span>", PageRanges: "1-2"}
+	params = opts.params("ReturnAsStream")
+	if params["paperWidth"] != PaperA4.Width || params["paperHeight"] != PaperA4.Height {
+		t.Fatalf("expected A4 paper got %v\n", params)
+	}
+	if params["marginTop"] != 0.5 || params["marginLeft"] != 0.0 {
+		t.Fatalf("expected zero margins to be sent got %v\n", params)
+	}
+	if params["displayHeaderFooter"] != true || params["headerTemplate"] != opts.HeaderTemplate || params["footerTemplate"] != "<span></span>" {
+		t.Fatalf("expected header with a blank footer got %v\n", params)
+	}
+	if params["pageRanges"] != "1-2" {
+		t.Fatalf("expected page ranges got %v\n", params)
+	}
+}
@@ -0,0 +1,198 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+/*
+Package pipeline standardizes the handling of data extracted from pages. Page processing
+code (for example a crawler.PageFunc) Emits typed Records which are delivered, in order,
+to one or more Sinks by a single goroutine. Emit blocks once the buffer is full, so a slow
+sink applies backpressure to the automation rather than growing memory without bound.
+*/
+package pipeline
+
+import (
+	"sync"
+	"time"
+)
+
+// ClosedErr returned when emitting to a closed Pipeline.
+type ClosedErr struct {
+}
+
+func (e *ClosedErr) Error() string {
+	return "pipeline is closed"
+}
+
+// TimeoutErr returned when EmitTimeout could not queue a record in time.
+type TimeoutErr struct {
+	Message string
+}
+
+func (e *TimeoutErr) Error() string {
+	return "Timed out " + e.Message
+}
+
+// Record is a single unit of extracted data.
+type Record struct {
+	Type string      `json:"type"` // user defined type of record, e.g. "product" or "link"
+	Url  string      `json:"url"`  // url of the page the record was extracted from
+	Time time.Time   `json:"time"` // when the record was emitted
+	Data interface{} `json:"data"` // the extracted data, must be serializable by the sinks used
+}
+
+// ErrorHandlerFunc is called when a sink fails to write a record.
+type ErrorHandlerFunc func(sink Sink, record *Record, err error)
+
+// Pipeline delivers emitted records to its sinks.
+type Pipeline struct {
+	lock         *sync.RWMutex   // protects closed while emitting
+	sending      *sync.WaitGroup // emitters which passed the closed check
+	records      chan *Record
+	sinks        []Sink
+	errorHandler ErrorHandlerFunc
+	closed       bool
+	closeCh      chan struct{} // closed by Close to release blocked emitters
+	doneCh       chan struct{}
+	firstErr     error
+}
+
+// New creates a pipeline buffering up to bufferSize records before Emit blocks, and
+// starts delivering to sinks.
+func New(bufferSize int, sinks ...Sink) *Pipeline {
+	p := &Pipeline{}
+	p.lock = &sync.RWMutex{}
+	p.sending = &sync.WaitGroup{}
+	p.records = make(chan *Record, bufferSize)
+	p.sinks = sinks
+	p.closeCh = make(chan struct{})
+	p.doneCh = make(chan struct{})
+	go p.deliver()
+	return p
+}
+
+// SetErrorHandler to be notified of sink errors, by default the first error is returned from Close.
+// Must be called before records are emitted.
+func (p *Pipeline) SetErrorHandler(handlerFn ErrorHandlerFunc) {
+	p.errorHandler = handlerFn
+}
+
+// Emit queues a record, blocking while the buffer is full. Returns ClosedErr if the
+// pipeline is closed while waiting.
+func (p *Pipeline) Emit(recordType, url string, data interface{}) error {
+	if err := p.startSend(); err != nil {
+		return err
+	}
+	defer p.sending.Done()
+
+	select {
+	case p.records <- &Record{Type: recordType, Url: url, Time: time.Now(), Data: data}:
+		return nil
+	case <-p.closeCh:
+		return &ClosedErr{}
+	}
+}
+
+// EmitTimeout works like Emit but gives up after timeout if the buffer stays full.
+func (p *Pipeline) EmitTimeout(recordType, url string, data interface{}, timeout time.Duration) error {
+	if err := p.startSend(); err != nil {
+		return err
+	}
+	defer p.sending.Done()
+
+	timeoutTimer := time.NewTimer(timeout)
+	defer timeoutTimer.Stop()
+
+	select {
+	case p.records <- &Record{Type: recordType, Url: url, Time: time.Now(), Data: data}:
+		return nil
+	case <-p.closeCh:
+		return &ClosedErr{}
+	case <-timeoutTimer.C:
+		return &TimeoutErr{Message: "waiting for pipeline buffer space"}
+	}
+}
+
+// registers an emitter so Close does not close the records channel underneath it.
+func (p *Pipeline) startSend() error {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.closed {
+		return &ClosedErr{}
+	}
+	p.sending.Add(1)
+	return nil
+}
+
+// Pending returns the number of records waiting to be delivered.
+func (p *Pipeline) Pending() int {
+	return len(p.records)
+}
+
+// Close stops accepting records, waits for pending records to be delivered and closes every sink.
+// Returns the first error from a sink if no error handler was set.
+func (p *Pipeline) Close() error {
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return &ClosedErr{}
+	}
+	p.closed = true
+	close(p.closeCh)
+	p.lock.Unlock()
+
+	// wait for emitters to queue their record or give up before closing the channel
+	p.sending.Wait()
+	close(p.records)
+	<-p.doneCh
+
+	for _, sink := range p.sinks {
+		if err := sink.Close(); err != nil {
+			p.handleErr(sink, nil, err)
+		}
+	}
+	return p.firstErr
+}
+
+// delivers records to every sink until the records channel is closed.
+func (p *Pipeline) deliver() {
+	defer close(p.doneCh)
+
+	for record := range p.records {
+		for _, sink := range p.sinks {
+			if err := sink.Write(record); err != nil {
+				p.handleErr(sink, record, err)
+			}
+		}
+	}
+}
+
+func (p *Pipeline) handleErr(sink Sink, record *Record, err error) {
+	if p.errorHandler != nil {
+		p.errorHandler(sink, record, err)
+		return
+	}
+	if p.firstErr == nil {
+		p.firstErr = err
+	}
+}
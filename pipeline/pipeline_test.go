@@ -0,0 +1,98 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPipelineJSONL(t *testing.T) {
+	buf := &bytes.Buffer{}
+	p := New(10, NewJSONLSink(buf))
+
+	for i := 0; i < 3; i++ {
+		if err := p.Emit("link", "http://localhost/", map[string]int{"index": i}); err != nil {
+			t.Fatalf("error emitting: %s\n", err)
+		}
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("error closing: %s\n", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines got %d\n", len(lines))
+	}
+	record := &Record{}
+	if err := json.Unmarshal([]byte(lines[2]), record); err != nil {
+		t.Fatalf("error decoding record: %s\n", err)
+	}
+	if record.Type != "link" || record.Data.(map[string]interface{})["index"].(float64) != 2 {
+		t.Fatalf("unexpected record %#v\n", record)
+	}
+
+	if err := p.Emit("link", "", nil); err == nil {
+		t.Fatalf("expected error emitting to a closed pipeline")
+	}
+}
+
+func TestPipelineBackpressure(t *testing.T) {
+	ch := make(chan *Record)
+	p := New(1, NewChannelSink(ch, true))
+
+	// one record is held by the sink, one in the buffer, the third must block
+	p.Emit("a", "", nil)
+	p.Emit("b", "", nil)
+	if err := p.EmitTimeout("c", "", nil, 50*time.Millisecond); err == nil {
+		t.Fatalf("expected timeout while the sink is blocked")
+	}
+
+	go p.Close()
+	count := 0
+	for range ch {
+		count++
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 records got %d\n", count)
+	}
+}
+
+func TestPipelineSinkError(t *testing.T) {
+	failing := SinkFunc(func(record *Record) error {
+		return errors.New("write failed")
+	})
+	p := New(1, failing)
+	p.Emit("a", "", nil)
+	if err := p.Close(); err == nil {
+		t.Fatalf("expected sink error to be returned from Close")
+	}
+}
+
+func TestPipelineCloseReleasesEmit(t *testing.T) {
+	ch := make(chan *Record)
+	p := New(1, NewChannelSink(ch, true))
+	p.Emit("a", "", nil)
+	p.Emit("b", "", nil)
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- p.Emit("c", "", nil)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	go p.Close()
+	select {
+	case err := <-errCh:
+		if _, ok := err.(*ClosedErr); !ok {
+			t.Fatalf("expected ClosedErr from a blocked Emit got %v\n", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Close did not release the blocked Emit")
+	}
+
+	for range ch {
+	}
+}
@@ -0,0 +1,116 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package pipeline
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// Sink receives records from a Pipeline. Write is only ever called from a single goroutine.
+type Sink interface {
+	Write(record *Record) error
+	Close() error
+}
+
+// SinkFunc adapts a function to the Sink interface, Close does nothing.
+type SinkFunc func(record *Record) error
+
+// Write calls the function.
+func (f SinkFunc) Write(record *Record) error {
+	return f(record)
+}
+
+// Close does nothing.
+func (f SinkFunc) Close() error {
+	return nil
+}
+
+// JSONLSink writes each record as a single line of JSON.
+type JSONLSink struct {
+	lock    *sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer // closed on Close if non-nil
+}
+
+// NewJSONLSink writes records to w. If w is an io.Closer it is not closed, use NewJSONLFileSink
+// or close it yourself after the Pipeline has been closed.
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	return &JSONLSink{lock: &sync.Mutex{}, encoder: json.NewEncoder(w)}
+}
+
+// NewJSONLFileSink appends records to the file at path, creating it if necessary.
+func NewJSONLFileSink(path string) (*JSONLSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	sink := NewJSONLSink(f)
+	sink.closer = f
+	return sink, nil
+}
+
+// Write encodes the record as a JSON line.
+func (s *JSONLSink) Write(record *Record) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.encoder.Encode(record)
+}
+
+// Close closes the underlying file if the sink was created with NewJSONLFileSink.
+func (s *JSONLSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// ChannelSink sends records to a channel. Since the pipeline waits for the channel to accept
+// each record, a slow reader applies backpressure all the way back to Emit.
+type ChannelSink struct {
+	ch          chan<- *Record
+	closeOnDone bool
+}
+
+// NewChannelSink sends records to ch, closing it when the pipeline closes if closeOnDone is true.
+func NewChannelSink(ch chan<- *Record, closeOnDone bool) *ChannelSink {
+	return &ChannelSink{ch: ch, closeOnDone: closeOnDone}
+}
+
+// Write sends the record on the channel.
+func (s *ChannelSink) Write(record *Record) error {
+	s.ch <- record
+	return nil
+}
+
+// Close closes the channel if requested.
+func (s *ChannelSink) Close() error {
+	if s.closeOnDone {
+		close(s.ch)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+
+	"github.com/wirepair/gcd"
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// OnPopup calls handler with a Tab for every page this tab opens with window.open or a target=_blank link.
+// The popup is attached and its events subscribed before handler is called, and it is added to the
+// AutoGcd's tabs so it is closed on Shutdown. Pass nil to stop listening. Connection errors are reported to
+// the ErrorHandlerFunc. After the tab re-attaches, call it again from the OnReattach handler to listen on the
+// new connection.
+func (t *Tab) OnPopup(handler PopupFunc) error {
+	if t.attachTab == nil {
+		return &InvalidTabErr{Message: "tab was not opened by AutoGcd, unable to attach popups"}
+	}
+
+	t.popupLock.Lock()
+	defer t.popupLock.Unlock()
+	if handler == nil {
+		if t.popupHandler != nil {
+			t.Unsubscribe("Target.targetCreated")
+		}
+		t.popupHandler = nil
+		t.popupTarget = nil
+		return nil
+	}
+
+	// subscriptions belong to the connection, subscribe again after re-attaching
+	if connection := t.target(); t.popupTarget != connection {
+		t.popupTarget = connection
+		t.Subscribe("Target.targetCreated", func(target *gcd.ChromeTarget, payload []byte) {
+			message := &gcdapi.TargetTargetCreatedEvent{}
+			if err := json.Unmarshal(payload, message); err == nil && message.Params.TargetInfo != nil {
+				t.handleTargetCreated(message.Params.TargetInfo)
+			}
+		})
+	}
+	t.popupHandler = handler
+	return nil
+}
+
+// attaches to pages opened by this tab and passes them to the popup handler.
+func (t *Tab) handleTargetCreated(info *gcdapi.TargetTargetInfo) {
+	if info.Type != "page" || info.OpenerId != t.Target.Id {
+		return
+	}
+
+	t.popupLock.RLock()
+	handler := t.popupHandler
+	t.popupLock.RUnlock()
+	if handler == nil {
+		return
+	}
+
+	popup, err := t.attachTab(info.TargetId)
+	if err != nil {
+		t.handleError(&InvalidTabErr{Message: "unable to attach popup " + info.TargetId + ": " + err.Error()})
+		return
+	}
+	handler(popup)
+}
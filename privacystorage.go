@@ -0,0 +1,125 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+// storage types cleared by ClearPrivacySandboxData
+const privacySandboxStorageTypes = "interest_groups,shared_storage,storage_buckets"
+
+// TrustTokens is the number of trust tokens the browser holds from an issuer.
+type TrustTokens struct {
+	IssuerOrigin string `json:"issuerOrigin"` // origin of the token issuer
+	Count        int    `json:"count"`        // number of tokens held
+}
+
+// SharedStorageMetadata describes an origin's shared storage database.
+type SharedStorageMetadata struct {
+	CreationTime    float64 `json:"creationTime"`    // when the database was created, seconds since the epoch
+	Length          int     `json:"length"`          // number of entries
+	RemainingBudget float64 `json:"remainingBudget"` // remaining privacy budget in bits
+	BytesUsed       int     `json:"bytesUsed"`       // bytes stored
+}
+
+// GetTrustTokens returns the trust tokens held for every issuer, from Storage.getTrustTokens.
+func (t *Tab) GetTrustTokens() ([]*TrustTokens, error) {
+	var result struct {
+		Tokens []*TrustTokens `json:"tokens"`
+	}
+	if err := sendCommand(t.targeter(), "Storage.getTrustTokens", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Tokens, nil
+}
+
+// ClearTrustTokens removes the trust tokens issued by issuerOrigin, returning true if any were deleted.
+func (t *Tab) ClearTrustTokens(issuerOrigin string) (bool, error) {
+	var result struct {
+		DidDeleteTokens bool `json:"didDeleteTokens"`
+	}
+	params := map[string]interface{}{"issuerOrigin": issuerOrigin}
+	if err := sendCommand(t.targeter(), "Storage.clearTrustTokens", params, &result); err != nil {
+		return false, err
+	}
+	return result.DidDeleteTokens, nil
+}
+
+// GetInterestGroup returns the details of the interest group name joined by ownerOrigin, as reported by
+// Storage.getInterestGroupDetails.
+func (t *Tab) GetInterestGroup(ownerOrigin, name string) (map[string]interface{}, error) {
+	var result struct {
+		Details map[string]interface{} `json:"details"`
+	}
+	params := map[string]interface{}{"ownerOrigin": ownerOrigin, "name": name}
+	if err := sendCommand(t.targeter(), "Storage.getInterestGroupDetails", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Details, nil
+}
+
+// GetSharedStorage returns the key/value entries in ownerOrigin's shared storage.
+func (t *Tab) GetSharedStorage(ownerOrigin string) (map[string]string, error) {
+	var result struct {
+		Entries []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"entries"`
+	}
+	params := map[string]interface{}{"ownerOrigin": ownerOrigin}
+	if err := sendCommand(t.targeter(), "Storage.getSharedStorageEntries", params, &result); err != nil {
+		return nil, err
+	}
+	entries := make(map[string]string, len(result.Entries))
+	for _, entry := range result.Entries {
+		entries[entry.Key] = entry.Value
+	}
+	return entries, nil
+}
+
+// GetSharedStorageMetadata returns the size and remaining budget of ownerOrigin's shared storage.
+func (t *Tab) GetSharedStorageMetadata(ownerOrigin string) (*SharedStorageMetadata, error) {
+	var result struct {
+		Metadata *SharedStorageMetadata `json:"metadata"`
+	}
+	params := map[string]interface{}{"ownerOrigin": ownerOrigin}
+	if err := sendCommand(t.targeter(), "Storage.getSharedStorageMetadata", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Metadata, nil
+}
+
+// ClearSharedStorage removes every entry in ownerOrigin's shared storage.
+func (t *Tab) ClearSharedStorage(ownerOrigin string) error {
+	params := map[string]interface{}{"ownerOrigin": ownerOrigin}
+	return sendCommand(t.targeter(), "Storage.clearSharedStorageEntries", params, nil)
+}
+
+// ClearPrivacySandboxData clears the trust tokens issued by origin and the interest groups, shared storage
+// and storage buckets it owns, so privacy feature tests can start from a clean slate.
+func (t *Tab) ClearPrivacySandboxData(origin string) error {
+	if _, err := t.ClearTrustTokens(origin); err != nil {
+		return err
+	}
+	_, err := t.storage().ClearDataForOrigin(origin, privacySandboxStorageTypes)
+	return err
+}
@@ -0,0 +1,242 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Files chrome uses to make sure only one browser process uses a profile, stale copies prevent chrome from
+// starting with the profile.
+var profileLockFiles = []string{"SingletonLock", "SingletonSocket", "SingletonCookie", "lockfile"}
+
+// Directories which are not needed to reuse a profile and are skipped when copying it.
+var profileSkipDirs = map[string]struct{}{
+	"Cache":         {},
+	"Code Cache":    {},
+	"GPUCache":      {},
+	"ShaderCache":   {},
+	"GrShaderCache": {},
+	"Crashpad":      {},
+}
+
+// ProfileInUseErr returned from Start when the profile passed to UseProfile is locked by a running browser.
+type ProfileInUseErr struct {
+	Message string
+}
+
+func (e *ProfileInUseErr) Error() string {
+	return "profile in use: " + e.Message
+}
+
+// Unwrap returns ErrProfileInUse so the error can be matched with errors.Is
+func (e *ProfileInUseErr) Unwrap() error {
+	return ErrProfileInUse
+}
+
+// returns the user data dir to start chrome with and whether it is a temporary copy to be removed on shutdown.
+func (s *Settings) prepareUserDir() (string, bool, error) {
+	if s.profilePath == "" {
+		return s.userDir, false, nil
+	}
+
+	if !s.profileCopy {
+		if err := removeStaleProfileLocks(s.profilePath); err != nil {
+			return "", false, err
+		}
+		return s.profilePath, false, nil
+	}
+
+	if err := checkProfileLock(s.profilePath); err != nil {
+		return "", false, err
+	}
+	dir, err := ioutil.TempDir("", "autogcd-profile")
+	if err != nil {
+		return "", false, err
+	}
+	if err := copyProfile(s.profilePath, dir); err != nil {
+		os.RemoveAll(dir)
+		return "", false, err
+	}
+	return dir, true, nil
+}
+
+// merges prefs into the Default/Preferences file of the profile in userDir, creating it if required.
+func writePreferences(userDir string, prefs map[string]interface{}) error {
+	path := filepath.Join(userDir, "Default", "Preferences")
+	existing := make(map[string]interface{})
+	if data, err := ioutil.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &existing); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	for key, value := range prefs {
+		setPreference(existing, strings.Split(key, "."), value)
+	}
+
+	encoded, err := json.Marshal(existing)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, encoded, 0600)
+}
+
+// sets value at the dotted path in prefs, nested maps are merged rather than replaced.
+func setPreference(prefs map[string]interface{}, path []string, value interface{}) {
+	for _, name := range path[:len(path)-1] {
+		child, ok := prefs[name].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			prefs[name] = child
+		}
+		prefs = child
+	}
+
+	name := path[len(path)-1]
+	if values, ok := value.(map[string]interface{}); ok {
+		for key, child := range values {
+			setPreference(prefs, []string{name, key}, child)
+		}
+		return
+	}
+	prefs[name] = value
+}
+
+// copies the profile at src to dst, skipping lock files and caches.
+func copyProfile(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if _, skip := profileSkipDirs[name]; skip {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dst, rel), 0700)
+		}
+		if isProfileLockFile(name) || !info.Mode().IsRegular() {
+			return nil
+		}
+		return copyFile(path, filepath.Join(dst, rel), info.Mode())
+	})
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func isProfileLockFile(name string) bool {
+	for _, lock := range profileLockFiles {
+		if name == lock {
+			return true
+		}
+	}
+	return false
+}
+
+// returns a ProfileInUseErr unless the profile's SingletonLock is missing or is stale, that is it refers
+// to a process on this host which is no longer running. A lock held by another host (such as a profile on
+// a shared home directory) can not be checked and is treated as in use.
+func checkProfileLock(dir string) error {
+	target, err := os.Readlink(filepath.Join(dir, "SingletonLock"))
+	if err != nil {
+		return nil // no lock, or not a symlink (windows), chrome will refuse the profile itself if it is in use
+	}
+
+	// the lock links to hostname-pid
+	sep := strings.LastIndex(target, "-")
+	if sep == -1 {
+		return &ProfileInUseErr{Message: dir + " has an unrecognized lock " + target}
+	}
+	hostname, err := os.Hostname()
+	if err != nil || target[:sep] != hostname {
+		return &ProfileInUseErr{Message: dir + " is locked by host " + target[:sep]}
+	}
+	pid, err := strconv.Atoi(target[sep+1:])
+	if err != nil {
+		return &ProfileInUseErr{Message: dir + " has an unrecognized lock " + target}
+	}
+	if processRunning(pid) {
+		return &ProfileInUseErr{Message: dir + " is locked by process " + strconv.Itoa(pid)}
+	}
+	return nil
+}
+
+// removes lock files from dir unless checkProfileLock reports the profile is in use.
+func removeStaleProfileLocks(dir string) error {
+	if err := checkProfileLock(dir); err != nil {
+		return err
+	}
+	for _, lock := range profileLockFiles {
+		if err := os.Remove(filepath.Join(dir, lock)); err != nil && !os.IsNotExist(err) {
+			return &ProfileInUseErr{Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+// returns true if pid is a running process, always false where signals are not supported.
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
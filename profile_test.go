@@ -0,0 +1,148 @@
+package autogcd
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func testProfileDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "autogcd-profile-test")
+	if err != nil {
+		t.Fatalf("error creating profile dir: %s\n", err)
+	}
+	files := map[string]string{
+		"Local State":             "{}",
+		"Default/Preferences":     "{\"profile\":{}}",
+		"Default/Cookies":         "cookies",
+		"Default/Cache/data_0":    "cached",
+		"Default/Code Cache/js/a": "cached",
+		"SingletonCookie":         "1234",
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("error writing %s: %s\n", name, err)
+		}
+	}
+	return dir
+}
+
+func TestSettingsUseProfileCopy(t *testing.T) {
+	profile := testProfileDir(t)
+	defer os.RemoveAll(profile)
+
+	s := NewSettings("", "/unused")
+	s.UseProfile(profile, true)
+	dir, temp, err := s.prepareUserDir()
+	if err != nil {
+		t.Fatalf("error preparing profile: %s\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if !temp || dir == profile {
+		t.Fatalf("expected a temporary copy got %s\n", dir)
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(dir, "Default", "Cookies")); err != nil || string(data) != "cookies" {
+		t.Fatalf("expected cookies to be copied: %s %v\n", data, err)
+	}
+	for _, skipped := range []string{"Default/Cache", "Default/Code Cache", "SingletonCookie"} {
+		if _, err := os.Stat(filepath.Join(dir, skipped)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be skipped\n", skipped)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(profile, "SingletonCookie")); err != nil {
+		t.Fatalf("original profile should not be modified: %s\n", err)
+	}
+}
+
+func TestSettingsUseProfileLocks(t *testing.T) {
+	profile := testProfileDir(t)
+	defer os.RemoveAll(profile)
+	hostname, _ := os.Hostname()
+
+	s := NewSettings("", "/unused")
+	s.UseProfile(profile, false)
+
+	// a lock held by this (running) process
+	lock := filepath.Join(profile, "SingletonLock")
+	if err := os.Symlink(hostname+"-"+strconv.Itoa(os.Getpid()), lock); err != nil {
+		t.Skipf("symlinks not supported: %s\n", err)
+	}
+	if _, _, err := s.prepareUserDir(); !errors.Is(err, ErrProfileInUse) {
+		t.Fatalf("expected profile in use error got %v\n", err)
+	}
+
+	// a lock held by another host can not be checked
+	os.Remove(lock)
+	os.Symlink("otherhost."+hostname+"-999999999", lock)
+	if _, _, err := s.prepareUserDir(); !errors.Is(err, ErrProfileInUse) {
+		t.Fatalf("expected profile in use error for another host got %v\n", err)
+	}
+	if _, err := os.Lstat(lock); err != nil {
+		t.Fatalf("expected another host's lock to be kept: %s\n", err)
+	}
+
+	// a stale lock from a process which no longer exists
+	os.Remove(lock)
+	os.Symlink(hostname+"-999999999", lock)
+	dir, temp, err := s.prepareUserDir()
+	if err != nil {
+		t.Fatalf("error preparing profile: %s\n", err)
+	}
+	if dir != profile || temp {
+		t.Fatalf("expected the profile to be used directly got %s %v\n", dir, temp)
+	}
+	for _, name := range []string{"SingletonLock", "SingletonCookie"} {
+		if _, err := os.Lstat(filepath.Join(profile, name)); !os.IsNotExist(err) {
+			t.Fatalf("expected stale %s to be removed\n", name)
+		}
+	}
+}
+
+func TestWritePreferences(t *testing.T) {
+	profile := testProfileDir(t)
+	defer os.RemoveAll(profile)
+
+	prefs := map[string]interface{}{
+		"credentials_enable_service":   false,
+		"download.default_directory":   "/tmp/downloads",
+		"download.prompt_for_download": false,
+		"profile":                      map[string]interface{}{"exit_type": "Normal"},
+	}
+	if err := writePreferences(profile, prefs); err != nil {
+		t.Fatalf("error writing preferences: %s\n", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(profile, "Default", "Preferences"))
+	if err != nil {
+		t.Fatalf("error reading preferences: %s\n", err)
+	}
+	written := make(map[string]interface{})
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("error decoding preferences: %s\n", err)
+	}
+
+	download := written["download"].(map[string]interface{})
+	if download["default_directory"] != "/tmp/downloads" || download["prompt_for_download"] != false {
+		t.Fatalf("expected dotted keys to be nested %#v\n", written)
+	}
+	if written["credentials_enable_service"] != false {
+		t.Fatalf("expected top level preference %#v\n", written)
+	}
+	if written["profile"].(map[string]interface{})["exit_type"] != "Normal" {
+		t.Fatalf("expected nested map to be merged into the existing profile key %#v\n", written)
+	}
+
+	// a new profile without a Preferences file
+	empty, _ := ioutil.TempDir("", "autogcd-empty-profile")
+	defer os.RemoveAll(empty)
+	if err := writePreferences(empty, prefs); err != nil {
+		t.Fatalf("error writing preferences to empty profile: %s\n", err)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// RateLimiter enforces a polite navigation policy. It limits the number of requests per
+// second made to any single host, as well as the total number of navigations that may be
+// in flight at once. A single RateLimiter can be shared between tabs (see Settings.SetRateLimiter)
+// to make the policy global.
+type RateLimiter struct {
+	lock        *sync.Mutex          // protects nextRequest
+	interval    time.Duration        // minimum amount of time between requests to the same host
+	nextRequest map[string]time.Time // host => the earliest time the next request may go out
+	slots       chan struct{}        // semaphore for concurrent navigations, nil if unlimited
+}
+
+// NewRateLimiter creates a limiter allowing perHostPerSecond requests to each host, and at most
+// maxConcurrent navigations at once. Pass 0 for either value to disable that limit.
+func NewRateLimiter(perHostPerSecond float64, maxConcurrent int) *RateLimiter {
+	r := &RateLimiter{}
+	r.lock = &sync.Mutex{}
+	r.nextRequest = make(map[string]time.Time)
+	if perHostPerSecond > 0 {
+		r.interval = time.Duration(float64(time.Second) / perHostPerSecond)
+	}
+	if maxConcurrent > 0 {
+		r.slots = make(chan struct{}, maxConcurrent)
+	}
+	return r
+}
+
+// Acquire blocks until a request to rawurl is allowed by the policy, or returns a TimeoutErr if
+// that would take longer than timeout. Every successful Acquire must be followed by a call to Release.
+func (r *RateLimiter) Acquire(rawurl string, timeout time.Duration) error {
+	return r.AcquireCtx(context.Background(), rawurl, timeout)
+}
+
+// AcquireCtx is Acquire which gives up and returns ctx.Err() when ctx is done.
+func (r *RateLimiter) AcquireCtx(ctx context.Context, rawurl string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	if r.slots != nil {
+		timeoutTimer := time.NewTimer(timeout)
+		defer timeoutTimer.Stop()
+
+		select {
+		case r.slots <- struct{}{}:
+		case <-timeoutTimer.C:
+			return &TimeoutErr{Message: "waiting for a free navigation slot for: " + rawurl}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	wait, ok := r.reserve(limiterHost(rawurl), deadline)
+	if !ok {
+		r.Release()
+		return &TimeoutErr{Message: "waiting for rate limit of host for: " + rawurl}
+	}
+
+	waitTimer := time.NewTimer(wait)
+	defer waitTimer.Stop()
+	select {
+	case <-waitTimer.C:
+		return nil
+	case <-ctx.Done():
+		r.Release()
+		return ctx.Err()
+	}
+}
+
+// Release frees the navigation slot taken by Acquire.
+func (r *RateLimiter) Release() {
+	if r.slots == nil {
+		return
+	}
+	select {
+	case <-r.slots:
+	default:
+	}
+}
+
+// reserves the next available time slot for host, returning how long the caller must wait.
+// Returns false without reserving if the slot falls after the deadline.
+func (r *RateLimiter) reserve(host string, deadline time.Time) (time.Duration, bool) {
+	if r.interval == 0 {
+		return 0, true
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	next := now
+	if scheduled, ok := r.nextRequest[host]; ok && scheduled.After(now) {
+		next = scheduled
+	}
+
+	if next.After(deadline) {
+		return 0, false
+	}
+	r.nextRequest[host] = next.Add(r.interval)
+	return next.Sub(now), true
+}
+
+// returns the host portion of the url, or the url itself if it can not be parsed.
+func limiterHost(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.Host == "" {
+		return rawurl
+	}
+	return u.Host
+}
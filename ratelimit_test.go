@@ -0,0 +1,81 @@
+package autogcd
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterPerHost(t *testing.T) {
+	limiter := NewRateLimiter(10, 0)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Acquire("http://localhost/page", time.Second); err != nil {
+			t.Fatalf("error acquiring: %s\n", err)
+		}
+		limiter.Release()
+	}
+
+	if elapsed := time.Now().Sub(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("expected requests to the same host to be spaced out, took %s\n", elapsed)
+	}
+
+	start = time.Now()
+	if err := limiter.Acquire("http://example.com/", time.Second); err != nil {
+		t.Fatalf("error acquiring: %s\n", err)
+	}
+	limiter.Release()
+
+	if elapsed := time.Now().Sub(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("different host should not have been limited, took %s\n", elapsed)
+	}
+}
+
+func TestRateLimiterConcurrency(t *testing.T) {
+	limiter := NewRateLimiter(0, 1)
+
+	if err := limiter.Acquire("http://localhost/", time.Second); err != nil {
+		t.Fatalf("error acquiring: %s\n", err)
+	}
+
+	err := limiter.Acquire("http://example.com/", 100*time.Millisecond)
+	if _, ok := err.(*TimeoutErr); !ok {
+		t.Fatalf("expected timeout waiting for a free slot got: %v\n", err)
+	}
+
+	limiter.Release()
+	if err := limiter.Acquire("http://example.com/", time.Second); err != nil {
+		t.Fatalf("error acquiring after release: %s\n", err)
+	}
+	limiter.Release()
+}
+
+func TestRateLimiterAcquireCtx(t *testing.T) {
+	limiter := NewRateLimiter(0, 1)
+	if err := limiter.Acquire("http://localhost/", time.Second); err != nil {
+		t.Fatalf("error acquiring: %s\n", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := limiter.AcquireCtx(ctx, "http://example.com/", 10*time.Second); err != context.DeadlineExceeded {
+		t.Fatalf("expected the context deadline waiting for a free slot got: %v\n", err)
+	}
+	limiter.Release()
+
+	// waiting on the per host limit also stops with the context
+	limiter = NewRateLimiter(0.1, 0)
+	if err := limiter.Acquire("http://localhost/", time.Second); err != nil {
+		t.Fatalf("error acquiring: %s\n", err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := limiter.AcquireCtx(ctx, "http://localhost/", time.Minute); err != context.DeadlineExceeded {
+		t.Fatalf("expected the context deadline waiting for the host got: %v\n", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected waiting for the host to stop with the context, took %s\n", elapsed)
+	}
+}
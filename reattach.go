@@ -0,0 +1,179 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"time"
+)
+
+// how many times, and how far apart, re-attaching to a detached target is attempted
+const (
+	maxReattachAttempts = 5
+	reattachBackoff     = 500 * time.Millisecond
+)
+
+// finds and connects to the target again after the debugger was detached, see AutoGcd.openTab
+type reattachFunc func() (*targetConnection, error)
+
+// ReattachedFunc function called after a tab's debugger connection has been restored, see OnReattach
+type ReattachedFunc func(tab *Tab, reason string)
+
+// OnReattach calls handler after the tab re-attaches to its target following an Inspector.detached event
+// that did not close the target (for example replaced_with_devtools when devtools was opened on it). The
+// tab's domains, event subscriptions, network capture, page bindings, child session auto attach and DOM are
+// restored before handler is called. Subscriptions made on demand (such as pause or console handlers) and
+// scripts added to evaluate on new documents (hooks, observers etc.) belonged to the old connection, the
+// handler is the place to set them up again.
+func (t *Tab) OnReattach(handler ReattachedFunc) {
+	t.reattachLock.Lock()
+	defer t.reattachLock.Unlock()
+	t.reattachHandler = handler
+}
+
+// called for Inspector.detached, returns true if re-attaching has been started in which case the tab is not
+// considered crashed unless it fails.
+func (t *Tab) handleDetached(reason string) bool {
+	t.reattachLock.Lock()
+	defer t.reattachLock.Unlock()
+
+	if reason == "target_closed" || t.reattacher == nil || t.reattaching || t.IsShuttingDown() {
+		return false
+	}
+	t.reattaching = true
+	go t.reattach(reason)
+	return true
+}
+
+// tries to connect to the target again, marking the tab as crashed if it can not.
+func (t *Tab) reattach(reason string) {
+	defer t.recoverCallback("reattach")
+	defer func() {
+		t.reattachLock.Lock()
+		t.reattaching = false
+		t.reattachLock.Unlock()
+	}()
+
+	var err error
+	for attempt := 1; attempt <= maxReattachAttempts; attempt++ {
+		select {
+		case <-time.After(time.Duration(attempt) * reattachBackoff):
+		case <-t.exitCh:
+			return
+		}
+
+		var target *targetConnection
+		if target, err = t.reattacher(); err != nil {
+			t.debugf("re-attach attempt %d failed: %s\n", attempt, err)
+			continue
+		}
+		if err = t.restoreTarget(target); err != nil {
+			t.debugf("restoring re-attached target failed: %s\n", err)
+			continue
+		}
+
+		t.reattachLock.Lock()
+		handler := t.reattachHandler
+		t.reattachLock.Unlock()
+		if handler != nil {
+			handler(t, reason)
+		}
+		return
+	}
+
+	t.debugf("giving up re-attaching: %v\n", err)
+	t.setCrashed(reason, "", 0)
+	select {
+	case t.crashedCh <- reason:
+	case <-t.exitCh:
+	}
+}
+
+// closes the old connection, swaps in the new one then enables everything that was enabled on the old one.
+func (t *Tab) restoreTarget(target *targetConnection) error {
+	target.SetApiTimeout(t.GetApiTimeout())
+	t.setTarget(target)
+	t.resumeRecordSession()
+
+	if err := t.enableDomains(); err != nil {
+		return err
+	}
+	t.subscribeEvents()
+
+	t.networkLock.Lock()
+	networkEnabled := t.networkEnabled
+	t.networkEnabled = false
+	t.networkLock.Unlock()
+	if networkEnabled {
+		if err := t.enableNetwork(); err != nil {
+			return err
+		}
+	}
+
+	t.bindingLock.Lock()
+	runtimeEnabled := t.runtimeEnabled
+	t.runtimeEnabled = false
+	names := make([]string, 0, len(t.bindings))
+	for name := range t.bindings {
+		names = append(names, name)
+	}
+	t.bindingLock.Unlock()
+	if runtimeEnabled || len(names) > 0 {
+		if err := t.enableRuntime(); err != nil {
+			return err
+		}
+	}
+	for _, name := range names {
+		if _, err := t.Runtime.AddBinding(name, 0); err != nil {
+			return err
+		}
+	}
+
+	t.errorLock.Lock()
+	errorsEnabled := t.errorsEnabled
+	t.errorLock.Unlock()
+	if errorsEnabled {
+		if _, err := t.Log.Enable(); err != nil {
+			return err
+		}
+	}
+
+	t.sessionLock.Lock()
+	autoAttach := t.autoAttachEnabled
+	t.autoAttachEnabled = false
+	for _, session := range t.sessions {
+		session.setDetached()
+	}
+	t.sessions = make(map[string]*ChildSession)
+	t.sessionLock.Unlock()
+	if autoAttach {
+		if err := t.enableAutoAttach(); err != nil {
+			return err
+		}
+	}
+
+	// node ids from the old connection are meaningless now
+	t.handleDocumentUpdated()
+	return nil
+}
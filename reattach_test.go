@@ -0,0 +1,136 @@
+package autogcd
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+func TestTabHandleDetached(t *testing.T) {
+	tab := &Tab{reattachLock: &sync.Mutex{}, exitCh: make(chan struct{})}
+	if tab.handleDetached("replaced_with_devtools") {
+		t.Fatalf("tabs without a reattacher should not re-attach")
+	}
+
+	tab.reattacher = func() (*targetConnection, error) {
+		return nil, &InvalidTabErr{Message: "unavailable"}
+	}
+	if tab.handleDetached("target_closed") {
+		t.Fatalf("closed targets should not be re-attached")
+	}
+
+	// the first attempt waits for the backoff, exiting stops it before it tries to connect
+	defer close(tab.exitCh)
+	if !tab.handleDetached("replaced_with_devtools") {
+		t.Fatalf("expected re-attach to be started")
+	}
+	if tab.handleDetached("replaced_with_devtools") {
+		t.Fatalf("expected only one re-attach at a time")
+	}
+}
+
+func TestTabReattachAfterDetach(t *testing.T) {
+	auto := testDefaultStartup(t)
+	defer auto.Shutdown()
+
+	tab, err := auto.GetTab()
+	if err != nil {
+		t.Fatalf("error getting tab: %s\n", err)
+	}
+	if _, err := tab.Navigate(testServerAddr + "index.html"); err != nil {
+		t.Fatalf("error navigating: %s\n", err)
+	}
+
+	// a second devtools client on the same target, as opening devtools on the page would attach
+	other, err := auto.reconnectTarget(tab.Target.Id)
+	if err != nil {
+		t.Fatalf("error opening a second client: %s\n", err)
+	}
+	defer other.Close()
+	if _, err := gcdapi.NewPage(other).GetFrameTree(); err != nil {
+		t.Fatalf("error using the second client: %s\n", err)
+	}
+
+	reattachedCh := make(chan string, 1)
+	tab.OnReattach(func(tab *Tab, reason string) {
+		reattachedCh <- reason
+	})
+
+	// keep using the tab's domains while the connection is swapped
+	oldTarget := tab.target()
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		for {
+			select {
+			case <-stopCh:
+				return
+			default:
+			}
+			tab.Page.GetFrameTree()
+			tab.Ping(time.Second)
+		}
+	}()
+
+	if !tab.handleDetached("replaced_with_devtools") {
+		t.Fatalf("expected re-attach to be started")
+	}
+	select {
+	case reason := <-reattachedCh:
+		if reason != "replaced_with_devtools" {
+			t.Fatalf("expected replaced_with_devtools got %s\n", reason)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for the tab to re-attach")
+	}
+	close(stopCh)
+	<-doneCh
+
+	if tab.target() == oldTarget {
+		t.Fatalf("expected the connection to be replaced")
+	}
+	select {
+	case <-oldTarget.GetDoneCh():
+	default:
+		t.Fatalf("expected the old connection to be closed")
+	}
+	if _, err := tab.Navigate(testServerAddr + "index.html"); err != nil {
+		t.Fatalf("error navigating after re-attaching: %s\n", err)
+	}
+	if elements, err := tab.GetElementsBySelector("div"); err != nil || len(elements) != 1 {
+		t.Fatalf("error finding element after re-attaching: %v\n", err)
+	}
+}
+
+func TestTabResubscribesAfterReattach(t *testing.T) {
+	tab, connect, closeServers := testConnectedTab(t)
+	defer closeServers()
+	tab.attachTab = func(targetId string) (*Tab, error) { return nil, nil }
+
+	intercept := func(tab *Tab, request *InterceptedRequest) {}
+	if err := tab.InterceptRequests(intercept); err != nil {
+		t.Fatalf("error intercepting requests: %s\n", err)
+	}
+	if err := tab.OnPopup(func(popup *Tab) {}); err != nil {
+		t.Fatalf("error listening for popups: %s\n", err)
+	}
+
+	// as restoreTarget does, then the OnReattach handler sets everything up again
+	tab.setTarget(connect())
+	if err := tab.InterceptRequests(intercept); err != nil {
+		t.Fatalf("error intercepting requests after re-attaching: %s\n", err)
+	}
+	if err := tab.OnPopup(func(popup *Tab) {}); err != nil {
+		t.Fatalf("error listening for popups after re-attaching: %s\n", err)
+	}
+
+	for _, method := range []string{"Fetch.requestPaused", "Fetch.authRequired", "Target.targetCreated"} {
+		if !testSubscribed(tab.target(), method) {
+			t.Fatalf("expected %s to be subscribed on the new connection\n", method)
+		}
+	}
+	tab.target().Close()
+}
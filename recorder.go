@@ -0,0 +1,230 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// name of the binding the injected listeners report interactions to
+const recorderBindingName = "__autogcdRecord"
+
+// Listens (in the capture phase, so pages can not stop propagation first) for clicks, changes to form fields
+// and enter key presses, reporting each with a selector for the target. Top level documents also report
+// their url when they load, which is recorded as a navigation. Selectors are computed by selectorFunctions.
+const recorderScript = `(function(bindingName) {
+	if (window.__autogcdRecorder) {
+		return;
+	}
+	window.__autogcdRecorder = true;
+	%s
+	function report(action) {
+		action.url = location.href;
+		try {
+			window[bindingName](JSON.stringify(action));
+		} catch (e) {
+		}
+	}
+	document.addEventListener('click', function(event) {
+		var target = event.target;
+		if (!target || target.nodeType !== 1) {
+			return;
+		}
+		var tag = target.nodeName.toLowerCase();
+		// clicks on text fields just focus them, their value is recorded on change
+		if ((tag === 'input' && !/^(button|submit|reset|checkbox|radio|image|file)$/i.test(target.type)) || tag === 'textarea' || tag === 'select' || tag === 'option') {
+			return;
+		}
+		report({type: 'click', selector: selectorFor(target)});
+	}, true);
+	document.addEventListener('change', function(event) {
+		var target = event.target;
+		var tag = target.nodeName.toLowerCase();
+		if (tag === 'select') {
+			report({type: 'select', selector: selectorFor(target), value: target.value});
+		} else if (tag === 'textarea' || (tag === 'input' && !/^(button|submit|reset|checkbox|radio|image|file)$/i.test(target.type))) {
+			report({type: 'input', selector: selectorFor(target), value: target.type === 'password' ? '' : target.value, secret: target.type === 'password'});
+		}
+	}, true);
+	document.addEventListener('keydown', function(event) {
+		if (event.key === 'Enter' && event.target && event.target.nodeName.toLowerCase() === 'input') {
+			var target = event.target;
+			report({type: 'input', selector: selectorFor(target), value: target.type === 'password' ? '' : target.value, secret: target.type === 'password'});
+			report({type: 'key', selector: selectorFor(target), key: 'Enter'});
+		}
+	}, true);
+	if (window === window.top) {
+		report({type: 'navigate'});
+	}
+})(%s)`
+
+// Types of actions captured by a Recorder
+const (
+	RecordedNavigate = "navigate" // a top level document loaded
+	RecordedClick    = "click"    // an element was clicked
+	RecordedInput    = "input"    // a text field's value was changed
+	RecordedSelect   = "select"   // a select's value was changed
+	RecordedKey      = "key"      // enter was pressed in an input
+)
+
+// RecordedAction is a single interaction captured by a Recorder.
+type RecordedAction struct {
+	Type     string `json:"type"`     // one of the Recorded action types
+	Selector string `json:"selector"` // unique CSS selector of the target element, empty for navigations
+	Value    string `json:"value"`    // new value for input and select actions, empty for password fields
+	Secret   bool   `json:"secret"`   // the value is a password and was not recorded
+	Key      string `json:"key"`      // key pressed for key actions
+	Url      string `json:"url"`      // url of the document the action occurred in
+}
+
+// Recorder captures interactions made by a person using a headful tab, see Tab.StartRecording.
+type Recorder struct {
+	tab      *Tab
+	lock     *sync.Mutex
+	actions  []*RecordedAction
+	scriptId string
+}
+
+// StartRecording injects listeners into the current and every following document of the tab which capture
+// clicks, form field changes, enter key presses and navigations. Interact with the page manually then call
+// Recorder.GenerateGo to turn the session into a script. Only one recorder per tab is active at a time.
+func (t *Tab) StartRecording() (*Recorder, error) {
+	r := &Recorder{tab: t, lock: &sync.Mutex{}, actions: make([]*RecordedAction, 0)}
+	if err := t.addBinding(recorderBindingName, r.record); err != nil {
+		return nil, err
+	}
+
+	script := fmt.Sprintf(recorderScript, selectorFunctions, jsQuote(recorderBindingName))
+	scriptId, err := t.Page.AddScriptToEvaluateOnNewDocument(script, "")
+	if err != nil {
+		t.removeBinding(recorderBindingName)
+		return nil, err
+	}
+	r.scriptId = scriptId
+
+	if _, err := t.evaluateScript(script, false); err != nil {
+		r.Stop()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Stop stops capturing interactions, listeners remain in the current document but are no longer reported.
+func (r *Recorder) Stop() error {
+	if err := r.tab.removeBinding(recorderBindingName); err != nil {
+		return err
+	}
+	_, err := r.tab.Page.RemoveScriptToEvaluateOnNewDocument(r.scriptId)
+	return err
+}
+
+// Actions returns a copy of the interactions captured so far.
+func (r *Recorder) Actions() []*RecordedAction {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	actions := make([]*RecordedAction, len(r.actions))
+	copy(actions, r.actions)
+	return actions
+}
+
+// called with each action reported by the injected listeners
+func (r *Recorder) record(payload string) {
+	action := &RecordedAction{}
+	if err := json.Unmarshal([]byte(payload), action); err != nil {
+		r.tab.debugf("invalid recorder payload: %s\n", err)
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.actions) > 0 {
+		last := r.actions[len(r.actions)-1]
+		// typing then pressing enter reports the value twice, and documents report their own load
+		// after a click caused it, only the latest of each is kept
+		if last.Type == action.Type && last.Selector == action.Selector && (action.Type == RecordedInput || action.Type == RecordedSelect) {
+			r.actions[len(r.actions)-1] = action
+			return
+		}
+		if action.Type == RecordedNavigate && last.Type == RecordedNavigate && last.Url == action.Url {
+			return
+		}
+	}
+	r.actions = append(r.actions, action)
+}
+
+// GenerateGo returns Go source for a function named funcName which replays the recorded actions with autogcd.
+// Navigations that follow a click or enter key press are emitted as waits for the url rather than calls
+// to Navigate. Password values are not recorded, the generated code reads them from an environment variable.
+func (r *Recorder) GenerateGo(funcName string) string {
+	actions := r.Actions()
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "// %s was generated by the autogcd recorder.\n", funcName)
+	fmt.Fprintf(&buf, "func %s(tab *autogcd.Tab) error {\n", funcName)
+	buf.WriteString("\tvar ele *autogcd.Element\n\tvar err error\n\t_, _ = ele, err\n\n")
+
+	caused := false // the previous action may have caused the next navigation
+	for _, action := range actions {
+		switch action.Type {
+		case RecordedNavigate:
+			if caused {
+				fmt.Fprintf(&buf, "\tif err := tab.WaitFor(100*time.Millisecond, 30*time.Second, autogcd.UrlEquals(tab, %s)); err != nil {\n\t\treturn err\n\t}\n", strconv.Quote(action.Url))
+			} else {
+				fmt.Fprintf(&buf, "\tif _, err := tab.Navigate(%s); err != nil {\n\t\treturn err\n\t}\n", strconv.Quote(action.Url))
+			}
+		case RecordedClick:
+			writeRecordedElement(&buf, action.Selector)
+			buf.WriteString("\tif err := ele.Click(); err != nil {\n\t\treturn err\n\t}\n")
+		case RecordedInput:
+			writeRecordedElement(&buf, action.Selector)
+			value := strconv.Quote(action.Value)
+			if action.Secret {
+				value = "os.Getenv(\"AUTOGCD_SECRET\")"
+			}
+			fmt.Fprintf(&buf, "\tele.Clear()\n\tif err := ele.SendKeys(%s); err != nil {\n\t\treturn err\n\t}\n", value)
+		case RecordedSelect:
+			script := fmt.Sprintf("var s = document.querySelector(%s); s.value = %s; s.dispatchEvent(new Event('change', {bubbles: true}));", jsQuote(action.Selector), jsQuote(action.Value))
+			fmt.Fprintf(&buf, "\tif _, err := tab.EvaluateScript(%s); err != nil {\n\t\treturn err\n\t}\n", strconv.Quote(script))
+		case RecordedKey:
+			writeRecordedElement(&buf, action.Selector)
+			buf.WriteString("\tif err := ele.SendKeys(\"\\n\"); err != nil {\n\t\treturn err\n\t}\n")
+		}
+		caused = action.Type == RecordedClick || action.Type == RecordedKey
+	}
+	buf.WriteString("\treturn nil\n}\n")
+	return buf.String()
+}
+
+// writes code waiting for, then assigning the element matching selector to ele
+func writeRecordedElement(buf *bytes.Buffer, selector string) {
+	quoted := strconv.Quote(selector)
+	fmt.Fprintf(buf, "\tif err := tab.WaitFor(100*time.Millisecond, 10*time.Second, autogcd.ElementsBySelectorNotEmpty(tab, %s)); err != nil {\n\t\treturn err\n\t}\n", quoted)
+	fmt.Fprintf(buf, "\tif eles, err := tab.GetElementsBySelector(%s); err != nil {\n\t\treturn err\n\t} else {\n\t\tele = eles[0]\n\t}\n", quoted)
+	buf.WriteString("\tif err := ele.WaitForReady(); err != nil {\n\t\treturn err\n\t}\n")
+}
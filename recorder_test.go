@@ -0,0 +1,48 @@
+package autogcd
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRecorderGenerateGo(t *testing.T) {
+	r := &Recorder{lock: &sync.Mutex{}}
+	r.record(`{"type":"navigate","url":"http://localhost/login.html"}`)
+	r.record(`{"type":"navigate","url":"http://localhost/login.html"}`)
+	r.record(`{"type":"input","selector":"#user","value":"a"}`)
+	r.record(`{"type":"input","selector":"#user","value":"admin"}`)
+	r.record(`{"type":"input","selector":"input[name=\"pass\"]","secret":true}`)
+	r.record(`{"type":"select","selector":"#lang","value":"en"}`)
+	r.record(`{"type":"click","selector":"form > button:nth-of-type(1)"}`)
+	r.record(`{"type":"navigate","url":"http://localhost/home.html"}`)
+
+	actions := r.Actions()
+	if len(actions) != 6 {
+		t.Fatalf("expected 6 actions after collapsing, got %d\n", len(actions))
+	}
+	if actions[1].Value != "admin" {
+		t.Fatalf("expected latest input value to be kept, got %s\n", actions[1].Value)
+	}
+
+	src := r.GenerateGo("replayLogin")
+	if _, err := parser.ParseFile(token.NewFileSet(), "recorded.go", "package recorded\n"+src, 0); err != nil {
+		t.Fatalf("generated code does not parse: %s\n%s", err, src)
+	}
+	for _, expected := range []string{
+		`tab.Navigate("http://localhost/login.html")`,
+		`ele.SendKeys("admin")`,
+		`os.Getenv("AUTOGCD_SECRET")`,
+		`autogcd.UrlEquals(tab, "http://localhost/home.html")`,
+		`ele.Click()`,
+	} {
+		if !strings.Contains(src, expected) {
+			t.Fatalf("expected generated code to contain %s\n%s", expected, src)
+		}
+	}
+	if strings.Count(src, "tab.Navigate(") != 1 {
+		t.Fatalf("navigation caused by click should not be emitted as Navigate\n%s", src)
+	}
+}
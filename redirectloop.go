@@ -0,0 +1,169 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/wirepair/gcd"
+)
+
+// Page.frameRequestedNavigation reasons which are redirects made by the page rather than the user
+var clientRedirectReasons = map[string]bool{
+	"metaTagRefresh":    true,
+	"httpHeaderRefresh": true,
+	"scriptInitiated":   true,
+}
+
+// Page.frameRequestedNavigation, not available in the vendored gcdapi
+type frameRequestedNavigationEvent struct {
+	Method string `json:"method"`
+	Params struct {
+		FrameId     string `json:"frameId"`     // Id of the frame that is being navigated.
+		Reason      string `json:"reason"`      // The reason for the navigation.
+		Url         string `json:"url"`         // The destination URL for the requested navigation.
+		Disposition string `json:"disposition"` // The disposition for the navigation.
+	} `json:"Params,omitempty"`
+}
+
+// RedirectLoopErr is returned by Navigate, and from RedirectLoop, once the top frame has made more
+// consecutive meta refresh or script redirects than the threshold given to DetectRedirectLoops.
+type RedirectLoopErr struct {
+	Chain []string // the urls of the chain in order, starting with the page that made the first redirect
+	Loop  bool     // a url was redirected to more than once
+}
+
+func (e *RedirectLoopErr) Error() string {
+	kind := "redirect chain"
+	if e.Loop {
+		kind = "redirect loop"
+	}
+	return fmt.Sprintf("%s of %d client side redirects: %s", kind, len(e.Chain)-1, strings.Join(e.Chain, " -> "))
+}
+
+// Unwrap returns ErrRedirectLoop so the error can be matched with errors.Is
+func (e *RedirectLoopErr) Unwrap() error {
+	return ErrRedirectLoop
+}
+
+// DetectRedirectLoops stops the page loading once the top frame makes more than threshold consecutive
+// client side redirects (meta refresh, Refresh headers and script navigations) so crawlers can bail out
+// of refresh chains and redirect loops instead of waiting for the navigation timeout. A Navigate in
+// progress returns a RedirectLoopErr, chains detected after Navigate returned are reported by RedirectLoop.
+// Navigate, and any navigation made by the user such as following a link, starts a new chain. HTTP
+// redirects are limited by chrome itself. A threshold of 0 or less disables detection.
+func (t *Tab) DetectRedirectLoops(threshold int) {
+	t.redirectLoopLock.Lock()
+	defer t.redirectLoopLock.Unlock()
+	t.redirectThreshold = threshold
+	t.clientRedirects = nil
+	t.redirectLoopErr = nil
+}
+
+// RedirectLoop returns the RedirectLoopErr detected since the last Navigate, or nil.
+func (t *Tab) RedirectLoop() error {
+	t.redirectLoopLock.Lock()
+	defer t.redirectLoopLock.Unlock()
+	if t.redirectLoopErr == nil {
+		return nil
+	}
+	return t.redirectLoopErr
+}
+
+// starts a new chain, called by Navigate
+func (t *Tab) resetClientRedirects() {
+	t.redirectLoopLock.Lock()
+	t.clientRedirects = nil
+	t.redirectLoopErr = nil
+	t.redirectLoopLock.Unlock()
+
+	select {
+	case <-t.redirectLoopCh:
+	default:
+	}
+}
+
+func (t *Tab) subscribeFrameRequestedNavigation() {
+	t.Subscribe("Page.frameRequestedNavigation", func(target *gcd.ChromeTarget, payload []byte) {
+		header := &frameRequestedNavigationEvent{}
+		if err := json.Unmarshal(payload, header); err == nil && header.Params.FrameId == t.GetTopFrameId() {
+			t.handleRequestedNavigation(header.Params.Reason, header.Params.Url)
+		}
+	})
+}
+
+// adds client side redirects of the top frame to the chain, stopping the page once it is too long.
+func (t *Tab) handleRequestedNavigation(reason, url string) {
+	t.redirectLoopLock.Lock()
+	t.topNavigationRequests++
+	if t.redirectThreshold <= 0 || t.redirectLoopErr != nil {
+		t.redirectLoopLock.Unlock()
+		return
+	}
+	if !clientRedirectReasons[reason] {
+		t.clientRedirects = nil
+		t.redirectLoopLock.Unlock()
+		return
+	}
+
+	if len(t.clientRedirects) == 0 {
+		t.softNavLock.Lock()
+		t.clientRedirects = append(t.clientRedirects, t.topFrameUrl)
+		t.softNavLock.Unlock()
+	}
+	t.clientRedirects = append(t.clientRedirects, url)
+	if len(t.clientRedirects)-1 <= t.redirectThreshold {
+		t.redirectLoopLock.Unlock()
+		return
+	}
+
+	chain := make([]string, len(t.clientRedirects))
+	copy(chain, t.clientRedirects)
+	t.redirectLoopErr = &RedirectLoopErr{Chain: chain, Loop: hasRepeatedUrl(chain)}
+	t.redirectLoopLock.Unlock()
+
+	t.debugf("stopping %s\n", t.redirectLoopErr)
+	if _, err := t.Page.StopLoading(); err != nil {
+		t.debugf("unable to stop loading: %s\n", err)
+	}
+	select {
+	case t.redirectLoopCh <- struct{}{}:
+	default:
+	}
+}
+
+// does any url appear in chain more than once
+func hasRepeatedUrl(chain []string) bool {
+	seen := make(map[string]struct{}, len(chain))
+	for _, url := range chain {
+		if _, ok := seen[url]; ok {
+			return true
+		}
+		seen[url] = struct{}{}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package autogcd
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestHandleRequestedNavigationChain(t *testing.T) {
+	tab := &Tab{redirectLoopLock: &sync.Mutex{}, softNavLock: &sync.Mutex{}, topFrameUrl: "http://localhost/a.html"}
+
+	tab.handleRequestedNavigation("metaTagRefresh", "http://localhost/b.html")
+	if len(tab.clientRedirects) != 0 {
+		t.Fatalf("redirects should not be tracked until DetectRedirectLoops is called")
+	}
+
+	tab.DetectRedirectLoops(3)
+	tab.handleRequestedNavigation("metaTagRefresh", "http://localhost/b.html")
+	tab.handleRequestedNavigation("scriptInitiated", "http://localhost/a.html")
+	if len(tab.clientRedirects) != 3 || tab.clientRedirects[0] != "http://localhost/a.html" {
+		t.Fatalf("expected chain starting at the top frame url got %v\n", tab.clientRedirects)
+	}
+	if tab.RedirectLoop() != nil {
+		t.Fatalf("chain is within the threshold")
+	}
+
+	tab.handleRequestedNavigation("anchorClick", "http://localhost/c.html")
+	if len(tab.clientRedirects) != 0 {
+		t.Fatalf("user navigations should start a new chain got %v\n", tab.clientRedirects)
+	}
+}
+
+func TestRedirectLoopErr(t *testing.T) {
+	err := error(&RedirectLoopErr{Chain: []string{"a", "b", "a"}, Loop: true})
+	if !errors.Is(err, ErrRedirectLoop) {
+		t.Fatalf("expected RedirectLoopErr to unwrap to ErrRedirectLoop")
+	}
+	if err.Error() != "redirect loop of 2 client side redirects: a -> b -> a" {
+		t.Fatalf("unexpected error text %s\n", err)
+	}
+	if !hasRepeatedUrl([]string{"a", "b", "a"}) || hasRepeatedUrl([]string{"a", "b", "c"}) {
+		t.Fatalf("hasRepeatedUrl returned the wrong result")
+	}
+}
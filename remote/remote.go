@@ -0,0 +1,310 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+/*
+Package remote exposes Tab operations of an AutoGcd over JSON-RPC 2.0 so services written in other languages
+can drive the same Chrome instance. Requests are POSTed to the Server's handler:
+
+	{"jsonrpc": "2.0", "id": 1, "method": "tab.navigate", "params": {"tabId": "...", "url": "https://example.com/"}}
+
+Methods:
+
+	tab.list                                    ids of all tabs
+	tab.open                                    {tabId} of a new tab
+	tab.close       {tabId}
+	tab.navigate    {tabId, url}                {url, status, statusText, errorText}
+	tab.evaluate    {tabId, script}             the script's return value
+	tab.html        {tabId}                     the rendered document html
+	tab.screenshot  {tabId}                     base64 encoded png of the viewport
+	tab.click       {tabId, selector}           clicks the first element matching selector
+	tab.sendKeys    {tabId, selector, keys}     types keys into the first element matching selector
+
+The server performs no authentication, only listen on trusted interfaces or wrap the handler.
+*/
+package remote
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/wirepair/autogcd"
+)
+
+// maximum request body size accepted
+const maxRequestSize = 10 * 1024 * 1024
+
+// JSON-RPC 2.0 error codes
+const (
+	ParseErrorCode     = -32700
+	InvalidRequestCode = -32600
+	MethodNotFoundCode = -32601
+	InvalidParamsCode  = -32602
+	ServerErrorCode    = -32000 // the tab operation failed
+	TabNotFoundCode    = -32001 // no tab with the requested id
+)
+
+// Error is a JSON-RPC error object, returned to clients when a call fails.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("remote error %d: %s", e.Code, e.Message)
+}
+
+type request struct {
+	Version string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// the parameters accepted by the tab methods, unused fields are ignored
+type tabParams struct {
+	TabId    string `json:"tabId"`
+	Url      string `json:"url"`
+	Script   string `json:"script"`
+	Selector string `json:"selector"`
+	Keys     string `json:"keys"`
+}
+
+type methodFunc func(params *tabParams) (interface{}, error)
+
+// Server is an http.Handler dispatching JSON-RPC calls to the tabs of an AutoGcd.
+type Server struct {
+	auto    *autogcd.AutoGcd
+	methods map[string]methodFunc
+}
+
+// New creates a server controlling the tabs of the started auto.
+func New(auto *autogcd.AutoGcd) *Server {
+	s := &Server{auto: auto}
+	s.methods = map[string]methodFunc{
+		"tab.list":       s.list,
+		"tab.open":       s.open,
+		"tab.close":      s.close,
+		"tab.navigate":   s.navigate,
+		"tab.evaluate":   s.evaluate,
+		"tab.html":       s.html,
+		"tab.screenshot": s.screenshot,
+		"tab.click":      s.click,
+		"tab.sendKeys":   s.sendKeys,
+	}
+	return s
+}
+
+// ServeHTTP implements http.Handler, replying with a JSON-RPC response. Errors are reported in the response
+// body with a 200 status as JSON-RPC over HTTP expects.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+
+	req := &request{}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestSize)).Decode(req); err != nil {
+		encoder.Encode(&response{Version: "2.0", Id: json.RawMessage("null"), Error: &Error{Code: ParseErrorCode, Message: err.Error()}})
+		return
+	}
+	encoder.Encode(s.call(req))
+}
+
+// dispatches a decoded request to its method
+func (s *Server) call(req *request) *response {
+	resp := &response{Version: "2.0", Id: req.Id}
+	if resp.Id == nil {
+		resp.Id = json.RawMessage("null")
+	}
+	if req.Version != "2.0" || req.Method == "" {
+		resp.Error = &Error{Code: InvalidRequestCode, Message: "invalid request"}
+		return resp
+	}
+
+	method, ok := s.methods[req.Method]
+	if !ok {
+		resp.Error = &Error{Code: MethodNotFoundCode, Message: "method not found: " + req.Method}
+		return resp
+	}
+
+	params := &tabParams{}
+	if len(req.Params) > 0 && string(req.Params) != "null" {
+		if err := json.Unmarshal(req.Params, params); err != nil {
+			resp.Error = &Error{Code: InvalidParamsCode, Message: err.Error()}
+			return resp
+		}
+	}
+
+	result, err := method(params)
+	if err != nil {
+		if rpcErr, ok := err.(*Error); ok {
+			resp.Error = rpcErr
+		} else {
+			resp.Error = &Error{Code: ServerErrorCode, Message: err.Error()}
+		}
+		return resp
+	}
+	if result == nil {
+		result = true
+	}
+	resp.Result = result
+	return resp
+}
+
+// returns the tab for params.TabId
+func (s *Server) tab(params *tabParams) (*autogcd.Tab, error) {
+	if params.TabId == "" {
+		return nil, &Error{Code: InvalidParamsCode, Message: "missing tabId"}
+	}
+	tab, ok := s.auto.GetAllTabs()[params.TabId]
+	if !ok {
+		return nil, &Error{Code: TabNotFoundCode, Message: "tab not found: " + params.TabId}
+	}
+	return tab, nil
+}
+
+// returns the first element matching params.Selector
+func (s *Server) element(params *tabParams) (*autogcd.Element, error) {
+	tab, err := s.tab(params)
+	if err != nil {
+		return nil, err
+	}
+	if params.Selector == "" {
+		return nil, &Error{Code: InvalidParamsCode, Message: "missing selector"}
+	}
+	elements, err := tab.GetElementsBySelector(params.Selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) == 0 {
+		return nil, &autogcd.ElementNotFoundErr{Message: params.Selector}
+	}
+	if err := elements[0].WaitForReady(); err != nil {
+		return nil, err
+	}
+	return elements[0], nil
+}
+
+func (s *Server) list(params *tabParams) (interface{}, error) {
+	ids := make([]string, 0)
+	for id := range s.auto.GetAllTabs() {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (s *Server) open(params *tabParams) (interface{}, error) {
+	tab, err := s.auto.NewTab()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"tabId": tab.Target.Id}, nil
+}
+
+func (s *Server) close(params *tabParams) (interface{}, error) {
+	tab, err := s.tab(params)
+	if err != nil {
+		return nil, err
+	}
+	return nil, s.auto.CloseTab(tab)
+}
+
+func (s *Server) navigate(params *tabParams) (interface{}, error) {
+	tab, err := s.tab(params)
+	if err != nil {
+		return nil, err
+	}
+	if params.Url == "" {
+		return nil, &Error{Code: InvalidParamsCode, Message: "missing url"}
+	}
+	result, err := tab.Navigate(params.Url)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"url": result.Url, "status": result.Status, "statusText": result.StatusText, "errorText": result.ErrorText}, nil
+}
+
+func (s *Server) evaluate(params *tabParams) (interface{}, error) {
+	tab, err := s.tab(params)
+	if err != nil {
+		return nil, err
+	}
+	rro, err := tab.EvaluateScript(params.Script)
+	if err != nil {
+		return nil, err
+	}
+	if rro.Value == nil {
+		return json.RawMessage("null"), nil
+	}
+	return rro.Value, nil
+}
+
+func (s *Server) html(params *tabParams) (interface{}, error) {
+	tab, err := s.tab(params)
+	if err != nil {
+		return nil, err
+	}
+	return tab.GetPageSource(0)
+}
+
+func (s *Server) screenshot(params *tabParams) (interface{}, error) {
+	tab, err := s.tab(params)
+	if err != nil {
+		return nil, err
+	}
+	img, err := tab.GetScreenShot()
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.EncodeToString(img), nil
+}
+
+func (s *Server) click(params *tabParams) (interface{}, error) {
+	ele, err := s.element(params)
+	if err != nil {
+		return nil, err
+	}
+	return nil, ele.Click()
+}
+
+func (s *Server) sendKeys(params *tabParams) (interface{}, error) {
+	ele, err := s.element(params)
+	if err != nil {
+		return nil, err
+	}
+	return nil, ele.SendKeys(params.Keys)
+}
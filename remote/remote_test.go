@@ -0,0 +1,43 @@
+package remote
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testCall(t *testing.T, s *Server, body string) *response {
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	resp := &response{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), resp); err != nil {
+		t.Fatalf("error decoding response %s: %s\n", recorder.Body.String(), err)
+	}
+	return resp
+}
+
+func TestServerErrors(t *testing.T) {
+	s := New(nil)
+
+	if resp := testCall(t, s, `{"jsonrpc": "2.0", "id": 1`); resp.Error == nil || resp.Error.Code != ParseErrorCode {
+		t.Fatalf("expected parse error got %#v\n", resp.Error)
+	}
+
+	if resp := testCall(t, s, `{"id": 2, "method": "tab.list"}`); resp.Error == nil || resp.Error.Code != InvalidRequestCode {
+		t.Fatalf("expected invalid request got %#v\n", resp.Error)
+	}
+
+	resp := testCall(t, s, `{"jsonrpc": "2.0", "id": "abc", "method": "tab.missing"}`)
+	if resp.Error == nil || resp.Error.Code != MethodNotFoundCode || string(resp.Id) != `"abc"` {
+		t.Fatalf("expected method not found with the request id got %#v %s\n", resp.Error, resp.Id)
+	}
+
+	if resp := testCall(t, s, `{"jsonrpc": "2.0", "id": 3, "method": "tab.navigate", "params": {"url": "http://localhost/"}}`); resp.Error == nil || resp.Error.Code != InvalidParamsCode {
+		t.Fatalf("expected missing tabId to be invalid params got %#v\n", resp.Error)
+	}
+
+	if resp := testCall(t, s, `{"jsonrpc": "2.0", "id": 4, "method": "tab.navigate", "params": ["positional"]}`); resp.Error == nil || resp.Error.Code != InvalidParamsCode {
+		t.Fatalf("expected positional params to be invalid got %#v\n", resp.Error)
+	}
+}
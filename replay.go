@@ -0,0 +1,191 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Re-issues a request with fetch in the page's context so the page's cookies and origin are used. Resolves to
+// the status, headers, final url and base64 encoded body.
+const replayRequestScript = `(function(request) {
+	var init = {method: request.method, headers: request.headers, credentials: 'include', redirect: 'follow', cache: 'no-store'};
+	if (request.body !== null && request.method !== 'GET' && request.method !== 'HEAD') {
+		init.body = request.body;
+	}
+	return fetch(request.url, init).then(function(response) {
+		var headers = {};
+		response.headers.forEach(function(value, name) {
+			headers[name] = value;
+		});
+		return response.arrayBuffer().then(function(buffer) {
+			var bytes = new Uint8Array(buffer);
+			var binary = '';
+			for (var i = 0; i < bytes.length; i += 8192) {
+				binary += String.fromCharCode.apply(null, bytes.subarray(i, i + 8192));
+			}
+			return {status: response.status, statusText: response.statusText, url: response.url, headers: headers, body: btoa(binary)};
+		});
+	});
+})(%s)`
+
+// headers the browser sets itself which fetch refuses to send, they are dropped from replayed requests.
+var forbiddenReplayHeaders = map[string]struct{}{
+	"accept-charset":    {},
+	"accept-encoding":   {},
+	"connection":        {},
+	"content-length":    {},
+	"cookie":            {},
+	"host":              {},
+	"keep-alive":        {},
+	"origin":            {},
+	"referer":           {},
+	"te":                {},
+	"trailer":           {},
+	"transfer-encoding": {},
+	"upgrade":           {},
+	"user-agent":        {},
+}
+
+// RequestModifications change a request replayed by ReplayRequest, empty fields keep the original values.
+type RequestModifications struct {
+	Url     string            // replace the url
+	Method  string            // replace the method
+	Headers map[string]string // add or replace headers, an empty value removes the header
+	Body    *string           // replace the body, nil keeps the original
+}
+
+// ReplayResponse is the result of ReplayRequest.
+type ReplayResponse struct {
+	Status     int               // HTTP status code
+	StatusText string            // HTTP status text
+	Url        string            // the final url after redirects
+	Headers    map[string]string // response headers, names are lower case
+	Body       []byte            // the response body
+}
+
+// GetCapturedRequest returns a request seen since the last Navigate (or ClearResources), false if it is not known.
+func (t *Tab) GetCapturedRequest(requestId string) (*NetworkRequest, bool) {
+	t.networkLock.RLock()
+	defer t.networkLock.RUnlock()
+
+	tracked, ok := t.resources[requestId]
+	if !ok || tracked.request == nil {
+		return nil, false
+	}
+	return tracked.request, true
+}
+
+// ReplayRequest re-issues a previously captured request from the page's context with fetch, applying mods
+// (which may be nil). The page's cookies and origin are used so authenticated API calls can be probed, headers
+// the browser controls (Cookie, Referer, User-Agent etc) can not be changed. The request must have been
+// captured since the last Navigate, and cross origin requests are subject to the page's CORS policy.
+func (t *Tab) ReplayRequest(requestId string, mods *RequestModifications) (*ReplayResponse, error) {
+	captured, ok := t.GetCapturedRequest(requestId)
+	if !ok || captured.Request == nil {
+		return nil, fmt.Errorf("request %s was not captured", requestId)
+	}
+
+	request := captured.Request
+	replay := map[string]interface{}{"url": request.Url, "method": request.Method}
+	headers := make(map[string]string, len(request.Headers))
+	for name, value := range request.Headers {
+		if _, forbidden := forbiddenReplayHeaders[strings.ToLower(name)]; forbidden || strings.HasPrefix(name, ":") {
+			continue
+		}
+		headers[name] = fmt.Sprintf("%v", value)
+	}
+
+	var body interface{}
+	if captured.HasPostData {
+		postData := captured.PostData
+		if postData == "" {
+			data, err := t.GetRequestPostData(requestId)
+			if err != nil {
+				return nil, err
+			}
+			postData = data
+		}
+		body = postData
+	}
+
+	if mods != nil {
+		if mods.Url != "" {
+			replay["url"] = mods.Url
+		}
+		if mods.Method != "" {
+			replay["method"] = mods.Method
+		}
+		for name, value := range mods.Headers {
+			for existing := range headers {
+				if strings.EqualFold(existing, name) {
+					delete(headers, existing)
+				}
+			}
+			if value != "" {
+				headers[name] = value
+			}
+		}
+		if mods.Body != nil {
+			body = *mods.Body
+		}
+	}
+	replay["headers"] = headers
+	replay["body"] = body
+
+	encoded, err := json.Marshal(replay)
+	if err != nil {
+		return nil, err
+	}
+
+	rro, err := t.evaluateScript(fmt.Sprintf(replayRequestScript, string(encoded)), true)
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := rro.Value.(map[string]interface{})
+	if !ok {
+		return nil, &ScriptEvaluationErr{Message: "replay returned no response"}
+	}
+
+	response := &ReplayResponse{Headers: make(map[string]string)}
+	status, _ := values["status"].(float64)
+	response.Status = int(status)
+	response.StatusText, _ = values["statusText"].(string)
+	response.Url, _ = values["url"].(string)
+	if responseHeaders, ok := values["headers"].(map[string]interface{}); ok {
+		for name, value := range responseHeaders {
+			response.Headers[name], _ = value.(string)
+		}
+	}
+	encodedBody, _ := values["body"].(string)
+	if response.Body, err = base64.StdEncoding.DecodeString(encodedBody); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,170 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// ToCurl returns a curl command line that repeats the request with its method, headers and body. Chrome does
+// not include the Cookie header in request events, so pass the tab's cookies (see Tab.GetCookies) and those
+// matching the request url are added. If HasPostData is set but PostData is empty, fill PostData using
+// Tab.GetRequestPostData first.
+func (r *NetworkRequest) ToCurl(cookies ...*gcdapi.NetworkCookie) string {
+	if r.Request == nil {
+		return ""
+	}
+
+	parts := []string{"curl", shellQuote(r.Request.Url)}
+	if r.Request.Method != "" && r.Request.Method != "GET" {
+		parts = append(parts, "-X", shellQuote(r.Request.Method))
+	}
+
+	header := r.header(cookies)
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			parts = append(parts, "-H", shellQuote(name+": "+value))
+		}
+	}
+
+	if r.PostData != "" {
+		parts = append(parts, "--data-raw", shellQuote(r.PostData))
+	}
+	parts = append(parts, "--compressed")
+	return strings.Join(parts, " ")
+}
+
+// ToHTTPRequest converts the request to a net/http Request with the same method, url, headers and body so
+// it can be sent outside of the browser. Cookies are handled the same as ToCurl.
+func (r *NetworkRequest) ToHTTPRequest(cookies ...*gcdapi.NetworkCookie) (*http.Request, error) {
+	if r.Request == nil {
+		return nil, fmt.Errorf("request %s has no request data", r.RequestId)
+	}
+
+	method := r.Request.Method
+	if method == "" {
+		method = "GET"
+	}
+
+	var body io.Reader
+	if r.PostData != "" {
+		body = strings.NewReader(r.PostData)
+	}
+
+	req, err := http.NewRequest(method, r.Request.Url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range r.header(cookies) {
+		if strings.EqualFold(name, "Host") {
+			req.Host = values[0]
+			continue
+		}
+		req.Header[name] = values
+	}
+	return req, nil
+}
+
+// returns the request headers, dropping HTTP/2 pseudo headers and Content-Length (which is recomputed) and
+// adding a Cookie header built from cookies matching the request url.
+func (r *NetworkRequest) header(cookies []*gcdapi.NetworkCookie) http.Header {
+	header := make(http.Header, len(r.Request.Headers)+1)
+	for name, value := range r.Request.Headers {
+		if strings.HasPrefix(name, ":") || strings.EqualFold(name, "Content-Length") {
+			continue
+		}
+		// multiple values are sent joined by newlines
+		for _, v := range strings.Split(fmt.Sprintf("%v", value), "\n") {
+			header.Add(name, v)
+		}
+	}
+
+	if header.Get("Cookie") != "" {
+		return header
+	}
+
+	u, err := url.Parse(r.Request.Url)
+	if err != nil {
+		return header
+	}
+	pairs := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		if cookieMatches(cookie, u) {
+			pairs = append(pairs, cookie.Name+"="+cookie.Value)
+		}
+	}
+	if len(pairs) > 0 {
+		header.Set("Cookie", strings.Join(pairs, "; "))
+	}
+	return header
+}
+
+// reports if the browser would send cookie to u, based on its domain, path and secure flag.
+func cookieMatches(cookie *gcdapi.NetworkCookie, u *url.URL) bool {
+	if cookie == nil {
+		return false
+	}
+	if cookie.Secure && u.Scheme != "https" && u.Scheme != "wss" {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	domain := strings.ToLower(cookie.Domain)
+	if strings.HasPrefix(domain, ".") {
+		if host != domain[1:] && !strings.HasSuffix(host, domain) {
+			return false
+		}
+	} else if host != domain {
+		return false
+	}
+
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	cookiePath := cookie.Path
+	if cookiePath == "" || cookiePath == "/" {
+		return true
+	}
+	return path == cookiePath || strings.HasPrefix(path, strings.TrimSuffix(cookiePath, "/")+"/")
+}
+
+// quotes value for a POSIX shell using single quotes.
+func shellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
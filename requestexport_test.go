@@ -0,0 +1,61 @@
+package autogcd
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+func testCapturedRequest() *NetworkRequest {
+	return &NetworkRequest{
+		RequestId: "1000.1",
+		Request: &gcdapi.NetworkRequest{
+			Url:     "https://example.com/api/search",
+			Method:  "POST",
+			Headers: map[string]interface{}{"Content-Type": "application/json", "X-Token": "it's", ":authority": "example.com"},
+		},
+		HasPostData: true,
+		PostData:    `{"q":"test"}`,
+	}
+}
+
+func testCookies() []*gcdapi.NetworkCookie {
+	return []*gcdapi.NetworkCookie{
+		{Name: "session", Value: "abc", Domain: ".example.com", Path: "/", Secure: true},
+		{Name: "other", Value: "nope", Domain: "other.com", Path: "/"},
+		{Name: "admin", Value: "nope", Domain: "example.com", Path: "/admin"},
+	}
+}
+
+func TestNetworkRequestToCurl(t *testing.T) {
+	curl := testCapturedRequest().ToCurl(testCookies()...)
+	expected := `curl 'https://example.com/api/search' -X 'POST' -H 'Content-Type: application/json' -H 'Cookie: session=abc' -H 'X-Token: it'\''s' --data-raw '{"q":"test"}' --compressed`
+	if curl != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s\n", expected, curl)
+	}
+}
+
+func TestNetworkRequestToHTTPRequest(t *testing.T) {
+	req, err := testCapturedRequest().ToHTTPRequest(testCookies()...)
+	if err != nil {
+		t.Fatalf("error converting request: %s\n", err)
+	}
+
+	if req.Method != "POST" || req.URL.String() != "https://example.com/api/search" {
+		t.Fatalf("unexpected request line: %s %s\n", req.Method, req.URL)
+	}
+
+	if req.Header.Get("X-Token") != "it's" || req.Header.Get("Cookie") != "session=abc" {
+		t.Fatalf("unexpected headers: %#v\n", req.Header)
+	}
+
+	if _, ok := req.Header[":authority"]; ok {
+		t.Fatalf("expected pseudo headers to be removed\n")
+	}
+
+	body, _ := ioutil.ReadAll(req.Body)
+	if string(body) != `{"q":"test"}` {
+		t.Fatalf("unexpected body: %s\n", string(body))
+	}
+}
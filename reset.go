@@ -0,0 +1,133 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"context"
+	"time"
+)
+
+// the timeouts a tab was opened with, restored by Reset.
+type tabTimeouts struct {
+	navigation  time.Duration
+	element     time.Duration
+	stability   time.Duration
+	stableAfter time.Duration
+	call        time.Duration
+}
+
+// sets the navigation, element and stability timeouts to their defaults.
+func (t *Tab) setDefaultTimeouts() {
+	t.navigationTimeout = 30 * time.Second // default 30 seconds for timeout
+	t.elementTimeout = 5 * time.Second     // default 5 seconds for waiting for element.
+	t.stabilityTimeout = 2 * time.Second   // default 2 seconds before we give up waiting for stability
+	t.stableAfter = 300 * time.Millisecond // default 300 ms for considering the DOM stable
+}
+
+// records the timeouts once the tab's options have been applied.
+func (t *Tab) saveOpenTimeouts() {
+	t.openTimeouts = tabTimeouts{
+		navigation:  t.navigationTimeout,
+		element:     t.elementTimeout,
+		stability:   t.stabilityTimeout,
+		stableAfter: t.stableAfter,
+		call:        t.GetApiTimeout(),
+	}
+}
+
+// restores the timeouts recorded by saveOpenTimeouts.
+func (t *Tab) restoreOpenTimeouts() {
+	t.navigationTimeout = t.openTimeouts.navigation
+	t.elementTimeout = t.openTimeouts.element
+	t.stabilityTimeout = t.openTimeouts.stability
+	t.stableAfter = t.openTimeouts.stableAfter
+	t.SetApiTimeout(t.openTimeouts.call)
+}
+
+// SetResetOrigins sets the origins, such as https://example.com, whose cookies, storage and caches Reset
+// clears.
+func (t *Tab) SetResetOrigins(origins ...string) {
+	t.resetOrigins = origins
+}
+
+// Reset recycles the tab for another scenario without closing it. Scripts injected into new documents by
+// HookFunction, ObserveMutations, InstrumentDOMSinks, FreezeTime and SeedRandom are removed, the tab
+// navigates to about:blank, the cookies, storage and caches of the origins set by SetResetOrigins are
+// cleared, the timeouts, including the call timeout, are restored to the values the tab was opened with
+// and the DOM change, element appear, soft navigation and popup handlers are removed. Middleware, the error
+// handler and network settings are kept.
+func (t *Tab) Reset() error {
+	t.bindingLock.RLock()
+	paths := make([]string, 0, len(t.hooks))
+	for path := range t.hooks {
+		paths = append(paths, path)
+	}
+	t.bindingLock.RUnlock()
+	for _, path := range paths {
+		if err := t.UnhookFunction(path); err != nil {
+			return err
+		}
+	}
+
+	if err := t.StopObservingMutations(); err != nil {
+		return err
+	}
+	if err := t.StopInstrumentingDOMSinks(); err != nil {
+		return err
+	}
+
+	t.bindingLock.Lock()
+	scriptIds := t.newDocScripts
+	t.newDocScripts = make(map[string]string)
+	t.bindingLock.Unlock()
+	for _, scriptId := range scriptIds {
+		if _, err := t.Page.RemoveScriptToEvaluateOnNewDocument(scriptId); err != nil {
+			return err
+		}
+	}
+
+	t.GetDOMChanges(nil)
+	t.OnSoftNavigation(nil)
+	t.watchLock.Lock()
+	t.watchers = make(map[string]ElementAppearFunc)
+	t.watchLock.Unlock()
+	if t.attachTab != nil {
+		t.OnPopup(nil)
+	}
+
+	t.restoreOpenTimeouts()
+	if _, err := t.navigate(context.Background(), "about:blank"); err != nil {
+		return err
+	}
+
+	for _, origin := range t.resetOrigins {
+		if _, err := t.storage().ClearDataForOrigin(origin, "all"); err != nil {
+			return err
+		}
+	}
+	t.ClearResources()
+	t.ClearErrors()
+	return nil
+}
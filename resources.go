@@ -0,0 +1,212 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// maximum number of resources tracked per page, protects long lived pages from growing forever.
+const maxTrackedResources = 5000
+
+// number of resources returned in ResourceReport.Largest
+const largestResourceCount = 10
+
+// ResourceStats holds the number of resources and bytes transferred for a resource type.
+type ResourceStats struct {
+	Count int     // number of resources that finished loading
+	Bytes float64 // encoded (over the wire) bytes
+}
+
+// Resource is a single resource loaded by the page.
+type Resource struct {
+	Url        string  // url of the resource
+	Type       string  // resource type as reported by chrome (Script, Stylesheet, Image, Font, XHR, Fetch...)
+	MimeType   string  // mime type of the response, empty if no response was received
+	Bytes      float64 // encoded (over the wire) bytes
+	ThirdParty bool    // was this resource served from a different site than the page, see ResourceReport
+}
+
+// ResourceReport summarizes the weight of the current page, suitable for enforcing performance budgets.
+// A resource is third party if the last two labels of its host differ from the page's, the public suffix
+// list is not consulted so hosts under multi label suffixes such as example.co.uk and other.co.uk, or
+// user.github.io and other.github.io, are considered the same site.
+type ResourceReport struct {
+	PageUrl         string                    // url of the page the report is for
+	Count           int                       // total number of resources that finished loading
+	Bytes           float64                   // total encoded bytes of all resources
+	ByType          map[string]*ResourceStats // resource type => stats
+	Largest         []*Resource               // the largest resources, in descending order of size
+	ThirdPartyCount int                       // number of resources served from other sites
+	ThirdPartyBytes float64                   // encoded bytes of resources served from other sites
+	ThirdPartyShare float64                   // ThirdPartyBytes / Bytes, 0 if nothing was loaded
+}
+
+// Type returns the stats for resourceType (Script, Stylesheet, Image, Font, XHR...), never nil.
+func (r *ResourceReport) Type(resourceType string) *ResourceStats {
+	if stats, ok := r.ByType[resourceType]; ok {
+		return stats
+	}
+	return &ResourceStats{}
+}
+
+// a resource seen on the network for the current page.
+type trackedResource struct {
+	Resource
+	finished bool
+	request  *NetworkRequest        // the request as it was sent, see GetCapturedRequest
+	headers  map[string]interface{} // response headers, nil until a response is received
+}
+
+// ResourceReport builds a report of every resource that finished loading since the last call
+// to Navigate (or ClearResources). Resources are only tracked while the Network domain is enabled,
+// which Navigate does automatically. Call after the page has loaded, and WaitStable if the page
+// loads resources lazily.
+func (t *Tab) ResourceReport() (*ResourceReport, error) {
+	pageUrl, err := t.GetCurrentUrl()
+	if err != nil {
+		return nil, err
+	}
+	pageSite := resourceSite(pageUrl)
+
+	report := &ResourceReport{PageUrl: pageUrl, ByType: make(map[string]*ResourceStats)}
+	resources := make([]*Resource, 0)
+
+	t.networkLock.RLock()
+	for _, requestId := range t.resourceOrder {
+		tracked := t.resources[requestId]
+		if !tracked.finished {
+			continue
+		}
+		resource := tracked.Resource
+		resources = append(resources, &resource)
+	}
+	t.networkLock.RUnlock()
+
+	for _, resource := range resources {
+		resource.ThirdParty = pageSite != "" && resourceSite(resource.Url) != "" && resourceSite(resource.Url) != pageSite
+
+		stats, ok := report.ByType[resource.Type]
+		if !ok {
+			stats = &ResourceStats{}
+			report.ByType[resource.Type] = stats
+		}
+		stats.Count++
+		stats.Bytes += resource.Bytes
+		report.Count++
+		report.Bytes += resource.Bytes
+
+		if resource.ThirdParty {
+			report.ThirdPartyCount++
+			report.ThirdPartyBytes += resource.Bytes
+		}
+	}
+
+	if report.Bytes > 0 {
+		report.ThirdPartyShare = report.ThirdPartyBytes / report.Bytes
+	}
+
+	sort.SliceStable(resources, func(i, j int) bool {
+		return resources[i].Bytes > resources[j].Bytes
+	})
+	if len(resources) > largestResourceCount {
+		resources = resources[:largestResourceCount]
+	}
+	report.Largest = resources
+	return report, nil
+}
+
+// ClearResources forgets all resources tracked for ResourceReport. Navigate calls this automatically,
+// call it manually before measuring navigations made by clicking links or submitting forms.
+func (t *Tab) ClearResources() {
+	t.networkLock.Lock()
+	t.resources = make(map[string]*trackedResource)
+	t.resourceOrder = make([]string, 0)
+	t.networkLock.Unlock()
+}
+
+// tracks a new request, redirects re-use the requestId so the url is simply updated.
+// caller must hold networkLock.
+func (t *Tab) trackResourceRequest(request *NetworkRequest) {
+	if request.Request == nil {
+		return
+	}
+	if tracked, ok := t.resources[request.RequestId]; ok {
+		tracked.Url = request.Request.Url
+		tracked.request = request
+		return
+	}
+	if len(t.resourceOrder) >= maxTrackedResources {
+		return
+	}
+	t.resources[request.RequestId] = &trackedResource{Resource: Resource{Url: request.Request.Url, Type: request.Type}, request: request}
+	t.resourceOrder = append(t.resourceOrder, request.RequestId)
+}
+
+// caller must hold networkLock.
+func (t *Tab) trackResourceResponse(response *NetworkResponse) {
+	tracked, ok := t.resources[response.RequestId]
+	if !ok {
+		return
+	}
+	if response.Type != "" {
+		tracked.Type = response.Type
+	}
+	if response.Response != nil {
+		tracked.Url = response.Response.Url
+		tracked.MimeType = response.Response.MimeType
+		tracked.headers = response.Response.Headers
+	}
+}
+
+// caller must hold networkLock.
+func (t *Tab) trackResourceFinished(requestId string, dataLength float64) {
+	if tracked, ok := t.resources[requestId]; ok {
+		tracked.Bytes = dataLength
+		tracked.finished = true
+	}
+}
+
+// returns an approximation of the registrable domain (last two labels) of rawurl's host, used to
+// decide if a resource is third party. IP addresses and single label hosts are returned as is. Without
+// the public suffix list, hosts under multi label suffixes (co.uk, github.io) all share a site.
+func resourceSite(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" || net.ParseIP(host) != nil {
+		return host
+	}
+	labels := strings.Split(strings.TrimSuffix(host, "."), ".")
+	if len(labels) <= 2 {
+		return strings.Join(labels, ".")
+	}
+	return strings.Join(labels[len(labels)-2:], ".")
+}
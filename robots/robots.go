@@ -0,0 +1,361 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+/*
+Package robots fetches, parses and caches robots.txt files so automation built on autogcd
+can honor a site's crawling policy. Rules are matched using the longest matching path
+(with support for the * and $ wildcards) where an Allow wins over a Disallow of equal length.
+*/
+package robots
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maximum robots.txt size we will read, anything after is ignored.
+const maxRobotsSize = 512 * 1024
+
+// FetchErr returned when the robots.txt file could not be retrieved.
+type FetchErr struct {
+	Message string
+}
+
+func (e *FetchErr) Error() string {
+	return "unable to fetch robots.txt: " + e.Message
+}
+
+// a single allow or disallow line
+type rule struct {
+	allow bool
+	path  string
+}
+
+// a group of rules for one or more user agents
+type group struct {
+	agents     []string
+	rules      []*rule
+	crawlDelay time.Duration
+}
+
+// Rules are the parsed contents of a robots.txt file.
+type Rules struct {
+	groups      []*group
+	disallowAll bool // set when the file was unavailable due to a server error
+	Sitemaps    []string
+}
+
+// Parse reads a robots.txt file, unknown directives and malformed lines are ignored.
+func Parse(r io.Reader) (*Rules, error) {
+	rules := &Rules{}
+	rules.groups = make([]*group, 0)
+	rules.Sitemaps = make([]string, 0)
+
+	var current *group
+	inAgents := false // are we reading consecutive user-agent lines
+
+	scanner := bufio.NewScanner(io.LimitReader(r, maxRobotsSize))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx != -1 {
+			line = line[:idx]
+		}
+		sep := strings.Index(line, ":")
+		if sep == -1 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:sep]))
+		value := strings.TrimSpace(line[sep+1:])
+
+		switch key {
+		case "user-agent":
+			if current == nil || !inAgents {
+				current = &group{agents: make([]string, 0), rules: make([]*rule, 0)}
+				rules.groups = append(rules.groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			inAgents = true
+		case "allow", "disallow":
+			inAgents = false
+			// rules before any user-agent line or empty disallows are meaningless
+			if current == nil || value == "" {
+				continue
+			}
+			current.rules = append(current.rules, &rule{allow: key == "allow", path: value})
+		case "crawl-delay":
+			inAgents = false
+			if current == nil {
+				continue
+			}
+			if delay, err := strconv.ParseFloat(value, 64); err == nil && delay > 0 {
+				current.crawlDelay = time.Duration(delay * float64(time.Second))
+			}
+		case "sitemap":
+			rules.Sitemaps = append(rules.Sitemaps, value)
+		default:
+			inAgents = false
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// Allowed returns true if userAgent may request the path (including any query string).
+func (r *Rules) Allowed(userAgent, path string) bool {
+	if r.disallowAll {
+		return false
+	}
+
+	if path == "" {
+		path = "/"
+	}
+
+	g := r.findGroup(userAgent)
+	if g == nil {
+		return true
+	}
+
+	var matched *rule
+	for _, rl := range g.rules {
+		if !matchPath(rl.path, path) {
+			continue
+		}
+		if matched == nil || len(rl.path) > len(matched.path) || (len(rl.path) == len(matched.path) && rl.allow) {
+			matched = rl
+		}
+	}
+	return matched == nil || matched.allow
+}
+
+// CrawlDelay returns the Crawl-delay for the userAgent, or 0 if none was specified.
+func (r *Rules) CrawlDelay(userAgent string) time.Duration {
+	if g := r.findGroup(userAgent); g != nil {
+		return g.crawlDelay
+	}
+	return 0
+}
+
+// finds the group with the most specific user agent match, falling back to *.
+func (r *Rules) findGroup(userAgent string) *group {
+	var found *group
+	var fallback *group
+	longest := 0
+	userAgent = strings.ToLower(userAgent)
+
+	for _, g := range r.groups {
+		for _, agent := range g.agents {
+			if agent == "*" {
+				if fallback == nil {
+					fallback = g
+				}
+				continue
+			}
+			if strings.Contains(userAgent, agent) && len(agent) > longest {
+				found = g
+				longest = len(agent)
+			}
+		}
+	}
+
+	if found != nil {
+		return found
+	}
+	return fallback
+}
+
+// matches path against a robots pattern supporting * and a trailing $.
+func matchPath(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+
+	parts := strings.Split(pattern, "*")
+	// the first part must be a prefix
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+
+	for i := 1; i < len(parts); i++ {
+		// an anchored pattern must end with the last part
+		if i == len(parts)-1 && anchored {
+			return len(path)-pos >= len(parts[i]) && strings.HasSuffix(path, parts[i])
+		}
+		idx := strings.Index(path[pos:], parts[i])
+		if idx == -1 {
+			return false
+		}
+		pos += idx + len(parts[i])
+	}
+
+	if anchored {
+		return pos == len(path)
+	}
+	return true
+}
+
+type cacheEntry struct {
+	rules   *Rules
+	fetched time.Time
+}
+
+// Cache fetches and caches robots.txt rules per origin. It is safe for concurrent use.
+type Cache struct {
+	lock      *sync.Mutex
+	client    *http.Client
+	userAgent string
+	ttl       time.Duration
+	entries   map[string]*cacheEntry // scheme://host => rules
+}
+
+// NewCache creates a cache that checks rules for the provided user agent. Fetched rules
+// are kept for 24 hours by default.
+func NewCache(userAgent string) *Cache {
+	c := &Cache{}
+	c.lock = &sync.Mutex{}
+	c.client = &http.Client{Timeout: 10 * time.Second}
+	c.userAgent = userAgent
+	c.ttl = 24 * time.Hour
+	c.entries = make(map[string]*cacheEntry)
+	return c
+}
+
+// SetClient to use a custom http client for fetching robots.txt files.
+func (c *Cache) SetClient(client *http.Client) {
+	c.client = client
+}
+
+// SetTTL for how long fetched rules are considered valid.
+func (c *Cache) SetTTL(ttl time.Duration) {
+	c.ttl = ttl
+}
+
+// Allowed returns true if the url may be requested. Only http and https urls are checked,
+// everything else (about:blank, data: etc) is always allowed.
+func (c *Cache) Allowed(rawurl string) (bool, error) {
+	return c.AllowedCtx(context.Background(), rawurl)
+}
+
+// AllowedCtx is Allowed which gives up fetching robots.txt when ctx is done.
+func (c *Cache) AllowedCtx(ctx context.Context, rawurl string) (bool, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return false, err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return true, nil
+	}
+
+	rules, err := c.RulesCtx(ctx, u.Scheme+"://"+u.Host)
+	if err != nil {
+		return false, err
+	}
+
+	path := u.EscapedPath()
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return rules.Allowed(c.userAgent, path), nil
+}
+
+// CrawlDelay returns the Crawl-delay for the origin of rawurl, or 0 if none was specified.
+func (c *Cache) CrawlDelay(rawurl string) (time.Duration, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return 0, err
+	}
+	rules, err := c.Rules(u.Scheme + "://" + u.Host)
+	if err != nil {
+		return 0, err
+	}
+	return rules.CrawlDelay(c.userAgent), nil
+}
+
+// Rules returns the (possibly cached) rules for the origin (scheme://host[:port]).
+func (c *Cache) Rules(origin string) (*Rules, error) {
+	return c.RulesCtx(context.Background(), origin)
+}
+
+// RulesCtx is Rules which gives up fetching robots.txt when ctx is done.
+func (c *Cache) RulesCtx(ctx context.Context, origin string) (*Rules, error) {
+	c.lock.Lock()
+	entry, ok := c.entries[origin]
+	c.lock.Unlock()
+
+	if ok && time.Now().Sub(entry.fetched) < c.ttl {
+		return entry.rules, nil
+	}
+
+	rules, cacheable, err := c.fetch(ctx, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheable {
+		c.lock.Lock()
+		c.entries[origin] = &cacheEntry{rules: rules, fetched: time.Now()}
+		c.lock.Unlock()
+	}
+	return rules, nil
+}
+
+// fetches robots.txt. A missing file (4xx) allows everything while a server error
+// disallows everything but is not cached so we try again next time.
+func (c *Cache) fetch(ctx context.Context, origin string) (*Rules, bool, error) {
+	req, err := http.NewRequest("GET", origin+"/robots.txt", nil)
+	if err != nil {
+		return nil, false, &FetchErr{Message: err.Error()}
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, false, &FetchErr{Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return &Rules{disallowAll: true}, false, nil
+	case resp.StatusCode >= 400:
+		io.Copy(ioutil.Discard, resp.Body)
+		return &Rules{}, true, nil
+	}
+
+	rules, err := Parse(resp.Body)
+	if err != nil {
+		return nil, false, &FetchErr{Message: err.Error()}
+	}
+	return rules, true, nil
+}
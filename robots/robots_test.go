@@ -0,0 +1,128 @@
+package robots
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testRobots = `# comment
+User-agent: *
+Disallow: /private/
+Allow: /private/public
+Disallow: /*.php$
+Crawl-delay: 2
+
+User-agent: autogcd
+User-agent: otherbot
+Disallow: /
+
+Sitemap: http://localhost/sitemap.xml
+`
+
+func TestParseAllowed(t *testing.T) {
+	rules, err := Parse(strings.NewReader(testRobots))
+	if err != nil {
+		t.Fatalf("error parsing: %s\n", err)
+	}
+
+	cases := []struct {
+		agent   string
+		path    string
+		allowed bool
+	}{
+		{"somebot", "/", true},
+		{"somebot", "/private/", false},
+		{"somebot", "/private/secret", false},
+		{"somebot", "/private/public", true},
+		{"somebot", "/index.php", false},
+		{"somebot", "/index.php?x=1", true},
+		{"autogcd/1.0", "/", false},
+		{"OtherBot", "/anything", false},
+	}
+
+	for _, c := range cases {
+		if got := rules.Allowed(c.agent, c.path); got != c.allowed {
+			t.Fatalf("%s %s expected allowed: %t got %t\n", c.agent, c.path, c.allowed, got)
+		}
+	}
+
+	if delay := rules.CrawlDelay("somebot"); delay != 2*time.Second {
+		t.Fatalf("expected crawl delay of 2s got %s\n", delay)
+	}
+
+	if len(rules.Sitemaps) != 1 || rules.Sitemaps[0] != "http://localhost/sitemap.xml" {
+		t.Fatalf("expected sitemap got %v\n", rules.Sitemaps)
+	}
+}
+
+func TestCacheAllowed(t *testing.T) {
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		fetches++
+		fmt.Fprint(w, testRobots)
+	}))
+	defer server.Close()
+
+	cache := NewCache("testbot")
+	for i := 0; i < 2; i++ {
+		allowed, err := cache.Allowed(server.URL + "/private/secret")
+		if err != nil {
+			t.Fatalf("error checking robots: %s\n", err)
+		}
+		if allowed {
+			t.Fatalf("expected /private/secret to be disallowed")
+		}
+	}
+
+	if fetches != 1 {
+		t.Fatalf("expected robots.txt to be fetched once got %d\n", fetches)
+	}
+
+	if allowed, _ := cache.Allowed("about:blank"); !allowed {
+		t.Fatalf("expected non http urls to always be allowed")
+	}
+}
+
+func TestCacheMissingRobots(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	cache := NewCache("testbot")
+	allowed, err := cache.Allowed(server.URL + "/anything")
+	if err != nil {
+		t.Fatalf("error checking robots: %s\n", err)
+	}
+	if !allowed {
+		t.Fatalf("expected missing robots.txt to allow everything")
+	}
+}
+
+func TestCacheAllowedCtx(t *testing.T) {
+	releaseCh := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-releaseCh
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	defer close(releaseCh)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := NewCache("testbot").AllowedCtx(ctx, server.URL+"/anything"); err == nil {
+		t.Fatalf("expected an error once the context expired")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected fetching robots.txt to stop with the context, took %s\n", elapsed)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// GetByRole returns the first element in the tab whose accessibility role (e.g. "button", "link",
+// "textbox", "checkbox") is role and whose accessible name is accessibleName. The name is what
+// screen readers announce, usually the text, label or aria-label of the control. Names are compared
+// ignoring case and extra whitespace, an empty accessibleName matches any name.
+func (t *Tab) GetByRole(role, accessibleName string) (*Element, error) {
+	elements, err := t.GetAllByRole(role, accessibleName)
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) == 0 {
+		return nil, &ElementNotFoundErr{Message: fmt.Sprintf("with role %s and name %q", role, accessibleName)}
+	}
+	return elements[0], nil
+}
+
+// GetAllByRole returns every element matching role and accessibleName, see GetByRole, in the order of
+// the accessibility tree.
+func (t *Tab) GetAllByRole(role, accessibleName string) ([]*Element, error) {
+	nodes, err := t.Accessibility.GetFullAXTree()
+	if err != nil {
+		return nil, err
+	}
+
+	name := normalizeAXName(accessibleName)
+	backendIds := make([]int, 0)
+	for _, node := range nodes {
+		if node.Ignored || node.BackendDOMNodeId == 0 || axValueString(node.Role) != role {
+			continue
+		}
+		if name != "" && normalizeAXName(axValueString(node.Name)) != name {
+			continue
+		}
+		backendIds = append(backendIds, node.BackendDOMNodeId)
+	}
+
+	elements := make([]*Element, 0, len(backendIds))
+	if len(backendIds) == 0 {
+		return elements, nil
+	}
+
+	nodeIds, err := t.DOM.PushNodesByBackendIdsToFrontend(backendIds)
+	if err != nil {
+		return nil, err
+	}
+	for _, nodeId := range nodeIds {
+		if nodeId == 0 {
+			continue
+		}
+		ele, _ := t.GetElementByNodeId(nodeId)
+		elements = append(elements, ele)
+	}
+	return elements, nil
+}
+
+func axValueString(value *gcdapi.AccessibilityAXValue) string {
+	if value == nil {
+		return ""
+	}
+	str, _ := value.Value.(string)
+	return str
+}
+
+func normalizeAXName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
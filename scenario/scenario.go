@@ -0,0 +1,389 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+/*
+Package scenario runs several scripted personas against the same site at the same time, each with its
+own tab, for testing applications where users interact, such as chat or collaborative editing:
+
+	s := scenario.New(auto)
+	s.AddPersona("alice", func(a *scenario.Actor) error {
+		if _, err := a.Tab.Navigate(chatUrl); err != nil {
+			return err
+		}
+		if err := a.Wait("joined"); err != nil {
+			return err
+		}
+		// send a message, then let bob check it arrived
+		a.Publish("sent", "hello bob")
+		return a.Wait("received")
+	})
+	s.AddPersona("bob", func(a *scenario.Actor) error {
+		...
+	})
+	result, err := s.Run()
+
+Personas synchronize with named barriers (Wait) and exchange values (Publish and Await). Assertions made by
+any persona are collected into the shared Result. If a persona's script fails every other persona blocked in
+Wait or Await is released with an AbortedErr so the scenario cannot deadlock.
+*/
+package scenario
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wirepair/autogcd"
+)
+
+// default time a persona waits at a barrier or for a published value.
+const DefaultWaitTimeout = 30 * time.Second
+
+// AbortedErr returned from Wait and Await when another persona failed.
+type AbortedErr struct {
+	Message string
+}
+
+func (e *AbortedErr) Error() string {
+	return "scenario aborted: " + e.Message
+}
+
+// TimeoutErr returned from Wait and Await when the other personas did not arrive in time.
+type TimeoutErr struct {
+	Message string
+}
+
+func (e *TimeoutErr) Error() string {
+	return "Timed out " + e.Message
+}
+
+// FailedErr returned by Run when a persona failed or an assertion did not hold.
+type FailedErr struct {
+	Result *Result
+}
+
+func (e *FailedErr) Error() string {
+	messages := make([]string, 0)
+	for _, name := range e.Result.order {
+		if err := e.Result.Errors[name]; err != nil {
+			messages = append(messages, name+": "+err.Error())
+		}
+	}
+	for _, failure := range e.Result.Failures {
+		messages = append(messages, failure.String())
+	}
+	return "scenario failed: " + strings.Join(messages, "; ")
+}
+
+// ScriptFunc drives a single persona, returning an error stops the persona and aborts the scenario.
+type ScriptFunc func(a *Actor) error
+
+// Persona is a named user of the scenario.
+type Persona struct {
+	Name    string              // unique name of the persona, used in results and assertions
+	Script  ScriptFunc          // the steps this persona performs
+	Options []autogcd.TabOption // options for the persona's tab
+}
+
+// Failure is an assertion which did not hold.
+type Failure struct {
+	Persona string    // the persona which made the assertion
+	Message string    // description of the failure
+	Time    time.Time // when the assertion was made
+}
+
+func (f *Failure) String() string {
+	return f.Persona + ": " + f.Message
+}
+
+// Result of a scenario run.
+type Result struct {
+	Duration time.Duration    // how long the scenario ran
+	Errors   map[string]error // error returned by each persona's script, nil if it succeeded
+	Failures []*Failure       // failed assertions from all personas, in the order they were made
+	order    []string
+}
+
+// Passed returns true if every persona succeeded and every assertion held.
+func (r *Result) Passed() bool {
+	if len(r.Failures) > 0 {
+		return false
+	}
+	for _, err := range r.Errors {
+		if err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// one use synchronization point, released once every running persona arrived.
+type barrier struct {
+	arrived map[string]struct{}
+	release chan struct{}
+}
+
+// a value published by a persona, ready is closed once it is set.
+type published struct {
+	value interface{}
+	ready chan struct{}
+}
+
+// opens a tab for a persona and returns the function to close it.
+type openTabFunc func(persona *Persona) (*autogcd.Tab, func(), error)
+
+// Scenario runs personas concurrently.
+type Scenario struct {
+	WaitTimeout time.Duration // maximum time to wait in Wait and Await, defaults to DefaultWaitTimeout
+
+	openTab  openTabFunc
+	personas []*Persona
+
+	lock      sync.Mutex
+	running   map[string]struct{} // personas whose script has not returned
+	barriers  map[string]*barrier
+	values    map[string]*published
+	failures  []*Failure
+	abortCh   chan struct{}
+	abortErr  string
+	abortOnce sync.Once
+}
+
+// New creates a scenario opening a new tab in auto for each persona. The tabs are closed once the
+// scenario finishes.
+func New(auto *autogcd.AutoGcd) *Scenario {
+	s := &Scenario{WaitTimeout: DefaultWaitTimeout}
+	s.openTab = func(persona *Persona) (*autogcd.Tab, func(), error) {
+		tab, err := auto.NewTabWithOptions(persona.Options...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tab, func() { auto.CloseTab(tab) }, nil
+	}
+	return s
+}
+
+// AddPersona adds a persona running script in its own tab created with opts.
+func (s *Scenario) AddPersona(name string, script ScriptFunc, opts ...autogcd.TabOption) {
+	s.personas = append(s.personas, &Persona{Name: name, Script: script, Options: opts})
+}
+
+// Run starts every persona concurrently and waits for all of them to finish. Returns a FailedErr
+// along with the result if any persona failed or any assertion did not hold. A scenario may only be run once.
+func (s *Scenario) Run() (*Result, error) {
+	result := &Result{Errors: make(map[string]error, len(s.personas))}
+	names := make(map[string]struct{}, len(s.personas))
+	for _, persona := range s.personas {
+		if _, ok := names[persona.Name]; ok {
+			return nil, fmt.Errorf("duplicate persona name %s", persona.Name)
+		}
+		names[persona.Name] = struct{}{}
+		result.order = append(result.order, persona.Name)
+	}
+
+	s.running = names
+	s.barriers = make(map[string]*barrier)
+	s.values = make(map[string]*published)
+	s.abortCh = make(chan struct{})
+
+	start := time.Now()
+	errs := make([]error, len(s.personas))
+	var wg sync.WaitGroup
+	for i, persona := range s.personas {
+		wg.Add(1)
+		go func(i int, persona *Persona) {
+			defer wg.Done()
+			errs[i] = s.runPersona(persona)
+		}(i, persona)
+	}
+	wg.Wait()
+
+	result.Duration = time.Now().Sub(start)
+	for i, persona := range s.personas {
+		result.Errors[persona.Name] = errs[i]
+	}
+	s.lock.Lock()
+	result.Failures = append(result.Failures, s.failures...)
+	s.lock.Unlock()
+
+	if !result.Passed() {
+		return result, &FailedErr{Result: result}
+	}
+	return result, nil
+}
+
+// opens the persona's tab and runs its script, recovering from panics so a single persona can not
+// take down the other personas.
+func (s *Scenario) runPersona(persona *Persona) (err error) {
+	defer s.finished(persona.Name)
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+		if err != nil {
+			s.abort(persona.Name + " failed: " + err.Error())
+		}
+	}()
+
+	tab, closeTab, err := s.openTab(persona)
+	if err != nil {
+		return err
+	}
+	defer closeTab()
+
+	return persona.Script(&Actor{Name: persona.Name, Tab: tab, scenario: s})
+}
+
+// aborts the scenario, releasing every waiting persona.
+func (s *Scenario) abort(reason string) {
+	s.abortOnce.Do(func() {
+		s.lock.Lock()
+		s.abortErr = reason
+		s.lock.Unlock()
+		close(s.abortCh)
+	})
+}
+
+// removes a persona from the running set, it no longer has to arrive at barriers.
+func (s *Scenario) finished(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.running, name)
+	for _, b := range s.barriers {
+		s.releaseIfComplete(b)
+	}
+}
+
+// releases the barrier if every running persona has arrived, must be called with the lock held.
+func (s *Scenario) releaseIfComplete(b *barrier) {
+	select {
+	case <-b.release:
+		return
+	default:
+	}
+	for name := range s.running {
+		if _, ok := b.arrived[name]; !ok {
+			return
+		}
+	}
+	close(b.release)
+}
+
+// waits for ch to be closed, the scenario to abort or the timeout.
+func (s *Scenario) waitOn(ch chan struct{}, what string) error {
+	timer := time.NewTimer(s.WaitTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-ch:
+		return nil
+	case <-s.abortCh:
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		return &AbortedErr{Message: s.abortErr}
+	case <-timer.C:
+		return &TimeoutErr{Message: what}
+	}
+}
+
+// Actor is a persona's view of a running scenario.
+type Actor struct {
+	Name     string       // the persona's name
+	Tab      *autogcd.Tab // the persona's own tab
+	scenario *Scenario
+}
+
+// Wait blocks until every persona which is still running has called Wait with the same name. Each
+// barrier name releases once, use a new name for every synchronization point.
+func (a *Actor) Wait(name string) error {
+	s := a.scenario
+	s.lock.Lock()
+	b, ok := s.barriers[name]
+	if !ok {
+		b = &barrier{arrived: make(map[string]struct{}), release: make(chan struct{})}
+		s.barriers[name] = b
+	}
+	b.arrived[a.Name] = struct{}{}
+	s.releaseIfComplete(b)
+	s.lock.Unlock()
+
+	return s.waitOn(b.release, "waiting at barrier "+name)
+}
+
+// Publish makes value available to every persona under key. Publishing the same key twice
+// keeps the first value.
+func (a *Actor) Publish(key string, value interface{}) {
+	s := a.scenario
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	p := s.published(key)
+	select {
+	case <-p.ready:
+		return
+	default:
+	}
+	p.value = value
+	close(p.ready)
+}
+
+// Await waits for a value to be published under key by any persona.
+func (a *Actor) Await(key string) (interface{}, error) {
+	s := a.scenario
+	s.lock.Lock()
+	p := s.published(key)
+	s.lock.Unlock()
+
+	if err := s.waitOn(p.ready, "waiting for "+key); err != nil {
+		return nil, err
+	}
+	return p.value, nil
+}
+
+// returns the published entry for key, creating it if required. Must be called with the lock held.
+func (s *Scenario) published(key string) *published {
+	p, ok := s.values[key]
+	if !ok {
+		p = &published{ready: make(chan struct{})}
+		s.values[key] = p
+	}
+	return p
+}
+
+// Assert records a failure in the shared result if condition is false, the script continues running.
+// Returns the condition so scripts may stop if required.
+func (a *Actor) Assert(condition bool, format string, args ...interface{}) bool {
+	if !condition {
+		a.Fail(format, args...)
+	}
+	return condition
+}
+
+// Fail records a failure in the shared result.
+func (a *Actor) Fail(format string, args ...interface{}) {
+	s := a.scenario
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.failures = append(s.failures, &Failure{Persona: a.Name, Message: fmt.Sprintf(format, args...), Time: time.Now()})
+}
@@ -0,0 +1,115 @@
+package scenario
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wirepair/autogcd"
+)
+
+// creates a scenario which does not require chrome, personas get a nil tab.
+func testScenario() *Scenario {
+	s := &Scenario{WaitTimeout: 2 * time.Second}
+	s.openTab = func(persona *Persona) (*autogcd.Tab, func(), error) {
+		return nil, func() {}, nil
+	}
+	return s
+}
+
+func TestScenarioBarrier(t *testing.T) {
+	s := testScenario()
+	var arrived int32
+	script := func(a *Actor) error {
+		atomic.AddInt32(&arrived, 1)
+		if err := a.Wait("start"); err != nil {
+			return err
+		}
+		a.Assert(atomic.LoadInt32(&arrived) == 3, "expected all personas to arrive before release got %d", atomic.LoadInt32(&arrived))
+		return nil
+	}
+	s.AddPersona("alice", script)
+	s.AddPersona("bob", script)
+	s.AddPersona("carol", script)
+
+	result, err := s.Run()
+	if err != nil {
+		t.Fatalf("error running scenario: %s\n", err)
+	}
+	if !result.Passed() || len(result.Errors) != 3 {
+		t.Fatalf("expected scenario to pass %#v\n", result)
+	}
+}
+
+func TestScenarioPublishAwait(t *testing.T) {
+	s := testScenario()
+	s.AddPersona("alice", func(a *Actor) error {
+		a.Publish("message", "hello bob")
+		return a.Wait("done")
+	})
+	s.AddPersona("bob", func(a *Actor) error {
+		value, err := a.Await("message")
+		if err != nil {
+			return err
+		}
+		a.Assert(value == "hello", "expected hello got %v", value)
+		return a.Wait("done")
+	})
+
+	result, err := s.Run()
+	if _, ok := err.(*FailedErr); !ok {
+		t.Fatalf("expected FailedErr got %v\n", err)
+	}
+	if len(result.Failures) != 1 || result.Failures[0].Persona != "bob" {
+		t.Fatalf("expected a single failure from bob got %#v\n", result.Failures)
+	}
+}
+
+func TestScenarioAbort(t *testing.T) {
+	s := testScenario()
+	s.WaitTimeout = time.Minute
+	s.AddPersona("alice", func(a *Actor) error {
+		return errors.New("could not log in")
+	})
+	s.AddPersona("bob", func(a *Actor) error {
+		_, err := a.Await("never")
+		return err
+	})
+
+	start := time.Now()
+	result, err := s.Run()
+	if err == nil {
+		t.Fatalf("expected error running scenario")
+	}
+	if time.Now().Sub(start) > 10*time.Second {
+		t.Fatalf("bob was not released when alice failed")
+	}
+	if _, ok := result.Errors["bob"].(*AbortedErr); !ok {
+		t.Fatalf("expected bob to be aborted got %v\n", result.Errors["bob"])
+	}
+}
+
+func TestScenarioFinishedPersonaReleasesBarrier(t *testing.T) {
+	s := testScenario()
+	s.AddPersona("alice", func(a *Actor) error {
+		return nil
+	})
+	s.AddPersona("bob", func(a *Actor) error {
+		return a.Wait("alice is gone")
+	})
+	if _, err := s.Run(); err != nil {
+		t.Fatalf("error running scenario: %s\n", err)
+	}
+}
+
+func TestScenarioPanic(t *testing.T) {
+	s := testScenario()
+	s.AddPersona("alice", func(a *Actor) error {
+		panic("boom")
+	})
+	result, err := s.Run()
+	if err == nil || result.Errors["alice"] == nil {
+		t.Fatalf("expected panic to be reported as an error")
+	}
+}
@@ -0,0 +1,149 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// Rect is a rectangle of the page in CSS pixels, X and Y are relative to the top left of the document
+// rather than the visible viewport.
+type Rect struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+// ScreenshotRegion captures a png of rect, which may extend beyond the visible viewport.
+func (t *Tab) ScreenshotRegion(rect Rect) ([]byte, error) {
+	if rect.Width <= 0 || rect.Height <= 0 {
+		return nil, &InvalidDimensionsErr{Message: fmt.Sprintf("region %gx%g must have a positive width and height", rect.Width, rect.Height)}
+	}
+	return t.captureClip(&gcdapi.PageViewport{X: rect.X, Y: rect.Y, Width: rect.Width, Height: rect.Height, Scale: 1})
+}
+
+// ScreenshotFrame captures a png of the area of the page the frame's (i)frame element occupies, for
+// capturing widgets or adverts. Passing the top frame id captures the full page.
+func (t *Tab) ScreenshotFrame(frameId string) ([]byte, error) {
+	if frameId == t.GetTopFrameId() {
+		return t.GetFullPageScreenShot()
+	}
+
+	backendNodeId, _, err := t.DOM.GetFrameOwner(frameId)
+	if err != nil {
+		return nil, err
+	}
+
+	rect, err := t.backendNodeRect(backendNodeId)
+	if err != nil {
+		return nil, err
+	}
+	return t.ScreenshotRegion(rect)
+}
+
+// returns the border box of the node in document coordinates.
+func (t *Tab) backendNodeRect(backendNodeId int) (Rect, error) {
+	box, err := t.DOM.GetBoxModelWithParams(&gcdapi.DOMGetBoxModelParams{BackendNodeId: backendNodeId})
+	if err != nil {
+		return Rect{}, err
+	}
+
+	// box models are relative to the viewport, shift by the scroll position
+	layout, _, _, err := t.Page.GetLayoutMetrics()
+	if err != nil {
+		return Rect{}, err
+	}
+
+	rect, err := quadBounds(box.Border)
+	if err != nil {
+		return Rect{}, err
+	}
+	rect.X += float64(layout.PageX)
+	rect.Y += float64(layout.PageY)
+	return rect, nil
+}
+
+// the bounding rectangle of a quad's points
+func quadBounds(points []float64) (Rect, error) {
+	if len(points) < 8 || len(points)%2 != 0 {
+		return Rect{}, &InvalidDimensionsErr{Message: fmt.Sprintf("expected a quad of 8 points got %d", len(points))}
+	}
+	minX, minY := points[0], points[1]
+	maxX, maxY := minX, minY
+	for i := 2; i < len(points); i += 2 {
+		if points[i] < minX {
+			minX = points[i]
+		}
+		if points[i] > maxX {
+			maxX = points[i]
+		}
+		if points[i+1] < minY {
+			minY = points[i+1]
+		}
+		if points[i+1] > maxY {
+			maxY = points[i+1]
+		}
+	}
+	return Rect{X: minX, Y: minY, Width: maxX - minX, Height: maxY - minY}, nil
+}
+
+// captures a png of clip from the compositor surface so regions outside the viewport are included.
+func (t *Tab) captureClip(clip *gcdapi.PageViewport) ([]byte, error) {
+	params := &gcdapi.PageCaptureScreenshotParams{
+		Format:      "png",
+		Clip:        clip,
+		FromSurface: true,
+	}
+
+	img, err := t.Page.CaptureScreenshotWithParams(params)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(img)
+}
+
+// Screenshot scrolls the element into view and captures a png of its border box. Returns an
+// InvalidDimensionsErr if the element is not rendered or has no size.
+func (e *Element) Screenshot() ([]byte, error) {
+	backendNodeId, err := e.BackendNodeId()
+	if err != nil {
+		return nil, err
+	}
+
+	// lazily rendered content and sticky headers depend on the element being in view
+	if _, err := e.callFunction(`function() { this.scrollIntoView({block: 'center', inline: 'center'}); }`); err != nil {
+		return nil, err
+	}
+
+	rect, err := e.tab.backendNodeRect(backendNodeId)
+	if err != nil {
+		return nil, err
+	}
+	return e.tab.ScreenshotRegion(rect)
+}
@@ -0,0 +1,19 @@
+package autogcd
+
+import (
+	"testing"
+)
+
+func TestQuadBounds(t *testing.T) {
+	rect, err := quadBounds([]float64{10, 20, 110, 20, 110, 70, 10, 70})
+	if err != nil {
+		t.Fatalf("error getting bounds: %s\n", err)
+	}
+	if rect != (Rect{X: 10, Y: 20, Width: 100, Height: 50}) {
+		t.Fatalf("unexpected bounds: %#v\n", rect)
+	}
+
+	if _, err := quadBounds([]float64{1, 2, 3}); err == nil {
+		t.Fatalf("expected error for invalid quad")
+	}
+}
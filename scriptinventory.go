@@ -0,0 +1,155 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"net/url"
+	"sort"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// Collects the integrity and crossorigin attributes of every external script in the document.
+const scriptAttributesScript = `(function() {
+	var scripts = {};
+	var elements = document.querySelectorAll('script[src]');
+	for (var i = 0; i < elements.length; i++) {
+		scripts[elements[i].src] = {integrity: elements[i].integrity || '', crossOrigin: elements[i].getAttribute('crossorigin') || ''};
+	}
+	return scripts;
+})()`
+
+// ScriptEntry is an external script loaded by the page, see ScriptInventory.
+type ScriptEntry struct {
+	Url          string  // url of the script
+	Origin       string  // scheme://host[:port] the script was served from
+	ThirdParty   bool    // served from a different site than the page
+	InDocument   bool    // referenced by a script element in the top level document, false for scripts injected into frames or loaded by workers
+	HasIntegrity bool    // the script element has a Subresource Integrity attribute
+	Integrity    string  // the integrity attribute, such as sha384-...
+	CrossOrigin  string  // the crossorigin attribute, integrity checks on other origins require anonymous or use-credentials
+	Bytes        float64 // encoded bytes from the network log, or the decoded size from the resource tree if the request was not seen
+}
+
+// ScriptInventory lists every script the page and its frames loaded, combining the resource tree, the
+// network log kept since the last Navigate and the top level document's script elements. Scripts are
+// sorted by url. Use it to find third party scripts loaded without Subresource Integrity.
+func (t *Tab) ScriptInventory() ([]*ScriptEntry, error) {
+	pageUrl, err := t.GetCurrentUrl()
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := t.Page.GetResourceTree()
+	if err != nil {
+		return nil, err
+	}
+	treeScripts := make([]*gcdapi.PageFrameResource, 0)
+	collectTreeScripts(tree, &treeScripts)
+
+	networkScripts := make([]Resource, 0)
+	t.networkLock.RLock()
+	for _, requestId := range t.resourceOrder {
+		if tracked := t.resources[requestId]; tracked.Type == "Script" {
+			networkScripts = append(networkScripts, tracked.Resource)
+		}
+	}
+	t.networkLock.RUnlock()
+
+	rro, err := t.evaluateScript(scriptAttributesScript, false)
+	if err != nil {
+		return nil, err
+	}
+	attributes, _ := rro.Value.(map[string]interface{})
+
+	return buildScriptInventory(pageUrl, treeScripts, networkScripts, attributes), nil
+}
+
+// appends the script resources of tree and its child frames to scripts.
+func collectTreeScripts(tree *gcdapi.PageFrameResourceTree, scripts *[]*gcdapi.PageFrameResource) {
+	if tree == nil {
+		return
+	}
+	for _, resource := range tree.Resources {
+		if resource.Type == "Script" && !resource.Failed && !resource.Canceled {
+			*scripts = append(*scripts, resource)
+		}
+	}
+	for _, child := range tree.ChildFrames {
+		collectTreeScripts(child, scripts)
+	}
+}
+
+// merges the scripts seen in the resource tree, the network log and the document's script elements by url.
+func buildScriptInventory(pageUrl string, treeScripts []*gcdapi.PageFrameResource, networkScripts []Resource, attributes map[string]interface{}) []*ScriptEntry {
+	pageSite := resourceSite(pageUrl)
+	entries := make(map[string]*ScriptEntry)
+	entry := func(scriptUrl string) *ScriptEntry {
+		if script, ok := entries[scriptUrl]; ok {
+			return script
+		}
+		script := &ScriptEntry{Url: scriptUrl, Origin: scriptOrigin(scriptUrl)}
+		script.ThirdParty = pageSite != "" && resourceSite(scriptUrl) != "" && resourceSite(scriptUrl) != pageSite
+		entries[scriptUrl] = script
+		return script
+	}
+
+	for _, resource := range networkScripts {
+		script := entry(resource.Url)
+		if resource.Bytes > script.Bytes {
+			script.Bytes = resource.Bytes
+		}
+	}
+	for _, resource := range treeScripts {
+		script := entry(resource.Url)
+		if script.Bytes == 0 {
+			script.Bytes = resource.ContentSize
+		}
+	}
+	for scriptUrl, value := range attributes {
+		values, _ := value.(map[string]interface{})
+		script := entry(scriptUrl)
+		script.InDocument = true
+		script.Integrity, _ = values["integrity"].(string)
+		script.CrossOrigin, _ = values["crossOrigin"].(string)
+		script.HasIntegrity = script.Integrity != ""
+	}
+
+	inventory := make([]*ScriptEntry, 0, len(entries))
+	for _, script := range entries {
+		inventory = append(inventory, script)
+	}
+	sort.Slice(inventory, func(i, j int) bool { return inventory[i].Url < inventory[j].Url })
+	return inventory
+}
+
+// returns the scheme://host[:port] of rawurl, empty for urls without a host such as data: and blob:.
+func scriptOrigin(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,32 @@
+package autogcd
+
+import (
+	"testing"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+func TestBuildScriptInventory(t *testing.T) {
+	treeScripts := []*gcdapi.PageFrameResource{
+		{Url: "https://example.com/app.js", Type: "Script", ContentSize: 300},
+		{Url: "https://cdn.other.net/lib.js", Type: "Script", ContentSize: 900},
+	}
+	networkScripts := []Resource{{Url: "https://example.com/app.js", Type: "Script", Bytes: 120}}
+	attributes := map[string]interface{}{
+		"https://example.com/app.js":   map[string]interface{}{"integrity": "", "crossOrigin": ""},
+		"https://cdn.other.net/lib.js": map[string]interface{}{"integrity": "sha384-abc", "crossOrigin": "anonymous"},
+	}
+
+	inventory := buildScriptInventory("https://www.example.com/", treeScripts, networkScripts, attributes)
+	if len(inventory) != 2 {
+		t.Fatalf("expected 2 scripts got %d\n", len(inventory))
+	}
+
+	lib, app := inventory[0], inventory[1]
+	if lib.Url != "https://cdn.other.net/lib.js" || !lib.ThirdParty || !lib.HasIntegrity || lib.CrossOrigin != "anonymous" || lib.Bytes != 900 || lib.Origin != "https://cdn.other.net" {
+		t.Fatalf("unexpected third party script %#v\n", lib)
+	}
+	if app.ThirdParty || app.HasIntegrity || !app.InDocument || app.Bytes != 120 {
+		t.Fatalf("unexpected first party script %#v\n", app)
+	}
+}
@@ -0,0 +1,96 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/wirepair/autogcd/sourcemap"
+	"github.com/wirepair/gcd"
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// fetches source maps for GetScriptSourceMap, so a slow source map host can not hang the caller
+var sourceMapClient = &http.Client{Timeout: 30 * time.Second}
+
+// ScriptInfo describes a script parsed by the page's JavaScript engine.
+type ScriptInfo struct {
+	ScriptId           string // id to pass to GetScriptSource
+	Url                string // url or name of the script, empty for inline scripts without a sourceURL
+	Hash               string // content hash of the script
+	SourceMapURL       string // url of the source map associated with the script (if any)
+	ExecutionContextId int    // context the script was created in
+	StartLine          int    // line offset of the script within the resource (for script tags)
+	StartColumn        int    // column offset of the script within the resource
+	Length             int    // length of the script
+	IsModule           bool   // is this script an ES6 module
+}
+
+// ListenScriptsParsed calls scriptFn for every script parsed from now on, including eval'd and inline
+// scripts. Scripts already parsed before this was called are not reported.
+func (t *Tab) ListenScriptsParsed(scriptFn ScriptParsedFunc) {
+	t.Subscribe("Debugger.scriptParsed", func(target *gcd.ChromeTarget, payload []byte) {
+		message := &gcdapi.DebuggerScriptParsedEvent{}
+		if err := json.Unmarshal(payload, message); err != nil {
+			return
+		}
+		p := message.Params
+		scriptFn(t, &ScriptInfo{ScriptId: p.ScriptId, Url: p.Url, Hash: p.Hash, SourceMapURL: p.SourceMapURL, ExecutionContextId: p.ExecutionContextId, StartLine: p.StartLine, StartColumn: p.StartColumn, Length: p.Length, IsModule: p.IsModule})
+	})
+}
+
+// StopScriptsParsed stops calling the handler set by ListenScriptsParsed.
+func (t *Tab) StopScriptsParsed() {
+	t.Unsubscribe("Debugger.scriptParsed")
+}
+
+// GetScriptSourceMap fetches and parses the source map of a script. Relative source map urls are resolved
+// against the script's url, data: urls are decoded. Returns a sourcemap.FetchErr if the script has no source map.
+func (t *Tab) GetScriptSourceMap(script *ScriptInfo) (*sourcemap.Map, error) {
+	return sourcemap.Fetch(sourceMapClient, script.Url, script.SourceMapURL)
+}
+
+// GetOriginalSources returns the original sources embedded in a script's source map, keyed by source url.
+// If the script has no source map, or the map does not embed its sources, the generated source is returned
+// keyed by the script's url.
+func (t *Tab) GetOriginalSources(script *ScriptInfo) (map[string]string, error) {
+	if script.SourceMapURL != "" {
+		sourceMap, err := t.GetScriptSourceMap(script)
+		if err != nil {
+			return nil, err
+		}
+		if sources := sourceMap.SourceContents(); len(sources) > 0 {
+			return sources, nil
+		}
+	}
+
+	source, err := t.GetScriptSource(script.ScriptId)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{script.Url: source}, nil
+}
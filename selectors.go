@@ -0,0 +1,118 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+// Defines selectorFor(node) and xpathFor(node) which compute a selector matching only node in its document.
+// Preference is given to a unique id, then unique test and form attributes, falling back to the
+// position of the node amongst its siblings from the closest ancestor with a unique id.
+const selectorFunctions = `function cssEscape(value) {
+		return window.CSS && CSS.escape ? CSS.escape(value) : value.replace(/([^\w-])/g, '\\$1');
+	}
+	function unique(selector, root) {
+		try {
+			return (root || document).querySelectorAll(selector).length === 1;
+		} catch (e) {
+			return false;
+		}
+	}
+	function selectorFor(node) {
+		var root = node.getRootNode ? node.getRootNode() : document;
+		if (node.id && unique('#' + cssEscape(node.id), root)) {
+			return '#' + cssEscape(node.id);
+		}
+		var tag = node.nodeName.toLowerCase();
+		var attrs = ['data-testid', 'data-test', 'name', 'aria-label', 'placeholder'];
+		for (var i = 0; i < attrs.length; i++) {
+			var value = node.getAttribute(attrs[i]);
+			if (value) {
+				var selector = tag + '[' + attrs[i] + '=' + JSON.stringify(value) + ']';
+				if (unique(selector, root)) {
+					return selector;
+				}
+			}
+		}
+		var parts = [];
+		while (node && node.nodeType === 1 && node !== document.documentElement) {
+			if (node.id && unique('#' + cssEscape(node.id), root)) {
+				parts.unshift('#' + cssEscape(node.id));
+				break;
+			}
+			var index = 1;
+			for (var sibling = node.previousElementSibling; sibling; sibling = sibling.previousElementSibling) {
+				if (sibling.nodeName === node.nodeName) {
+					index++;
+				}
+			}
+			parts.unshift(node.nodeName.toLowerCase() + ':nth-of-type(' + index + ')');
+			node = node.parentElement;
+		}
+		if (parts.length === 0) {
+			return 'html';
+		}
+		return parts.join(' > ');
+	}
+	function xpathFor(node) {
+		var parts = [];
+		while (node && node.nodeType !== 9) {
+			if (node.nodeType === 1 && node.id && node.id.indexOf('"') === -1 && document.querySelectorAll('[id="' + node.id + '"]').length === 1) {
+				parts.unshift('//*[@id="' + node.id + '"]');
+				return parts.join('/');
+			}
+			var name = node.nodeType === 3 ? 'text()' : node.nodeType === 8 ? 'comment()' : node.nodeName.toLowerCase();
+			var index = 1, count = 0;
+			for (var sibling = node.parentNode ? node.parentNode.firstChild : null; sibling; sibling = sibling.nextSibling) {
+				if (sibling.nodeType === node.nodeType && sibling.nodeName === node.nodeName) {
+					count++;
+					if (sibling === node) {
+						index = count;
+					}
+				}
+			}
+			parts.unshift(count > 1 ? name + '[' + index + ']' : name);
+			node = node.parentNode;
+		}
+		return '/' + parts.join('/');
+	}`
+
+// UniqueSelector returns a CSS selector which matches only this element in its document, preferring
+// a unique id or attribute (data-testid, name etc) and falling back to nth-of-type positions.
+func (e *Element) UniqueSelector() (string, error) {
+	return e.selectorString("function() { " + selectorFunctions + " return selectorFor(this); }")
+}
+
+// XPath returns an XPath expression which matches only this node in its document, anchored to the
+// closest ancestor with a unique id if there is one.
+func (e *Element) XPath() (string, error) {
+	return e.selectorString("function() { " + selectorFunctions + " return xpathFor(this); }")
+}
+
+func (e *Element) selectorString(functionDeclaration string) (string, error) {
+	rro, err := e.callFunction(functionDeclaration)
+	if err != nil {
+		return "", err
+	}
+	selector, _ := rro.Value.(string)
+	return selector, nil
+}
@@ -0,0 +1,157 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/wirepair/autogcd"
+)
+
+// ClosedErr returned when acquiring a tab from a closed TabPool.
+type ClosedErr struct {
+}
+
+func (e *ClosedErr) Error() string {
+	return "tab pool is closed"
+}
+
+// TimeoutErr returned when no tab became available in time.
+type TimeoutErr struct {
+	Message string
+}
+
+func (e *TimeoutErr) Error() string {
+	return "Timed out " + e.Message
+}
+
+// TabPool manages a fixed number of tabs which are handed out one caller at a time, limiting how many pages
+// are rendered concurrently. Tabs that crash or are discarded are replaced. It is safe for concurrent use.
+type TabPool struct {
+	auto    *autogcd.AutoGcd
+	options []autogcd.TabOption
+	tabs    chan *autogcd.Tab
+	lock    *sync.Mutex // protects closed and all
+	closed  bool
+	all     map[*autogcd.Tab]struct{} // every tab owned by the pool, idle or acquired
+}
+
+// NewTabPool opens size tabs in the started auto, each created with opts.
+func NewTabPool(auto *autogcd.AutoGcd, size int, opts ...autogcd.TabOption) (*TabPool, error) {
+	p := &TabPool{auto: auto, options: opts}
+	p.tabs = make(chan *autogcd.Tab, size)
+	p.lock = &sync.Mutex{}
+	p.all = make(map[*autogcd.Tab]struct{}, size)
+
+	for i := 0; i < size; i++ {
+		tab, err := auto.NewTabWithOptions(opts...)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.all[tab] = struct{}{}
+		p.tabs <- tab
+	}
+	return p, nil
+}
+
+// Size returns the number of tabs managed by the pool.
+func (p *TabPool) Size() int {
+	return cap(p.tabs)
+}
+
+// Acquire waits up to timeout for an idle tab. The tab must be returned with Release or Discard.
+func (p *TabPool) Acquire(timeout time.Duration) (*autogcd.Tab, error) {
+	timeoutTimer := time.NewTimer(timeout)
+	defer timeoutTimer.Stop()
+
+	select {
+	case tab, ok := <-p.tabs:
+		if !ok {
+			return nil, &ClosedErr{}
+		}
+		return tab, nil
+	case <-timeoutTimer.C:
+		return nil, &TimeoutErr{Message: "waiting for an idle tab"}
+	}
+}
+
+// Release returns an acquired tab to the pool, tabs that have crashed are replaced.
+func (p *TabPool) Release(tab *autogcd.Tab) {
+	if tab.CrashErr() != nil {
+		p.Discard(tab)
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.closed {
+		p.auto.CloseTab(tab)
+		return
+	}
+	p.tabs <- tab
+}
+
+// Discard closes an acquired tab that is in an unknown state (for example it timed out mid render) and
+// replaces it with a new tab.
+func (p *TabPool) Discard(tab *autogcd.Tab) {
+	p.auto.CloseTab(tab)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.all, tab)
+	if p.closed {
+		return
+	}
+
+	replacement, err := p.auto.NewTabWithOptions(p.options...)
+	if err != nil {
+		// the pool shrinks rather than failing callers, a later Discard may succeed
+		return
+	}
+	p.all[replacement] = struct{}{}
+	p.tabs <- replacement
+}
+
+// Close closes every tab, including acquired ones. Acquire returns ClosedErr afterwards.
+func (p *TabPool) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	close(p.tabs)
+
+	var firstErr error
+	for tab := range p.all {
+		if err := p.auto.CloseTab(tab); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	p.all = make(map[*autogcd.Tab]struct{})
+	return firstErr
+}
@@ -0,0 +1,200 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+/*
+Package server exposes page rendering over HTTP using a TabPool, making autogcd an embeddable rendering
+service. Each endpoint takes the page to render in the url query parameter:
+
+	GET /screenshot?url=https://example.com/   png of the visible viewport
+	GET /pdf?url=https://example.com/          pdf of the page, chrome must be running headless
+	GET /html?url=https://example.com/         the rendered document's html
+
+Add stable=1 to wait for the DOM to stop changing before rendering. At most TabPool.Size pages are rendered
+at once, requests wait up to Options.AcquireTimeout for a tab before failing with 503.
+*/
+package server
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/wirepair/autogcd"
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// InvalidUrlErr returned for missing, malformed or disallowed urls.
+type InvalidUrlErr struct {
+	Message string
+}
+
+func (e *InvalidUrlErr) Error() string {
+	return "invalid url: " + e.Message
+}
+
+// Options for a Server, zero values use the defaults.
+type Options struct {
+	RequestTimeout time.Duration // maximum time to load and render a page, defaults to 30 seconds
+	AcquireTimeout time.Duration // maximum time to wait for an idle tab, defaults to 10 seconds
+	AllowedSchemes []string      // url schemes that may be rendered, defaults to http and https
+}
+
+// renders the loaded page, returning the body and its content type
+type renderFunc func(tab *autogcd.Tab) ([]byte, string, error)
+
+// Server is an http.Handler rendering pages with tabs from a TabPool.
+type Server struct {
+	pool    *TabPool
+	options Options
+	mux     *http.ServeMux
+}
+
+// New creates a server rendering pages with tabs from pool, opts may be nil.
+func New(pool *TabPool, opts *Options) *Server {
+	s := &Server{pool: pool}
+	if opts != nil {
+		s.options = *opts
+	}
+	if s.options.RequestTimeout <= 0 {
+		s.options.RequestTimeout = 30 * time.Second
+	}
+	if s.options.AcquireTimeout <= 0 {
+		s.options.AcquireTimeout = 10 * time.Second
+	}
+	if len(s.options.AllowedSchemes) == 0 {
+		s.options.AllowedSchemes = []string{"http", "https"}
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("/screenshot", s.handler(renderScreenshot))
+	s.mux.HandleFunc("/pdf", s.handler(renderPDF))
+	s.mux.HandleFunc("/html", s.handler(renderHTML))
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// wraps a renderFunc with validation, tab acquisition and the request timeout.
+func (s *Server) handler(render renderFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" && r.Method != "HEAD" {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		target := r.URL.Query().Get("url")
+		if err := s.validateUrl(target); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		stable := r.URL.Query().Get("stable") == "1"
+
+		tab, err := s.pool.Acquire(s.options.AcquireTimeout)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		type rendered struct {
+			body        []byte
+			contentType string
+			err         error
+		}
+		doneCh := make(chan *rendered, 1)
+		go func() {
+			tab.SetNavigationTimeout(s.options.RequestTimeout)
+			if _, err := tab.Navigate(target); err != nil {
+				doneCh <- &rendered{err: err}
+				return
+			}
+			if stable {
+				tab.WaitStable()
+			}
+			body, contentType, err := render(tab)
+			doneCh <- &rendered{body: body, contentType: contentType, err: err}
+		}()
+
+		timeoutTimer := time.NewTimer(s.options.RequestTimeout)
+		defer timeoutTimer.Stop()
+
+		select {
+		case result := <-doneCh:
+			s.pool.Release(tab)
+			if result.err != nil {
+				http.Error(w, result.err.Error(), http.StatusBadGateway)
+				return
+			}
+			w.Header().Set("Content-Type", result.contentType)
+			w.Write(result.body)
+		case <-timeoutTimer.C:
+			// the tab is still busy, replace it once the render gives up
+			go func() {
+				<-doneCh
+				s.pool.Discard(tab)
+			}()
+			http.Error(w, "timed out rendering "+target, http.StatusGatewayTimeout)
+		}
+	}
+}
+
+// only absolute urls with an allowed scheme may be rendered, so callers can not read local files.
+func (s *Server) validateUrl(target string) error {
+	if target == "" {
+		return &InvalidUrlErr{Message: "missing url parameter"}
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return &InvalidUrlErr{Message: err.Error()}
+	}
+	for _, scheme := range s.options.AllowedSchemes {
+		if strings.EqualFold(u.Scheme, scheme) && u.Host != "" {
+			return nil
+		}
+	}
+	return &InvalidUrlErr{Message: "scheme not allowed: " + target}
+}
+
+func renderScreenshot(tab *autogcd.Tab) ([]byte, string, error) {
+	body, err := tab.GetScreenShot()
+	return body, "image/png", err
+}
+
+func renderPDF(tab *autogcd.Tab) ([]byte, string, error) {
+	data, err := tab.Page.PrintToPDFWithParams(&gcdapi.PagePrintToPDFParams{PrintBackground: true})
+	if err != nil {
+		return nil, "", err
+	}
+	body, err := base64.StdEncoding.DecodeString(data)
+	return body, "application/pdf", err
+}
+
+func renderHTML(tab *autogcd.Tab) ([]byte, string, error) {
+	source, err := tab.GetPageSource(0)
+	return []byte(source), "text/html; charset=utf-8", err
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServerRejectsInvalidUrls(t *testing.T) {
+	s := New(nil, nil)
+
+	for _, target := range []string{"/screenshot", "/html?url=file:///etc/passwd", "/pdf?url=javascript:alert(1)", "/html?url=/relative"} {
+		recorder := httptest.NewRecorder()
+		s.ServeHTTP(recorder, httptest.NewRequest("GET", target, nil))
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("expected %s to be rejected got %d\n", target, recorder.Code)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest("POST", "/html?url=http://localhost/", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected POST to be rejected got %d\n", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest("GET", "/unknown", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected unknown path to 404 got %d\n", recorder.Code)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// CommandTiming pairs a recorded command with its response.
+type CommandTiming struct {
+	Id       int64          // command id
+	Method   string         // protocol method
+	Sent     time.Duration  // when the command was sent, relative to the start of the recording
+	Latency  time.Duration  // time until the response arrived, 0 if it never did
+	Answered bool           // a response was recorded
+	Error    string         // the protocol error returned, empty if the command succeeded
+	Command  *SessionRecord // the recorded command
+	Response *SessionRecord // the recorded response, nil if unanswered
+}
+
+// ReplayAnalyzer inspects a session written by RecordSession offline, for debugging failures that depend on
+// the order or timing of protocol messages.
+type ReplayAnalyzer struct {
+	Records  []*SessionRecord // every record in the order it was written
+	commands []*CommandTiming
+}
+
+// LoadSession reads a recording written by RecordSession.
+func LoadSession(r io.Reader) (*ReplayAnalyzer, error) {
+	a := &ReplayAnalyzer{Records: make([]*SessionRecord, 0)}
+	decoder := json.NewDecoder(r)
+	for {
+		record := &SessionRecord{}
+		if err := decoder.Decode(record); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, &SessionRecordErr{Message: fmt.Sprintf("record %d: %s", len(a.Records)+1, err)}
+		}
+		a.Records = append(a.Records, record)
+	}
+	a.pairCommands()
+	return a, nil
+}
+
+// matches responses to their commands
+func (a *ReplayAnalyzer) pairCommands() {
+	a.commands = make([]*CommandTiming, 0)
+	pending := make(map[int64]*CommandTiming)
+	for _, record := range a.Records {
+		switch record.Kind {
+		case RecordCommand:
+			timing := &CommandTiming{Id: record.Id, Method: record.Method, Sent: record.Elapsed, Command: record}
+			pending[record.Id] = timing
+			a.commands = append(a.commands, timing)
+		case RecordResponse:
+			timing, ok := pending[record.Id]
+			if !ok {
+				continue
+			}
+			delete(pending, record.Id)
+			timing.Answered = true
+			timing.Response = record
+			timing.Latency = record.Elapsed - timing.Sent
+
+			var resp struct {
+				Error *struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if json.Unmarshal(record.Data, &resp) == nil && resp.Error != nil {
+				timing.Error = resp.Error.Message
+			}
+		}
+	}
+}
+
+// Commands returns every recorded command in the order it was sent.
+func (a *ReplayAnalyzer) Commands() []*CommandTiming {
+	return a.commands
+}
+
+// Unanswered returns the commands the browser never responded to before the recording stopped.
+func (a *ReplayAnalyzer) Unanswered() []*CommandTiming {
+	unanswered := make([]*CommandTiming, 0)
+	for _, timing := range a.commands {
+		if !timing.Answered {
+			unanswered = append(unanswered, timing)
+		}
+	}
+	return unanswered
+}
+
+// Errors returns the commands the browser returned an error for.
+func (a *ReplayAnalyzer) Errors() []*CommandTiming {
+	failed := make([]*CommandTiming, 0)
+	for _, timing := range a.commands {
+		if timing.Error != "" {
+			failed = append(failed, timing)
+		}
+	}
+	return failed
+}
+
+// Slowest returns up to n answered commands, slowest first.
+func (a *ReplayAnalyzer) Slowest(n int) []*CommandTiming {
+	answered := make([]*CommandTiming, 0)
+	for _, timing := range a.commands {
+		if timing.Answered {
+			answered = append(answered, timing)
+		}
+	}
+	sort.SliceStable(answered, func(i, j int) bool {
+		return answered[i].Latency > answered[j].Latency
+	})
+	if n >= 0 && len(answered) > n {
+		answered = answered[:n]
+	}
+	return answered
+}
+
+// Events returns the recorded events named method, or every event if method is empty.
+func (a *ReplayAnalyzer) Events(method string) []*SessionRecord {
+	events := make([]*SessionRecord, 0)
+	for _, record := range a.Records {
+		if record.Kind == RecordEvent && (method == "" || record.Method == method) {
+			events = append(events, record)
+		}
+	}
+	return events
+}
+
+// Between returns the records written from start up to, but not including, end relative to the start of
+// the recording.
+func (a *ReplayAnalyzer) Between(start, end time.Duration) []*SessionRecord {
+	records := make([]*SessionRecord, 0)
+	for _, record := range a.Records {
+		if record.Elapsed >= start && record.Elapsed < end {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// MethodCounts returns how many times each command was sent and each event was received.
+func (a *ReplayAnalyzer) MethodCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, record := range a.Records {
+		if record.Kind != RecordResponse {
+			counts[record.Method]++
+		}
+	}
+	return counts
+}
+
+// Timeline writes a line per record to w, commands are marked with ->, responses with <- and events with *.
+func (a *ReplayAnalyzer) Timeline(w io.Writer) error {
+	marks := map[string]string{RecordCommand: "->", RecordResponse: "<-", RecordEvent: "* "}
+	for _, record := range a.Records {
+		line := fmt.Sprintf("%12s %s %s", record.Elapsed, marks[record.Kind], record.Method)
+		if record.Kind != RecordEvent {
+			line += fmt.Sprintf(" (%d)", record.Id)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package autogcd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wirepair/gcd/gcdmessage"
+)
+
+func TestSessionRecorderLoadSession(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := newSessionRecorder(&buf)
+	recorder.recordCommand(&gcdmessage.Message{Id: 1, Data: []byte(`{"id":1,"method":"Page.navigate","params":{"url":"http://localhost/"}}`)})
+	recorder.recordCommand(&gcdmessage.Message{Id: 2, Data: []byte(`{"id":2,"method":"DOM.getDocument"}`)})
+	recorder.recordEvent("Page.loadEventFired", []byte(`{"method":"Page.loadEventFired","params":{"timestamp":1}}`))
+	time.Sleep(5 * time.Millisecond)
+	recorder.recordResponse(&gcdmessage.Message{Id: 1, Data: []byte(`{"id":1,"result":{"frameId":"1"}}`)})
+	recorder.recordCommand(&gcdmessage.Message{Id: 3, Data: []byte(`{"id":3,"method":"DOM.focus"}`)})
+	recorder.recordResponse(&gcdmessage.Message{Id: 3, Data: []byte(`{"id":3,"error":{"code":-32000,"message":"Element is not focusable"}}`)})
+	if err := recorder.stop(); err != nil {
+		t.Fatalf("error recording: %s\n", err)
+	}
+	recorder.recordEvent("Page.frameNavigated", []byte(`{}`))
+
+	analyzer, err := LoadSession(&buf)
+	if err != nil {
+		t.Fatalf("error loading session: %s\n", err)
+	}
+	if len(analyzer.Records) != 6 {
+		t.Fatalf("expected 6 records got %d\n", len(analyzer.Records))
+	}
+	if analyzer.Records[3].Method != "Page.navigate" {
+		t.Fatalf("response was not labelled with its command's method: %#v\n", analyzer.Records[3])
+	}
+
+	commands := analyzer.Commands()
+	if len(commands) != 3 || !commands[0].Answered || commands[0].Latency < 5*time.Millisecond {
+		t.Fatalf("unexpected commands %#v\n", commands)
+	}
+	unanswered := analyzer.Unanswered()
+	if len(unanswered) != 1 || unanswered[0].Method != "DOM.getDocument" {
+		t.Fatalf("expected DOM.getDocument to be unanswered got %#v\n", unanswered)
+	}
+	failed := analyzer.Errors()
+	if len(failed) != 1 || failed[0].Error != "Element is not focusable" {
+		t.Fatalf("expected DOM.focus to have failed got %#v\n", failed)
+	}
+	slowest := analyzer.Slowest(1)
+	if len(slowest) != 1 || slowest[0].Method != "Page.navigate" {
+		t.Fatalf("expected Page.navigate to be slowest got %#v\n", slowest)
+	}
+	if events := analyzer.Events("Page.loadEventFired"); len(events) != 1 {
+		t.Fatalf("expected 1 load event got %d\n", len(events))
+	}
+	if counts := analyzer.MethodCounts(); counts["Page.navigate"] != 1 || counts["Page.loadEventFired"] != 1 {
+		t.Fatalf("unexpected counts %v\n", counts)
+	}
+	if records := analyzer.Between(0, commands[0].Response.Elapsed); len(records) != 3 {
+		t.Fatalf("expected 3 records before the navigate response got %d\n", len(records))
+	}
+
+	var timeline bytes.Buffer
+	if err := analyzer.Timeline(&timeline); err != nil {
+		t.Fatalf("error writing timeline: %s\n", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(timeline.String()), "\n"); len(lines) != 6 || !strings.Contains(lines[0], "-> Page.navigate (1)") {
+		t.Fatalf("unexpected timeline:\n%s\n", timeline.String())
+	}
+}
+
+func TestLoadSessionInvalid(t *testing.T) {
+	if _, err := LoadSession(strings.NewReader(`{"kind":"event"}` + "\nnot json")); err == nil {
+		t.Fatalf("expected an error loading an invalid session\n")
+	}
+}
@@ -0,0 +1,239 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/wirepair/gcd/gcdmessage"
+)
+
+// Kinds of SessionRecord
+const (
+	RecordCommand  = "command"  // a protocol command sent to the browser
+	RecordResponse = "response" // the browser's reply to a command
+	RecordEvent    = "event"    // an event received from the browser
+)
+
+// SessionRecord is a single protocol message written by RecordSession as a line of JSON.
+type SessionRecord struct {
+	Kind    string          `json:"kind"`         // one of RecordCommand, RecordResponse or RecordEvent
+	Id      int64           `json:"id,omitempty"` // command id, shared by a command and its response
+	Method  string          `json:"method"`       // protocol method or event name
+	Time    time.Time       `json:"time"`         // when the message was sent or received
+	Elapsed time.Duration   `json:"elapsed"`      // time since the recording started
+	Data    json.RawMessage `json:"data"`         // the raw message
+}
+
+type SessionRecordErr struct {
+	Message string
+}
+
+func (e *SessionRecordErr) Error() string {
+	return "session record error: " + e.Message
+}
+
+// Unwrap returns ErrSessionRecord so the error can be matched with errors.Is
+func (e *SessionRecordErr) Unwrap() error {
+	return ErrSessionRecord
+}
+
+// writes the protocol traffic of a tab to an io.Writer
+type sessionRecorder struct {
+	lock    *sync.Mutex
+	encoder *json.Encoder
+	start   time.Time
+	methods map[int64]string // command id => method, for labelling responses
+	stopped bool
+	err     error // first write error, returned by StopRecordSession
+}
+
+func newSessionRecorder(w io.Writer) *sessionRecorder {
+	return &sessionRecorder{lock: &sync.Mutex{}, encoder: json.NewEncoder(w), start: time.Now(), methods: make(map[int64]string)}
+}
+
+func (r *sessionRecorder) write(record *SessionRecord) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.stopped || r.err != nil {
+		return
+	}
+
+	switch record.Kind {
+	case RecordCommand:
+		r.methods[record.Id] = record.Method
+	case RecordResponse:
+		record.Method = r.methods[record.Id]
+		delete(r.methods, record.Id)
+	}
+	record.Elapsed = record.Time.Sub(r.start)
+	r.err = r.encoder.Encode(record)
+}
+
+func (r *sessionRecorder) recordCommand(msg *gcdmessage.Message) {
+	var request struct {
+		Method string `json:"method"`
+	}
+	json.Unmarshal(msg.Data, &request)
+	r.write(&SessionRecord{Kind: RecordCommand, Id: msg.Id, Method: request.Method, Time: time.Now(), Data: rawData(msg.Data)})
+}
+
+func (r *sessionRecorder) recordResponse(msg *gcdmessage.Message) {
+	r.write(&SessionRecord{Kind: RecordResponse, Id: msg.Id, Time: time.Now(), Data: rawData(msg.Data)})
+}
+
+func (r *sessionRecorder) recordEvent(method string, payload []byte) {
+	r.write(&SessionRecord{Kind: RecordEvent, Method: method, Time: time.Now(), Data: rawData(payload)})
+}
+
+func (r *sessionRecorder) stop() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.stopped = true
+	return r.err
+}
+
+// copies data so the recorder does not hold on to buffers reused by gcd, nil if it is not valid json.
+func rawData(data []byte) json.RawMessage {
+	if !json.Valid(data) {
+		return nil
+	}
+	return append(json.RawMessage(nil), data...)
+}
+
+// recordingTarget sits between the gcdapi domains and the tab's connection, recording commands as they are sent
+// and their responses as they are returned.
+type recordingTarget struct {
+	target   *targetConnection
+	recorder *sessionRecorder
+	sendCh   chan *gcdmessage.Message
+}
+
+func newRecordingTarget(target *targetConnection, recorder *sessionRecorder) *recordingTarget {
+	r := &recordingTarget{target: target, recorder: recorder, sendCh: make(chan *gcdmessage.Message)}
+	go r.forward()
+	return r
+}
+
+// GetSendCh returns the channel the domains send commands on.
+func (r *recordingTarget) GetSendCh() chan *gcdmessage.Message {
+	return r.sendCh
+}
+
+// forwards commands to the connection until it is closed. It keeps running after the recording stops so
+// calls still holding the recording domains do not block.
+func (r *recordingTarget) forward() {
+	doneCh := r.target.GetDoneCh()
+	for {
+		select {
+		case msg := <-r.sendCh:
+			r.recorder.recordCommand(msg)
+			replyCh := msg.ReplyCh
+			proxyCh := make(chan *gcdmessage.Message, 1)
+			msg.ReplyCh = proxyCh
+			go r.reply(proxyCh, replyCh, doneCh)
+
+			select {
+			case r.target.GetSendCh() <- msg:
+			case <-doneCh:
+				return
+			}
+		case <-doneCh:
+			return
+		}
+	}
+}
+
+// records the response and hands it back to the caller, replyCh is buffered by gcdmessage so this never blocks.
+func (r *recordingTarget) reply(proxyCh, replyCh chan *gcdmessage.Message, doneCh chan struct{}) {
+	select {
+	case resp, ok := <-proxyCh:
+		if !ok {
+			// the target detached or crashed before responding
+			close(replyCh)
+			return
+		}
+		r.recorder.recordResponse(resp)
+		replyCh <- resp
+	case <-doneCh:
+	}
+}
+
+// RecordSession writes every protocol command the tab sends, the browser's responses and the events the tab is
+// subscribed to, to w as lines of JSON (see SessionRecord) until StopRecordSession is called. Load the output
+// with LoadSession to inspect timing dependent failures offline. Recording may be started and stopped while
+// other goroutines use the tab. Commands sent by child sessions are not recorded.
+func (t *Tab) RecordSession(w io.Writer) error {
+	t.recordLock.Lock()
+	defer t.recordLock.Unlock()
+	if t.sessionRecorder != nil {
+		return &SessionRecordErr{Message: "already recording"}
+	}
+	t.sessionRecorder = newSessionRecorder(w)
+	t.recordingTarget = newRecordingTarget(t.target(), t.sessionRecorder)
+	return nil
+}
+
+// StopRecordSession stops recording, returning the first error writing the recording encountered.
+func (t *Tab) StopRecordSession() error {
+	t.recordLock.Lock()
+	defer t.recordLock.Unlock()
+	if t.sessionRecorder == nil {
+		return &SessionRecordErr{Message: "not recording"}
+	}
+	err := t.sessionRecorder.stop()
+	t.sessionRecorder = nil
+	t.recordingTarget = nil
+	return err
+}
+
+// returns what commands built outside of the gcdapi domains should be sent through. Like the domains they
+// go through the tab, which sends them to the recording target while recording, see GetSendCh.
+func (t *Tab) targeter() gcdmessage.ChromeTargeter {
+	return t
+}
+
+// returns the channel commands are sent on while recording, nil if not recording.
+func (t *Tab) recordingSendCh() chan *gcdmessage.Message {
+	t.recordLock.RLock()
+	defer t.recordLock.RUnlock()
+	if t.recordingTarget == nil {
+		return nil
+	}
+	return t.recordingTarget.GetSendCh()
+}
+
+// continues an active recording on a re-attached target.
+func (t *Tab) resumeRecordSession() {
+	t.recordLock.Lock()
+	defer t.recordLock.Unlock()
+	if t.sessionRecorder == nil {
+		return
+	}
+	t.recordingTarget = newRecordingTarget(t.target(), t.sessionRecorder)
+}
@@ -0,0 +1,435 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/wirepair/gcd"
+	"github.com/wirepair/gcd/gcdapi"
+	"strings"
+	"sync"
+	"time"
+)
+
+// how long to wait for a child session to reply to a call
+const childSessionTimeout = 30 * time.Second
+
+// ChildSessionErr is returned when a call to a child session fails or the session was detached.
+type ChildSessionErr struct {
+	Message string
+}
+
+func (e *ChildSessionErr) Error() string {
+	return "child session error: " + e.Message
+}
+
+// Unwrap returns ErrChildSession so the error can be matched with errors.Is
+func (e *ChildSessionErr) Unwrap() error {
+	return ErrChildSession
+}
+
+// ChildSession is a debugger session for a target that belongs to a tab but runs in its own process,
+// such as an out of process (cross origin) iframe or a worker. Commands are sent and events received
+// through the tab's connection.
+type ChildSession struct {
+	SessionId string // identifier of the session
+	TargetId  string // identifier of the target
+	Type      string // iframe, worker, service_worker etc
+	Url       string // url of the target when it was attached
+	tab       *Tab
+	lock      *sync.Mutex
+	nextId    int64
+	replies   map[int64]chan *sessionMessage
+	events    map[string]SessionEventFunc
+	detached  chan struct{}
+}
+
+// a protocol message received from a child session
+type sessionMessage struct {
+	Id     int64           `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func newChildSession(tab *Tab, sessionId string, info *gcdapi.TargetTargetInfo) *ChildSession {
+	s := &ChildSession{SessionId: sessionId, tab: tab}
+	if info != nil {
+		s.TargetId = info.TargetId
+		s.Type = info.Type
+		s.Url = info.Url
+	}
+	s.lock = &sync.Mutex{}
+	s.replies = make(map[int64]chan *sessionMessage)
+	s.events = make(map[string]SessionEventFunc)
+	s.detached = make(chan struct{})
+	return s
+}
+
+// Call sends a protocol command (such as DOM.getDocument) to the child target and returns the raw result.
+func (s *ChildSession) Call(method string, params interface{}) (json.RawMessage, error) {
+	s.lock.Lock()
+	s.nextId++
+	id := s.nextId
+	replyCh := make(chan *sessionMessage, 1)
+	s.replies[id] = replyCh
+	s.lock.Unlock()
+
+	defer func() {
+		s.lock.Lock()
+		delete(s.replies, id)
+		s.lock.Unlock()
+	}()
+
+	request := map[string]interface{}{"id": id, "method": method}
+	if params != nil {
+		request["params"] = params
+	}
+	message, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.tab.TargetApi.SendMessageToTarget(string(message), s.SessionId, ""); err != nil {
+		return nil, err
+	}
+
+	timeoutTimer := time.NewTimer(childSessionTimeout)
+	defer timeoutTimer.Stop()
+
+	select {
+	case reply := <-replyCh:
+		if reply.Error != nil {
+			return nil, &ChildSessionErr{Message: method + ": " + reply.Error.Message}
+		}
+		return reply.Result, nil
+	case <-s.detached:
+		return nil, &ChildSessionErr{Message: "session detached during " + method}
+	case <-timeoutTimer.C:
+		return nil, &TimeoutErr{Message: "waiting for child session reply to " + method}
+	}
+}
+
+// EvaluateScript evaluates script in the child target's global context and returns the result by value.
+func (s *ChildSession) EvaluateScript(script string) (interface{}, error) {
+	params := map[string]interface{}{"expression": script, "returnByValue": true, "awaitPromise": true}
+	raw, err := s.Call("Runtime.evaluate", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Result           *gcdapi.RuntimeRemoteObject
+		ExceptionDetails *gcdapi.RuntimeExceptionDetails
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+	if result.ExceptionDetails != nil {
+		return nil, &ScriptEvaluationErr{Message: "error executing script: ", ExceptionText: result.ExceptionDetails.Text, ExceptionDetails: result.ExceptionDetails}
+	}
+	if result.Result == nil {
+		return nil, nil
+	}
+	return result.Result.Value, nil
+}
+
+// Subscribe calls eventFn for every event of method sent by the child target. The payload has the same
+// shape as events of the tab so it can be unmarshaled into the gcdapi event types.
+func (s *ChildSession) Subscribe(method string, eventFn SessionEventFunc) {
+	s.lock.Lock()
+	s.events[method] = eventFn
+	s.lock.Unlock()
+}
+
+// Unsubscribe stops calling the handler for method.
+func (s *ChildSession) Unsubscribe(method string) {
+	s.lock.Lock()
+	delete(s.events, method)
+	s.lock.Unlock()
+}
+
+// SessionLogEntry is a console message or uncaught exception from a child session.
+type SessionLogEntry struct {
+	Level     string  // console call type (log, warning, error...) or "exception"
+	Text      string  // the console arguments joined by spaces, or the exception description
+	Url       string  // script url of the call or exception, if known
+	Line      int     // line number of the call or exception, if known
+	Exception bool    // true for uncaught exceptions
+	Timestamp float64 // when the message was logged
+}
+
+// ListenLogs enables the Runtime domain of the child target and calls logFn for every console message and
+// uncaught exception. Call it from the ChildSessionFunc to capture messages logged while the target starts.
+func (s *ChildSession) ListenLogs(logFn SessionLogFunc) error {
+	s.Subscribe("Runtime.consoleAPICalled", func(session *ChildSession, payload []byte) {
+		message := &gcdapi.RuntimeConsoleAPICalledEvent{}
+		if err := json.Unmarshal(payload, message); err != nil {
+			return
+		}
+		p := message.Params
+		entry := &SessionLogEntry{Level: p.Type, Text: remoteObjectsText(p.Args), Timestamp: p.Timestamp}
+		if p.StackTrace != nil && len(p.StackTrace.CallFrames) > 0 {
+			entry.Url = p.StackTrace.CallFrames[0].Url
+			entry.Line = p.StackTrace.CallFrames[0].LineNumber
+		}
+		logFn(session, entry)
+	})
+	s.Subscribe("Runtime.exceptionThrown", func(session *ChildSession, payload []byte) {
+		message := &gcdapi.RuntimeExceptionThrownEvent{}
+		if err := json.Unmarshal(payload, message); err != nil || message.Params.ExceptionDetails == nil {
+			return
+		}
+		details := message.Params.ExceptionDetails
+		entry := &SessionLogEntry{Level: "exception", Text: details.Text, Url: details.Url, Line: details.LineNumber, Exception: true, Timestamp: message.Params.Timestamp}
+		if details.Exception != nil && details.Exception.Description != "" {
+			entry.Text = details.Exception.Description
+		}
+		logFn(session, entry)
+	})
+
+	_, err := s.Call("Runtime.enable", nil)
+	return err
+}
+
+// IsDetached returns true once the target has gone away.
+func (s *ChildSession) IsDetached() bool {
+	select {
+	case <-s.detached:
+		return true
+	default:
+		return false
+	}
+}
+
+// routes a message from the child target to a waiting Call or an event handler.
+func (s *ChildSession) dispatch(payload []byte) {
+	message := &sessionMessage{}
+	if err := json.Unmarshal(payload, message); err != nil {
+		return
+	}
+
+	s.lock.Lock()
+	if message.Method == "" {
+		replyCh, ok := s.replies[message.Id]
+		s.lock.Unlock()
+		if ok {
+			replyCh <- message
+		}
+		return
+	}
+	eventFn := s.events[message.Method]
+	s.lock.Unlock()
+
+	if eventFn != nil {
+		eventFn(s, payload)
+	}
+}
+
+func (s *ChildSession) setDetached() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.IsDetached() {
+		close(s.detached)
+	}
+}
+
+// AttachFrameTargets automatically attaches to out of process (cross origin) iframes of this tab. Their
+// network traffic is routed into the tab's network handlers and ResourceReport, and sessionFn (which may
+// be nil) is called with a ChildSession for each frame so its DOM can be queried directly. Element queries
+// on the tab do not see the contents of out of process iframes.
+func (t *Tab) AttachFrameTargets(sessionFn ChildSessionFunc) error {
+	t.sessionLock.Lock()
+	t.frameSessionHandler = sessionFn
+	t.attachFrames = true
+	t.sessionLock.Unlock()
+	return t.enableAutoAttach()
+}
+
+// ListenWorkers automatically attaches to the workers of this tab (dedicated and service workers, as well as
+// shared workers chrome reports as related to the page) and calls sessionFn with a ChildSession for each. The
+// worker waits until sessionFn returns, so ChildSession.ListenLogs called from sessionFn captures everything
+// the worker logs. Worker network traffic is routed into the tab's network handlers.
+func (t *Tab) ListenWorkers(sessionFn ChildSessionFunc) error {
+	t.sessionLock.Lock()
+	t.workerSessionHandler = sessionFn
+	t.sessionLock.Unlock()
+	return t.enableAutoAttach()
+}
+
+// ChildSessions returns the currently attached child sessions.
+func (t *Tab) ChildSessions() []*ChildSession {
+	t.sessionLock.RLock()
+	defer t.sessionLock.RUnlock()
+
+	sessions := make([]*ChildSession, 0, len(t.sessions))
+	for _, session := range t.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// turns on Target.setAutoAttach, new targets wait for us to set them up before they run.
+func (t *Tab) enableAutoAttach() error {
+	t.sessionLock.Lock()
+	defer t.sessionLock.Unlock()
+
+	if t.autoAttachEnabled {
+		return nil
+	}
+
+	t.Subscribe("Target.attachedToTarget", func(target *gcd.ChromeTarget, payload []byte) {
+		message := &gcdapi.TargetAttachedToTargetEvent{}
+		if err := json.Unmarshal(payload, message); err == nil {
+			t.handleAttachedToTarget(message.Params.SessionId, message.Params.TargetInfo, message.Params.WaitingForDebugger)
+		}
+	})
+	t.Subscribe("Target.detachedFromTarget", func(target *gcd.ChromeTarget, payload []byte) {
+		message := &gcdapi.TargetDetachedFromTargetEvent{}
+		if err := json.Unmarshal(payload, message); err == nil {
+			t.handleDetachedFromTarget(message.Params.SessionId)
+		}
+	})
+	t.Subscribe("Target.receivedMessageFromTarget", func(target *gcd.ChromeTarget, payload []byte) {
+		message := &gcdapi.TargetReceivedMessageFromTargetEvent{}
+		if err := json.Unmarshal(payload, message); err != nil {
+			return
+		}
+		t.sessionLock.RLock()
+		session, ok := t.sessions[message.Params.SessionId]
+		t.sessionLock.RUnlock()
+		if ok {
+			session.dispatch([]byte(message.Params.Message))
+		}
+	})
+
+	if _, err := t.TargetApi.SetAutoAttach(true, true, false); err != nil {
+		return err
+	}
+	t.autoAttachEnabled = true
+	return nil
+}
+
+// sets up a newly attached target and lets it run.
+func (t *Tab) handleAttachedToTarget(sessionId string, info *gcdapi.TargetTargetInfo, waiting bool) {
+	session := newChildSession(t, sessionId, info)
+
+	t.sessionLock.Lock()
+	t.sessions[sessionId] = session
+	var sessionFn ChildSessionFunc
+	route := false
+	switch {
+	case session.Type == "iframe" && t.attachFrames:
+		sessionFn = t.frameSessionHandler
+		route = true
+	case isWorkerTarget(session.Type) && t.workerSessionHandler != nil:
+		sessionFn = t.workerSessionHandler
+		route = true
+	}
+	t.sessionLock.Unlock()
+
+	if route {
+		t.routeSessionNetwork(session)
+	}
+
+	if sessionFn != nil {
+		sessionFn(t, session)
+	}
+
+	if waiting {
+		if _, err := session.Call("Runtime.runIfWaitingForDebugger", nil); err != nil {
+			t.debugf("error resuming child target %s: %s\n", session.TargetId, err)
+		}
+	}
+}
+
+func (t *Tab) handleDetachedFromTarget(sessionId string) {
+	t.sessionLock.Lock()
+	session, ok := t.sessions[sessionId]
+	delete(t.sessions, sessionId)
+	t.sessionLock.Unlock()
+
+	if ok {
+		session.setDetached()
+	}
+}
+
+// forwards the child's network events to the tab's network handlers.
+func (t *Tab) routeSessionNetwork(session *ChildSession) {
+	session.Subscribe("Network.requestWillBeSent", func(session *ChildSession, payload []byte) {
+		if request, err := parseNetworkRequest(payload); err == nil {
+			t.handleNetworkRequest(request)
+		}
+	})
+	session.Subscribe("Network.responseReceived", func(session *ChildSession, payload []byte) {
+		if response, err := parseNetworkResponse(payload); err == nil {
+			t.handleNetworkResponse(response)
+		}
+	})
+	session.Subscribe("Network.loadingFinished", func(session *ChildSession, payload []byte) {
+		message := &gcdapi.NetworkLoadingFinishedEvent{}
+		if err := json.Unmarshal(payload, message); err == nil {
+			t.handleNetworkFinished(message.Params.RequestId, message.Params.EncodedDataLength, message.Params.Timestamp)
+		}
+	})
+	session.Subscribe("Network.loadingFailed", func(session *ChildSession, payload []byte) {
+		message := &gcdapi.NetworkLoadingFailedEvent{}
+		if err := json.Unmarshal(payload, message); err == nil {
+			t.handleNetworkFailed(parseLoadingFailed(message))
+		}
+	})
+
+	if _, err := session.Call("Network.enable", nil); err != nil {
+		t.debugf("error enabling network for child target %s: %s\n", session.TargetId, err)
+	}
+}
+
+func isWorkerTarget(targetType string) bool {
+	return targetType == "worker" || targetType == "shared_worker" || targetType == "service_worker"
+}
+
+// joins console arguments the way the console displays them.
+func remoteObjectsText(args []*gcdapi.RuntimeRemoteObject) string {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch {
+		case arg == nil:
+			continue
+		case arg.Value != nil:
+			parts = append(parts, fmt.Sprintf("%v", arg.Value))
+		case arg.UnserializableValue != "":
+			parts = append(parts, arg.UnserializableValue)
+		case arg.Description != "":
+			parts = append(parts, arg.Description)
+		default:
+			parts = append(parts, arg.Type)
+		}
+	}
+	return strings.Join(parts, " ")
+}
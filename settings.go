@@ -26,20 +26,36 @@ package autogcd
 
 import (
 	"fmt"
+	"runtime"
+	"strings"
 	"time"
+
+	"github.com/wirepair/autogcd/robots"
 )
 
 type Settings struct {
 	connectToInstance bool
-	timeout           time.Duration // timeout for giving up on chrome starting and connecting to the debugger service
-	chromePath        string        // path to chrome
-	chromeHost        string        // can really only be localhost
-	chromePort        string        // port to chrome debugger
-	userDir           string        // the user directory to use
-	removeUserDir     bool          // should we delete the user directory on shutdown?
-	extensions        []string      // custom extensions to load
-	flags             []string      // custom os.Environ flags to use to start the chrome process
-	env               []string      // custom env vars for launching the process
+	timeout           time.Duration          // timeout for giving up on chrome starting and connecting to the debugger service
+	chromePath        string                 // path to chrome
+	chromeHost        string                 // can really only be localhost
+	chromePort        string                 // port to chrome debugger
+	userDir           string                 // the user directory to use
+	removeUserDir     bool                   // should we delete the user directory on shutdown?
+	extensions        []string               // custom extensions to load
+	flags             []string               // custom os.Environ flags to use to start the chrome process
+	env               []string               // custom env vars for launching the process
+	rateLimiter       *RateLimiter           // navigation rate limiter shared by all tabs
+	robots            *robots.Cache          // robots.txt policy shared by all tabs
+	profilePath       string                 // existing profile to start with, see UseProfile
+	profileCopy       bool                   // copy profilePath to a temporary directory instead of using it directly
+	preferences       map[string]interface{} // written to Default/Preferences before launching, see SetPreferences
+	detectLeaks       bool                   // report leaks at Shutdown, see DetectLeaks
+	leakHandler       ResourceLeakFunc       // called with the leak report, logged if nil
+	tabTempRoot       string                 // directory tab temp dirs are created in, see SetTabTempDir
+	retainFailedTabs  bool                   // keep the temp dirs of failed tabs
+	headless          bool                   // start chrome without a window, see SetHeadless
+	windowWidth       int                    // initial window width, 0 for chrome's default
+	windowHeight      int                    // initial window height, 0 for chrome's default
 }
 
 // Creates a new settings object to start Chrome and enable remote debugging
@@ -91,6 +107,101 @@ func (s *Settings) AddStartupFlags(flags []string) {
 	s.flags = append(s.flags, flags...)
 }
 
+// Sets a RateLimiter that is shared by every tab, making it a global navigation policy.
+// Individual tabs may override it with Tab.SetRateLimiter.
+func (s *Settings) SetRateLimiter(limiter *RateLimiter) {
+	s.rateLimiter = limiter
+}
+
+// Sets a robots.txt cache that is shared by every tab so navigations to disallowed urls are refused.
+func (s *Settings) SetRobotsCache(cache *robots.Cache) {
+	s.robots = cache
+}
+
+// UseProfile starts chrome with the existing profile at path, so logins, cookies and extensions are reused
+// instead of starting from an empty profile. If readOnlyCopy is true the profile is copied to a temporary
+// directory, which is removed on Shutdown, so the original is never modified and several browsers may use it
+// at once. Otherwise chrome uses path directly. Lock files left behind by a browser on this host which is no
+// longer running are removed, Start returns a ProfileInUseErr if a running browser or another host holds the
+// profile. Replaces the userDir passed to NewSettings.
+func (s *Settings) UseProfile(path string, readOnlyCopy bool) {
+	s.profilePath = path
+	s.profileCopy = readOnlyCopy
+}
+
+// SetPreferences writes prefs to the profile's Default/Preferences file before chrome is launched, for
+// behavior which can not be controlled by flags. Keys are dotted preference paths or nested maps, for example:
+//
+//	s.SetPreferences(map[string]interface{}{
+//		"credentials_enable_service":   false,
+//		"download.default_directory":   "/tmp/downloads",
+//		"download.prompt_for_download": false,
+//	})
+//
+// Preferences are merged into an existing Preferences file, such as one from UseProfile, replacing values
+// with the same path. Not applied when connecting to an existing instance.
+func (s *Settings) SetPreferences(prefs map[string]interface{}) {
+	s.preferences = prefs
+}
+
+// SetTabTempDir creates each tab's TempDir under root, os.TempDir if empty. Temp dirs are removed when their
+// tab is closed, if retainOnFailure is true the directories of tabs that crashed or were marked with
+// Tab.MarkFailed are kept for inspection.
+func (s *Settings) SetTabTempDir(root string, retainOnFailure bool) {
+	s.tabTempRoot = root
+	s.retainFailedTabs = retainOnFailure
+}
+
+// DetectLeaks enables a debug mode reporting tabs that were never closed, event subscriptions tabs still hold
+// and go routines still running after Shutdown. The report is passed to handler, or logged if handler is nil.
+// Meant for tracking down leaks in long running services, the go routine count covers the whole program.
+func (s *Settings) DetectLeaks(handler ResourceLeakFunc) {
+	s.detectLeaks = true
+	s.leakHandler = handler
+}
+
+// SetHeadless starts chrome without a visible window using the new headless mode (--headless=new), which runs
+// the same browser as headful chrome. Scrollbars are hidden and audio muted so screenshots are consistent, and
+// the GPU is disabled on Windows where headless chrome can fail to start with it. Flags already passed to
+// AddStartupFlags take precedence. Not applied when connecting to an existing instance.
+func (s *Settings) SetHeadless(headless bool) {
+	s.headless = headless
+}
+
+// SetWindowSize sets the size of chrome's window in pixels, which is also the viewport size of new tabs when
+// headless. Not applied when connecting to an existing instance.
+func (s *Settings) SetWindowSize(width, height int) {
+	s.windowWidth = width
+	s.windowHeight = height
+}
+
+// returns the flags for the headless and window size settings, skipping any the caller already added.
+func (s *Settings) launchFlags() []string {
+	flags := make([]string, 0)
+	add := func(flag string) {
+		name := strings.SplitN(flag, "=", 2)[0]
+		for _, existing := range s.flags {
+			if strings.SplitN(existing, "=", 2)[0] == name {
+				return
+			}
+		}
+		flags = append(flags, flag)
+	}
+
+	if s.headless {
+		add("--headless=new")
+		add("--hide-scrollbars")
+		add("--mute-audio")
+		if runtime.GOOS == "windows" {
+			add("--disable-gpu")
+		}
+	}
+	if s.windowWidth > 0 && s.windowHeight > 0 {
+		add(fmt.Sprintf("--window-size=%d,%d", s.windowWidth, s.windowHeight))
+	}
+	return flags
+}
+
 // Adds a custom extension to launch with chrome. Note this extension MAY NOT USE
 // the chrome.debugger API since you can not attach debuggers to a Tab twice.
 func (s *Settings) AddExtension(paths []string) {
@@ -0,0 +1,26 @@
+package autogcd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSettingsLaunchFlags(t *testing.T) {
+	s := NewSettings("", "")
+	if flags := s.launchFlags(); len(flags) != 0 {
+		t.Fatalf("expected no flags by default got %v\n", flags)
+	}
+
+	s.SetHeadless(true)
+	s.SetWindowSize(1280, 720)
+	flags := strings.Join(s.launchFlags(), " ")
+	if !strings.Contains(flags, "--headless=new") || !strings.Contains(flags, "--hide-scrollbars") || !strings.Contains(flags, "--window-size=1280,720") {
+		t.Fatalf("unexpected flags %s\n", flags)
+	}
+
+	s.AddStartupFlags([]string{"--headless", "--window-size=800,600"})
+	flags = strings.Join(s.launchFlags(), " ")
+	if strings.Contains(flags, "--headless") || strings.Contains(flags, "--window-size") {
+		t.Fatalf("expected flags added by the caller to take precedence got %s\n", flags)
+	}
+}
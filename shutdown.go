@@ -0,0 +1,153 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// how long Shutdown waits for go routines to exit before reporting them as leaked
+const leakSettleTime = 2 * time.Second
+
+// ShutdownFunc is called by Shutdown before any tabs are closed, see OnShutdown
+type ShutdownFunc func(auto *AutoGcd) error
+
+// ResourceLeakFunc is called with the leaks found at Shutdown, see Settings.DetectLeaks
+type ResourceLeakFunc func(report *ResourceLeakReport)
+
+// LeakedTab is a tab still open at Shutdown.
+type LeakedTab struct {
+	Id            string   // target id
+	Url           string   // the tab's url when Shutdown was called
+	Subscriptions []string // events subscribed to after the tab opened that were never unsubscribed
+}
+
+// ResourceLeakReport of the resources still held at Shutdown.
+type ResourceLeakReport struct {
+	Tabs             []*LeakedTab // tabs opened after Start that were not closed
+	Subscriptions    []*LeakedTab // tabs, including those opened by Start, still holding event subscriptions
+	GoroutinesBefore int          // go routines running when Start was called
+	GoroutinesAfter  int          // go routines still running after Shutdown
+	Stacks           string       // stacks of every go routine, set if GoroutinesAfter exceeds GoroutinesBefore
+}
+
+// Leaked returns true if the report found any leaks.
+func (r *ResourceLeakReport) Leaked() bool {
+	return len(r.Tabs) > 0 || len(r.Subscriptions) > 0 || r.GoroutinesAfter > r.GoroutinesBefore
+}
+
+func (r *ResourceLeakReport) String() string {
+	if !r.Leaked() {
+		return "no leaks detected"
+	}
+	var b strings.Builder
+	for _, tab := range r.Tabs {
+		fmt.Fprintf(&b, "tab %s (%s) was not closed\n", tab.Id, tab.Url)
+	}
+	for _, tab := range r.Subscriptions {
+		fmt.Fprintf(&b, "tab %s (%s) is still subscribed to %s\n", tab.Id, tab.Url, strings.Join(tab.Subscriptions, ", "))
+	}
+	if r.GoroutinesAfter > r.GoroutinesBefore {
+		fmt.Fprintf(&b, "%d go routines leaked (%d before Start, %d after Shutdown)\n%s", r.GoroutinesAfter-r.GoroutinesBefore, r.GoroutinesBefore, r.GoroutinesAfter, r.Stacks)
+	}
+	return b.String()
+}
+
+// OnShutdown registers fn to be called when Shutdown is called, while the browser and tabs are still
+// available. Hooks run in the reverse order they were registered, like deferred calls, and Shutdown returns
+// the first error a hook returns if the browser otherwise shut down cleanly.
+func (auto *AutoGcd) OnShutdown(fn ShutdownFunc) {
+	auto.hookLock.Lock()
+	defer auto.hookLock.Unlock()
+	auto.shutdownHooks = append(auto.shutdownHooks, fn)
+}
+
+// runs the shutdown hooks last registered first, returning the first error.
+func (auto *AutoGcd) runShutdownHooks() error {
+	auto.hookLock.Lock()
+	hooks := auto.shutdownHooks
+	auto.shutdownHooks = nil
+	auto.hookLock.Unlock()
+
+	var hookErr error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](auto); err != nil && hookErr == nil {
+			hookErr = err
+		}
+	}
+	return hookErr
+}
+
+// collects the tabs and subscriptions still open, called before Shutdown closes the tabs.
+func (auto *AutoGcd) leakedResources() *ResourceLeakReport {
+	report := &ResourceLeakReport{GoroutinesBefore: auto.goroutines, Tabs: make([]*LeakedTab, 0), Subscriptions: make([]*LeakedTab, 0)}
+	for id, tab := range auto.GetAllTabs() {
+		leaked := &LeakedTab{Id: id, Url: tab.Target.Url, Subscriptions: tab.addedSubscriptions()}
+		if _, ok := auto.startTabs[id]; !ok {
+			report.Tabs = append(report.Tabs, leaked)
+		}
+		if len(leaked.Subscriptions) > 0 {
+			report.Subscriptions = append(report.Subscriptions, leaked)
+		}
+	}
+	return report
+}
+
+// waits for go routines to exit and reports the leaks, called after the browser has been shut down.
+func (auto *AutoGcd) reportLeaks(report *ResourceLeakReport) {
+	deadline := time.Now().Add(leakSettleTime)
+	report.GoroutinesAfter = runtime.NumGoroutine()
+	for report.GoroutinesAfter > report.GoroutinesBefore && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+		report.GoroutinesAfter = runtime.NumGoroutine()
+	}
+	if report.GoroutinesAfter > report.GoroutinesBefore {
+		report.Stacks = goroutineStacks()
+	}
+
+	if auto.settings.leakHandler != nil {
+		auto.settings.leakHandler(report)
+		return
+	}
+	if report.Leaked() {
+		log.Printf("autogcd: leaks detected at shutdown:\n%s", report)
+	}
+}
+
+// returns the stacks of every go routine.
+func goroutineStacks() string {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+}
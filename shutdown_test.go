@@ -0,0 +1,55 @@
+package autogcd
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRunShutdownHooks(t *testing.T) {
+	auto := &AutoGcd{hookLock: &sync.Mutex{}}
+	order := make([]int, 0)
+	first := errors.New("first")
+	auto.OnShutdown(func(auto *AutoGcd) error {
+		order = append(order, 1)
+		return errors.New("last")
+	})
+	auto.OnShutdown(func(auto *AutoGcd) error {
+		order = append(order, 2)
+		return first
+	})
+	auto.OnShutdown(func(auto *AutoGcd) error {
+		order = append(order, 3)
+		return nil
+	})
+
+	if err := auto.runShutdownHooks(); err != first {
+		t.Fatalf("expected the first error returned got %v\n", err)
+	}
+	if len(order) != 3 || order[0] != 3 || order[1] != 2 || order[2] != 1 {
+		t.Fatalf("expected hooks in reverse order got %v\n", order)
+	}
+	if err := auto.runShutdownHooks(); err != nil || len(order) != 3 {
+		t.Fatalf("expected hooks to only run once\n")
+	}
+}
+
+func TestResourceLeakReport(t *testing.T) {
+	report := &ResourceLeakReport{GoroutinesBefore: 4, GoroutinesAfter: 4}
+	if report.Leaked() || report.String() != "no leaks detected" {
+		t.Fatalf("expected no leaks got %s\n", report)
+	}
+
+	report.Tabs = []*LeakedTab{{Id: "1", Url: "http://localhost/"}}
+	report.Subscriptions = []*LeakedTab{{Id: "2", Url: "about:blank", Subscriptions: []string{"Console.messageAdded", "Network.requestWillBeSent"}}}
+	report.GoroutinesAfter = 6
+	if !report.Leaked() {
+		t.Fatalf("expected leaks\n")
+	}
+	for _, expected := range []string{"tab 1 (http://localhost/) was not closed", "tab 2 (about:blank) is still subscribed to Console.messageAdded, Network.requestWillBeSent", "2 go routines leaked"} {
+		if !strings.Contains(report.String(), expected) {
+			t.Fatalf("expected %q in report:\n%s\n", expected, report)
+		}
+	}
+}
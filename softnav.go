@@ -0,0 +1,145 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"time"
+)
+
+// SoftNavigation describes a single page application route change, see OnSoftNavigation.
+type SoftNavigation struct {
+	Url         string        // the url pushed on to the history
+	PreviousUrl string        // the url of the top frame before the change
+	DOMChanges  int           // number of nodes inserted or removed while the page settled
+	Duration    time.Duration // time from the history change until the DOM settled
+}
+
+// pending soft navigation waiting for the DOM to settle
+type softNavigation struct {
+	nav      *SoftNavigation
+	start    time.Time
+	baseline int
+}
+
+// how long after the last DOM change a soft navigation is considered complete, and the most it waits
+const (
+	softNavigationQuiet   = 300 * time.Millisecond
+	softNavigationMaxWait = 5 * time.Second
+)
+
+// OnSoftNavigation calls handler whenever the top frame's url changes without loading a new document
+// (history.pushState, replaceState or a fragment change) and the DOM is significantly modified while the
+// page settles, which is how single page applications change routes. IsTransitioning returns true from the
+// history change until the DOM settles. Pass nil to remove the handler.
+func (t *Tab) OnSoftNavigation(handler SoftNavigationFunc) {
+	t.softNavLock.Lock()
+	defer t.softNavLock.Unlock()
+	t.softNavHandler = handler
+}
+
+// SetSoftNavigationThreshold sets how many DOM nodes must be inserted or removed after a same document
+// url change for it to be reported as a soft navigation, the default is 5. Nodes are counted as they are
+// reported by the debugger, an inserted subtree counts as its root plus its direct children.
+func (t *Tab) SetSoftNavigationThreshold(nodes int) {
+	t.softNavLock.Lock()
+	defer t.softNavLock.Unlock()
+	t.softNavThreshold = nodes
+}
+
+// records the top frame url so soft navigations can report where they came from
+func (t *Tab) setTopFrameUrl(url string) {
+	t.softNavLock.Lock()
+	defer t.softNavLock.Unlock()
+	t.topFrameUrl = url
+}
+
+// counts nodes inserted into or removed from the DOM
+func (t *Tab) countDOMChanges(nodes int) {
+	t.softNavLock.Lock()
+	t.domChangeCount += nodes
+	t.softNavLock.Unlock()
+}
+
+// called when the top frame navigates within the same document, starts waiting for the DOM to settle
+// unless a previous history change is still settling, in which case that one takes the new url.
+func (t *Tab) handleNavigatedWithinDocument(url string) {
+	t.softNavLock.Lock()
+	defer t.softNavLock.Unlock()
+
+	previous := t.topFrameUrl
+	t.topFrameUrl = url
+	if t.softNavPending != nil {
+		t.softNavPending.nav.Url = url
+		return
+	}
+
+	t.softNavPending = &softNavigation{
+		nav:      &SoftNavigation{Url: url, PreviousUrl: previous},
+		start:    time.Now(),
+		baseline: t.domChangeCount,
+	}
+	t.setIsTransitioning(true)
+	go t.settleSoftNavigation(t.softNavPending)
+}
+
+// waits until there have been no DOM changes for softNavigationQuiet then reports the soft navigation if
+// enough of the DOM changed.
+func (t *Tab) settleSoftNavigation(pending *softNavigation) {
+	defer t.recoverCallback("soft navigation")
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-t.exitCh:
+			return
+		}
+		lastChange, _ := t.lastNodeChangeTimeVal.Load().(time.Time)
+		quietSince := lastChange
+		if quietSince.Before(pending.start) {
+			quietSince = pending.start
+		}
+		if time.Since(quietSince) >= softNavigationQuiet || time.Since(pending.start) >= softNavigationMaxWait {
+			break
+		}
+	}
+
+	t.softNavLock.Lock()
+	t.softNavPending = nil
+	nav := pending.nav
+	nav.DOMChanges = t.domChangeCount - pending.baseline
+	nav.Duration = time.Since(pending.start)
+	handler := t.softNavHandler
+	threshold := t.softNavThreshold
+	t.softNavLock.Unlock()
+
+	if !t.IsNavigating() {
+		t.setIsTransitioning(false)
+	}
+	if handler != nil && nav.DOMChanges >= threshold {
+		handler(t, nav)
+	}
+}
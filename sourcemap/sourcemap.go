@@ -0,0 +1,334 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+/*
+Package sourcemap parses version 3 source maps so the original sources of bundled or minified
+scripts can be recovered, and positions in generated code mapped back to their original location.
+Index maps (maps with sections) are not supported.
+*/
+package sourcemap
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maximum source map size we will read.
+const maxSourceMapSize = 50 * 1024 * 1024
+
+const base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// ParseErr returned when the source map is invalid or unsupported.
+type ParseErr struct {
+	Message string
+}
+
+func (e *ParseErr) Error() string {
+	return "invalid source map: " + e.Message
+}
+
+// FetchErr returned when the source map could not be retrieved.
+type FetchErr struct {
+	Message string
+}
+
+func (e *FetchErr) Error() string {
+	return "unable to fetch source map: " + e.Message
+}
+
+// Mapping of a position in the generated code to its original source. Lines and columns are 0 based.
+type Mapping struct {
+	GeneratedLine   int
+	GeneratedColumn int
+	Source          string // resolved original source url, empty if the segment has no source
+	OriginalLine    int
+	OriginalColumn  int
+	Name            string // original symbol name, if any
+}
+
+// Map is a parsed source map.
+type Map struct {
+	Version        int           `json:"version"`
+	File           string        `json:"file"`
+	SourceRoot     string        `json:"sourceRoot"`
+	Sources        []string      `json:"sources"`
+	SourcesContent []string      `json:"sourcesContent"`
+	Names          []string      `json:"names"`
+	RawMappings    string        `json:"mappings"`
+	Sections       []interface{} `json:"sections"`
+	lines          [][]*Mapping  // decoded mappings indexed by generated line
+}
+
+// Parse a source map, decoding all of its mappings.
+func Parse(data []byte) (*Map, error) {
+	// maps may be prefixed with )]}' to prevent XSSI
+	text := strings.TrimSpace(string(data))
+	if strings.HasPrefix(text, ")]}") {
+		if idx := strings.Index(text, "\n"); idx != -1 {
+			text = text[idx+1:]
+		}
+	}
+
+	m := &Map{}
+	if err := json.Unmarshal([]byte(text), m); err != nil {
+		return nil, &ParseErr{Message: err.Error()}
+	}
+	if m.Version != 3 {
+		return nil, &ParseErr{Message: "unsupported version"}
+	}
+	if len(m.Sections) > 0 {
+		return nil, &ParseErr{Message: "index maps are not supported"}
+	}
+	if err := m.decode(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SourceContent returns the embedded content of the original source, matching on either the
+// name as listed in sources or its resolved url. Returns false if the content was not embedded.
+func (m *Map) SourceContent(source string) (string, bool) {
+	for i, name := range m.Sources {
+		if name != source && m.resolveSource(i) != source {
+			continue
+		}
+		if i < len(m.SourcesContent) {
+			return m.SourcesContent[i], true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// SourceContents returns every embedded original source keyed by its resolved url.
+func (m *Map) SourceContents() map[string]string {
+	contents := make(map[string]string, len(m.SourcesContent))
+	for i := range m.Sources {
+		if i < len(m.SourcesContent) {
+			contents[m.resolveSource(i)] = m.SourcesContent[i]
+		}
+	}
+	return contents
+}
+
+// Mappings returns all decoded mappings in generated order.
+func (m *Map) Mappings() []*Mapping {
+	mappings := make([]*Mapping, 0)
+	for _, line := range m.lines {
+		mappings = append(mappings, line...)
+	}
+	return mappings
+}
+
+// Lookup the original position of a 0 based line and column of the generated code. Returns the closest
+// mapping at or before the column on the same line, or false if there is none.
+func (m *Map) Lookup(line, column int) (*Mapping, bool) {
+	if line < 0 || line >= len(m.lines) {
+		return nil, false
+	}
+	var found *Mapping
+	for _, mapping := range m.lines[line] {
+		if mapping.GeneratedColumn > column {
+			break
+		}
+		found = mapping
+	}
+	if found == nil || found.Source == "" {
+		return nil, false
+	}
+	return found, true
+}
+
+// returns the source at index i prefixed with the sourceRoot.
+func (m *Map) resolveSource(i int) string {
+	if i < 0 || i >= len(m.Sources) {
+		return ""
+	}
+	source := m.Sources[i]
+	if m.SourceRoot == "" {
+		return source
+	}
+	if strings.HasSuffix(m.SourceRoot, "/") {
+		return m.SourceRoot + source
+	}
+	return m.SourceRoot + "/" + source
+}
+
+// decodes the base64 VLQ mappings string.
+func (m *Map) decode() error {
+	m.lines = make([][]*Mapping, 0)
+	var source, originalLine, originalColumn, name int
+
+	for lineNum, line := range strings.Split(m.RawMappings, ";") {
+		mappings := make([]*Mapping, 0)
+		generatedColumn := 0
+
+		for _, segment := range strings.Split(line, ",") {
+			if segment == "" {
+				continue
+			}
+			fields, err := decodeVLQ(segment)
+			if err != nil {
+				return err
+			}
+			if len(fields) != 1 && len(fields) != 4 && len(fields) != 5 {
+				return &ParseErr{Message: "invalid segment: " + segment}
+			}
+
+			generatedColumn += fields[0]
+			mapping := &Mapping{GeneratedLine: lineNum, GeneratedColumn: generatedColumn}
+			if len(fields) >= 4 {
+				source += fields[1]
+				originalLine += fields[2]
+				originalColumn += fields[3]
+				mapping.Source = m.resolveSource(source)
+				mapping.OriginalLine = originalLine
+				mapping.OriginalColumn = originalColumn
+			}
+			if len(fields) == 5 {
+				name += fields[4]
+				if name >= 0 && name < len(m.Names) {
+					mapping.Name = m.Names[name]
+				}
+			}
+			mappings = append(mappings, mapping)
+		}
+		m.lines = append(m.lines, mappings)
+	}
+	return nil
+}
+
+// decodes a single segment of base64 VLQ encoded values.
+func decodeVLQ(segment string) ([]int, error) {
+	values := make([]int, 0, 5)
+	value, shift := 0, uint(0)
+
+	for i := 0; i < len(segment); i++ {
+		digit := strings.IndexByte(base64Chars, segment[i])
+		if digit == -1 {
+			return nil, &ParseErr{Message: "invalid character in mappings: " + segment}
+		}
+		value += (digit & 31) << shift
+		if digit&32 != 0 {
+			shift += 5
+			continue
+		}
+
+		if value&1 == 1 {
+			values = append(values, -(value >> 1))
+		} else {
+			values = append(values, value>>1)
+		}
+		value, shift = 0, 0
+	}
+
+	if shift != 0 {
+		return nil, &ParseErr{Message: "truncated segment: " + segment}
+	}
+	return values, nil
+}
+
+// Resolve a sourceMappingURL relative to the url of the script it was found in.
+func Resolve(scriptUrl, sourceMapURL string) (string, error) {
+	if strings.HasPrefix(sourceMapURL, "data:") {
+		return sourceMapURL, nil
+	}
+	mapUrl, err := url.Parse(sourceMapURL)
+	if err != nil {
+		return "", err
+	}
+	base, err := url.Parse(scriptUrl)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(mapUrl).String(), nil
+}
+
+// Fetch the source map for a script, sourceMapURL may be relative to scriptUrl or a data: url.
+// If client is nil, http.DefaultClient is used.
+func Fetch(client *http.Client, scriptUrl, sourceMapURL string) (*Map, error) {
+	if sourceMapURL == "" {
+		return nil, &FetchErr{Message: "script has no source map: " + scriptUrl}
+	}
+
+	mapUrl, err := Resolve(scriptUrl, sourceMapURL)
+	if err != nil {
+		return nil, &FetchErr{Message: err.Error()}
+	}
+
+	if strings.HasPrefix(mapUrl, "data:") {
+		data, err := decodeDataURL(mapUrl)
+		if err != nil {
+			return nil, err
+		}
+		return Parse(data)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(mapUrl)
+	if err != nil {
+		return nil, &FetchErr{Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &FetchErr{Message: mapUrl + " returned " + resp.Status}
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxSourceMapSize))
+	if err != nil {
+		return nil, &FetchErr{Message: err.Error()}
+	}
+	return Parse(data)
+}
+
+// decodes a data: url, only base64 and plain (url encoded) data is supported.
+func decodeDataURL(dataUrl string) ([]byte, error) {
+	comma := strings.Index(dataUrl, ",")
+	if comma == -1 {
+		return nil, &FetchErr{Message: "invalid data url"}
+	}
+	header, payload := dataUrl[len("data:"):comma], dataUrl[comma+1:]
+
+	if strings.HasSuffix(header, ";base64") {
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, &FetchErr{Message: err.Error()}
+		}
+		return data, nil
+	}
+
+	data, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, &FetchErr{Message: err.Error()}
+	}
+	return []byte(data), nil
+}
@@ -0,0 +1,103 @@
+package sourcemap
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// generated from: src/a.js "function add(a, b) {\n  return a + b;\n}" minified to "function add(n,r){return n+r}"
+const testMap = `)]}'
+{
+	"version": 3,
+	"file": "min.js",
+	"sourceRoot": "webpack:///",
+	"sources": ["src/a.js"],
+	"sourcesContent": ["function add(a, b) {\n  return a + b;\n}"],
+	"names": ["add", "a", "b"],
+	"mappings": "AAAA,SAASA,IAAIC,EAAGC,GACd,OAAOD,EAAIC"
+}`
+
+func TestParseLookup(t *testing.T) {
+	m, err := Parse([]byte(testMap))
+	if err != nil {
+		t.Fatalf("error parsing: %s\n", err)
+	}
+
+	cases := []struct {
+		column int
+		line   int
+		col    int
+		name   string
+	}{
+		{0, 0, 0, ""},
+		{9, 0, 9, "add"},
+		{10, 0, 9, "add"},
+		{13, 0, 13, "a"},
+		{18, 1, 2, ""},
+		{25, 1, 9, "a"},
+		{27, 1, 13, "b"},
+	}
+
+	for _, c := range cases {
+		mapping, ok := m.Lookup(0, c.column)
+		if !ok {
+			t.Fatalf("expected mapping for column %d\n", c.column)
+		}
+		if mapping.Source != "webpack:///src/a.js" || mapping.OriginalLine != c.line || mapping.OriginalColumn != c.col || mapping.Name != c.name {
+			t.Fatalf("column %d expected %d:%d %q got %s %d:%d %q\n", c.column, c.line, c.col, c.name, mapping.Source, mapping.OriginalLine, mapping.OriginalColumn, mapping.Name)
+		}
+	}
+
+	if _, ok := m.Lookup(1, 0); ok {
+		t.Fatalf("expected no mapping for line 1\n")
+	}
+
+	content, ok := m.SourceContent("src/a.js")
+	if !ok || content != "function add(a, b) {\n  return a + b;\n}" {
+		t.Fatalf("expected embedded source content got %q\n", content)
+	}
+
+	if _, ok := m.SourceContents()["webpack:///src/a.js"]; !ok {
+		t.Fatalf("expected source contents to be keyed by resolved url\n")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse([]byte(`{"version": 2, "mappings": ""}`)); err == nil {
+		t.Fatalf("expected error for version 2\n")
+	}
+	if _, err := Parse([]byte(`{"version": 3, "mappings": "A!"}`)); err == nil {
+		t.Fatalf("expected error for invalid mappings\n")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/js/min.js.map" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, testMap)
+	}))
+	defer ts.Close()
+
+	m, err := Fetch(nil, ts.URL+"/js/min.js", "min.js.map")
+	if err != nil {
+		t.Fatalf("error fetching: %s\n", err)
+	}
+	if m.File != "min.js" {
+		t.Fatalf("expected min.js got %s\n", m.File)
+	}
+
+	if _, err := Fetch(nil, ts.URL+"/js/min.js", "missing.js.map"); err == nil {
+		t.Fatalf("expected error fetching missing map\n")
+	}
+
+	dataUrl := "data:application/json;charset=utf-8;base64," + base64.StdEncoding.EncodeToString([]byte(testMap))
+	if _, err := Fetch(nil, ts.URL+"/js/min.js", dataUrl); err != nil {
+		t.Fatalf("error decoding data url: %s\n", err)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+// StorageUsage of an origin returned by GetStorageUsage, sizes are in bytes.
+type StorageUsage struct {
+	Origin string             // the security origin, such as https://example.com
+	Usage  float64            // total bytes stored
+	Quota  float64            // bytes the origin may store
+	ByType map[string]float64 // storage type (indexeddb, local_storage, cache_storage, service_workers...) => bytes, unused types are omitted
+}
+
+// Ratio returns Usage as a fraction of Quota, 0 if the quota is unknown.
+func (u *StorageUsage) Ratio() float64 {
+	if u.Quota <= 0 {
+		return 0
+	}
+	return u.Usage / u.Quota
+}
+
+// NearQuota returns true if the origin has used at least threshold (0 to 1) of its quota.
+func (u *StorageUsage) NearQuota(threshold float64) bool {
+	return u.Quota > 0 && u.Ratio() >= threshold
+}
+
+// GetStorageUsage returns how much an origin is storing and its quota from Storage.getUsageAndQuota, so
+// pages approaching their quota can be detected. If origin is empty the current document's origin is used.
+func (t *Tab) GetStorageUsage(origin string) (*StorageUsage, error) {
+	if origin == "" {
+		rro, err := t.evaluateScript("location.origin", false)
+		if err != nil {
+			return nil, err
+		}
+		origin, _ = rro.Value.(string)
+		if origin == "" || origin == "null" {
+			return nil, &InvalidNavigationErr{Message: "the current document has no origin"}
+		}
+	}
+
+	usage, quota, breakdown, err := t.storage().GetUsageAndQuota(origin)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &StorageUsage{Origin: origin, Usage: usage, Quota: quota, ByType: make(map[string]float64)}
+	for _, forType := range breakdown {
+		if forType.Usage > 0 {
+			result.ByType[forType.StorageType] = forType.Usage
+		}
+	}
+	return result, nil
+}
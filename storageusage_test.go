@@ -0,0 +1,15 @@
+package autogcd
+
+import "testing"
+
+func TestStorageUsageRatio(t *testing.T) {
+	usage := &StorageUsage{Usage: 90, Quota: 100}
+	if usage.Ratio() != 0.9 || !usage.NearQuota(0.8) || usage.NearQuota(0.95) {
+		t.Fatalf("unexpected ratio %v\n", usage.Ratio())
+	}
+
+	unknown := &StorageUsage{Usage: 90}
+	if unknown.Ratio() != 0 || unknown.NearQuota(0) {
+		t.Fatalf("expected an unknown quota to never be near\n")
+	}
+}
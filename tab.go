@@ -26,14 +26,18 @@ package autogcd
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/wirepair/autogcd/robots"
 	"github.com/wirepair/gcd"
 	"github.com/wirepair/gcd/gcdapi"
 )
@@ -45,6 +49,9 @@ const maximumResourceBufferSize = -1
 
 const maximumPostDataSize = -1
 
+// base amount of time to wait between NavigateWithRetry attempts, multiplied by the attempt number.
+const retryBackoff = time.Second
+
 // ElementNotFoundErr when we are unable to find an element/nodeId
 type ElementNotFoundErr struct {
 	Message string
@@ -54,6 +61,11 @@ func (e *ElementNotFoundErr) Error() string {
 	return "Unable to find element " + e.Message
 }
 
+// Unwrap returns ErrElementNotFound so the error can be matched with errors.Is
+func (e *ElementNotFoundErr) Unwrap() error {
+	return ErrElementNotFound
+}
+
 // InvalidTabErr when we are unable to access a tab
 type InvalidTabErr struct {
 	Message string
@@ -63,6 +75,11 @@ func (e *InvalidTabErr) Error() string {
 	return "Unable to access tab: " + e.Message
 }
 
+// Unwrap returns ErrInvalidTab so the error can be matched with errors.Is
+func (e *InvalidTabErr) Unwrap() error {
+	return ErrInvalidTab
+}
+
 // InvalidNavigationErr when unable to navigate Forward or Back
 type InvalidNavigationErr struct {
 	Message string
@@ -72,6 +89,27 @@ func (e *InvalidNavigationErr) Error() string {
 	return e.Message
 }
 
+// Unwrap returns ErrInvalidNavigation so the error can be matched with errors.Is
+func (e *InvalidNavigationErr) Unwrap() error {
+	return ErrInvalidNavigation
+}
+
+// NavigationFailedErr when chrome refused or failed to load the navigated url, for example
+// net::ERR_NAME_NOT_RESOLVED or net::ERR_BLOCKED_BY_CLIENT.
+type NavigationFailedErr struct {
+	Url       string
+	ErrorText string
+}
+
+func (e *NavigationFailedErr) Error() string {
+	return "navigation to " + e.Url + " failed: " + e.ErrorText
+}
+
+// Unwrap returns ErrNavigationFailed so the error can be matched with errors.Is
+func (e *NavigationFailedErr) Unwrap() error {
+	return ErrNavigationFailed
+}
+
 // ScriptEvaluationErr returned when an injected script caused an error
 type ScriptEvaluationErr struct {
 	Message          string
@@ -83,6 +121,11 @@ func (e *ScriptEvaluationErr) Error() string {
 	return e.Message + " " + e.ExceptionText
 }
 
+// Unwrap returns ErrScriptEvaluation so the error can be matched with errors.Is
+func (e *ScriptEvaluationErr) Unwrap() error {
+	return ErrScriptEvaluation
+}
+
 // TimeoutErr when Tab.Navigate has timed out
 type TimeoutErr struct {
 	Message string
@@ -92,12 +135,100 @@ func (e *TimeoutErr) Error() string {
 	return "Timed out " + e.Message
 }
 
+// Unwrap returns ErrTimeout so the error can be matched with errors.Is
+func (e *TimeoutErr) Unwrap() error {
+	return ErrTimeout
+}
+
+// RobotsDisallowedErr when navigation was refused due to the site's robots.txt
+type RobotsDisallowedErr struct {
+	Url string
+}
+
+func (e *RobotsDisallowedErr) Error() string {
+	return "navigation disallowed by robots.txt: " + e.Url
+}
+
+// Unwrap returns ErrRobotsDisallowed so the error can be matched with errors.Is
+func (e *RobotsDisallowedErr) Unwrap() error {
+	return ErrRobotsDisallowed
+}
+
+// CrashedErr is returned when the tab crashed or the debugger was detached. Status and ErrorCode are
+// only set if chrome reported the renderer's termination status, OOM is true if it ran out of memory.
+type CrashedErr struct {
+	Reason    string // crashed, or the reason the inspector was detached
+	Status    string // termination status of the renderer (crashed, oom, killed, abnormal...)
+	ErrorCode int    // termination error code of the renderer
+	OOM       bool   // the renderer was terminated because it ran out of memory
+}
+
+func (e *CrashedErr) Error() string {
+	if e.Status != "" {
+		return "tab " + e.Reason + ": " + e.Status
+	}
+	return "tab " + e.Reason
+}
+
+// Unwrap returns ErrTabCrashed so the error can be matched with errors.Is
+func (e *CrashedErr) Unwrap() error {
+	return ErrTabCrashed
+}
+
 // GcdResponseFunc internal response function type
 type GcdResponseFunc func(target *gcd.ChromeTarget, payload []byte)
 
 // TabDisconnectedHandler is called when the tab crashes or the inspector was disconnected
 type TabDisconnectedHandler func(tab *Tab, reason string)
 
+// ScriptParsedFunc function for handling parsed scripts, see ListenScriptsParsed
+type ScriptParsedFunc func(tab *Tab, script *ScriptInfo)
+
+// PausedHandlerFunc function for handling the page pausing on a breakpoint, see SetPausedHandler
+type PausedHandlerFunc func(tab *Tab, paused *PauseInfo)
+
+// HookFunc function for handling calls to a function instrumented by HookFunction
+type HookFunc func(tab *Tab, call *HookCall)
+
+// FrameEventFunc function for handling frames being attached, navigated or detached
+type FrameEventFunc func(tab *Tab, event *FrameEvent)
+
+// ChildSessionFunc function for handling newly attached child sessions (out of process iframes, workers)
+type ChildSessionFunc func(tab *Tab, session *ChildSession)
+
+// SessionEventFunc function for handling events sent by a child session
+type SessionEventFunc func(session *ChildSession, payload []byte)
+
+// SessionLogFunc function for handling console messages and exceptions from a child session
+type SessionLogFunc func(session *ChildSession, entry *SessionLogEntry)
+
+// ElementAppearFunc function called with elements matching a watched selector, see OnElementAppear
+type ElementAppearFunc func(tab *Tab, ele *Element)
+
+// DOMSinkFunc function for handling values written to DOM XSS sinks, see InstrumentDOMSinks
+type DOMSinkFunc func(tab *Tab, call *DOMSinkCall)
+
+// MutationBatchFunc function for handling batches of mutations, see ObserveMutations
+type MutationBatchFunc func(tab *Tab, batch *MutationBatch)
+
+// SoftNavigationFunc function called when a single page application changes route, see OnSoftNavigation
+type SoftNavigationFunc func(tab *Tab, nav *SoftNavigation)
+
+// ConsoleEntryFunc function for handling filtered console entries, see GetConsoleEntries
+type ConsoleEntryFunc func(tab *Tab, entry *ConsoleEntry)
+
+// PopupFunc is called with a tab opened by another tab, see OnPopup
+type PopupFunc func(popup *Tab)
+
+// RequestInterceptFunc function deciding what happens to a request paused by InterceptRequests
+type RequestInterceptFunc func(tab *Tab, request *InterceptedRequest)
+
+// ErrorHandlerFunc is called with errors raised outside of a caller's go routine, see SetErrorHandler
+type ErrorHandlerFunc func(tab *Tab, err error)
+
+// TabActionFunc is an action performed against a tab, see DetectLeak
+type TabActionFunc func(tab *Tab) error
+
 // PromptHandlerFunc function to handle javascript dialog prompts as they occur, pass to SetJavaScriptPromptHandler
 // Internally this should call tab.Page.HandleJavaScriptDialog(accept bool, promptText string)
 type PromptHandlerFunc func(tab *Tab, message, promptType string)
@@ -120,12 +251,17 @@ type StorageFunc func(tab *Tab, eventType string, eventDetails *StorageEvent)
 // DomChangeHandlerFunc function to listen for DOM Node Change Events
 type DomChangeHandlerFunc func(tab *Tab, change *NodeChangeEvent)
 
+// RetryClassifierFunc decides if a navigation attempt should be retried given its result and error
+type RetryClassifierFunc func(result *NavigationResult, err error) bool
+
 // ConditionalFunc function to iteratively call until returns without error
 type ConditionalFunc func(tab *Tab) bool
 
 // Tab object for driving a specific tab and gathering elements.
 type Tab struct {
-	*gcd.ChromeTarget                            // underlying chrometarget
+	*gcd.ChromeTarget                            // the tab's domains, bound to the tab's current connection
+	targetLock            *sync.RWMutex          // protects connection
+	connection            *targetConnection      // current connection to the target, replaced when re-attaching
 	eleMutex              *sync.RWMutex          // locks our elements when added/removed.
 	elements              map[int]*Element       // our map of elements for this tab
 	topNodeId             atomic.Value           // the nodeId of the current top level #document
@@ -144,46 +280,219 @@ type Tab struct {
 	elementTimeout        time.Duration          // amount of time to wait for element readiness
 	stabilityTimeout      time.Duration          // amount of time to give up waiting for stability
 	stableAfter           time.Duration          // amount of time of no activity to consider the DOM stable
+	openTimeouts          tabTimeouts            // the timeouts after options were applied, restored by Reset
 	lastNodeChangeTimeVal atomic.Value           // timestamp of when the last node change occurred atomic because multiple go routines will modify
 	domChangeHandler      DomChangeHandlerFunc   // allows the caller to be notified of DOM change events.
-}
-
-// Creates a new tab using the underlying ChromeTarget
-func open(target *gcd.ChromeTarget) (*Tab, error) {
-	t := &Tab{ChromeTarget: target}
+	rateLimiter           *RateLimiter           // optional navigation rate limiter, may be shared between tabs
+	robots                *robots.Cache          // optional robots.txt policy, navigation to disallowed urls is refused
+	networkLock           *sync.RWMutex          // protects network handlers and navigation responses
+	networkEnabled        bool                   // has the Network domain been enabled
+	requestHandler        NetworkRequestHandlerFunc
+	responseHandler       NetworkResponseHandlerFunc
+	finishedHandler       NetworkFinishedHandlerFunc
+	navigationResponses   map[string]*NetworkResponse  // loaderId => top frame document responses seen during Navigate
+	resources             map[string]*trackedResource  // requestId => resources loaded since the last Navigate, see ResourceReport
+	resourceOrder         []string                     // requestIds in the order they were requested
+	redirectChain         []*RedirectHop               // redirects of the main document seen during the last Navigate
+	inflight              map[string]struct{}          // requestIds that have not finished or failed, see WaitNetworkIdle
+	lastNetworkActivity   time.Time                    // when a request was last sent, finished or failed
+	responseWaiters       []*ResponseWaiter            // pending ExpectResponse calls
+	har                   *harRecorder                 // collects traffic for ExportHAR while recording, see RecordHAR
+	harStopped            *harRecorder                 // the last recording, kept for ExportHAR after StopRecordHAR
+	fpsLock               *sync.Mutex                  // protects fpsMeter
+	fpsMeter              *fpsMeter                    // running frame rate meter, see StartFPSMeter
+	pauseLock             *sync.Mutex                  // protects pausedHandler and pausedTarget
+	pausedHandler         PausedHandlerFunc            // called when the page pauses on a breakpoint
+	pausedTarget          *targetConnection            // connection Debugger.paused is subscribed on
+	frameLock             *sync.RWMutex                // protects frameHandler
+	frameHandler          FrameEventFunc               // called for frame attached, navigated and detached events
+	sessionLock           *sync.RWMutex                // protects sessions and the child session handlers
+	sessions              map[string]*ChildSession     // sessionId => attached child targets
+	autoAttachEnabled     bool                         // has Target.setAutoAttach been enabled
+	attachFrames          bool                         // set up out of process iframes as they are attached
+	frameSessionHandler   ChildSessionFunc             // called for every out of process iframe attached
+	workerSessionHandler  ChildSessionFunc             // called for every worker attached, see ListenWorkers
+	bindingLock           *sync.RWMutex                // protects bindings, hooks, the mutation observer, sink instrumentation, newDocScripts and runtimeEnabled
+	bindings              map[string]bindingFunc       // page binding name => handler, see addBinding
+	hooks                 map[string]*hook             // hooked function path => handler, see HookFunction
+	mutationHandler       MutationBatchFunc            // called with batches from the injected MutationObserver
+	mutationScriptId      string                       // identifier of the observer's new document script
+	sinkHandler           DOMSinkFunc                  // called with values written to DOM XSS sinks, see InstrumentDOMSinks
+	sinkScriptId          string                       // identifier of the sink instrumentation's new document script
+	runtimeEnabled        bool                         // has the Runtime domain been enabled
+	crashLock             *sync.Mutex                  // protects crashErr
+	crashErr              *CrashedErr                  // why the tab crashed, nil if it has not
+	crashedNotifyCh       chan struct{}                // closed once the tab crashes or is detached
+	consoleDisabled       bool                         // the Console domain was not enabled, see WithoutConsoleDomain
+	domSyncMode           DOMSyncMode                  // how much of the DOM to request on document load
+	logger                *log.Logger                  // debug output goes here if set, otherwise the standard logger
+	watchLock             *sync.RWMutex                // protects watchers
+	watchers              map[string]ElementAppearFunc // selector => handler, see OnElementAppear
+	testIdAttribute       string                       // attribute GetByTestId matches on
+	locators              *LocatorRegistry             // resolves locators for Locate, FillForm and ClickAndWait
+	domChangeCount        int                          // nodes inserted or removed, see SetSoftNavigationThreshold
+	softNavLock           *sync.Mutex                  // protects the soft navigation fields
+	softNavHandler        SoftNavigationFunc           // called for route changes, see OnSoftNavigation
+	softNavThreshold      int                          // DOM changes needed for a url change to be a soft navigation
+	softNavPending        *softNavigation              // the url change currently waiting for the DOM to settle
+	topFrameUrl           string                       // last known url of the top frame
+	redirectLoopLock      *sync.Mutex                  // protects the client redirect fields and topNavigationRequests
+	redirectThreshold     int                          // client side redirects allowed before the page is stopped, see DetectRedirectLoops
+	clientRedirects       []string                     // the current chain of client side redirects of the top frame
+	redirectLoopErr       *RedirectLoopErr             // the chain which exceeded redirectThreshold, nil if none
+	redirectLoopCh        chan struct{}                // signals readyWait when a redirect loop is detected
+	topNavigationRequests int                          // navigations requested by the top frame, see ClickWithOptions
+	elementReadyMode      ElementReadyMode             // when elements only known by id become ready, see WithElementReadyMode
+	childNodeDepth        int                          // depth of children requested for added nodes, see WithChildNodeDepth
+	loaderLock            *sync.RWMutex                // protects topLoaderIds
+	topLoaderIds          []string                     // loaders of the most recent top frame navigations, newest last
+	reattachLock          *sync.Mutex                  // protects the re-attach fields
+	reattacher            reattachFunc                 // connects to the target again after a detach, nil if not opened by AutoGcd
+	reattaching           bool                         // a re-attach is in progress
+	reattachHandler       ReattachedFunc               // called after re-attaching, see OnReattach
+	consoleLock           *sync.RWMutex                // protects the console entry handler and options
+	consoleEntryHandler   ConsoleEntryFunc             // called for console entries, see GetConsoleEntries
+	consoleOptions        *ConsoleOptions              // filters for consoleEntryHandler
+	consoleWatchers       map[int]ConsoleEntryFunc     // internal watchers called with every Runtime console entry
+	consoleWatcherId      int                          // id of the last console watcher added
+	errorLock             *sync.Mutex                  // protects the page error collection fields and errorHandler
+	errorHandler          ErrorHandlerFunc             // called with panics recovered from callbacks, see SetErrorHandler
+	errorsEnabled         bool                         // have the domains CollectErrors requires been enabled
+	pageErrors            *PageErrors                  // errors observed since the last Navigate, see CollectErrors
+	middlewareLock        *sync.RWMutex                // protects middleware
+	middleware            []Middleware                 // wraps actions, see Use
+	findTab               findTabFunc                  // looks up other tabs of the AutoGcd, nil if not opened by AutoGcd
+	attachTab             findTabFunc                  // returns the AutoGcd's tab for a target, attaching to it if required, nil if not opened by AutoGcd
+	browserContextId      string                       // the browser context of tabs opened by NewIsolatedTab and their popups, empty for the default context
+	popupLock             *sync.RWMutex                // protects popupHandler and popupTarget
+	popupHandler          PopupFunc                    // called with pages opened by this tab, see OnPopup
+	popupTarget           *targetConnection            // connection Target.targetCreated is subscribed on
+	interceptLock         *sync.RWMutex                // protects interceptHandler, interceptTarget, credentials and authAttempts
+	interceptHandler      RequestInterceptFunc         // decides requests paused by the Fetch domain, see InterceptRequests
+	interceptTarget       *targetConnection            // connection the Fetch events are subscribed on
+	interceptUser         string                       // username for authentication challenges while intercepting
+	interceptPassword     string                       // password for authentication challenges while intercepting
+	authAttempts          map[string]struct{}          // requests already given credentials
+	resetOrigins          []string                     // origins whose data Reset clears, see SetResetOrigins
+	newDocScripts         map[string]string            // purpose => identifier of a new document script, see FreezeTime and SeedRandom
+	recordLock            *sync.RWMutex                // protects the session recording fields
+	tempLock              *sync.Mutex                  // protects the temp dir fields
+	tempRoot              string                       // directory TempDir is created in, os.TempDir if empty
+	tempDir               string                       // the tab's temp dir, empty until TempDir is called
+	retainOnFailure       bool                         // keep tempDir when a failed tab is closed
+	failed                bool                         // the tab was marked as failed, see MarkFailed
+	subscriptionLock      *sync.Mutex                  // protects subscriptions and openSubscriptions
+	subscriptions         map[string]struct{}          // events the tab is subscribed to, see Subscribe
+	openSubscriptions     map[string]struct{}          // events subscribed to by the time the tab finished opening
+	sessionRecorder       *sessionRecorder             // writes protocol traffic, see RecordSession
+	recordingTarget       *recordingTarget             // records commands sent by the domains while recording
+	stats                 *statsRecorder               // event handling measurements, see InternalStats
+}
+
+// Creates a new tab using the underlying ChromeTarget, options are applied before any domains are enabled.
+func open(target *targetConnection, opts ...TabOption) (*Tab, error) {
+	t := &Tab{}
+	t.targetLock = &sync.RWMutex{}
+	t.connection = target
+	t.ChromeTarget = &gcd.ChromeTarget{Target: target.info}
+	initDomains(t.ChromeTarget, t)
 	t.eleMutex = &sync.RWMutex{}
 	t.elements = make(map[int]*Element)
+	t.networkLock = &sync.RWMutex{}
+	t.navigationResponses = make(map[string]*NetworkResponse)
+	t.resources = make(map[string]*trackedResource)
+	t.resourceOrder = make([]string, 0)
+	t.inflight = make(map[string]struct{})
 	t.nodeChange = make(chan *NodeChangeEvent)
-	t.navigationCh = make(chan int, 1)  // for signaling navigation complete
-	t.docUpdateCh = make(chan struct{}) // wait for documentUpdate to be called during navigation
-	t.crashedCh = make(chan string)     // reason the tab crashed/was disconnected.
+	t.stats = newStatsRecorder()
+	t.navigationCh = make(chan int, 1)     // for signaling navigation complete
+	t.docUpdateCh = make(chan struct{}, 1) // wait for documentUpdate to be called during navigation
+	t.crashedCh = make(chan string)        // reason the tab crashed/was disconnected.
 	t.exitCh = make(chan struct{})
-	t.navigationTimeout = 30 * time.Second // default 30 seconds for timeout
-	t.elementTimeout = 5 * time.Second     // default 5 seconds for waiting for element.
-	t.stabilityTimeout = 2 * time.Second   // default 2 seconds before we give up waiting for stability
-	t.stableAfter = 300 * time.Millisecond // default 300 ms for considering the DOM stable
+	t.crashLock = &sync.Mutex{}
+	t.fpsLock = &sync.Mutex{}
+	t.pauseLock = &sync.Mutex{}
+	t.bindingLock = &sync.RWMutex{}
+	t.frameLock = &sync.RWMutex{}
+	t.sessionLock = &sync.RWMutex{}
+	t.sessions = make(map[string]*ChildSession)
+	t.bindings = make(map[string]bindingFunc)
+	t.hooks = make(map[string]*hook)
+	t.newDocScripts = make(map[string]string)
+	t.crashedNotifyCh = make(chan struct{})
+	t.redirectLoopLock = &sync.Mutex{}
+	t.redirectLoopCh = make(chan struct{}, 1)
+	t.watchLock = &sync.RWMutex{}
+	t.watchers = make(map[string]ElementAppearFunc)
+	t.setDefaultTimeouts()
 	t.domChangeHandler = nil
+	t.testIdAttribute = DefaultTestIdAttribute
+	t.locators = DefaultLocators
+	t.softNavLock = &sync.Mutex{}
+	t.softNavThreshold = 5
+	t.childNodeDepth = 1
+	t.loaderLock = &sync.RWMutex{}
+	t.reattachLock = &sync.Mutex{}
+	t.consoleLock = &sync.RWMutex{}
+	t.consoleWatchers = make(map[int]ConsoleEntryFunc)
+	t.errorLock = &sync.Mutex{}
+	t.middlewareLock = &sync.RWMutex{}
+	t.recordLock = &sync.RWMutex{}
+	t.tempLock = &sync.Mutex{}
+	t.popupLock = &sync.RWMutex{}
+	t.interceptLock = &sync.RWMutex{}
+	t.authAttempts = make(map[string]struct{})
+	t.subscriptionLock = &sync.Mutex{}
+	t.subscriptions = make(map[string]struct{})
+	t.pageErrors = &PageErrors{}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.saveOpenTimeouts()
+
+	if err := t.enableDomains(); err != nil {
+		return nil, err
+	}
+	t.disconnectedHandler = t.defaultDisconnectedHandler
+	t.subscribeEvents()
+	t.subscriptionLock.Lock()
+	t.openSubscriptions = make(map[string]struct{}, len(t.subscriptions))
+	for method := range t.subscriptions {
+		t.openSubscriptions[method] = struct{}{}
+	}
+	t.subscriptionLock.Unlock()
+	go t.listenDebuggerEvents()
+	return t, nil
+}
 
-	// enable various debugger services
+// enable various debugger services, called when the tab is opened and re-attached.
+func (t *Tab) enableDomains() error {
 	if _, err := t.Page.Enable(); err != nil {
-		return nil, err
+		return err
 	}
 
 	if _, err := t.DOM.Enable(); err != nil {
-		return nil, err
+		return err
 	}
 
-	if _, err := t.Console.Enable(); err != nil {
-		return nil, err
+	t.consoleLock.RLock()
+	consoleDisabled := t.consoleDisabled
+	t.consoleLock.RUnlock()
+	if !consoleDisabled {
+		if _, err := t.Console.Enable(); err != nil {
+			return err
+		}
 	}
 
 	if _, err := t.Debugger.Enable(); err != nil {
-		return nil, err
+		return err
 	}
-	t.disconnectedHandler = t.defaultDisconnectedHandler
-	t.subscribeEvents()
-	go t.listenDebuggerEvents()
-	return t, nil
+	// required for Target.targetCrashed which tells us why the renderer was terminated
+	if _, err := t.TargetApi.SetDiscoverTargets(true); err != nil {
+		t.debugf("unable to discover targets, termination status will not be available: %s\n", err)
+	}
+	return nil
 }
 
 // close our exitch.
@@ -216,8 +525,38 @@ func (t *Tab) SetDisconnectedHandler(handlerFn TabDisconnectedHandler) {
 	t.disconnectedHandler = handlerFn
 }
 
+// CrashErr returns why the tab crashed or was detached, or nil if it has not.
+func (t *Tab) CrashErr() *CrashedErr {
+	t.crashLock.Lock()
+	defer t.crashLock.Unlock()
+	if t.crashErr == nil {
+		return nil
+	}
+	crashErr := *t.crashErr
+	return &crashErr
+}
+
+// records the reason and/or termination status of a crash, the first call notifies any waiters.
+func (t *Tab) setCrashed(reason, status string, errorCode int) {
+	t.crashLock.Lock()
+	defer t.crashLock.Unlock()
+
+	if t.crashErr == nil {
+		t.crashErr = &CrashedErr{Reason: "crashed"}
+		close(t.crashedNotifyCh)
+	}
+	if reason != "" {
+		t.crashErr.Reason = reason
+	}
+	if status != "" {
+		t.crashErr.Status = status
+		t.crashErr.ErrorCode = errorCode
+		t.crashErr.OOM = status == "oom"
+	}
+}
+
 func (t *Tab) defaultDisconnectedHandler(tab *Tab, reason string) {
-	t.debugf("tab %s tabId: %s", reason, tab.ChromeTarget.Target.Id)
+	t.debugf("tab %s tabId: %s", reason, tab.Target.Id)
 }
 
 // SetNavigationTimeout to wait in seconds for navigations before giving up, default is 30 seconds
@@ -242,6 +581,18 @@ func (t *Tab) SetStabilityTime(stableAfter time.Duration) {
 	t.stableAfter = stableAfter
 }
 
+// SetRateLimiter to enforce a navigation policy on Navigate, pass nil to disable rate limiting.
+// The same limiter may be shared by multiple tabs.
+func (t *Tab) SetRateLimiter(limiter *RateLimiter) {
+	t.rateLimiter = limiter
+}
+
+// SetRobotsCache to have Navigate refuse urls disallowed by the site's robots.txt, returning
+// a RobotsDisallowedErr. Pass nil to stop checking robots.txt.
+func (t *Tab) SetRobotsCache(cache *robots.Cache) {
+	t.robots = cache
+}
+
 func (t *Tab) setIsNavigating(set bool) {
 	t.isNavigatingFlag.Store(set)
 }
@@ -293,13 +644,41 @@ func (t *Tab) GetTopNodeId() int {
 }
 
 // Navigate to a URL and does not return until the Page.loadEventFired event
-// as well as all setChildNode events have completed.
-// If successful, returns frameId.
-// If failed, returns frameId, friendly error text, and the error.
-func (t *Tab) Navigate(url string) (string, string, error) {
+// as well as all setChildNode events have completed. If a RateLimiter is set
+// Navigate will first wait for the policy to allow the request. If a robots.Cache is set
+// urls disallowed by robots.txt return a RobotsDisallowedErr.
+// Returns a NavigationResult containing the frameId, loaderId, friendly error text (if any) and the
+// main document's HTTP status and headers. The result is never nil, even on error. If chrome reports
+// error text, such as net::ERR_BLOCKED_BY_CLIENT, a NavigationFailedErr is returned as well.
+// To capture the main document response Navigate always enables the Network domain, which stays
+// enabled afterwards.
+// Internal pages (see IsInternalUrl) are ready once their document has loaded, they have no status and
+// are not rate limited. Navigate runs through the tab's middleware, see Use.
+func (t *Tab) Navigate(url string) (*NavigationResult, error) {
+	return t.NavigateCtx(context.Background(), url)
+}
+
+// NavigateCtx is Navigate which gives up when ctx is done, stopping the page from loading and returning
+// ctx.Err() along with whatever is known of the result.
+func (t *Tab) NavigateCtx(ctx context.Context, url string) (*NavigationResult, error) {
+	action := &Action{Name: ActionNavigate, Tab: t, Input: url}
+	err := t.runAction(action, func(action *Action) error {
+		result, err := t.navigate(ctx, action.Input)
+		action.Result = result
+		return err
+	})
+	result, ok := action.Result.(*NavigationResult)
+	if !ok || result == nil {
+		result = &NavigationResult{}
+	}
+	return result, err
+}
+
+func (t *Tab) navigate(ctx context.Context, url string) (*NavigationResult, error) {
+	result := &NavigationResult{}
 
 	if t.IsNavigating() {
-		return "", "", &InvalidNavigationErr{Message: "Unable to navigate, already navigating."}
+		return result, &InvalidNavigationErr{Message: "Unable to navigate, already navigating."}
 	}
 	t.setIsNavigating(true)
 	t.debugf("navigating to %s", url)
@@ -308,26 +687,128 @@ func (t *Tab) Navigate(url string) (string, string, error) {
 		t.setIsNavigating(false)
 	}()
 
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	if t.robots != nil {
+		allowed, err := t.robots.AllowedCtx(ctx, url)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return result, ctxErr
+		}
+		if err != nil {
+			return result, err
+		}
+		if !allowed {
+			return result, &RobotsDisallowedErr{Url: url}
+		}
+	}
+
+	internal := IsInternalUrl(url)
+	if t.rateLimiter != nil && !internal {
+		if err := t.rateLimiter.AcquireCtx(ctx, url, t.navigationTimeout); err != nil {
+			return result, err
+		}
+		defer t.rateLimiter.Release()
+	}
+
+	// the Network domain must be enabled to capture the main document response.
+	if err := t.enableNetwork(); err != nil {
+		t.debugf("unable to enable network for navigation: %s\n", err)
+	}
+	t.resetNavigationResponses()
+	t.resetClientRedirects()
+	t.ClearResources()
+	t.ClearErrors()
+	t.drainNavigationSignals()
+
 	navParams := &gcdapi.PageNavigateParams{Url: url, TransitionType: "typed"}
-	frameId, _, errorText, err := t.Page.NavigateWithParams(navParams)
+	frameId, loaderId, errorText, err := t.Page.NavigateWithParams(navParams)
+	result.FrameId = frameId
+	result.LoaderId = loaderId
+	result.ErrorText = errorText
 	if err != nil {
-		return "", errorText, err
+		return result, err
+	}
+	// chrome reports failures such as blocked or unresolvable urls here rather than as an error,
+	// no document will load so do not wait for one.
+	if errorText != "" {
+		return result, &NavigationFailedErr{Url: url, ErrorText: errorText}
 	}
 	t.lastNodeChangeTimeVal.Store(time.Now())
 
-	err = t.readyWait(url)
+	if internal {
+		result.Url, err = t.internalReadyWait(ctx, url, loaderId)
+		if err != nil {
+			return result, err
+		}
+		t.debugf("navigation complete")
+		return result, nil
+	}
+
+	err = t.readyWait(ctx, url, loaderId)
+	if err != nil && err == ctx.Err() {
+		t.Page.StopLoading()
+	}
+	result.setResponse(t.navigationResponse(loaderId))
+	if result.Response != nil {
+		t.addRedirectHop(result.Url, result.Status, result.StatusText)
+	}
 	if err != nil {
-		return frameId, "", err
+		return result, err
 	}
 	t.debugf("navigation complete")
-	return frameId, "", err
+	return result, nil
+}
+
+// NavigateWithRetry calls Navigate up to attempts times, retrying while classifierFn returns true.
+// If classifierFn is nil, RetryOnServerError is used. Waits an increasing amount of time between
+// attempts. Returns the result and error of the last attempt, or an InvalidNavigationErr with an empty
+// result if attempts is less than 1.
+func (t *Tab) NavigateWithRetry(url string, attempts int, classifierFn RetryClassifierFunc) (*NavigationResult, error) {
+	if attempts < 1 {
+		return &NavigationResult{}, &InvalidNavigationErr{Message: "navigation attempts must be at least 1"}
+	}
+	if classifierFn == nil {
+		classifierFn = RetryOnServerError
+	}
+
+	var result *NavigationResult
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		result, err = t.Navigate(url)
+		result.Attempts = attempt
+		if !classifierFn(result, err) {
+			break
+		}
+		if attempt < attempts {
+			t.debugf("retrying navigation to %s (attempt %d): %v\n", url, attempt, err)
+			time.Sleep(time.Duration(attempt) * retryBackoff)
+		}
+	}
+	return result, err
+}
+
+// RetryOnServerError is a RetryClassifierFunc which retries timeouts, chrome network
+// errors (net::ERR_*) and 5xx status codes.
+func RetryOnServerError(result *NavigationResult, err error) bool {
+	if err != nil {
+		if _, ok := err.(*TimeoutErr); ok {
+			return true
+		}
+		return strings.Contains(err.Error(), "net::ERR_")
+	}
+	if strings.HasPrefix(result.ErrorText, "net::ERR_") {
+		return true
+	}
+	return result.Status >= 500
 }
 
 // An undocumented method of determining if chromium failed to load
 // a page due to DNS or connection timeouts.
 func (t *Tab) DidNavigationFail() (bool, string) {
 	// if loadTimeData doesn't exist, or we get a js error, this means no error occurred.
-	rro, err := t.EvaluateScript("loadTimeData.data_.errorCode")
+	rro, err := t.evaluateScript("loadTimeData.data_.errorCode", false)
 	if err != nil {
 		return false, ""
 	}
@@ -343,24 +824,90 @@ func (t *Tab) DidNavigationFail() (bool, string) {
 // navigationCh waits for a Page.loadEventFired or timeout.
 // docUpdateCh waits for document updated event from Tab.documentUpdated
 // event processing to finish so we have a valid set of elements.
-func (t *Tab) readyWait(url string) error {
-	var navigated bool
+// If loaderId is set the document update only counts once the top frame has navigated
+// with that loader, so events from the previous document can not end the wait early. Returns ctx.Err()
+// if ctx is done first.
+func (t *Tab) readyWait(ctx context.Context, url, loaderId string) error {
+	var navigated, docUpdated bool
 	timeoutTimer := time.NewTimer(t.navigationTimeout)
 	defer timeoutTimer.Stop()
+	loaderTicker := time.NewTicker(50 * time.Millisecond)
+	defer loaderTicker.Stop()
 
 	for {
 		select {
 		case <-t.navigationCh:
 			navigated = true
 		case <-t.docUpdateCh:
-			return nil
+			docUpdated = true
+		case <-loaderTicker.C:
+		case <-t.crashedNotifyCh:
+			return t.CrashErr()
+		case <-t.redirectLoopCh:
+			return t.RedirectLoop()
+		case <-ctx.Done():
+			return ctx.Err()
 		case <-timeoutTimer.C:
 			msg := "navigating to: "
 			if navigated == true {
 				msg = "waiting for document updated failed for: "
 			}
+			if docUpdated == true {
+				msg = "waiting for loader " + loaderId + " to navigate failed for: "
+			}
 			return &TimeoutErr{Message: msg + url}
 		}
+		if docUpdated && (loaderId == "" || t.hasNavigatedLoader(loaderId)) {
+			return nil
+		}
+	}
+}
+
+// the number of top frame loader ids remembered, only the most recent few matter
+const maxTopLoaderIds = 16
+
+// records the loader of a top frame navigation
+func (t *Tab) addTopLoaderId(loaderId string) {
+	t.loaderLock.Lock()
+	defer t.loaderLock.Unlock()
+	t.topLoaderIds = append(t.topLoaderIds, loaderId)
+	if len(t.topLoaderIds) > maxTopLoaderIds {
+		t.topLoaderIds = t.topLoaderIds[len(t.topLoaderIds)-maxTopLoaderIds:]
+	}
+}
+
+// has the top frame navigated with loaderId recently
+func (t *Tab) hasNavigatedLoader(loaderId string) bool {
+	t.loaderLock.RLock()
+	defer t.loaderLock.RUnlock()
+	for _, id := range t.topLoaderIds {
+		if id == loaderId {
+			return true
+		}
+	}
+	return false
+}
+
+// LoaderId returns the loader of the document currently in the top frame. Each navigation, other than
+// those within the same document, gets a new loader so it can be used to tell documents apart.
+func (t *Tab) LoaderId() string {
+	t.loaderLock.RLock()
+	defer t.loaderLock.RUnlock()
+	if len(t.topLoaderIds) == 0 {
+		return ""
+	}
+	return t.topLoaderIds[len(t.topLoaderIds)-1]
+}
+
+// drains signals left over from a previous navigation
+func (t *Tab) drainNavigationSignals() {
+	for {
+		select {
+		case <-t.navigationCh:
+		case <-t.docUpdateCh:
+		default:
+			return
+		}
 	}
 }
 
@@ -428,6 +975,11 @@ func (t *Tab) BackEntry() (*gcdapi.PageNavigationEntry, error) {
 
 // Calls a function every tick until conditionFn returns true or timeout occurs.
 func (t *Tab) WaitFor(rate, timeout time.Duration, conditionFn ConditionalFunc) error {
+	return t.WaitForCtx(context.Background(), rate, timeout, conditionFn)
+}
+
+// WaitForCtx is WaitFor which returns ctx.Err() if ctx is done before conditionFn returns true or timeout occurs.
+func (t *Tab) WaitForCtx(ctx context.Context, rate, timeout time.Duration, conditionFn ConditionalFunc) error {
 	rateTicker := time.NewTicker(rate)
 	timeoutTimer := time.NewTimer(timeout)
 
@@ -438,6 +990,8 @@ func (t *Tab) WaitFor(rate, timeout time.Duration, conditionFn ConditionalFunc)
 
 	for {
 		select {
+		case <-ctx.Done():
+			return ctx.Err()
 		case <-timeoutTimer.C:
 			return &TimeoutErr{Message: "waiting for conditional func to return true"}
 		case <-rateTicker.C:
@@ -458,6 +1012,11 @@ func (t *Tab) WaitFor(rate, timeout time.Duration, conditionFn ConditionalFunc)
 // would be submitting an XHR based form that does a history.pushState and does *not* actually load a new
 // page but simply inserts and removes elements dynamically. Returns error only if we timed out.
 func (t *Tab) WaitStable() error {
+	return t.WaitStableCtx(context.Background())
+}
+
+// WaitStableCtx is WaitStable which returns ctx.Err() if ctx is done before the DOM is stable.
+func (t *Tab) WaitStableCtx(ctx context.Context) error {
 	checkRate := 150 * time.Millisecond
 	timeoutTimer := time.NewTimer(t.stabilityTimeout)
 
@@ -474,6 +1033,8 @@ func (t *Tab) WaitStable() error {
 
 	for {
 		select {
+		case <-ctx.Done():
+			return ctx.Err()
 		case <-timeoutTimer.C:
 			return &TimeoutErr{Message: "waiting for DOM stability"}
 		case <-stableCheck.C:
@@ -500,7 +1061,7 @@ func (t *Tab) GetScriptSource(scriptId string) (string, error) {
 // the page has loaded, it creates new nodeIds and all functions that look up elements (QuerySelector)
 // will fail.
 func (t *Tab) getDocument() (*Element, error) {
-	doc, err := t.DOM.GetDocument(-1, false)
+	doc, err := t.DOM.GetDocument(t.domSyncMode.depth(), false)
 	if err != nil {
 		return nil, err
 	}
@@ -538,9 +1099,29 @@ func (t *Tab) GetElementByNodeId(nodeId int) (*Element, bool) {
 	t.eleMutex.Lock()
 	t.elements[nodeId] = newEle // add non-ready element to our list.
 	t.eleMutex.Unlock()
+	if t.elementReadyMode == ElementReadyOnDescribe {
+		go t.describeElement(newEle)
+	}
 	return newEle, false
 }
 
+// populates a not ready element with its name and attributes, see ElementReadyOnDescribe.
+func (t *Tab) describeElement(ele *Element) {
+	nodeId := ele.NodeId()
+	node, err := t.DOM.DescribeNode(nodeId, 0, "", 0, false)
+	if err != nil {
+		t.debugf("error describing node %d: %s\n", nodeId, err)
+		return
+	}
+	if ele.IsReady() {
+		return
+	}
+	// described nodes only carry the backend id, and the children must come from setChildNodes
+	node.NodeId = nodeId
+	node.Children = nil
+	ele.populateElement(node)
+}
+
 // Returns the element given the x, y coordinates on the page, or returns error.
 func (t *Tab) GetElementByLocation(x, y int) (*Element, error) {
 	_, nodeId, err := t.DOM.GetNodeForLocation(x, y, false)
@@ -763,8 +1344,15 @@ func (t *Tab) MoveMouse(x, y float64) error {
 }
 
 // Sends keystrokes to whatever is focused, best called from Element.SendKeys which will
-// try to focus on the element first. Use \n for Enter, \b for backspace or \t for Tab.
+// try to focus on the element first. Use \n for Enter, \b for backspace or \t for Tab, and
+// SendKeyCombo for other keys or modifiers. Runs through the tab's middleware, see Use.
 func (t *Tab) SendKeys(text string) error {
+	return t.runAction(&Action{Name: ActionSendKeys, Tab: t, Input: text}, func(action *Action) error {
+		return t.sendKeys(action.Input)
+	})
+}
+
+func (t *Tab) sendKeys(text string) error {
 	inputParams := &gcdapi.InputDispatchKeyEventParams{TheType: "char"}
 
 	// loop over input, looking for system keys and handling them
@@ -847,12 +1435,59 @@ func (t *Tab) RemoveScriptFromOnLoad(scriptId string) error {
 
 // Evaluates script in the global context.
 func (t *Tab) EvaluateScript(scriptSource string) (*gcdapi.RuntimeRemoteObject, error) {
-	return t.evaluateScript(scriptSource, false)
+	return t.evaluateAction(context.Background(), ActionEvaluateScript, scriptSource, false)
+}
+
+// EvaluateScriptCtx is EvaluateScript which terminates the script and returns ctx.Err() if ctx is done
+// before the script returns.
+func (t *Tab) EvaluateScriptCtx(ctx context.Context, scriptSource string) (*gcdapi.RuntimeRemoteObject, error) {
+	return t.evaluateAction(ctx, ActionEvaluateScript, scriptSource, false)
 }
 
 // Evaluates script in the global context.
 func (t *Tab) EvaluatePromiseScript(scriptSource string) (*gcdapi.RuntimeRemoteObject, error) {
-	return t.evaluateScript(scriptSource, true)
+	return t.evaluateAction(context.Background(), ActionEvaluatePromiseScript, scriptSource, true)
+}
+
+// evaluates script through the tab's middleware.
+func (t *Tab) evaluateAction(ctx context.Context, name, scriptSource string, awaitPromise bool) (*gcdapi.RuntimeRemoteObject, error) {
+	action := &Action{Name: name, Tab: t, Input: scriptSource}
+	err := t.runAction(action, func(action *Action) error {
+		rro, err := t.evaluateScriptCtx(ctx, action.Input, awaitPromise)
+		action.Result = rro
+		return err
+	})
+	rro, _ := action.Result.(*gcdapi.RuntimeRemoteObject)
+	return rro, err
+}
+
+// evaluates script, terminating it if ctx is done before it returns.
+func (t *Tab) evaluateScriptCtx(ctx context.Context, scriptSource string, awaitPromise bool) (*gcdapi.RuntimeRemoteObject, error) {
+	if ctx.Done() == nil {
+		return t.evaluateScript(scriptSource, awaitPromise)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type evaluation struct {
+		rro *gcdapi.RuntimeRemoteObject
+		err error
+	}
+	evaluatedCh := make(chan *evaluation, 1)
+	go func() {
+		rro, err := t.evaluateScript(scriptSource, awaitPromise)
+		evaluatedCh <- &evaluation{rro: rro, err: err}
+	}()
+
+	select {
+	case evaluated := <-evaluatedCh:
+		return evaluated.rro, evaluated.err
+	case <-ctx.Done():
+		// stops synchronous scripts such as infinite loops, awaited promises are simply abandoned
+		t.Runtime.TerminateExecution()
+		return nil, ctx.Err()
+	}
 }
 
 // Evaluates script in the global context.
@@ -864,7 +1499,7 @@ func (t *Tab) evaluateScript(scriptSource string, awaitPromise bool) (*gcdapi.Ru
 	returnByValue := true
 	generatePreview := true
 	userGestures := true
-	rro, exception, err := overridenRuntimeEvaluate(t.ChromeTarget, scriptSource, objectGroup, includeCommandLineAPI, silent, contextId, returnByValue, generatePreview, userGestures, awaitPromise)
+	rro, exception, err := overridenRuntimeEvaluate(t.targeter(), scriptSource, objectGroup, includeCommandLineAPI, silent, contextId, returnByValue, generatePreview, userGestures, awaitPromise)
 	if err != nil {
 		return nil, err
 	}
@@ -874,6 +1509,74 @@ func (t *Tab) evaluateScript(scriptSource string, awaitPromise bool) (*gcdapi.Ru
 	return rro, nil
 }
 
+// Evaluates script which returns a DOM node, or an array (or NodeList) of DOM nodes, and returns them as
+// Elements. For arrays the position of each entry is kept, entries that are not nodes are returned as nil.
+// Nodes chrome has not told us about yet are returned as not ready Elements.
+func (t *Tab) evaluateElements(scriptSource string) ([]*Element, error) {
+	objectGroup := "autogcdElements"
+	rro, exception, err := overridenRuntimeEvaluate(t.targeter(), scriptSource, objectGroup, false, true, 0, false, false, true, false)
+	if err != nil {
+		return nil, err
+	}
+	if exception != nil {
+		return nil, &ScriptEvaluationErr{Message: "error executing script: ", ExceptionText: exception.Text, ExceptionDetails: exception}
+	}
+	defer t.Runtime.ReleaseObjectGroup(objectGroup)
+	return t.remoteObjectToElements(rro)
+}
+
+// converts a remote node, or array of remote nodes, to Elements.
+func (t *Tab) remoteObjectToElements(rro *gcdapi.RuntimeRemoteObject) ([]*Element, error) {
+	elements := make([]*Element, 0)
+	if rro == nil || rro.ObjectId == "" {
+		return elements, nil
+	}
+
+	if rro.Subtype == "node" {
+		nodeId, err := t.DOM.RequestNode(rro.ObjectId)
+		if err != nil {
+			return nil, err
+		}
+		ele, _ := t.GetElementByNodeId(nodeId)
+		return append(elements, ele), nil
+	}
+
+	if rro.Subtype != "array" && rro.Subtype != "nodelist" {
+		return elements, nil
+	}
+
+	props, _, _, err := t.Runtime.GetProperties(rro.ObjectId, true, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	indexed := make(map[int]*gcdapi.RuntimeRemoteObject)
+	length := 0
+	for _, prop := range props {
+		idx, err := strconv.Atoi(prop.Name)
+		if err != nil {
+			continue
+		}
+		indexed[idx] = prop.Value
+		if idx+1 > length {
+			length = idx + 1
+		}
+	}
+
+	elements = make([]*Element, length)
+	for idx, value := range indexed {
+		if value == nil || value.Subtype != "node" {
+			continue
+		}
+		nodeId, err := t.DOM.RequestNode(value.ObjectId)
+		if err != nil {
+			return nil, err
+		}
+		elements[idx], _ = t.GetElementByNodeId(nodeId)
+	}
+	return elements, nil
+}
+
 // Takes a screenshot of the currently loaded page (only the dimensions visible in browser window)
 func (t *Tab) GetScreenShot() ([]byte, error) {
 	var imgBytes []byte
@@ -933,7 +1636,7 @@ func (t *Tab) GetTitle() (string, error) {
 	var title string
 	var ok bool
 
-	resp, err := t.EvaluateScript("window.top.document.title")
+	resp, err := t.evaluateScript("window.top.document.title", false)
 	if err != nil {
 		return "", err
 	}
@@ -1006,6 +1709,15 @@ func (t *Tab) SetUserAgent(userAgent string) error {
 // Registers chrome to start retrieving console messages, caller must pass in call back
 // function to handle it.
 func (t *Tab) GetConsoleMessages(messageHandler ConsoleMessageFunc) {
+	t.consoleLock.Lock()
+	consoleDisabled := t.consoleDisabled
+	t.consoleDisabled = false
+	t.consoleLock.Unlock()
+	if consoleDisabled {
+		if _, err := t.Console.Enable(); err != nil {
+			t.debugf("unable to enable console: %s\n", err)
+		}
+	}
 	t.Subscribe("Console.messageAdded", t.defaultConsoleMessageAdded(messageHandler))
 }
 
@@ -1014,69 +1726,221 @@ func (t *Tab) GetConsoleMessages(messageHandler ConsoleMessageFunc) {
 func (t *Tab) StopConsoleMessages(shouldDisable bool) error {
 	var err error
 	t.Unsubscribe("Console.messageAdded")
+	t.consoleLock.Lock()
+	t.consoleEntryHandler = nil
+	t.consoleLock.Unlock()
 	if shouldDisable {
 		_, err = t.Console.Disable()
 	}
 	return err
 }
 
+// ListenFrameNavigations calls frameFn whenever a frame (including the top frame) is attached, navigated
+// or detached. Pass nil to stop listening.
+func (t *Tab) ListenFrameNavigations(frameFn FrameEventFunc) {
+	t.frameLock.Lock()
+	t.frameHandler = frameFn
+	t.frameLock.Unlock()
+}
+
+// called for every frame event, see tab_subscribers.go
+func (t *Tab) dispatchFrameEvent(event *FrameEvent) {
+	t.frameLock.RLock()
+	handlerFn := t.frameHandler
+	t.frameLock.RUnlock()
+
+	if handlerFn != nil {
+		handlerFn(t, event)
+	}
+}
+
+// GetRequestPostData returns the body of a request. Use it when NetworkRequest.HasPostData is set but
+// PostData is empty because the body was too large to be sent with the request event. Must be called
+// while chrome still has the request, typically from a network handler or soon after it completes.
+func (t *Tab) GetRequestPostData(requestId string) (string, error) {
+	return t.Network.GetRequestPostData(requestId)
+}
+
 // Listens to network traffic, each handler can be nil in which case we'll only call the handlers defined.
 func (t *Tab) GetNetworkTraffic(requestHandlerFn NetworkRequestHandlerFunc, responseHandlerFn NetworkResponseHandlerFunc, finishedHandlerFn NetworkFinishedHandlerFunc) error {
 	if requestHandlerFn == nil && responseHandlerFn == nil && finishedHandlerFn == nil {
 		return nil
 	}
-	_, err := t.Network.Enable(maximumTotalBufferSize, maximumResourceBufferSize, maximumPostDataSize)
-	if err != nil {
+	if err := t.enableNetwork(); err != nil {
 		return err
 	}
 
-	if requestHandlerFn != nil {
-		t.Subscribe("Network.requestWillBeSent", func(target *gcd.ChromeTarget, payload []byte) {
-			message := &gcdapi.NetworkRequestWillBeSentEvent{}
-			if err := json.Unmarshal(payload, message); err == nil {
-				p := message.Params
-				request := &NetworkRequest{RequestId: p.RequestId, FrameId: p.FrameId, LoaderId: p.LoaderId, DocumentURL: p.DocumentURL, Request: p.Request, Timestamp: p.Timestamp, Initiator: p.Initiator, RedirectResponse: p.RedirectResponse, Type: p.Type}
-				requestHandlerFn(t, request)
-			}
-		})
-	}
-
-	if responseHandlerFn != nil {
-		t.Subscribe("Network.responseReceived", func(target *gcd.ChromeTarget, payload []byte) {
-			message := &gcdapi.NetworkResponseReceivedEvent{}
-			if err := json.Unmarshal(payload, message); err == nil {
-				p := message.Params
-				response := &NetworkResponse{RequestId: p.RequestId, FrameId: p.FrameId, LoaderId: p.LoaderId, Response: p.Response, Timestamp: p.Timestamp, Type: p.Type}
-				responseHandlerFn(t, response)
-			}
-		})
-	}
-
-	if finishedHandlerFn != nil {
-		t.Subscribe("Network.loadingFinished", func(target *gcd.ChromeTarget, payload []byte) {
-			message := &gcdapi.NetworkLoadingFinishedEvent{}
-			if err := json.Unmarshal(payload, message); err == nil {
-				p := message.Params
-				finishedHandlerFn(t, p.RequestId, p.EncodedDataLength, p.Timestamp)
-			}
-		})
-	}
+	t.networkLock.Lock()
+	t.requestHandler = requestHandlerFn
+	t.responseHandler = responseHandlerFn
+	t.finishedHandler = finishedHandlerFn
+	t.networkLock.Unlock()
 	return nil
 }
 
-// Unsubscribes from network request/response events and disables the Network debugger.
-// Pass shouldDisable as true if you wish to disable the network service.
+// Stops calling the network handlers set by GetNetworkTraffic.
+// Pass shouldDisable as true if you wish to disable the network service, note the main
+// document response will then not be available from Navigate until it is re-enabled.
 func (t *Tab) StopNetworkTraffic(shouldDisable bool) error {
 	var err error
-	t.Unsubscribe("Network.requestWillBeSent")
-	t.Unsubscribe("Network.responseReceived")
-	t.Unsubscribe("Network.loadingFinished")
+	t.networkLock.Lock()
+	defer t.networkLock.Unlock()
+
+	t.requestHandler = nil
+	t.responseHandler = nil
+	t.finishedHandler = nil
 	if shouldDisable {
 		_, err = t.Network.Disable()
+		t.networkEnabled = false
 	}
 	return err
 }
 
+// enables the Network domain if it is not already enabled.
+func (t *Tab) enableNetwork() error {
+	t.networkLock.Lock()
+	defer t.networkLock.Unlock()
+
+	if t.networkEnabled {
+		return nil
+	}
+	if _, err := t.Network.Enable(maximumTotalBufferSize, maximumResourceBufferSize, maximumPostDataSize); err != nil {
+		return err
+	}
+	t.networkEnabled = true
+	return nil
+}
+
+// called for every Network.requestWillBeSent event, see tab_subscribers.go. Requests are tracked for ResourceReport
+// and redirects of the main document while navigating are kept for GetRedirectChain.
+func (t *Tab) handleNetworkRequest(request *NetworkRequest) {
+	t.networkLock.Lock()
+	t.trackResourceRequest(request)
+	if t.har != nil {
+		t.har.request(request)
+	}
+	t.inflight[request.RequestId] = struct{}{}
+	t.lastNetworkActivity = time.Now()
+	if request.RedirectResponse != nil && t.IsNavigating() && request.Type == "Document" && (t.GetTopFrameId() == "" || request.FrameId == t.GetTopFrameId()) {
+		redirect := request.RedirectResponse
+		location, _ := redirect.Headers["Location"].(string)
+		if location == "" {
+			location, _ = redirect.Headers["location"].(string)
+		}
+		t.redirectChain = append(t.redirectChain, &RedirectHop{Url: redirect.Url, Status: redirect.Status, StatusText: redirect.StatusText, Location: location})
+	}
+	handlerFn := t.requestHandler
+	t.networkLock.Unlock()
+
+	if handlerFn != nil {
+		handlerFn(t, request)
+	}
+}
+
+// called for every Network.responseReceived event. While navigating, document responses for the top
+// frame are kept so Navigate can return the status and headers.
+func (t *Tab) handleNetworkResponse(response *NetworkResponse) {
+	t.networkLock.Lock()
+	if t.IsNavigating() && response.Type == "Document" && (t.GetTopFrameId() == "" || response.FrameId == t.GetTopFrameId()) {
+		t.navigationResponses[response.LoaderId] = response
+	}
+	t.trackResourceResponse(response)
+	if t.har != nil {
+		t.har.response(response)
+	}
+	t.matchResponseWaiters(response)
+	handlerFn := t.responseHandler
+	t.networkLock.Unlock()
+
+	if response.Response != nil && response.Response.Status >= 400 {
+		failure := &FailedRequest{RequestId: response.RequestId, Url: response.Response.Url, Type: response.Type, Status: response.Response.Status, ErrorText: response.Response.StatusText}
+		t.recordPageError(func(errors *PageErrors) {
+			errors.FailedRequests = append(errors.FailedRequests, failure)
+		})
+	}
+
+	if handlerFn != nil {
+		handlerFn(t, response)
+	}
+}
+
+// called for every Network.loadingFinished event
+func (t *Tab) handleNetworkFinished(requestId string, dataLength, timeStamp float64) {
+	t.networkLock.Lock()
+	t.trackResourceFinished(requestId, dataLength)
+	delete(t.inflight, requestId)
+	t.lastNetworkActivity = time.Now()
+	t.finishResponseWaiters(requestId, false)
+	handlerFn := t.finishedHandler
+	recorder := t.har
+	var recorded *harEntry
+	if recorder != nil {
+		recorded = recorder.finished(requestId, timeStamp, dataLength)
+	}
+	t.networkLock.Unlock()
+
+	if recorded != nil && recorder.options.Bodies {
+		t.recordHARBody(recorder, recorded, requestId)
+	}
+
+	if handlerFn != nil {
+		handlerFn(t, requestId, dataLength, timeStamp)
+	}
+}
+
+// called for every Network.loadingFailed event, failed and canceled requests are no longer in flight.
+func (t *Tab) handleNetworkFailed(failure *FailedRequest) {
+	t.networkLock.Lock()
+	delete(t.inflight, failure.RequestId)
+	t.lastNetworkActivity = time.Now()
+	t.finishResponseWaiters(failure.RequestId, true)
+	if tracked, ok := t.resources[failure.RequestId]; ok {
+		failure.Url = tracked.Url
+	}
+	if t.har != nil {
+		t.har.failed(failure)
+	}
+	t.networkLock.Unlock()
+
+	if !failure.Canceled {
+		t.recordPageError(func(errors *PageErrors) {
+			errors.FailedRequests = append(errors.FailedRequests, failure)
+		})
+	}
+}
+
+func (t *Tab) resetNavigationResponses() {
+	t.networkLock.Lock()
+	t.navigationResponses = make(map[string]*NetworkResponse)
+	t.redirectChain = make([]*RedirectHop, 0)
+	t.networkLock.Unlock()
+}
+
+// GetRedirectChain returns the HTTP redirects followed by the last call to Navigate, in order, ending
+// with the final response. Each hop has the url that was requested and the status it returned.
+// Redirects made by meta refresh or script are separate navigations and are not included.
+func (t *Tab) GetRedirectChain() []*RedirectHop {
+	t.networkLock.RLock()
+	defer t.networkLock.RUnlock()
+
+	chain := make([]*RedirectHop, len(t.redirectChain))
+	copy(chain, t.redirectChain)
+	return chain
+}
+
+func (t *Tab) addRedirectHop(url string, status int, statusText string) {
+	t.networkLock.Lock()
+	t.redirectChain = append(t.redirectChain, &RedirectHop{Url: url, Status: status, StatusText: statusText})
+	t.networkLock.Unlock()
+}
+
+// returns the document response for the loaderId of a navigation, or nil.
+func (t *Tab) navigationResponse(loaderId string) *NetworkResponse {
+	t.networkLock.RLock()
+	defer t.networkLock.RUnlock()
+	return t.navigationResponses[loaderId]
+}
+
 // Listens for storage events, storageFn should switch on type of cleared, removed, added or updated.
 // cleared holds IsLocalStorage and SecurityOrigin values only.
 // removed contains above plus Key.
@@ -1180,13 +2044,32 @@ func (t *Tab) subscribeEvents() {
 	// This doesn't seem useful.
 	// t.subscribeInlineStyleInvalidated()
 
+	// Network Related
+	t.subscribeRequestWillBeSent()
+	t.subscribeResponseReceived()
+	t.subscribeLoadingFinished()
+	t.subscribeLoadingFailed()
+
 	// Navigation Related
 	t.subscribeLoadEvent()
+	t.subscribeDomContentEvent()
 	t.subscribeFrameLoadingEvent()
 	t.subscribeFrameFinishedEvent()
+	t.subscribeFrameAttached()
+	t.subscribeFrameNavigated()
+	t.subscribeFrameDetached()
+	t.subscribeNavigatedWithinDocument()
+	t.subscribeFrameRequestedNavigation()
+
+	// Runtime related
+	t.subscribeBindingCalled()
+	t.subscribeConsoleAPICalled()
+	t.subscribeExceptionThrown()
+	t.subscribeLogEntryAdded()
 
 	// Crash related
 	t.subscribeTargetCrashed()
+	t.subscribeTargetTerminated()
 	t.subscribeTargetDetached()
 }
 
@@ -1198,15 +2081,11 @@ func (t *Tab) listenDebuggerEvents() {
 		select {
 		case nodeChangeEvent := <-t.nodeChange:
 			t.debugf("%s\n", nodeChangeEvent.EventType)
-			t.handleNodeChange(nodeChangeEvent)
-			// if the caller registered a dom change listener, call it
-			if t.domChangeHandler != nil {
-				t.domChangeHandler(t, nodeChangeEvent)
-			}
+			t.processNodeChange(nodeChangeEvent)
 			t.lastNodeChangeTimeVal.Store(time.Now())
 		case reason := <-t.crashedCh:
 			if t.disconnectedHandler != nil {
-				go t.disconnectedHandler(t, reason)
+				go t.callDisconnectedHandler(reason)
 			}
 		case <-t.exitCh:
 			t.debugf("exiting...")
@@ -1215,6 +2094,27 @@ func (t *Tab) listenDebuggerEvents() {
 	}
 }
 
+// handles a node change and calls the dom change handler, recovering panics so the event loop keeps running.
+func (t *Tab) processNodeChange(change *NodeChangeEvent) {
+	defer t.recoverCallback(change.EventType.String())
+	start := time.Now()
+	t.handleNodeChange(change)
+	handling := time.Since(start)
+	// if the caller registered a dom change listener, call it
+	if t.domChangeHandler != nil {
+		start = time.Now()
+		t.domChangeHandler(t, change)
+		t.stats.nodeChangeHandled(handling, time.Since(start), true)
+		return
+	}
+	t.stats.nodeChangeHandled(handling, 0, false)
+}
+
+func (t *Tab) callDisconnectedHandler(reason string) {
+	defer t.recoverCallback("disconnect")
+	t.disconnectedHandler(t, reason)
+}
+
 // handle node change events, updating, inserting invalidating and removing
 func (t *Tab) handleNodeChange(change *NodeChangeEvent) {
 	// if we are shutting down, do not handle new node changes.
@@ -1254,8 +2154,18 @@ func (t *Tab) handleNodeChange(change *NodeChangeEvent) {
 			t.requestChildNodes(change.NodeId, 1)
 		}
 	case ChildNodeInsertedEvent:
+		if change.Node != nil {
+			t.countDOMChanges(1 + change.Node.ChildNodeCount)
+		}
 		t.handleChildNodeInserted(change.ParentNodeId, change.Node)
+		// only start matching when something is watched, pages insert nodes far more often than tabs watch them
+		if change.Node != nil && change.Node.NodeType == 1 {
+			if watchers := t.elementWatchers(); watchers != nil {
+				go t.matchInsertedNode(change.ParentNodeId, change.Node, watchers)
+			}
+		}
 	case ChildNodeRemovedEvent:
+		t.countDOMChanges(1)
 		t.handleChildNodeRemoved(change.ParentNodeId, change.NodeId)
 	}
 
@@ -1295,7 +2205,11 @@ func (t *Tab) handleDocumentUpdated() {
 	t.documentUpdated()
 	// notify if navigating that we received the document update event.
 	if t.IsNavigating() {
-		t.docUpdateCh <- struct{}{} // notify listeners document was updated
+		// never block, Navigate may have given up waiting
+		select {
+		case t.docUpdateCh <- struct{}{}: // notify listeners document was updated
+		default:
+		}
 	}
 }
 
@@ -1431,7 +2345,9 @@ func (t *Tab) addNodes(node *gcdapi.DOMNode) {
 	t.elements[newEle.id] = newEle
 	t.eleMutex.Unlock()
 	//log.Printf("Added new element: %s\n", newEle)
-	t.requestChildNodes(newEle.id, 1)
+	if t.childNodeDepth != 0 {
+		t.requestChildNodes(newEle.id, t.childNodeDepth)
+	}
 	if node.Children != nil {
 		// add child nodes
 		for _, v := range node.Children {
@@ -1445,7 +2361,12 @@ func (t *Tab) addNodes(node *gcdapi.DOMNode) {
 }
 
 func (t *Tab) debugf(format string, args ...interface{}) {
-	if t.debug {
+	if !t.debug {
+		return
+	}
+	if t.logger != nil {
+		t.logger.Printf(format, args...)
+	} else {
 		log.Printf(format, args...)
 	}
 }
@@ -28,10 +28,58 @@ import (
 	"encoding/json"
 	"github.com/wirepair/gcd"
 	"github.com/wirepair/gcd/gcdapi"
+	"sort"
+	"time"
 )
 
+// Subscribe binds callback to the event method, recording the events while RecordSession is active. It shadows
+// gcd.ChromeTarget.Subscribe so every subscription the tab makes is recorded, and a panicking callback is
+// reported to the ErrorHandlerFunc instead of crashing the program. The callback is passed the tab's
+// ChromeTarget, whose domains send through the tab's current connection.
+func (t *Tab) Subscribe(method string, callback func(*gcd.ChromeTarget, []byte)) {
+	t.subscriptionLock.Lock()
+	t.subscriptions[method] = struct{}{}
+	t.subscriptionLock.Unlock()
+
+	t.target().subscribe(method, func(payload []byte) {
+		defer t.recoverCallback(method)
+		t.recordLock.RLock()
+		recorder := t.sessionRecorder
+		t.recordLock.RUnlock()
+		if recorder != nil {
+			recorder.recordEvent(method, payload)
+		}
+		start := time.Now()
+		defer func() { t.stats.callbackReturned(method, time.Since(start)) }()
+		callback(t.ChromeTarget, payload)
+	})
+}
+
+// Unsubscribe stops calling the handler bound to the event method.
+func (t *Tab) Unsubscribe(method string) {
+	t.subscriptionLock.Lock()
+	delete(t.subscriptions, method)
+	t.subscriptionLock.Unlock()
+	t.target().unsubscribe(method)
+}
+
+// returns the events subscribed to since the tab was opened, sorted.
+func (t *Tab) addedSubscriptions() []string {
+	t.subscriptionLock.Lock()
+	defer t.subscriptionLock.Unlock()
+	added := make([]string, 0)
+	for method := range t.subscriptions {
+		if _, ok := t.openSubscriptions[method]; !ok {
+			added = append(added, method)
+		}
+	}
+	sort.Strings(added)
+	return added
+}
+
 func (t *Tab) subscribeTargetCrashed() {
 	t.Subscribe("Inspector.targetCrashed", func(target *gcd.ChromeTarget, payload []byte) {
+		t.setCrashed("crashed", "", 0)
 		select {
 		case t.crashedCh <- "crashed":
 		case <-t.exitCh:
@@ -48,6 +96,10 @@ func (t *Tab) subscribeTargetDetached() {
 		if err == nil {
 			reason = header.Params.Reason
 		}
+		if t.handleDetached(reason) {
+			return
+		}
+		t.setCrashed(reason, "", 0)
 
 		select {
 		case t.crashedCh <- reason:
@@ -56,9 +108,24 @@ func (t *Tab) subscribeTargetDetached() {
 	})
 }
 
+// Target.targetCrashed is sent for every discovered target, it carries the termination status (oom etc)
+// which Inspector.targetCrashed does not. The disconnected handler is called by Inspector.targetCrashed.
+func (t *Tab) subscribeTargetTerminated() {
+	t.Subscribe("Target.targetCrashed", func(target *gcd.ChromeTarget, payload []byte) {
+		header := &gcdapi.TargetTargetCrashedEvent{}
+		if err := json.Unmarshal(payload, header); err == nil && header.Params.TargetId == t.Target.Id {
+			t.setCrashed("crashed", header.Params.Status, header.Params.ErrorCode)
+		}
+	})
+}
+
 // our default loadFiredEvent handler, returns a response to resp channel to navigate once complete.
 func (t *Tab) subscribeLoadEvent() {
 	t.Subscribe("Page.loadEventFired", func(target *gcd.ChromeTarget, payload []byte) {
+		event := &gcdapi.PageLoadEventFiredEvent{}
+		if err := json.Unmarshal(payload, event); err == nil {
+			t.recordHARPageEvent(event.Params.Timestamp, true)
+		}
 		if t.IsNavigating() {
 			select {
 			case t.navigationCh <- 0:
@@ -69,6 +136,15 @@ func (t *Tab) subscribeLoadEvent() {
 	})
 }
 
+func (t *Tab) subscribeDomContentEvent() {
+	t.Subscribe("Page.domContentEventFired", func(target *gcd.ChromeTarget, payload []byte) {
+		event := &gcdapi.PageDomContentEventFiredEvent{}
+		if err := json.Unmarshal(payload, event); err == nil {
+			t.recordHARPageEvent(event.Params.Timestamp, false)
+		}
+	})
+}
+
 func (t *Tab) subscribeFrameLoadingEvent() {
 	t.Subscribe("Page.frameStartedLoading", func(target *gcd.ChromeTarget, payload []byte) {
 		t.debugf("frameStartedLoading: %s\n", string(payload))
@@ -99,6 +175,146 @@ func (t *Tab) subscribeFrameFinishedEvent() {
 	})
 }
 
+func (t *Tab) subscribeFrameAttached() {
+	t.Subscribe("Page.frameAttached", func(target *gcd.ChromeTarget, payload []byte) {
+		header := &gcdapi.PageFrameAttachedEvent{}
+		if err := json.Unmarshal(payload, header); err == nil {
+			t.dispatchFrameEvent(&FrameEvent{EventType: FrameAttachedEvent, FrameId: header.Params.FrameId, ParentId: header.Params.ParentFrameId})
+		}
+	})
+}
+
+func (t *Tab) subscribeFrameNavigated() {
+	t.Subscribe("Page.frameNavigated", func(target *gcd.ChromeTarget, payload []byte) {
+		header := &gcdapi.PageFrameNavigatedEvent{}
+		if err := json.Unmarshal(payload, header); err == nil && header.Params.Frame != nil {
+			frame := header.Params.Frame
+			if frame.ParentId == "" {
+				t.setTopFrameUrl(frame.Url)
+				t.addTopLoaderId(frame.LoaderId)
+			}
+			t.dispatchFrameEvent(&FrameEvent{EventType: FrameNavigatedEvent, FrameId: frame.Id, ParentId: frame.ParentId, Url: frame.Url, Name: frame.Name, LoaderId: frame.LoaderId, SecurityOrigin: frame.SecurityOrigin, UnreachableUrl: frame.UnreachableUrl})
+		}
+	})
+}
+
+func (t *Tab) subscribeNavigatedWithinDocument() {
+	t.Subscribe("Page.navigatedWithinDocument", func(target *gcd.ChromeTarget, payload []byte) {
+		header := &gcdapi.PageNavigatedWithinDocumentEvent{}
+		if err := json.Unmarshal(payload, header); err == nil && header.Params.FrameId == t.GetTopFrameId() {
+			t.handleNavigatedWithinDocument(header.Params.Url)
+		}
+	})
+}
+
+func (t *Tab) subscribeFrameDetached() {
+	t.Subscribe("Page.frameDetached", func(target *gcd.ChromeTarget, payload []byte) {
+		header := &gcdapi.PageFrameDetachedEvent{}
+		if err := json.Unmarshal(payload, header); err == nil {
+			t.dispatchFrameEvent(&FrameEvent{EventType: FrameDetachedEvent, FrameId: header.Params.FrameId})
+		}
+	})
+}
+
+// Network events are only sent once the Network domain is enabled by Navigate or GetNetworkTraffic.
+func (t *Tab) subscribeRequestWillBeSent() {
+	t.Subscribe("Network.requestWillBeSent", func(target *gcd.ChromeTarget, payload []byte) {
+		if request, err := parseNetworkRequest(payload); err == nil {
+			t.handleNetworkRequest(request)
+		}
+	})
+}
+
+func (t *Tab) subscribeResponseReceived() {
+	t.Subscribe("Network.responseReceived", func(target *gcd.ChromeTarget, payload []byte) {
+		if response, err := parseNetworkResponse(payload); err == nil {
+			t.handleNetworkResponse(response)
+		}
+	})
+}
+
+// parses a Network.requestWillBeSent event, shared with child sessions.
+func parseNetworkRequest(payload []byte) (*NetworkRequest, error) {
+	message := &gcdapi.NetworkRequestWillBeSentEvent{}
+	if err := json.Unmarshal(payload, message); err != nil {
+		return nil, err
+	}
+	p := message.Params
+	request := &NetworkRequest{RequestId: p.RequestId, FrameId: p.FrameId, LoaderId: p.LoaderId, DocumentURL: p.DocumentURL, Request: p.Request, Timestamp: p.Timestamp, WallTime: p.WallTime, Initiator: p.Initiator, RedirectResponse: p.RedirectResponse, Type: p.Type}
+	if p.Request != nil {
+		request.HasPostData = p.Request.HasPostData || p.Request.PostData != ""
+		request.PostData = p.Request.PostData
+	}
+	return request, nil
+}
+
+// parses a Network.responseReceived event, shared with child sessions.
+func parseNetworkResponse(payload []byte) (*NetworkResponse, error) {
+	message := &gcdapi.NetworkResponseReceivedEvent{}
+	if err := json.Unmarshal(payload, message); err != nil {
+		return nil, err
+	}
+	p := message.Params
+	return newNetworkResponse(p.RequestId, p.FrameId, p.LoaderId, p.Response, p.Timestamp, p.Type), nil
+}
+
+func (t *Tab) subscribeLoadingFinished() {
+	t.Subscribe("Network.loadingFinished", func(target *gcd.ChromeTarget, payload []byte) {
+		message := &gcdapi.NetworkLoadingFinishedEvent{}
+		if err := json.Unmarshal(payload, message); err == nil {
+			p := message.Params
+			t.handleNetworkFinished(p.RequestId, p.EncodedDataLength, p.Timestamp)
+		}
+	})
+}
+
+func (t *Tab) subscribeLoadingFailed() {
+	t.Subscribe("Network.loadingFailed", func(target *gcd.ChromeTarget, payload []byte) {
+		message := &gcdapi.NetworkLoadingFailedEvent{}
+		if err := json.Unmarshal(payload, message); err == nil {
+			t.handleNetworkFailed(parseLoadingFailed(message))
+		}
+	})
+}
+
+// Binding events are only sent once the Runtime domain is enabled by addBinding.
+func (t *Tab) subscribeBindingCalled() {
+	t.Subscribe("Runtime.bindingCalled", func(target *gcd.ChromeTarget, payload []byte) {
+		message := &gcdapi.RuntimeBindingCalledEvent{}
+		if err := json.Unmarshal(payload, message); err == nil {
+			t.handleBindingCalled(message.Params.Name, message.Params.Payload)
+		}
+	})
+}
+
+// Console api calls and exceptions are only sent once the Runtime domain is enabled.
+func (t *Tab) subscribeConsoleAPICalled() {
+	t.Subscribe("Runtime.consoleAPICalled", func(target *gcd.ChromeTarget, payload []byte) {
+		if entry, err := parseConsoleAPICalled(payload); err == nil {
+			t.handleConsoleAPICalled(entry)
+		}
+	})
+}
+
+func (t *Tab) subscribeExceptionThrown() {
+	t.Subscribe("Runtime.exceptionThrown", func(target *gcd.ChromeTarget, payload []byte) {
+		message := &gcdapi.RuntimeExceptionThrownEvent{}
+		if err := json.Unmarshal(payload, message); err == nil && message.Params.ExceptionDetails != nil {
+			t.handleExceptionThrown(message.Params.ExceptionDetails)
+		}
+	})
+}
+
+// Log entries are only sent once the Log domain is enabled by CollectErrors.
+func (t *Tab) subscribeLogEntryAdded() {
+	t.Subscribe("Log.entryAdded", func(target *gcd.ChromeTarget, payload []byte) {
+		message := &gcdapi.LogEntryAddedEvent{}
+		if err := json.Unmarshal(payload, message); err == nil && message.Params.Entry != nil {
+			t.handleLogEntry(message.Params.Entry)
+		}
+	})
+}
+
 func (t *Tab) subscribeSetChildNodes() {
 	// new nodes
 	t.Subscribe("DOM.setChildNodes", func(target *gcd.ChromeTarget, payload []byte) {
@@ -176,9 +392,13 @@ func (t *Tab) subscribeChildNodeRemoved() {
 }
 
 func (t *Tab) dispatchNodeChange(evt *NodeChangeEvent) {
+	t.stats.queued()
+	start := time.Now()
 	select {
 	case t.nodeChange <- evt:
+		t.stats.dequeued(time.Since(start), true)
 	case <-t.exitCh:
+		t.stats.dequeued(0, false)
 		return
 	}
 }
@@ -198,9 +418,6 @@ func (t *Tab) subscribeInlineStyleInvalidated() {
 func (t *Tab) subscribeDocumentUpdated() {
 	// node ids are no longer valid
 	t.Subscribe("DOM.documentUpdated", func(target *gcd.ChromeTarget, payload []byte) {
-		select {
-		case t.nodeChange <- &NodeChangeEvent{EventType: DocumentUpdatedEvent}:
-		case <-t.exitCh:
-		}
+		t.dispatchNodeChange(&NodeChangeEvent{EventType: DocumentUpdatedEvent})
 	})
 }
@@ -2,9 +2,17 @@ package autogcd
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"image/png"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
@@ -23,8 +31,54 @@ func TestTabNavigate(t *testing.T) {
 		t.Fatalf("error getting tab")
 	}
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "index.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "index.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+}
+
+func TestTabNavigateStatus(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	result, err := tab.Navigate(testServerAddr + "index.html")
+	if err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	if result.Status != 200 || result.Headers == nil {
+		t.Fatalf("expected 200 status with headers got: %d %v\n", result.Status, result.Headers)
+	}
+
+	if result.Response.Timing == nil || result.Response.Protocol == "" || result.Response.RemoteIPAddress == "" {
+		t.Fatalf("expected timing, protocol and remote address got: %v %s %s\n", result.Response.Timing, result.Response.Protocol, result.Response.RemoteIPAddress)
+	}
+
+	attempts := 0
+	classifierFn := func(result *NavigationResult, err error) bool {
+		attempts++
+		return result.Status == 404
+	}
+	result, err = tab.NavigateWithRetry(testServerAddr+"does_not_exist.html", 2, classifierFn)
+	if err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	if result.Status != 404 || result.Attempts != 2 || attempts != 2 {
+		t.Fatalf("expected 2 attempts with a 404 status got: %d attempts status %d\n", result.Attempts, result.Status)
+	}
+}
+
+func TestTabNavigateWithRetryAttempts(t *testing.T) {
+	tab := &Tab{}
+	result, err := tab.NavigateWithRetry("http://localhost/", 0, nil)
+	if !errors.Is(err, ErrInvalidNavigation) {
+		t.Fatalf("expected an InvalidNavigationErr got %v\n", err)
+	}
+	if result == nil {
+		t.Fatalf("expected a non nil result")
 	}
 }
 
@@ -37,8 +91,8 @@ func TestTabGetCurrentUrl(t *testing.T) {
 		t.Fatalf("error getting tab")
 	}
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "console.html?x=1"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "console.html?x=1"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 	tab.WaitStable()
 
@@ -61,8 +115,8 @@ func TestTabGetTitle(t *testing.T) {
 		t.Fatalf("error getting tab")
 	}
 	tab.DebugEvents(true)
-	if _, errorText, err := tab.Navigate(testServerAddr + "index.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "index.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	title, err := tab.GetTitle()
@@ -93,8 +147,8 @@ func TestTabGetConsoleMessage(t *testing.T) {
 	}
 	tab.GetConsoleMessages(msgHandler)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "console_log.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "console_log.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	select {
@@ -116,8 +170,8 @@ func TestTabGetPageSource(t *testing.T) {
 		t.Fatalf("error getting tab")
 	}
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "inner.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "inner.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 	tab.WaitStable()
 
@@ -144,8 +198,8 @@ func TestTabGetBodySize(t *testing.T) {
 		t.Fatalf("error getting tab")
 	}
 	//tab.Debug(true)
-	if _, errorText, err := tab.Navigate(testServerAddr + "big_body.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "big_body.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 	tab.WaitStable()
 
@@ -173,8 +227,8 @@ func TestTabFrameGetPageSource(t *testing.T) {
 		t.Fatalf("error getting tab")
 	}
 	//tab.Debug(true)
-	if _, errorText, err := tab.Navigate(testServerAddr + "iframe.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "iframe.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementByIdReady(tab, "innerfr"))
@@ -217,8 +271,8 @@ func TestTabGetFrameResources(t *testing.T) {
 		t.Fatalf("error getting tab")
 	}
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "iframe.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "iframe.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	resourceMap, err = tab.GetFrameResources()
@@ -262,8 +316,8 @@ func TestTabPromptHandler(t *testing.T) {
 	}
 	tab.GetConsoleMessages(msgHandler)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "prompt.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "prompt.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	select {
@@ -284,8 +338,8 @@ func TestTabNavigationTimeout(t *testing.T) {
 		t.Fatalf("error getting tab")
 	}
 	tab.SetNavigationTimeout(10)
-	if _, errorText, err := tab.Navigate(testServerAddr + "prompt.html"); err == nil {
-		t.Fatalf("did not get an error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "prompt.html"); err == nil {
+		t.Fatalf("did not get an error navigating: %s\n", err)
 	}
 }
 
@@ -308,8 +362,8 @@ func TestTabInjectScript(t *testing.T) {
 	tab.GetConsoleMessages(msgHandler)
 	tab.InjectScriptOnLoad("console.log('inject ' + location.href);")
 	tab.InjectScriptOnLoad("console.log('inject 2' + location.href);")
-	if _, errorText, err := tab.Navigate(testServerAddr + "script.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "script.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	wg.Wait()
@@ -333,8 +387,8 @@ func TestTabEvaluateScript(t *testing.T) {
 	}
 	tab.GetConsoleMessages(msgHandler)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 	_, errEval := tab.EvaluateScript("JSON.stringify(document)")
 	if errEval != nil {
@@ -362,8 +416,8 @@ func TestTabTwoTabCookies(t *testing.T) {
 		t.Fatalf("error getting tab")
 	}
 
-	if _, errorText, err := tab1.Navigate(testServerAddr + "cookie1.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab1.Navigate(testServerAddr + "cookie1.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	_, err = tab1.GetCookies()
@@ -371,8 +425,8 @@ func TestTabTwoTabCookies(t *testing.T) {
 		t.Fatalf("Error getting first tab cookies: %s\n", err)
 	}
 
-	if _, errorText, err := tab2.Navigate(testServerAddr + "cookie2.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab2.Navigate(testServerAddr + "cookie2.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 	_, err = tab2.GetCookies()
 	if err != nil {
@@ -405,16 +459,16 @@ func TestTabNetworkTraffic(t *testing.T) {
 		t.Fatalf("Error listening to network traffic: %s\n", err)
 	}
 
-	if _, errorText, err := tab1.Navigate(testServerAddr + "button.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab1.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	tab2, err := testAuto.NewTab()
 	if err != nil {
 		t.Fatalf("error getting tab")
 	}
-	if _, errorText, err := tab2.Navigate(testServerAddr + "console_log.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab2.Navigate(testServerAddr + "console_log.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 }
@@ -426,8 +480,8 @@ func TestTabWindows(t *testing.T) {
 	if err != nil {
 		t.Fatalf("error getting tab")
 	}
-	if _, errorText, err := tab.Navigate(testServerAddr + "window_main.html"); err != nil {
-		t.Fatalf("error opening first window: %s %s", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "window_main.html"); err != nil {
+		t.Fatalf("error opening first window: %s", err)
 	}
 	tab.WaitStable()
 
@@ -459,8 +513,8 @@ func TestTabAfterRedirect(t *testing.T) {
 	if err != nil {
 		t.Fatalf("error getting tab")
 	}
-	if _, errorText, err := tab.Navigate(testServerAddr + "redirect.html"); err != nil {
-		t.Fatalf("error opening first window: %s %s", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "redirect.html"); err != nil {
+		t.Fatalf("error opening first window: %s", err)
 	}
 
 	tab.WaitStable()
@@ -535,8 +589,8 @@ func TestTabGetElementAtLocation(t *testing.T) {
 		t.Fatalf("error getting tab")
 	}
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
-		t.Fatalf("error opening first window: %s %s", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("error opening first window: %s", err)
 	}
 	tab.WaitStable()
 
@@ -567,8 +621,8 @@ func TestTabFrameRedirect(t *testing.T) {
 
 	//tab.Debug(true)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "frame_top.html"); err != nil {
-		t.Fatalf("error opening first window: %s %s", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "frame_top.html"); err != nil {
+		t.Fatalf("error opening first window: %s", err)
 	}
 
 	tab.WaitStable()
@@ -642,38 +696,26 @@ func TestTabNavigationError(t *testing.T) {
 	if err != nil {
 		t.Fatalf("error getting tab")
 	}
-	// test invalid domain name
-	if _, errorText, err := tab.Navigate("http://asdfasdf"); err != nil {
-		t.Fatalf("error opening first window: %s %s", errorText, err)
-	}
-	tab.WaitStable()
-	ret, failText := tab.DidNavigationFail()
-	if ret == false {
-		t.Fatalf("navigation should have failed but got false back\n")
-	} else {
-		t.Logf("nav error: %s\n", failText)
-	}
-
-	// test unopen port
-	if _, errorText, err := tab.Navigate("http://127.0.0.1:19145"); err != nil {
-		t.Fatalf("error opening window: %s %s", errorText, err)
-	}
-	tab.WaitStable()
-
-	ret, failText = tab.DidNavigationFail()
-	if ret == false {
-		t.Fatalf("navigation should have failed but got false back\n")
-	} else {
-		t.Logf("nav error: %s\n", failText)
+	// test invalid domain name and unopen port
+	for _, failUrl := range []string{"http://asdfasdf", "http://127.0.0.1:19145"} {
+		result, err := tab.Navigate(failUrl)
+		var navErr *NavigationFailedErr
+		if !errors.As(err, &navErr) {
+			t.Fatalf("navigation to %s should have failed but got: %v\n", failUrl, err)
+		}
+		if result.ErrorText == "" || result.ErrorText != navErr.ErrorText || result.FrameId == "" {
+			t.Fatalf("expected result to carry the error text: %#v\n", result)
+		}
+		t.Logf("nav error: %s\n", navErr.ErrorText)
 	}
 
 	// test valid site
-	if _, errorText, err := tab.Navigate(testServerAddr); err != nil {
-		t.Fatalf("error opening window: %s %s", errorText, err)
+	if _, err := tab.Navigate(testServerAddr); err != nil {
+		t.Fatalf("error opening window: %s", err)
 	}
 	tab.WaitStable()
 
-	ret, failText = tab.DidNavigationFail()
+	ret, failText := tab.DidNavigationFail()
 	if ret == true {
 		t.Fatalf("navigation should have succeeded but got error back: %s\n", failText)
 	}
@@ -689,8 +731,8 @@ func TestTabSslError(t *testing.T) {
 	//tab.Debug(true)
 	// Test expired SSL certificate
 	// "--test-type", "--ignore-certificate-errors", should not return any errors
-	if _, errorText, err := tab.Navigate("https://expired.identrustssl.com/"); err != nil {
-		t.Fatalf("error opening first window: %s %s", errorText, err)
+	if _, err := tab.Navigate("https://expired.identrustssl.com/"); err != nil {
+		t.Fatalf("error opening first window: %s", err)
 	}
 
 	ret, failText := tab.DidNavigationFail()
@@ -701,8 +743,8 @@ func TestTabSslError(t *testing.T) {
 	// Test invalid CN name
 	// example.com ip https://93.184.216.34/
 	// "--test-type", "--ignore-certificate-errors", should not return any errors
-	if _, errorText, err := tab.Navigate("https://93.184.216.34/"); err != nil {
-		t.Fatalf("error opening invalid cn host: %s %s\n", errorText, err)
+	if _, err := tab.Navigate("https://93.184.216.34/"); err != nil {
+		t.Fatalf("error opening invalid cn host: %s\n", err)
 	}
 
 	ret, failText = tab.DidNavigationFail()
@@ -734,7 +776,7 @@ func TestTabChromeCrash(t *testing.T) {
 
 	tab.SetDisconnectedHandler(handlerFn)
 	// apparently chrome://crash does not disconnect any more? but this will. id: 4/22/2018
-	if _, _, err := tab.Navigate("chrome://inducebrowsercrashforrealz/"); err == nil {
+	if _, err := tab.Navigate("chrome://inducebrowsercrashforrealz/"); err == nil {
 		t.Fatalf("crash window did not cause error\n")
 	}
 	<-doneCh
@@ -753,8 +795,8 @@ func testMultiNavigateSendKeys(t *testing.T, wg *sync.WaitGroup, tab *Tab) {
 	}
 	tab.GetConsoleMessages(msgHandler)
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "input.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "input.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 
 	tab.WaitStable()
@@ -781,8 +823,8 @@ func TestTabSearchBySelector(t *testing.T) {
 		t.Fatalf("error getting tab")
 	}
 	//tab.Debug(true)
-	if _, errorText, err := tab.Navigate(testServerAddr + "table.html"); err != nil {
-		t.Fatalf("Error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "table.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
 	}
 	tab.WaitStable()
 
@@ -810,8 +852,8 @@ func TestTabFullPageScreenShot(t *testing.T) {
 		t.Fatalf("error getting tab")
 	}
 
-	if _, errorText, err := tab.Navigate(testServerAddr + "background.html"); err != nil {
-		t.Fatalf("error navigating: %s %s\n", errorText, err)
+	if _, err := tab.Navigate(testServerAddr + "background.html"); err != nil {
+		t.Fatalf("error navigating: %s\n", err)
 	}
 
 	tab.WaitStable()
@@ -835,3 +877,2540 @@ func TestTabFullPageScreenShot(t *testing.T) {
 	}
 
 }
+
+func TestTabDetectCaptcha(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "index.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	result, err := tab.DetectCaptcha()
+	if err != nil {
+		t.Fatalf("error detecting captcha: %s\n", err)
+	}
+	if result.Detected {
+		t.Fatalf("expected no captcha on index.html got: %s %s\n", result.Kind, result.Evidence)
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "captcha.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	result, err = tab.DetectCaptcha()
+	if err != nil {
+		t.Fatalf("error detecting captcha: %s\n", err)
+	}
+	if !result.Detected || result.Kind != HCaptcha {
+		t.Fatalf("expected hcaptcha to be detected got: %s %s\n", result.Kind, result.Evidence)
+	}
+}
+
+func TestTabResourceReport(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "resources.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	report, err := tab.ResourceReport()
+	if err != nil {
+		t.Fatalf("error getting resource report: %s\n", err)
+	}
+
+	if report.Type("Script").Count != 1 || report.Type("Stylesheet").Count != 1 {
+		t.Fatalf("expected one script and one stylesheet got: %d %d\n", report.Type("Script").Count, report.Type("Stylesheet").Count)
+	}
+
+	if report.Bytes == 0 || len(report.Largest) == 0 {
+		t.Fatalf("expected bytes to be recorded got: %v %d\n", report.Bytes, len(report.Largest))
+	}
+
+	if report.ThirdPartyCount != 0 {
+		t.Fatalf("expected no third party resources got: %d\n", report.ThirdPartyCount)
+	}
+}
+
+func TestTabMeasureWebVitals(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "index.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	vitals, err := tab.MeasureWebVitals(500 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("error measuring web vitals: %s\n", err)
+	}
+
+	if vitals.TTFB <= 0 {
+		t.Fatalf("expected time to first byte to be measured got: %s\n", vitals.TTFB)
+	}
+}
+
+func TestTabFPSMeter(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "scroll.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	if _, err := tab.StopFPSMeter(); err == nil {
+		t.Fatalf("expected error stopping a meter that was not started\n")
+	}
+
+	if err := tab.StartFPSMeter(); err != nil {
+		t.Fatalf("error starting fps meter: %s\n", err)
+	}
+
+	if _, err := tab.EvaluateScript("window.scrollTo(0, document.body.scrollHeight)"); err != nil {
+		t.Fatalf("error scrolling: %s\n", err)
+	}
+	time.Sleep(500 * time.Millisecond)
+
+	report, err := tab.StopFPSMeter()
+	if err != nil {
+		t.Fatalf("error stopping fps meter: %s\n", err)
+	}
+
+	if report.Duration < 500*time.Millisecond {
+		t.Fatalf("expected meter to run for at least 500ms got: %s\n", report.Duration)
+	}
+}
+
+func TestTabSimulateMemoryPressure(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "index.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	if err := tab.SimulateMemoryPressure(CriticalMemoryPressure); err != nil {
+		t.Fatalf("error simulating memory pressure: %s\n", err)
+	}
+
+	if crashErr := tab.CrashErr(); crashErr != nil {
+		t.Fatalf("tab should not have crashed: %s\n", crashErr)
+	}
+}
+
+func TestTabHeapSampling(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "index.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	samples, err := tab.SampleHeap(100*time.Millisecond, 500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("error sampling heap: %s\n", err)
+	}
+	if len(samples) < 2 || samples[0].Used == 0 {
+		t.Fatalf("expected multiple heap samples got: %d\n", len(samples))
+	}
+
+	report, err := tab.DetectLeak(5, func(tab *Tab) error {
+		_, err := tab.EvaluateScript("window.leaky = (window.leaky || []).concat(new Array(100000).fill('leak'))")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("error detecting leak: %s\n", err)
+	}
+
+	if report.Growth <= 0 {
+		t.Fatalf("expected heap to grow got: %v\n", report.Growth)
+	}
+}
+
+func TestTabSampleHeapArguments(t *testing.T) {
+	tab := &Tab{}
+	if _, err := tab.SampleHeap(0, time.Second); !errors.Is(err, ErrHeapSample) {
+		t.Fatalf("expected a HeapSampleErr for a zero interval got %v\n", err)
+	}
+	if _, err := tab.SampleHeap(time.Second, -time.Second); !errors.Is(err, ErrHeapSample) {
+		t.Fatalf("expected a HeapSampleErr for a negative duration got %v\n", err)
+	}
+}
+
+func TestTabFindDetachedNodes(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "index.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	count, err := tab.CountDOMNodes()
+	if err != nil {
+		t.Fatalf("error counting nodes: %s\n", err)
+	}
+	if count == 0 {
+		t.Fatalf("expected nodes in the document\n")
+	}
+
+	script := "var leaked = document.createElement('div'); leaked.id = 'leaked'; leaked.appendChild(document.createElement('span')); document.body.appendChild(leaked); document.body.removeChild(leaked); window.leakedNode = leaked;"
+	if _, err := tab.EvaluateScript(script); err != nil {
+		t.Fatalf("error creating detached node: %s\n", err)
+	}
+
+	report, err := tab.FindDetachedNodes()
+	if err != nil {
+		t.Fatalf("error finding detached nodes: %s\n", err)
+	}
+
+	found := false
+	for _, root := range report.Roots {
+		if root.Description == "div#leaked" && root.Size == 2 {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected div#leaked to be reported as detached\n")
+	}
+}
+
+func TestTabListenScriptsParsed(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	scriptCh := make(chan *ScriptInfo, 10)
+	tab.ListenScriptsParsed(func(tab *Tab, script *ScriptInfo) {
+		if script.SourceMapURL != "" {
+			scriptCh <- script
+		}
+	})
+	defer tab.StopScriptsParsed()
+
+	if _, err := tab.Navigate(testServerAddr + "sourcemap.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	var script *ScriptInfo
+	select {
+	case script = <-scriptCh:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for script with a source map\n")
+	}
+
+	sources, err := tab.GetOriginalSources(script)
+	if err != nil {
+		t.Fatalf("error getting original sources: %s\n", err)
+	}
+
+	if _, ok := sources["src/add.js"]; !ok {
+		t.Fatalf("expected src/add.js in original sources got: %v\n", sources)
+	}
+}
+
+func TestTabHookFunction(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	callCh := make(chan *HookCall, 10)
+	if err := tab.HookFunction("JSON.parse", func(tab *Tab, call *HookCall) {
+		callCh <- call
+	}); err != nil {
+		t.Fatalf("error hooking function: %s\n", err)
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "index.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	if _, err := tab.EvaluateScript(`JSON.parse('{"hooked": true}')`); err != nil {
+		t.Fatalf("error calling hooked function: %s\n", err)
+	}
+
+	select {
+	case call := <-callCh:
+		if call.Path != "JSON.parse" || len(call.Arguments) != 1 || call.Arguments[0] != `{"hooked": true}` {
+			t.Fatalf("unexpected hook call: %#v\n", call)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for hooked call\n")
+	}
+
+	if err := tab.UnhookFunction("JSON.parse"); err != nil {
+		t.Fatalf("error unhooking function: %s\n", err)
+	}
+}
+
+func TestTabGetRequestPostData(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "index.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	requestCh := make(chan *NetworkRequest, 1)
+	requestHandlerFn := func(callerTab *Tab, request *NetworkRequest) {
+		if request.HasPostData {
+			requestCh <- request
+		}
+	}
+	if err := tab.GetNetworkTraffic(requestHandlerFn, nil, nil); err != nil {
+		t.Fatalf("Error listening to network traffic: %s\n", err)
+	}
+	defer tab.StopNetworkTraffic(false)
+
+	if _, err := tab.EvaluateScript("fetch('index.html', {method: 'POST', body: 'x'.repeat(200000)})"); err != nil {
+		t.Fatalf("error sending post: %s\n", err)
+	}
+
+	var request *NetworkRequest
+	select {
+	case request = <-requestCh:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for post request\n")
+	}
+
+	postData, err := tab.GetRequestPostData(request.RequestId)
+	if err != nil {
+		t.Fatalf("error getting post data: %s\n", err)
+	}
+	if len(postData) != 200000 {
+		t.Fatalf("expected 200000 bytes of post data got: %d\n", len(postData))
+	}
+}
+
+func TestTabGetRedirectChain(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	// the test file server redirects /index.html to /
+	if _, err := tab.Navigate(testServerAddr + "index.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	chain := tab.GetRedirectChain()
+	if len(chain) != 2 {
+		t.Fatalf("expected a redirect and the final response got %d hops\n", len(chain))
+	}
+
+	if chain[0].Url != testServerAddr+"index.html" || chain[0].Status != 301 || chain[0].Location == "" {
+		t.Fatalf("expected 301 redirect from index.html got: %s %d %s\n", chain[0].Url, chain[0].Status, chain[0].Location)
+	}
+
+	if chain[1].Url != testServerAddr || chain[1].Status != 200 {
+		t.Fatalf("expected final 200 response got: %s %d\n", chain[1].Url, chain[1].Status)
+	}
+}
+
+func TestTabListenFrameNavigations(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	eventCh := make(chan *FrameEvent, 20)
+	tab.ListenFrameNavigations(func(tab *Tab, event *FrameEvent) {
+		eventCh <- event
+	})
+
+	if _, err := tab.Navigate(testServerAddr + "iframe.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	tab.ListenFrameNavigations(nil)
+
+	attached := false
+	childNavigated := false
+	for len(eventCh) > 0 {
+		event := <-eventCh
+		if event.EventType == FrameAttachedEvent && event.ParentId != "" {
+			attached = true
+		}
+		if event.EventType == FrameNavigatedEvent && event.ParentId != "" && event.Url != "" {
+			childNavigated = true
+		}
+	}
+
+	if !attached || !childNavigated {
+		t.Fatalf("expected child frame to be attached and navigated got: %t %t\n", attached, childNavigated)
+	}
+}
+
+func TestTabAttachFrameTargets(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	sessionCh := make(chan *ChildSession, 1)
+	if err := tab.AttachFrameTargets(func(tab *Tab, session *ChildSession) {
+		sessionCh <- session
+	}); err != nil {
+		t.Fatalf("error attaching to frame targets: %s\n", err)
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "oopif.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	var session *ChildSession
+	select {
+	case session = <-sessionCh:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for out of process iframe\n")
+	}
+
+	if err := tab.WaitFor(testWaitRate, testWaitTimeout, func(tab *Tab) bool {
+		value, err := session.EvaluateScript("document.readyState")
+		return err == nil && value == "complete"
+	}); err != nil {
+		t.Fatalf("error waiting for iframe to load: %s\n", err)
+	}
+
+	value, err := session.EvaluateScript("document.body.innerText")
+	if err != nil {
+		t.Fatalf("error evaluating in child session: %s\n", err)
+	}
+	if text, _ := value.(string); text == "" {
+		t.Fatalf("expected iframe body text\n")
+	}
+}
+
+func TestTabListenWorkers(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	logCh := make(chan *SessionLogEntry, 10)
+	if err := tab.ListenWorkers(func(tab *Tab, session *ChildSession) {
+		if err := session.ListenLogs(func(session *ChildSession, entry *SessionLogEntry) {
+			logCh <- entry
+		}); err != nil {
+			t.Errorf("error listening to worker logs: %s\n", err)
+		}
+	}); err != nil {
+		t.Fatalf("error listening for workers: %s\n", err)
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "worker.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	select {
+	case entry := <-logCh:
+		if entry.Level != "log" || entry.Text != "hello from worker" {
+			t.Fatalf("unexpected worker log entry: %#v\n", entry)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for worker log\n")
+	}
+}
+
+func TestTabNewTabWithOptions(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	var buf bytes.Buffer
+	tab, err := testAuto.NewTabWithOptions(WithNavigationTimeout(10*time.Second), WithoutConsoleDomain(),
+		WithDOMSyncMode(DOMSyncLazy), WithLogger(log.New(&buf, "", 0)))
+	if err != nil {
+		t.Fatalf("error getting tab: %s\n", err)
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	ele, _, err := tab.GetElementById("button")
+	if err != nil {
+		t.Fatalf("error finding button with lazy dom sync: %s\n", err)
+	}
+
+	ele.WaitForReady()
+	if tagName, _ := ele.GetTagName(); tagName != "button" {
+		t.Fatalf("expected button element got: %s\n", tagName)
+	}
+
+	if buf.Len() == 0 {
+		t.Fatalf("expected debug output to be written to the logger\n")
+	}
+}
+
+func TestTabOnElementAppear(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	appearCh := make(chan *Element, 1)
+	tab.OnElementAppear("#widget .accept", func(tab *Tab, ele *Element) {
+		appearCh <- ele
+	})
+
+	if _, err := tab.Navigate(testServerAddr + "appear.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	select {
+	case ele := <-appearCh:
+		if tagName, _ := ele.GetTagName(); tagName != "button" {
+			t.Fatalf("expected button to appear got: %s\n", tagName)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for element to appear\n")
+	}
+}
+
+func TestTabObserveMutations(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	batchCh := make(chan *MutationBatch, 10)
+	if err := tab.ObserveMutations(func(tab *Tab, batch *MutationBatch) {
+		batchCh <- batch
+	}); err != nil {
+		t.Fatalf("error observing mutations: %s\n", err)
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "appear.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case batch := <-batchCh:
+			for _, record := range batch.Records {
+				if record.Type == "childList" && len(record.AddedTags) > 0 && record.AddedTags[0] == "div" && record.Target == "body" {
+					if err := tab.StopObservingMutations(); err != nil {
+						t.Fatalf("error stopping mutation observer: %s\n", err)
+					}
+					return
+				}
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for widget insertion to be observed\n")
+		}
+	}
+}
+
+func TestTabExtract(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "extract.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	rows, err := tab.Extract(Schema{"title": "h2 a", "link": "h2 a@href", "price": ".price", "sku": "@data-sku"}, "#products .card")
+	if err != nil {
+		t.Fatalf("error extracting: %s\n", err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows got %d\n", len(rows))
+	}
+
+	if rows[1]["title"] != "Second" || rows[1]["link"] != "/second" || rows[1]["price"] != "$20" || rows[1]["sku"] != "b2" {
+		t.Fatalf("unexpected row: %#v\n", rows[1])
+	}
+
+	if rows[2]["price"] != "" {
+		t.Fatalf("expected missing price to be empty got: %s\n", rows[2]["price"])
+	}
+}
+
+func TestTabExtractArticle(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "article.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	article, err := tab.ExtractArticle()
+	if err != nil {
+		t.Fatalf("error extracting article: %s\n", err)
+	}
+
+	if article.Title != "Automating Chrome" || article.Byline != "isaac dawson" {
+		t.Fatalf("unexpected article metadata: %#v\n", article)
+	}
+
+	if article.PublishedTime.Year() != 2018 {
+		t.Fatalf("expected published time to be parsed got: %s\n", article.PublishedTime)
+	}
+
+	if !strings.Contains(article.Text, "chrome debugger protocol") || strings.Contains(article.Text, "newsletter") {
+		t.Fatalf("unexpected article text: %s\n", article.Text)
+	}
+
+	if strings.Contains(article.HTML, "<script") {
+		t.Fatalf("expected scripts to be removed from html: %s\n", article.HTML)
+	}
+}
+
+func TestTabCollectImages(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "images.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	images, err := tab.CollectImages(&ImageOptions{Download: true})
+	if err != nil {
+		t.Fatalf("error collecting images: %s\n", err)
+	}
+
+	if len(images) != 2 {
+		t.Fatalf("expected 2 images got %d\n", len(images))
+	}
+
+	for _, image := range images {
+		if !image.Loaded || image.Width != 4 || image.Height != 3 {
+			t.Fatalf("expected %s (%s) to be loaded with natural dimensions: %#v\n", image.Url, image.Alt, image)
+		}
+		if !bytes.HasPrefix(image.Data, []byte("\x89PNG")) {
+			t.Fatalf("expected %s to be downloaded\n", image.Url)
+		}
+	}
+}
+
+func TestTabAutoScroll(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "infinite.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	report, err := tab.AutoScroll(&ScrollOptions{ItemSelector: ".item", StepTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("error scrolling: %s\n", err)
+	}
+
+	if report.Items != 30 {
+		t.Fatalf("expected all 30 items to be loaded got %d\n", report.Items)
+	}
+
+	if report.StopReason != ScrollNoNewContent {
+		t.Fatalf("expected to stop when no new content appeared got: %s\n", report.StopReason)
+	}
+
+	if len(report.Steps) == 0 || report.Steps[0].NewItems != 10 {
+		t.Fatalf("expected first step to add 10 items: %#v\n", report.Steps)
+	}
+}
+
+func TestTabWaitForResponse(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "ajax.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	waiter, err := tab.ExpectResponse("*/api.json?page=*")
+	if err != nil {
+		t.Fatalf("error expecting response: %s\n", err)
+	}
+
+	ele, _, err := tab.GetElementById("load")
+	if err != nil {
+		t.Fatalf("error finding button: %s\n", err)
+	}
+	ele.WaitForReady()
+	if err := ele.Click(); err != nil {
+		t.Fatalf("error clicking: %s\n", err)
+	}
+
+	response, err := waiter.Wait(5 * time.Second)
+	if err != nil {
+		t.Fatalf("error waiting for response: %s\n", err)
+	}
+
+	if response.Response.Status != 200 {
+		t.Fatalf("expected 200 got %v\n", response.Response.Status)
+	}
+
+	body, err := tab.GetResponseBody(response.RequestId)
+	if err != nil {
+		t.Fatalf("error getting response body: %s\n", err)
+	}
+
+	if !strings.Contains(string(body), "items") {
+		t.Fatalf("unexpected body: %s\n", string(body))
+	}
+
+	if _, err := tab.WaitForResponse("*/never.json", 100*time.Millisecond); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected timeout waiting for a response that never arrives got: %v\n", err)
+	}
+}
+
+func TestTabReplayRequest(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "ajax.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	waiter, err := tab.ExpectResponse("*/api.json?page=*")
+	if err != nil {
+		t.Fatalf("error expecting response: %s\n", err)
+	}
+
+	if _, err := tab.EvaluateScript("document.getElementById('load').click()"); err != nil {
+		t.Fatalf("error clicking: %s\n", err)
+	}
+
+	response, err := waiter.Wait(5 * time.Second)
+	if err != nil {
+		t.Fatalf("error waiting for response: %s\n", err)
+	}
+
+	replayed, err := tab.ReplayRequest(response.RequestId, &RequestModifications{Url: testServerAddr + "api.json?page=2", Headers: map[string]string{"X-Replayed": "1"}})
+	if err != nil {
+		t.Fatalf("error replaying request: %s\n", err)
+	}
+
+	if replayed.Status != 200 || !strings.HasSuffix(replayed.Url, "page=2") {
+		t.Fatalf("unexpected replay response: %d %s\n", replayed.Status, replayed.Url)
+	}
+
+	if !strings.Contains(string(replayed.Body), "items") {
+		t.Fatalf("unexpected replay body: %s\n", string(replayed.Body))
+	}
+
+	if _, err := tab.ReplayRequest("unknown", nil); err == nil {
+		t.Fatalf("expected error replaying unknown request\n")
+	}
+}
+
+func TestTabCookieJar(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "cookie1.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	jar := tab.CookieJar()
+	pageUrl, _ := url.Parse(testServerAddr + "cookie1.html")
+	found := false
+	for _, cookie := range jar.Cookies(pageUrl) {
+		if cookie.Name == "cookie1" && cookie.Value == "true" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected page cookie to be visible through the jar\n")
+	}
+
+	httpJar, _ := cookiejar.New(nil)
+	rootUrl, _ := url.Parse(testServerAddr)
+	httpJar.SetCookies(rootUrl, []*http.Cookie{{Name: "fromjar", Value: "synced", Path: "/"}})
+	if err := tab.SyncFromJar(httpJar, testServerAddr); err != nil {
+		t.Fatalf("error syncing from jar: %s\n", err)
+	}
+
+	rro, err := tab.EvaluateScript("document.cookie")
+	if err != nil {
+		t.Fatalf("error reading document.cookie: %s\n", err)
+	}
+	if cookies, _ := rro.Value.(string); !strings.Contains(cookies, "fromjar=synced") {
+		t.Fatalf("expected synced cookie in document.cookie got: %s\n", cookies)
+	}
+}
+
+func TestTabStartRecording(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "record.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	recorder, err := tab.StartRecording()
+	if err != nil {
+		t.Fatalf("error starting recording: %s\n", err)
+	}
+	defer recorder.Stop()
+
+	eles, err := tab.GetElementsBySelector("input[name=user]")
+	if err != nil || len(eles) == 0 {
+		t.Fatalf("error getting input: %s\n", err)
+	}
+	if err := eles[0].SendKeys("admin"); err != nil {
+		t.Fatalf("error sending keys: %s\n", err)
+	}
+
+	buttons, err := tab.GetElementsBySelector(".go")
+	if err != nil || len(buttons) == 0 {
+		t.Fatalf("error getting button: %s\n", err)
+	}
+	// clicking the button blurs the input which fires its change event first
+	if err := buttons[0].Click(); err != nil {
+		t.Fatalf("error clicking: %s\n", err)
+	}
+
+	err = tab.WaitFor(100*time.Millisecond, 5*time.Second, func(tab *Tab) bool {
+		return len(recorder.Actions()) >= 2
+	})
+	if err != nil {
+		t.Fatalf("timed out waiting for recorded actions: %#v\n", recorder.Actions())
+	}
+
+	actions := recorder.Actions()
+	if actions[0].Type != RecordedInput || actions[0].Selector != `input[name="user"]` || actions[0].Value != "admin" {
+		t.Fatalf("unexpected input action: %#v\n", actions[0])
+	}
+	if actions[1].Type != RecordedClick || actions[1].Selector != `#login > button:nth-of-type(1)` {
+		t.Fatalf("unexpected click action: %#v\n", actions[1])
+	}
+
+	src := recorder.GenerateGo("replay")
+	if !strings.Contains(src, `ele.SendKeys("admin")`) {
+		t.Fatalf("generated code missing input: %s\n", src)
+	}
+}
+
+func TestElementUniqueSelector(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "record.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	expected := map[string][]string{
+		"#lang":           {"#lang", `//*[@id="lang"]`},
+		"input":           {`input[name="user"]`, `//*[@id="login"]/input`},
+		"#login > button": {"#login > button:nth-of-type(1)", `//*[@id="login"]/button`},
+	}
+
+	for query, selectors := range expected {
+		eles, err := tab.GetElementsBySelector(query)
+		if err != nil || len(eles) == 0 {
+			t.Fatalf("error getting %s: %s\n", query, err)
+		}
+		selector, err := eles[0].UniqueSelector()
+		if err != nil {
+			t.Fatalf("error getting unique selector: %s\n", err)
+		}
+		if selector != selectors[0] {
+			t.Fatalf("expected selector %s got %s\n", selectors[0], selector)
+		}
+		xpath, err := eles[0].XPath()
+		if err != nil {
+			t.Fatalf("error getting xpath: %s\n", err)
+		}
+		if xpath != selectors[1] {
+			t.Fatalf("expected xpath %s got %s\n", selectors[1], xpath)
+		}
+	}
+}
+
+func TestElementTraversal(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "record.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	lang, _, err := tab.GetElementById("lang")
+	if err != nil {
+		t.Fatalf("error getting lang: %s\n", err)
+	}
+
+	parent, err := lang.Parent()
+	if err != nil {
+		t.Fatalf("error getting parent: %s\n", err)
+	}
+	parent.WaitForReady()
+	if parent.GetAttribute("id") != "login" {
+		t.Fatalf("expected parent to be the login form: %s\n", parent)
+	}
+
+	prev, err := lang.PreviousSibling()
+	if err != nil {
+		t.Fatalf("error getting previous sibling: %s\n", err)
+	}
+	prev.WaitForReady()
+	if tagName, _ := prev.GetTagName(); tagName != "input" {
+		t.Fatalf("expected previous sibling to be input got %s\n", tagName)
+	}
+
+	next, err := lang.NextSibling()
+	if err != nil {
+		t.Fatalf("error getting next sibling: %s\n", err)
+	}
+	next.WaitForReady()
+	if tagName, _ := next.GetTagName(); tagName != "button" {
+		t.Fatalf("expected next sibling to be button got %s\n", tagName)
+	}
+
+	if _, err := next.NextSibling(); !errors.Is(err, ErrElementNotFound) {
+		t.Fatalf("expected ErrElementNotFound for last sibling got %v\n", err)
+	}
+
+	form, err := next.Closest("form")
+	if err != nil {
+		t.Fatalf("error getting closest: %s\n", err)
+	}
+	if form.NodeId() != parent.NodeId() {
+		t.Fatalf("expected closest form to be the parent")
+	}
+
+	options, err := lang.Children("option")
+	if err != nil {
+		t.Fatalf("error getting children: %s\n", err)
+	}
+	if len(options) != 2 {
+		t.Fatalf("expected 2 options got %d\n", len(options))
+	}
+}
+
+func TestElementEquals(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "record.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	lang, _, err := tab.GetElementById("lang")
+	if err != nil {
+		t.Fatalf("error getting lang: %s\n", err)
+	}
+
+	eles, err := tab.GetElementsBySelector("form > select")
+	if err != nil || len(eles) == 0 {
+		t.Fatalf("error getting select: %s\n", err)
+	}
+	if !lang.Equals(eles[0]) {
+		t.Fatalf("expected elements from different queries to be equal")
+	}
+
+	input, err := lang.PreviousSibling()
+	if err != nil {
+		t.Fatalf("error getting previous sibling: %s\n", err)
+	}
+	if lang.Equals(input) {
+		t.Fatalf("expected different elements to not be equal")
+	}
+
+	id, err := lang.BackendNodeId()
+	if err != nil || id == 0 {
+		t.Fatalf("error getting backend node id: %d %v\n", id, err)
+	}
+}
+
+func TestTabForEachElement(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "extract.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	results, err := tab.ForEachElement("#products .card", "return {index: index, sku: element.getAttribute('data-sku')};")
+	if err != nil {
+		t.Fatalf("error running for each element: %s\n", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results got %d\n", len(results))
+	}
+	second, ok := results[1].(map[string]interface{})
+	if !ok || second["sku"] != "b2" || second["index"] != float64(1) {
+		t.Fatalf("unexpected result: %#v\n", results[1])
+	}
+
+	if _, err := tab.ForEachElement("#products .card", "return element.;"); !errors.Is(err, ErrScriptEvaluation) {
+		t.Fatalf("expected script evaluation error got %v\n", err)
+	}
+}
+
+func TestElementQuerySelector(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "extract.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	cards, err := tab.GetElementsBySelector(".card")
+	if err != nil || len(cards) != 3 {
+		t.Fatalf("error getting cards: %s\n", err)
+	}
+
+	link, err := cards[1].QuerySelector("a")
+	if err != nil {
+		t.Fatalf("error querying card: %s\n", err)
+	}
+	link.WaitForReady()
+	if link.GetAttribute("href") != "/second" {
+		t.Fatalf("expected link from second card got %s\n", link.GetAttribute("href"))
+	}
+
+	if _, err := cards[2].QuerySelector(".price"); !errors.Is(err, ErrElementNotFound) {
+		t.Fatalf("expected ErrElementNotFound got %v\n", err)
+	}
+
+	products, _, err := tab.GetElementById("products")
+	if err != nil {
+		t.Fatalf("error getting products: %s\n", err)
+	}
+	prices, err := products.QuerySelectorAll(".price")
+	if err != nil {
+		t.Fatalf("error querying all: %s\n", err)
+	}
+	if len(prices) != 2 {
+		t.Fatalf("expected 2 prices got %d\n", len(prices))
+	}
+}
+
+func TestTabGetElementByText(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "text.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	ele, err := tab.GetElementByText("Save changes", &TextOptions{Exact: true})
+	if err != nil {
+		t.Fatalf("error getting element by text: %s\n", err)
+	}
+	ele.WaitForReady()
+	if ele.GetAttribute("id") != "save" {
+		t.Fatalf("expected save button got %s\n", ele)
+	}
+
+	ele, err = tab.GetElementByText("discard", &TextOptions{CaseInsensitive: true})
+	if err != nil {
+		t.Fatalf("error getting input button by text: %s\n", err)
+	}
+	ele.WaitForReady()
+	if ele.GetAttribute("id") != "reset" {
+		t.Fatalf("expected reset input got %s\n", ele)
+	}
+
+	eles, err := tab.GetElementsContainingText("sav")
+	if err != nil {
+		t.Fatalf("error getting elements containing text: %s\n", err)
+	}
+	if len(eles) != 1 {
+		t.Fatalf("expected only the help link to contain sav got %d\n", len(eles))
+	}
+
+	if _, err := tab.GetElementByText("Missing", nil); !errors.Is(err, ErrElementNotFound) {
+		t.Fatalf("expected ErrElementNotFound got %v\n", err)
+	}
+}
+
+func TestTabGetFieldByLabel(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "labels.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	expected := map[string]string{
+		"email address": "email",
+		"Password":      "pass",
+		"(again)":       "email2",
+		"Country":       "country",
+	}
+	for label, name := range expected {
+		ele, err := tab.GetFieldByLabel(label)
+		if err != nil {
+			t.Fatalf("error getting field labelled %s: %s\n", label, err)
+		}
+		ele.WaitForReady()
+		if ele.GetAttribute("name") != name {
+			t.Fatalf("expected %s for label %s got %s\n", name, label, ele)
+		}
+	}
+
+	if _, err := tab.GetFieldByLabel("Phone"); !errors.Is(err, ErrElementNotFound) {
+		t.Fatalf("expected ErrElementNotFound got %v\n", err)
+	}
+}
+
+func TestTabGetByRole(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "text.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	ele, err := tab.GetByRole("button", "save changes")
+	if err != nil {
+		t.Fatalf("error getting by role: %s\n", err)
+	}
+	ele.WaitForReady()
+	if ele.GetAttribute("id") != "save" {
+		t.Fatalf("expected save button got %s\n", ele)
+	}
+
+	buttons, err := tab.GetAllByRole("button", "")
+	if err != nil {
+		t.Fatalf("error getting all by role: %s\n", err)
+	}
+	if len(buttons) != 2 {
+		t.Fatalf("expected 2 buttons got %d\n", len(buttons))
+	}
+
+	if _, err := tab.GetByRole("link", "Save changes"); !errors.Is(err, ErrElementNotFound) {
+		t.Fatalf("expected ErrElementNotFound got %v\n", err)
+	}
+}
+
+func TestTabLocators(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "locators.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	button, err := tab.GetByTestId("submit")
+	if err != nil {
+		t.Fatalf("error getting by test id: %s\n", err)
+	}
+	button.WaitForReady()
+	if tagName, _ := button.GetTagName(); tagName != "button" {
+		t.Fatalf("expected button got %s\n", tagName)
+	}
+
+	tab.SetTestIdAttribute("data-qa")
+	err = tab.FillForm(map[string]string{
+		"label=User":          "admin",
+		"testid=plan":         "pro",
+		"[data-testid=terms]": "true",
+	})
+	if err != nil {
+		t.Fatalf("error filling form: %s\n", err)
+	}
+
+	if err := tab.ClickAndWait("role=button:Sign up", TitleEquals(tab, "admin:pro:true"), 5*time.Second); err != nil {
+		title, _ := tab.GetTitle()
+		t.Fatalf("error waiting for form submission, title is %s: %s\n", title, err)
+	}
+}
+
+func TestTabOnSoftNavigation(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "spa.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	navCh := make(chan *SoftNavigation, 2)
+	tab.OnSoftNavigation(func(tab *Tab, nav *SoftNavigation) {
+		navCh <- nav
+	})
+
+	// only changes the url, not enough of the DOM to be a route change
+	if _, err := tab.EvaluateScript("route('/spa/empty', 0)"); err != nil {
+		t.Fatalf("error changing route: %s\n", err)
+	}
+	if err := tab.WaitFor(10*time.Millisecond, time.Second, func(tab *Tab) bool { return tab.IsTransitioning() }); err != nil {
+		t.Fatalf("expected tab to be transitioning after history change")
+	}
+	select {
+	case nav := <-navCh:
+		t.Fatalf("url change without DOM changes reported as soft navigation: %#v\n", nav)
+	case <-time.After(time.Second):
+	}
+
+	if _, err := tab.EvaluateScript("route('/spa/about', 10)"); err != nil {
+		t.Fatalf("error changing route: %s\n", err)
+	}
+	select {
+	case nav := <-navCh:
+		if !strings.HasSuffix(nav.Url, "/spa/about") || !strings.HasSuffix(nav.PreviousUrl, "/spa/empty") {
+			t.Fatalf("unexpected soft navigation urls: %#v\n", nav)
+		}
+		if nav.DOMChanges < 5 {
+			t.Fatalf("expected at least 5 DOM changes got %d\n", nav.DOMChanges)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for soft navigation")
+	}
+	if tab.IsTransitioning() {
+		t.Fatalf("expected tab to have finished transitioning")
+	}
+}
+
+func TestTabElementReadyOnDescribe(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTabWithOptions(WithDOMSyncMode(DOMSyncLazy), WithChildNodeDepth(0), WithElementReadyMode(ElementReadyOnDescribe))
+	if err != nil {
+		t.Fatalf("error getting tab: %s\n", err)
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "extract.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	links, err := tab.GetElementsBySelector(".card h2 a")
+	if err != nil || len(links) != 3 {
+		t.Fatalf("error getting links: %s\n", err)
+	}
+
+	for _, link := range links {
+		if err := link.WaitForReady(); err != nil {
+			t.Fatalf("error waiting for described element: %s\n", err)
+		}
+		if tagName, _ := link.GetTagName(); tagName != "a" {
+			t.Fatalf("expected a element got: %s\n", tagName)
+		}
+	}
+	if links[2].GetAttribute("href") != "/third" {
+		t.Fatalf("expected described element to have attributes: %s\n", links[2])
+	}
+}
+
+func TestTabWaitForSelector(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "appear.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	if _, err := tab.EvaluateScript("setTimeout(function() { var p = document.createElement('p'); p.className = 'late'; document.body.appendChild(p); }, 500)"); err != nil {
+		t.Fatalf("error adding element: %s\n", err)
+	}
+
+	ele, err := tab.WaitForSelector("p.late", 5*time.Second)
+	if err != nil {
+		t.Fatalf("error waiting for selector: %s\n", err)
+	}
+	if tagName, _ := ele.GetTagName(); tagName != "p" {
+		t.Fatalf("expected p element got: %s\n", tagName)
+	}
+
+	eles, err := tab.WaitForSelectorAll("p.late", time.Second)
+	if err != nil || len(eles) != 1 {
+		t.Fatalf("error waiting for all: %v %s\n", eles, err)
+	}
+
+	_, err = tab.WaitForSelector("p.never", 500*time.Millisecond)
+	if !errors.Is(err, ErrTimeout) || !strings.Contains(err.Error(), "to match an element") {
+		t.Fatalf("expected match timeout got %v\n", err)
+	}
+}
+
+func TestTabNavigateLoaderId(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	first, err := tab.Navigate(testServerAddr + "button.html")
+	if err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	if first.LoaderId == "" || tab.LoaderId() != first.LoaderId {
+		t.Fatalf("expected tab loader %s to match navigation %s\n", tab.LoaderId(), first.LoaderId)
+	}
+
+	// the quick redirect's document events must not be mistaken for those of the next navigation
+	if _, err := tab.Navigate(testServerAddr + "quickredirect.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	second, err := tab.Navigate(testServerAddr + "input.html")
+	if err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	if second.LoaderId == first.LoaderId {
+		t.Fatalf("expected a new loader for each navigation")
+	}
+
+	tab.WaitStable()
+	if url, _ := tab.GetCurrentUrl(); !strings.HasSuffix(url, "input.html") {
+		t.Fatalf("expected to end on input.html got %s\n", url)
+	}
+	if _, _, err := tab.GetElementById("attr"); err != nil {
+		t.Fatalf("expected input.html document to be loaded: %s\n", err)
+	}
+}
+
+func TestTabScreenshotFrame(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "iframe.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	iframe, _, err := tab.GetElementById("innerfr")
+	if err != nil {
+		t.Fatalf("error getting iframe: %s\n", err)
+	}
+	iframe.WaitForReady()
+	docNodeId, err := iframe.GetFrameDocumentNodeId()
+	if err != nil {
+		t.Fatalf("error getting frame document: %s\n", err)
+	}
+	doc, _ := tab.GetElementByNodeId(docNodeId)
+	frameId, err := doc.FrameId()
+	if err != nil {
+		t.Fatalf("error getting frame id: %s\n", err)
+	}
+
+	img, err := tab.ScreenshotFrame(frameId)
+	if err != nil {
+		t.Fatalf("error taking frame screenshot: %s\n", err)
+	}
+	config, err := png.DecodeConfig(bytes.NewReader(img))
+	if err != nil {
+		t.Fatalf("error decoding screenshot: %s\n", err)
+	}
+	// default iframe size plus its border
+	if config.Width < 300 || config.Width > 310 || config.Height < 150 || config.Height > 160 {
+		t.Fatalf("unexpected frame screenshot size %dx%d\n", config.Width, config.Height)
+	}
+
+	img, err = tab.ScreenshotRegion(Rect{X: 0, Y: 0, Width: 64, Height: 32})
+	if err != nil {
+		t.Fatalf("error taking region screenshot: %s\n", err)
+	}
+	config, err = png.DecodeConfig(bytes.NewReader(img))
+	if err != nil || config.Width != 64 || config.Height != 32 {
+		t.Fatalf("unexpected region screenshot %v %#v\n", err, config)
+	}
+
+	if _, err := tab.ScreenshotRegion(Rect{Width: 0, Height: 10}); !errors.Is(err, ErrInvalidDimensions) {
+		t.Fatalf("expected ErrInvalidDimensions got %v\n", err)
+	}
+}
+
+func TestElementScreenshot(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "iframe.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	iframe, _, err := tab.GetElementById("innerfr")
+	if err != nil {
+		t.Fatalf("error getting iframe: %s\n", err)
+	}
+	iframe.WaitForReady()
+
+	img, err := iframe.Screenshot()
+	if err != nil {
+		t.Fatalf("error taking element screenshot: %s\n", err)
+	}
+	config, err := png.DecodeConfig(bytes.NewReader(img))
+	if err != nil {
+		t.Fatalf("error decoding screenshot: %s\n", err)
+	}
+	if config.Width < 300 || config.Width > 310 || config.Height < 150 || config.Height > 160 {
+		t.Fatalf("unexpected element screenshot size %dx%d\n", config.Width, config.Height)
+	}
+}
+
+func TestTabArchivePage(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "iframe.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	dir := t.TempDir()
+	manifest, err := tab.ArchivePage(dir)
+	if err != nil {
+		t.Fatalf("error archiving page: %s\n", err)
+	}
+	if len(manifest.Errors) != 0 {
+		t.Fatalf("expected every file to be captured: %#v\n", manifest.Errors)
+	}
+	if manifest.Title != "IFR SRC" || !strings.HasSuffix(manifest.Url, "iframe.html") {
+		t.Fatalf("unexpected manifest: %#v\n", manifest)
+	}
+
+	for _, name := range []string{ArchiveHTMLFile, ArchiveMHTMLFile, ArchiveScreenshotFile, ArchiveTextFile, ArchiveManifestFile} {
+		if _, err := os.Stat(dir + "/" + name); err != nil {
+			t.Fatalf("expected %s to be written: %s\n", name, err)
+		}
+	}
+
+	text, _ := os.ReadFile(dir + "/" + ArchiveTextFile)
+	if !strings.Contains(string(text), "BLAH BLAH BODY") || !strings.Contains(string(text), "page > frame 1") {
+		t.Fatalf("expected flattened text to include the frame: %s\n", text)
+	}
+}
+
+func TestTabPing(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	if !tab.IsHealthy() {
+		t.Fatalf("expected new tab to be healthy: %#v\n", tab.Health(time.Second))
+	}
+
+	// block the renderer's main thread for a couple of seconds
+	if _, err := tab.EvaluateScript("setTimeout(function() { var end = Date.now() + 2000; while (Date.now() < end) {} }, 0)"); err != nil {
+		t.Fatalf("error evaluating script: %s\n", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, err := tab.Ping(500 * time.Millisecond); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected ping of busy renderer to time out got %v\n", err)
+	}
+
+	time.Sleep(2 * time.Second)
+	health := tab.Health(time.Second)
+	if !health.Healthy || health.Crashed || health.LastActivity.IsZero() {
+		t.Fatalf("expected tab to recover: %#v\n", health)
+	}
+}
+
+func TestTabFreezeTimeSeedRandom(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	start := time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC)
+	if err := tab.FreezeTime(start); err != nil {
+		t.Fatalf("error freezing time: %s\n", err)
+	}
+	if err := tab.SeedRandom(1234); err != nil {
+		t.Fatalf("error seeding random: %s\n", err)
+	}
+
+	sample := func() string {
+		if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+			t.Fatalf("Error navigating: %s\n", err)
+		}
+		rro, err := tab.EvaluateScript("new Date().toISOString() + ' ' + Date.now() + ' ' + Math.random() + ' ' + Math.random()")
+		if err != nil {
+			t.Fatalf("error evaluating script: %s\n", err)
+		}
+		return rro.Value.(string)
+	}
+
+	first := sample()
+	if !strings.HasPrefix(first, "2020-01-02T03:04:05.000Z 1577934245000 ") {
+		t.Fatalf("expected frozen time got %s\n", first)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if second := sample(); second != first {
+		t.Fatalf("expected the same values after reloading got %s and %s\n", first, second)
+	}
+}
+
+func TestTabGetConsoleEntries(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	entries := make(chan *ConsoleEntry, 10)
+	opts := &ConsoleOptions{MinLevel: ConsoleWarning, IncludeRuntime: true}
+	if err := tab.GetConsoleEntries(opts, func(callerTab *Tab, entry *ConsoleEntry) {
+		entries <- entry
+	}); err != nil {
+		t.Fatalf("error getting console entries: %s\n", err)
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "console_levels.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	timeout := time.NewTimer(5 * time.Second)
+	defer timeout.Stop()
+	expected := []string{"warning message", "error message 42"}
+	for i := 0; i < len(expected); i++ {
+		select {
+		case entry := <-entries:
+			if entry.Text != expected[i] || !entry.Runtime || entry.Line == 0 {
+				t.Fatalf("unexpected entry %#v\n", entry)
+			}
+		case <-timeout.C:
+			t.Fatalf("error waiting for console entries")
+		}
+	}
+
+	select {
+	case entry := <-entries:
+		t.Fatalf("unexpected extra entry %#v\n", entry)
+	case <-time.After(250 * time.Millisecond):
+	}
+}
+
+func TestTabCollectErrors(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.CollectErrors(0); err != nil {
+		t.Fatalf("error enabling error collection: %s\n", err)
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "errors.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	errors, err := tab.CollectErrors(time.Second)
+	if err != nil {
+		t.Fatalf("error collecting errors: %s\n", err)
+	}
+
+	if len(errors.ConsoleErrors) != 1 || errors.ConsoleErrors[0].Text != "something went wrong" {
+		t.Fatalf("expected console error got %#v\n", errors.ConsoleErrors)
+	}
+	if len(errors.Exceptions) != 1 || !strings.Contains(errors.Exceptions[0].Text, "uncaught failure") {
+		t.Fatalf("expected exception got %#v\n", errors.Exceptions)
+	}
+	if len(errors.FailedRequests) != 1 || errors.FailedRequests[0].Status != 404 || !strings.HasSuffix(errors.FailedRequests[0].Url, "missing_resource.json") {
+		t.Fatalf("expected failed request got %#v\n", errors.FailedRequests)
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	if errors, _ := tab.CollectErrors(0); errors.HasErrors() {
+		t.Fatalf("expected errors to be cleared by Navigate got %#v\n", errors)
+	}
+}
+
+func TestTabInfoOpener(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+	if _, err := tab.Navigate(testServerAddr + "window_main.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	info, err := tab.Info()
+	if err != nil {
+		t.Fatalf("error getting info: %s\n", err)
+	}
+	if info.TargetId != tab.Target.Id || info.Title != "window main" || info.Type != "page" || !info.Attached {
+		t.Fatalf("unexpected info %#v\n", info)
+	}
+	if opener, err := tab.Opener(); err != nil || opener != nil {
+		t.Fatalf("expected no opener for tab created by NewTab got %v %v\n", opener, err)
+	}
+
+	var popup *Tab
+	err = tab.WaitFor(100*time.Millisecond, 5*time.Second, func(*Tab) bool {
+		tabs, err := testAuto.RefreshTabList()
+		if err != nil {
+			return false
+		}
+		for _, candidate := range tabs {
+			if info, err := candidate.Info(); err == nil && info.OpenerId == tab.Target.Id {
+				popup = candidate
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		t.Fatalf("error waiting for popup: %s\n", err)
+	}
+
+	opener, err := popup.Opener()
+	if err != nil {
+		t.Fatalf("error getting opener: %s\n", err)
+	}
+	if opener != tab {
+		t.Fatalf("expected the popup's opener to be the original tab")
+	}
+}
+
+func TestTabUseMiddleware(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	actions := make([]string, 0)
+	tab.Use(func(next ActionFunc) ActionFunc {
+		return func(action *Action) error {
+			actions = append(actions, action.Name+" "+action.Input)
+			return next(action)
+		}
+	})
+
+	if _, err := tab.Navigate(testServerAddr + "input.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	// helpers do not pass through middleware
+	if _, err := tab.GetTitle(); err != nil {
+		t.Fatalf("error getting title: %s\n", err)
+	}
+	if _, err := tab.EvaluateScript("1+1"); err != nil {
+		t.Fatalf("error evaluating script: %s\n", err)
+	}
+
+	expected := []string{ActionNavigate + " " + testServerAddr + "input.html", ActionEvaluateScript + " 1+1"}
+	if len(actions) != len(expected) || actions[0] != expected[0] || actions[1] != expected[1] {
+		t.Fatalf("expected actions %v got %v\n", expected, actions)
+	}
+}
+
+func TestTabRecordTranscript(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	var buf bytes.Buffer
+	tab.RecordTranscript(NewTranscript(&buf))
+
+	if _, err := tab.Navigate(testServerAddr + "login.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	pass, _, err := tab.GetElementById("pass")
+	if err != nil {
+		t.Fatalf("error getting password field: %s\n", err)
+	}
+	pass.WaitForReady()
+	if err := pass.SendKeys("secret"); err != nil {
+		t.Fatalf("error sending keys: %s\n", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 entries got %s\n", buf.String())
+	}
+	entry := &TranscriptEntry{}
+	if err := json.Unmarshal([]byte(lines[1]), entry); err != nil {
+		t.Fatalf("error decoding entry: %s\n", err)
+	}
+	if entry.Action != ActionSendKeys || entry.Selector != "#pass" || entry.Text != "******" {
+		t.Fatalf("unexpected entry %#v\n", entry)
+	}
+}
+
+func TestTabRecordSession(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	var buf bytes.Buffer
+	if err := tab.RecordSession(&buf); err != nil {
+		t.Fatalf("error recording session: %s\n", err)
+	}
+	if err := tab.RecordSession(&buf); err == nil {
+		t.Fatalf("expected an error recording twice\n")
+	}
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	if _, err := tab.EvaluateScript("document.title"); err != nil {
+		t.Fatalf("error evaluating script: %s\n", err)
+	}
+	if err := tab.StopRecordSession(); err != nil {
+		t.Fatalf("error stopping recording: %s\n", err)
+	}
+
+	analyzer, err := LoadSession(&buf)
+	if err != nil {
+		t.Fatalf("error loading session: %s\n", err)
+	}
+	counts := analyzer.MethodCounts()
+	if counts["Page.navigate"] != 1 || counts["Runtime.evaluate"] == 0 || counts["Page.loadEventFired"] == 0 {
+		t.Fatalf("expected navigate, evaluate and load to be recorded got %v\n", counts)
+	}
+	if len(analyzer.Unanswered()) != 0 {
+		t.Fatalf("expected every command to be answered got %#v\n", analyzer.Unanswered())
+	}
+}
+
+func TestTabSetErrorHandler(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	reported := make(chan error, 1)
+	tab.SetErrorHandler(func(tab *Tab, err error) {
+		reported <- err
+	})
+	tab.GetConsoleMessages(func(tab *Tab, message *gcdapi.ConsoleConsoleMessage) {
+		panic(message.Text)
+	})
+
+	if _, err := tab.Navigate(testServerAddr + "console_log.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	select {
+	case err := <-reported:
+		if !errors.Is(err, ErrCallbackPanic) {
+			t.Fatalf("expected ErrCallbackPanic got %v\n", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the panic to be reported\n")
+	}
+
+	if _, err := tab.EvaluateScript("1+1"); err != nil {
+		t.Fatalf("tab stopped working after a callback panicked: %s\n", err)
+	}
+}
+
+func TestTabOnPopup(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	popups := make(chan *Tab, 1)
+	if err := tab.OnPopup(func(popup *Tab) {
+		popups <- popup
+	}); err != nil {
+		t.Fatalf("error listening for popups: %s\n", err)
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "window_main.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	var popup *Tab
+	select {
+	case popup = <-popups:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for popup\n")
+	}
+
+	if _, ok := testAuto.GetAllTabs()[popup.Target.Id]; !ok {
+		t.Fatalf("expected the popup to be added to the AutoGcd's tabs\n")
+	}
+	if opener, err := popup.Opener(); err != nil || opener != tab {
+		t.Fatalf("expected the popup's opener to be the original tab got %v %v\n", opener, err)
+	}
+	if _, err := popup.EvaluateScript("document.title"); err != nil {
+		t.Fatalf("error evaluating script in popup: %s\n", err)
+	}
+
+	if err := tab.OnPopup(nil); err != nil {
+		t.Fatalf("error stopping popup listener: %s\n", err)
+	}
+}
+
+func TestElementClickForceSameTab(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	for id, expected := range map[string]string{"blank": "button.html", "open": "window_sub2.html"} {
+		if _, err := tab.Navigate(testServerAddr + "target_blank.html"); err != nil {
+			t.Fatalf("Error navigating: %s\n", err)
+		}
+		ele, _, err := tab.GetElementById(id)
+		if err != nil {
+			t.Fatalf("error getting %s: %s\n", id, err)
+		}
+		ele.WaitForReady()
+		tabCount := len(testAuto.GetAllTabs())
+
+		if err := ele.ClickForceSameTab(); err != nil {
+			t.Fatalf("error clicking %s: %s\n", id, err)
+		}
+		err = tab.WaitFor(100*time.Millisecond, 5*time.Second, func(tab *Tab) bool {
+			url, err := tab.GetCurrentUrl()
+			return err == nil && strings.HasSuffix(url, expected)
+		})
+		if err != nil {
+			t.Fatalf("expected clicking %s to navigate the tab to %s: %s\n", id, expected, err)
+		}
+
+		tabs, err := testAuto.RefreshTabList()
+		if err != nil {
+			t.Fatalf("error refreshing tabs: %s\n", err)
+		}
+		if len(tabs) != tabCount {
+			t.Fatalf("expected no new tabs after clicking %s, had %d now %d\n", id, tabCount, len(tabs))
+		}
+	}
+}
+
+func TestTabNavigateInternalPages(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+	tab.SetNavigationTimeout(5 * time.Second)
+
+	for _, url := range []string{testServerAddr + "button.html", "about:blank", "chrome://version", "about:blank"} {
+		result, err := tab.Navigate(url)
+		if err != nil {
+			t.Fatalf("error navigating to %s: %s\n", url, err)
+		}
+		if IsInternalUrl(url) && !strings.HasPrefix(result.Url, url) {
+			t.Fatalf("expected result url %s got %s\n", url, result.Url)
+		}
+	}
+
+	if _, _, err := tab.GetElementById("button"); err == nil {
+		t.Fatalf("expected elements of the previous document to be gone\n")
+	}
+}
+
+func TestTabReset(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTabWithOptions(WithNavigationTimeout(10 * time.Second))
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if err := tab.FreezeTime(time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("error freezing time: %s\n", err)
+	}
+	tab.SetNavigationTimeout(time.Second)
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	if _, err := tab.EvaluateScript("localStorage.setItem('scenario', '1')"); err != nil {
+		t.Fatalf("error setting storage: %s\n", err)
+	}
+
+	tab.SetResetOrigins(strings.TrimSuffix(testServerAddr, "/"))
+	if err := tab.Reset(); err != nil {
+		t.Fatalf("error resetting tab: %s\n", err)
+	}
+	if url, _ := tab.GetCurrentUrl(); url != "about:blank" {
+		t.Fatalf("expected about:blank after reset got %s\n", url)
+	}
+	if tab.navigationTimeout != 10*time.Second {
+		t.Fatalf("expected the navigation timeout the tab was opened with got %s\n", tab.navigationTimeout)
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	rro, err := tab.EvaluateScript("localStorage.getItem('scenario') === null && new Date().getFullYear() > 2001")
+	if err != nil {
+		t.Fatalf("error evaluating script: %s\n", err)
+	}
+	if cleared, _ := rro.Value.(bool); !cleared {
+		t.Fatalf("expected storage to be cleared and time to no longer be frozen\n")
+	}
+}
+
+func TestTabGetStorageUsage(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	if _, err := tab.EvaluateScript("localStorage.setItem('data', new Array(10000).join('x'))"); err != nil {
+		t.Fatalf("error setting storage: %s\n", err)
+	}
+
+	usage, err := tab.GetStorageUsage("")
+	if err != nil {
+		t.Fatalf("error getting storage usage: %s\n", err)
+	}
+	if usage.Origin != strings.TrimSuffix(testServerAddr, "/") || usage.Quota <= 0 {
+		t.Fatalf("unexpected usage %#v\n", usage)
+	}
+}
+
+func TestTabClearPrivacySandboxData(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	origin := strings.TrimSuffix(testServerAddr, "/")
+	if err := tab.ClearPrivacySandboxData(origin); err != nil {
+		t.Fatalf("error clearing privacy sandbox data: %s\n", err)
+	}
+
+	tokens, err := tab.GetTrustTokens()
+	if err != nil {
+		t.Fatalf("error getting trust tokens: %s\n", err)
+	}
+	for _, issuer := range tokens {
+		if issuer.IssuerOrigin == origin && issuer.Count != 0 {
+			t.Fatalf("expected no tokens for %s got %d\n", origin, issuer.Count)
+		}
+	}
+}
+
+func TestTabInterceptRequests(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	err = tab.InterceptRequests(func(tab *Tab, request *InterceptedRequest) {
+		if strings.HasSuffix(request.Url, "mocked.html") {
+			request.Fulfill(200, map[string]string{"Content-Type": "text/html"}, []byte("<html><body id='mocked'>mocked</body></html>"))
+		}
+	}, &RequestPattern{ResourceType: "Document"})
+	if err != nil {
+		t.Fatalf("error intercepting requests: %s\n", err)
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "mocked.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	if _, _, err := tab.GetElementById("mocked"); err != nil {
+		t.Fatalf("expected the fulfilled response to be rendered: %s\n", err)
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("expected unmatched requests to continue: %s\n", err)
+	}
+	if err := tab.StopInterceptingRequests(); err != nil {
+		t.Fatalf("error stopping interception: %s\n", err)
+	}
+}
+
+func TestTabAuditCookies(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	if _, err := tab.EvaluateScript("document.cookie = 'audit=1; SameSite=Lax'"); err != nil {
+		t.Fatalf("error setting cookie: %s\n", err)
+	}
+
+	violations, err := tab.AuditCookies(&CookiePolicy{RequireHttpOnly: true})
+	if err != nil {
+		t.Fatalf("error auditing cookies: %s\n", err)
+	}
+	if len(violations) != 1 || violations[0].Cookie.Name != "audit" || violations[0].Rule != CookieRuleHttpOnly {
+		t.Fatalf("expected a httponly violation got %v\n", violations)
+	}
+}
+
+func TestTabScriptInventory(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "resources.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	inventory, err := tab.ScriptInventory()
+	if err != nil {
+		t.Fatalf("error getting script inventory: %s\n", err)
+	}
+	if len(inventory) != 1 || inventory[0].Url != testServerAddr+"resources.js" {
+		t.Fatalf("expected resources.js got %v\n", inventory)
+	}
+	if !inventory[0].InDocument || inventory[0].HasIntegrity || inventory[0].ThirdParty || inventory[0].Bytes == 0 {
+		t.Fatalf("unexpected script %#v\n", inventory[0])
+	}
+}
+
+func TestTabAuditResponseHeaders(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "resources.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	found := make(map[string]bool)
+	for _, finding := range tab.AuditResponseHeaders() {
+		found[finding.ResourceType+" "+finding.Header] = true
+	}
+	if !found["Document Content-Security-Policy"] || !found["Document X-Frame-Options"] || !found["Script X-Content-Type-Options"] {
+		t.Fatalf("expected missing header findings got %v\n", found)
+	}
+}
+
+func TestTabDetectRedirectLoops(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+	tab.DetectRedirectLoops(3)
+
+	// the loop may be detected during or after the navigation
+	if _, err := tab.Navigate(testServerAddr + "refresh_a.html"); err != nil && !errors.Is(err, ErrRedirectLoop) {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	err = tab.WaitFor(50*time.Millisecond, 10*time.Second, func(tab *Tab) bool {
+		return tab.RedirectLoop() != nil
+	})
+	if err != nil {
+		t.Fatalf("redirect loop was not detected: %s\n", err)
+	}
+
+	loopErr := &RedirectLoopErr{}
+	if !errors.As(tab.RedirectLoop(), &loopErr) || !loopErr.Loop || len(loopErr.Chain) != 5 {
+		t.Fatalf("unexpected redirect loop %v\n", tab.RedirectLoop())
+	}
+}
+
+func TestTabInternalStats(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	stats := tab.InternalStats()
+	if stats.NodeChangeHandling.Count == 0 || stats.NodeChangeWait.Count == 0 {
+		t.Fatalf("expected node changes to be measured got %#v\n", stats)
+	}
+	if stats.Callbacks["DOM.documentUpdated"].Count == 0 || stats.Callbacks["Page.loadEventFired"].Count == 0 {
+		t.Fatalf("expected callbacks to be measured got %v\n", stats.Callbacks)
+	}
+
+	tab.ResetInternalStats()
+	if stats := tab.InternalStats(); stats.Callbacks["Page.loadEventFired"].Count != 0 {
+		t.Fatalf("expected stats to be reset got %#v\n", stats)
+	}
+}
+
+func TestTabPrintToPDF(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "article.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	pdf, err := tab.PrintToPDF(&PDFOptions{Paper: PaperA4, Margins: &PDFMargins{}, FooterTemplate: "<span class=pageNumber></span>"})
+	if err != nil {
+		t.Fatalf("error printing to pdf: %s\n", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		t.Fatalf("expected a pdf got %d bytes\n", len(pdf))
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tab.PrintToPDFWriter(buf, nil); err != nil {
+		t.Fatalf("error streaming pdf: %s\n", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+		t.Fatalf("expected a streamed pdf got %d bytes\n", buf.Len())
+	}
+}
+
+func TestTabGetAttributesBatch(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "attributes.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementByIdReady(tab, "attr"))
+	if err != nil {
+		t.Fatalf("error finding attr, timed out waiting: %s\n", err)
+	}
+
+	elements, err := tab.GetElementsBySelector("form, input")
+	if err != nil || len(elements) != 2 {
+		t.Fatalf("error getting elements: %v %d\n", err, len(elements))
+	}
+	input := elements[1]
+	missingId := 9999999
+
+	batch, err := tab.GetAttributesBatch([]int{elements[0].NodeId(), input.NodeId(), missingId})
+	if err == nil {
+		t.Fatalf("expected an error for the missing node\n")
+	}
+	if len(batch) != 2 || batch[input.NodeId()]["name"] != "attrtest" || batch[input.NodeId()]["z"] != "1" {
+		t.Fatalf("unexpected attributes %v\n", batch)
+	}
+	if _, ok := batch[missingId]; ok {
+		t.Fatalf("missing node should not be in the results\n")
+	}
+	if input.GetAttribute("x") != "y" {
+		t.Fatalf("element cache was not updated\n")
+	}
+}
+
+func TestTabSendKeyCombo(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "input.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	err = tab.WaitFor(testWaitRate, testWaitTimeout, ElementByIdReady(tab, "attr"))
+	if err != nil {
+		t.Fatalf("error finding input, timed out waiting: %s\n", err)
+	}
+	ele, _, _ := tab.GetElementById("attr")
+	if err := ele.SendKeys("hello"); err != nil {
+		t.Fatalf("error sending keys: %s\n", err)
+	}
+
+	// select all and replace with a shifted character
+	if err := tab.SendKeyCombo(ModifierCtrl, "a"); err != nil {
+		t.Fatalf("error sending ctrl+a: %s\n", err)
+	}
+	if err := tab.SendKeyCombo(ModifierShift, "1"); err != nil {
+		t.Fatalf("error sending shift+1: %s\n", err)
+	}
+	if err := tab.SendKeyCombo(ModifierNone, KeyHome); err != nil {
+		t.Fatalf("error sending home: %s\n", err)
+	}
+	if err := tab.SendKeyCombo(ModifierNone, "x"); err != nil {
+		t.Fatalf("error sending x: %s\n", err)
+	}
+
+	rro, err := tab.EvaluateScript("document.getElementById('attr').value")
+	if err != nil || rro.Value != "x!" {
+		t.Fatalf("expected x! got %v %v\n", rro, err)
+	}
+
+	if err := tab.SendKeyCombo(ModifierNone, "not a key"); err == nil {
+		t.Fatalf("expected an error for an unknown key\n")
+	}
+}
+
+func TestTabEvaluateScriptCtx(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if _, err := tab.NavigateCtx(ctx, testServerAddr+"button.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	if _, err := tab.EvaluateScriptCtx(ctx, "while(true) {}"); err != context.DeadlineExceeded {
+		t.Fatalf("expected the script to be terminated got %v\n", err)
+	}
+
+	rro, err := tab.EvaluateScript("1+1")
+	if err != nil || rro.Value.(float64) != 2 {
+		t.Fatalf("expected the tab to be usable after terminating the script got %v %v\n", rro, err)
+	}
+}
+
+func TestTabInstrumentDOMSinks(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	callCh := make(chan *DOMSinkCall, 10)
+	if err := tab.InstrumentDOMSinks(func(tab *Tab, call *DOMSinkCall) {
+		callCh <- call
+	}); err != nil {
+		t.Fatalf("error instrumenting sinks: %s\n", err)
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "index.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+
+	if _, err := tab.EvaluateScript(`document.body.innerHTML = '<b>autogcd-canary</b>'`); err != nil {
+		t.Fatalf("error writing to sink: %s\n", err)
+	}
+
+	select {
+	case call := <-callCh:
+		if call.Sink != "Element.innerHTML" || !call.Contains("autogcd-canary") || call.Stack == "" {
+			t.Fatalf("unexpected sink call: %#v\n", call)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for sink call\n")
+	}
+
+	if err := tab.StopInstrumentingDOMSinks(); err != nil {
+		t.Fatalf("error stopping sink instrumentation: %s\n", err)
+	}
+}
+
+func TestTabExportHAR(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if err := tab.RecordHAR(&HAROptions{Bodies: true}); err != nil {
+		t.Fatalf("error recording HAR: %s\n", err)
+	}
+	if _, err := tab.Navigate(testServerAddr + "resources.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	if err := tab.WaitNetworkIdle(500*time.Millisecond, 5*time.Second); err != nil {
+		t.Fatalf("error waiting for network idle: %s\n", err)
+	}
+	tab.StopRecordHAR()
+
+	buf := &bytes.Buffer{}
+	if err := tab.ExportHAR(buf); err != nil {
+		t.Fatalf("error exporting HAR: %s\n", err)
+	}
+
+	archive := &HAR{}
+	if err := json.Unmarshal(buf.Bytes(), archive); err != nil {
+		t.Fatalf("error decoding HAR: %s\n", err)
+	}
+	if archive.Log.Version != "1.2" || len(archive.Log.Pages) != 1 || len(archive.Log.Entries) < 3 {
+		t.Fatalf("unexpected HAR %s\n", buf.String())
+	}
+	document := archive.Log.Entries[0]
+	if document.Request.Url != testServerAddr+"resources.html" || document.Response.Status != 200 || !strings.Contains(document.Response.Content.Text, "resource report test") {
+		t.Fatalf("unexpected document entry %#v\n", document)
+	}
+}
+
+func TestTabDiscoverInputs(t *testing.T) {
+	testAuto := testDefaultStartup(t)
+	defer testAuto.Shutdown()
+
+	tab, err := testAuto.NewTab()
+	if err != nil {
+		t.Fatalf("error getting tab")
+	}
+
+	if _, err := tab.Navigate(testServerAddr + "forms.html"); err != nil {
+		t.Fatalf("Error navigating: %s\n", err)
+	}
+	if err := tab.WaitFor(100*time.Millisecond, 5*time.Second, func(tab *Tab) bool {
+		rro, err := tab.EvaluateScript("window.frames[0].document.readyState")
+		return err == nil && rro.Value == "complete"
+	}); err != nil {
+		t.Fatalf("error waiting for frame: %s\n", err)
+	}
+
+	forms, err := tab.DiscoverInputs()
+	if err != nil {
+		t.Fatalf("error discovering inputs: %s\n", err)
+	}
+	// register, the formless search input, then the frame's search and login forms
+	if len(forms) != 4 {
+		t.Fatalf("expected 4 forms got %d\n", len(forms))
+	}
+
+	register := forms[0]
+	if register.Method != "POST" || register.Action != testServerAddr+"register" || !register.NoValidate || len(register.Inputs) != 6 {
+		t.Fatalf("unexpected register form %#v\n", register)
+	}
+	if email := register.Inputs[0]; email.Type != "email" || !email.Required || email.MaxLength != 64 {
+		t.Fatalf("unexpected email input %#v\n", email)
+	}
+	if plan := register.Inputs[3]; plan.Tag != "select" || len(plan.Options) != 2 {
+		t.Fatalf("unexpected plan input %#v\n", plan)
+	}
+	if !forms[1].Formless || forms[1].Inputs[0].Name != "filter" {
+		t.Fatalf("expected formless inputs got %#v\n", forms[1])
+	}
+	if forms[3].FrameUrl != testServerAddr+"login.html" || forms[3].Id != "loginform" {
+		t.Fatalf("expected the frame's login form got %#v\n", forms[3])
+	}
+}
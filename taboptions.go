@@ -0,0 +1,155 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"log"
+	"time"
+)
+
+// DOMSyncMode controls how much of the DOM the tab mirrors into its element map when a document is loaded.
+type DOMSyncMode uint8
+
+const (
+	DOMSyncFull DOMSyncMode = 0x0 // request the entire document tree, the default
+	DOMSyncLazy DOMSyncMode = 0x1 // request only the top level, nodes are pushed as they are queried
+)
+
+var domSyncModeMap = map[DOMSyncMode]string{
+	DOMSyncFull: "DOMSyncFull",
+	DOMSyncLazy: "DOMSyncLazy",
+}
+
+func (mode DOMSyncMode) String() string {
+	if s, ok := domSyncModeMap[mode]; ok {
+		return s
+	}
+	return ""
+}
+
+// the depth passed to DOM.getDocument for this sync mode
+func (mode DOMSyncMode) depth() int {
+	if mode == DOMSyncLazy {
+		return 1
+	}
+	return -1
+}
+
+// ElementReadyMode controls when Elements the tab has only been given a node id for become ready.
+type ElementReadyMode uint8
+
+const (
+	ElementReadyOnChildNodes ElementReadyMode = 0x0 // ready once chrome sends the node as a child of its parent, the default
+	ElementReadyOnDescribe   ElementReadyMode = 0x1 // the node is described straight away so it is ready as soon as its name and attributes are known
+)
+
+var elementReadyModeMap = map[ElementReadyMode]string{
+	ElementReadyOnChildNodes: "ElementReadyOnChildNodes",
+	ElementReadyOnDescribe:   "ElementReadyOnDescribe",
+}
+
+func (mode ElementReadyMode) String() string {
+	if s, ok := elementReadyModeMap[mode]; ok {
+		return s
+	}
+	return ""
+}
+
+// TabOption configures a tab before any debugger domains are enabled or events subscribed, so
+// unlike the SetX methods it can not race with events that arrive as the tab opens.
+// See AutoGcd.NewTabWithOptions.
+type TabOption func(t *Tab)
+
+// WithNavigationTimeout sets how long Navigate waits before failing, same as SetNavigationTimeout.
+func WithNavigationTimeout(timeout time.Duration) TabOption {
+	return func(t *Tab) {
+		t.navigationTimeout = timeout
+	}
+}
+
+// WithoutConsoleDomain does not enable the Console domain when the tab is opened, reducing event
+// traffic for pages that log heavily. GetConsoleMessages will still enable it when called.
+func WithoutConsoleDomain() TabOption {
+	return func(t *Tab) {
+		t.consoleLock.Lock()
+		t.consoleDisabled = true
+		t.consoleLock.Unlock()
+	}
+}
+
+// WithDOMSyncMode sets how much of the DOM is mirrored when a document loads. DOMSyncLazy is
+// considerably cheaper for very large pages, elements are still available after being queried.
+func WithDOMSyncMode(mode DOMSyncMode) TabOption {
+	return func(t *Tab) {
+		t.domSyncMode = mode
+	}
+}
+
+// WithLogger sends the tab's debug output to logger and enables debug printing.
+func WithLogger(logger *log.Logger) TabOption {
+	return func(t *Tab) {
+		t.logger = logger
+		t.debug = logger != nil
+	}
+}
+
+// WithTestIdAttribute sets the attribute GetByTestId matches on, same as SetTestIdAttribute.
+func WithTestIdAttribute(name string) TabOption {
+	return func(t *Tab) {
+		t.testIdAttribute = name
+	}
+}
+
+// WithLocatorRegistry sets the registry used to resolve locators, same as SetLocatorRegistry.
+func WithLocatorRegistry(registry *LocatorRegistry) TabOption {
+	return func(t *Tab) {
+		t.locators = registry
+	}
+}
+
+// WithElementReadyMode sets when Elements become ready. ElementReadyOnDescribe avoids WaitForReady timing
+// out on nodes which chrome has given an id for (from a query or search) but whose parent's children
+// were never requested, which is common with DOMSyncLazy.
+func WithElementReadyMode(mode ElementReadyMode) TabOption {
+	return func(t *Tab) {
+		t.elementReadyMode = mode
+	}
+}
+
+// WithChildNodeDepth sets how deep children are requested for each node added to the tab. The default of 1
+// requests the direct children of every node, which cascades to the whole tree, 0 does not request
+// children at all and -1 requests the entire subtree in one call.
+func WithChildNodeDepth(depth int) TabOption {
+	return func(t *Tab) {
+		t.childNodeDepth = depth
+	}
+}
+
+// WithCallTimeout sets how long each debugger request waits for chrome to respond, same as SetCallTimeout.
+func WithCallTimeout(timeout time.Duration) TabOption {
+	return func(t *Tab) {
+		t.SetApiTimeout(timeout)
+	}
+}
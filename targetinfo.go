@@ -0,0 +1,74 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+// finds another tab of the same AutoGcd by target id.
+type findTabFunc func(targetId string) (*Tab, error)
+
+// TabInfo is the browser's current metadata for a tab's target.
+type TabInfo struct {
+	TargetId         string // the target id, the same as Tab.Target.Id
+	Type             string // target type, page for tabs and popups
+	Title            string // current document title
+	Url              string // current url
+	Attached         bool   // a debugger client is attached
+	OpenerId         string // target id of the tab which opened this one, empty if it was not opened by a page
+	BrowserContextId string // browser context the target belongs to
+}
+
+// Info returns the current metadata of the tab's target from Target.getTargetInfo. Unlike Tab.Target,
+// which is the metadata from when the tab was created, the title and url are up to date.
+func (t *Tab) Info() (*TabInfo, error) {
+	info, err := t.TargetApi.GetTargetInfo(t.Target.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &TabInfo{
+		TargetId:         info.TargetId,
+		Type:             info.Type,
+		Title:            info.Title,
+		Url:              info.Url,
+		Attached:         info.Attached,
+		OpenerId:         info.OpenerId,
+		BrowserContextId: info.BrowserContextId,
+	}, nil
+}
+
+// Opener returns the tab which opened this one with window.open or a target=_blank link. Returns nil
+// without an error if the tab was not opened by another page, or an InvalidTabErr if the opener is
+// not known to the AutoGcd (call AutoGcd.RefreshTabList to pick up new tabs).
+func (t *Tab) Opener() (*Tab, error) {
+	info, err := t.Info()
+	if err != nil {
+		return nil, err
+	}
+	if info.OpenerId == "" {
+		return nil, nil
+	}
+	if t.findTab == nil {
+		return nil, &InvalidTabErr{Message: "tab was not opened by AutoGcd, unable to find opener " + info.OpenerId}
+	}
+	return t.findTab(info.OpenerId)
+}
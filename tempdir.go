@@ -0,0 +1,90 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// TempDir returns a directory for the tab's artifacts, such as downloads, screenshots or traces, creating it
+// the first time it is called. It is removed when the tab is closed by CloseTab or Shutdown, unless the tab
+// failed and Settings.SetTabTempDir asked for failed tabs to be retained.
+func (t *Tab) TempDir() (string, error) {
+	t.tempLock.Lock()
+	defer t.tempLock.Unlock()
+	if t.tempDir != "" {
+		return t.tempDir, nil
+	}
+	dir, err := ioutil.TempDir(t.tempRoot, "autogcd-tab-")
+	if err != nil {
+		return "", err
+	}
+	t.tempDir = dir
+	return dir, nil
+}
+
+// EnableDownloads allows the tab to download files, saving them to a downloads directory inside TempDir
+// which is returned.
+func (t *Tab) EnableDownloads() (string, error) {
+	dir, err := t.TempDir()
+	if err != nil {
+		return "", err
+	}
+	downloads := filepath.Join(dir, "downloads")
+	if err := os.MkdirAll(downloads, 0755); err != nil {
+		return "", err
+	}
+	if _, err := t.Page.SetDownloadBehavior("allow", downloads); err != nil {
+		return "", err
+	}
+	return downloads, nil
+}
+
+// MarkFailed flags the tab as failed, so its TempDir is kept when it is closed if Settings.SetTabTempDir
+// enabled retaining failed tabs. Tabs that crashed are always considered failed.
+func (t *Tab) MarkFailed() {
+	t.tempLock.Lock()
+	defer t.tempLock.Unlock()
+	t.failed = true
+}
+
+// removes the tab's temp directory unless the tab failed and it should be retained, called when the
+// tab is closed.
+func (t *Tab) removeTempDir() error {
+	t.tempLock.Lock()
+	defer t.tempLock.Unlock()
+	if t.tempDir == "" {
+		return nil
+	}
+	if t.retainOnFailure && (t.failed || t.CrashErr() != nil) {
+		t.debugf("retaining temp dir %s of failed tab\n", t.tempDir)
+		return nil
+	}
+	err := os.RemoveAll(t.tempDir)
+	t.tempDir = ""
+	return err
+}
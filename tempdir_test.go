@@ -0,0 +1,44 @@
+package autogcd
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestTabTempDir(t *testing.T) {
+	root, err := ioutil.TempDir("", "autogcd-temproot")
+	if err != nil {
+		t.Fatalf("error creating root: %s\n", err)
+	}
+	defer os.RemoveAll(root)
+
+	tab := &Tab{tempLock: &sync.Mutex{}, crashLock: &sync.Mutex{}, tempRoot: root}
+	dir, err := tab.TempDir()
+	if err != nil {
+		t.Fatalf("error creating temp dir: %s\n", err)
+	}
+	if again, _ := tab.TempDir(); again != dir {
+		t.Fatalf("expected the same dir got %s and %s\n", dir, again)
+	}
+	if err := tab.removeTempDir(); err != nil {
+		t.Fatalf("error removing temp dir: %s\n", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed\n", dir)
+	}
+
+	tab = &Tab{tempLock: &sync.Mutex{}, crashLock: &sync.Mutex{}, tempRoot: root, retainOnFailure: true}
+	dir, err = tab.TempDir()
+	if err != nil {
+		t.Fatalf("error creating temp dir: %s\n", err)
+	}
+	tab.MarkFailed()
+	if err := tab.removeTempDir(); err != nil {
+		t.Fatalf("error removing temp dir: %s\n", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("expected the failed tab's dir to be retained: %s\n", err)
+	}
+}
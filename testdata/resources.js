@@ -0,0 +1,3 @@
+window.addEventListener('load', function() {
+	document.getElementById('content').setAttribute('loaded', 'true');
+});
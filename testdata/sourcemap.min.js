@@ -0,0 +1,2 @@
+function add(n,r){return n+r}
+//# sourceMappingURL=sourcemap.min.js.map
@@ -0,0 +1,118 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Finds the innermost visible elements whose text (or value, for input buttons) matches. Whitespace is
+// collapsed before comparing, and an element is skipped when one of its descendants also matched so a
+// button is returned rather than the form and body containing it.
+const textSearchScript = `(function(text, opts) {
+	function normalize(value) {
+		value = (value || '').replace(/\s+/g, ' ').trim();
+		return opts.caseInsensitive ? value.toLowerCase() : value;
+	}
+	function visible(node) {
+		var style = window.getComputedStyle(node);
+		return style.visibility !== 'hidden' && style.display !== 'none' && node.getClientRects().length > 0;
+	}
+	function textOf(node) {
+		if (node.nodeName === 'INPUT' && /^(button|submit|reset)$/i.test(node.type)) {
+			return node.value;
+		}
+		return opts.includeHidden ? node.textContent : node.innerText;
+	}
+	var wanted = normalize(text);
+	var candidates = document.querySelectorAll(opts.selector || 'body *');
+	var matches = [];
+	for (var i = 0; i < candidates.length; i++) {
+		var node = candidates[i];
+		if (node.nodeName === 'SCRIPT' || node.nodeName === 'STYLE' || (!opts.includeHidden && !visible(node))) {
+			continue;
+		}
+		var value = normalize(textOf(node));
+		if (opts.exact ? value === wanted : value.indexOf(wanted) !== -1) {
+			matches.push(node);
+		}
+	}
+	return matches.filter(function(node) {
+		return !matches.some(function(other) {
+			return other !== node && node.contains(other);
+		});
+	});
+})(%s, %s)`
+
+// TextOptions controls how GetElementByText matches element text.
+type TextOptions struct {
+	Exact           bool   `json:"exact"`           // the whole text must equal, rather than contain, the search text
+	CaseInsensitive bool   `json:"caseInsensitive"` // ignore case when comparing
+	IncludeHidden   bool   `json:"includeHidden"`   // also match elements which are not displayed
+	Selector        string `json:"selector"`        // only consider elements matching this CSS selector, e.g. "button, a"
+}
+
+// GetElementByText returns the first element in the top level document whose visible text matches text,
+// for finding buttons and links by their label. By default text must be contained in the element's text,
+// whitespace is collapsed and only the innermost matching element is returned. A nil opts uses the defaults.
+func (t *Tab) GetElementByText(text string, opts *TextOptions) (*Element, error) {
+	elements, err := t.getElementsByText(text, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) == 0 {
+		return nil, &ElementNotFoundErr{Message: fmt.Sprintf("with text %q", text)}
+	}
+	return elements[0], nil
+}
+
+// GetElementsContainingText returns the innermost visible elements in the top level document whose text
+// contains substr, in document order.
+func (t *Tab) GetElementsContainingText(substr string) ([]*Element, error) {
+	return t.getElementsByText(substr, nil)
+}
+
+func (t *Tab) getElementsByText(text string, opts *TextOptions) ([]*Element, error) {
+	if opts == nil {
+		opts = &TextOptions{}
+	}
+	encoded, err := json.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+	elements, err := t.evaluateElements(fmt.Sprintf(textSearchScript, jsQuote(text), string(encoded)))
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]*Element, 0, len(elements))
+	for _, ele := range elements {
+		if ele != nil {
+			found = append(found, ele)
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,147 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TranscriptEntry is a single action written to a Transcript as a line of JSON.
+type TranscriptEntry struct {
+	Time       time.Time `json:"time"`                 // when the action started
+	TabId      string    `json:"tabId"`                // target id of the tab
+	Action     string    `json:"action"`               // one of the Action constants
+	Url        string    `json:"url,omitempty"`        // url navigated to
+	Selector   string    `json:"selector,omitempty"`   // unique selector of the element clicked or typed into
+	Text       string    `json:"text,omitempty"`       // text typed, masked for password fields
+	ScriptHash string    `json:"scriptHash,omitempty"` // hex sha256 of the evaluated script
+	Status     int       `json:"status,omitempty"`     // HTTP status of the navigated document
+	DurationMs int64     `json:"durationMs"`           // how long the action took
+	Error      string    `json:"error,omitempty"`      // why the action failed, empty if it succeeded
+}
+
+// Transcript records every action performed through the tabs it is attached to, so failed runs can be
+// reconstructed step by step. Safe to share between tabs.
+type Transcript struct {
+	lock    *sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer
+}
+
+// NewTranscript writes entries to w, one JSON object per line.
+func NewTranscript(w io.Writer) *Transcript {
+	return &Transcript{lock: &sync.Mutex{}, encoder: json.NewEncoder(w)}
+}
+
+// NewTranscriptFile appends entries to the JSONL file at path, creating it if needed. Call Close when done.
+func NewTranscriptFile(path string) (*Transcript, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	tr := NewTranscript(f)
+	tr.closer = f
+	return tr, nil
+}
+
+// Close closes the file opened by NewTranscriptFile, it does nothing for transcripts created by NewTranscript.
+func (tr *Transcript) Close() error {
+	if tr.closer == nil {
+		return nil
+	}
+	return tr.closer.Close()
+}
+
+// Write writes entry to the transcript, used by the middleware and for custom entries.
+func (tr *Transcript) Write(entry *TranscriptEntry) error {
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	return tr.encoder.Encode(entry)
+}
+
+// Middleware returns middleware which writes an entry for every action, see Tab.Use.
+func (tr *Transcript) Middleware() Middleware {
+	return func(next ActionFunc) ActionFunc {
+		return func(action *Action) error {
+			entry := newTranscriptEntry(action)
+			start := time.Now()
+			err := next(action)
+			entry.DurationMs = int64(time.Since(start) / time.Millisecond)
+			if err != nil {
+				entry.Error = err.Error()
+			}
+			if result, ok := action.Result.(*NavigationResult); ok && result != nil {
+				entry.Status = result.Status
+			}
+			if writeErr := tr.Write(entry); writeErr != nil && action.Tab != nil {
+				action.Tab.debugf("unable to write transcript: %s\n", writeErr)
+			}
+			return err
+		}
+	}
+}
+
+// describes action before it runs, while the element is still in the document.
+func newTranscriptEntry(action *Action) *TranscriptEntry {
+	entry := &TranscriptEntry{Time: time.Now().UTC(), Action: action.Name}
+	if action.Tab != nil {
+		entry.TabId = action.Tab.Target.Id
+	}
+
+	switch action.Name {
+	case ActionNavigate:
+		entry.Url = action.Input
+	case ActionEvaluateScript, ActionEvaluatePromiseScript:
+		sum := sha256.Sum256([]byte(action.Input))
+		entry.ScriptHash = hex.EncodeToString(sum[:])
+	case ActionSendKeys, ActionSendKeyCombo:
+		entry.Text = action.Input
+	}
+
+	if action.Element != nil {
+		selector, err := action.Element.UniqueSelector()
+		if err != nil {
+			selector = "nodeId=" + strconv.Itoa(action.Element.NodeId())
+		}
+		entry.Selector = selector
+		if action.Name == ActionSendKeys && strings.EqualFold(action.Element.GetAttribute("type"), "password") {
+			entry.Text = strings.Repeat("*", len(action.Input))
+		}
+	}
+	return entry
+}
+
+// RecordTranscript writes every action performed through the tab to tr, see Transcript.
+func (t *Tab) RecordTranscript(tr *Transcript) {
+	t.Use(tr.Middleware())
+}
@@ -0,0 +1,46 @@
+package autogcd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTranscriptMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	tr := NewTranscript(&buf)
+	tab := &Tab{middlewareLock: &sync.RWMutex{}}
+	tab.Use(tr.Middleware())
+
+	tab.runAction(&Action{Name: ActionNavigate, Input: "http://localhost/"}, func(action *Action) error {
+		action.Result = &NavigationResult{Status: 200}
+		return nil
+	})
+	tab.runAction(&Action{Name: ActionEvaluateScript, Input: "document.title"}, func(action *Action) error {
+		return errors.New("script failed")
+	})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 entries got %d: %s\n", len(lines), buf.String())
+	}
+
+	navigate := &TranscriptEntry{}
+	if err := json.Unmarshal([]byte(lines[0]), navigate); err != nil {
+		t.Fatalf("error decoding entry: %s\n", err)
+	}
+	if navigate.Action != ActionNavigate || navigate.Url != "http://localhost/" || navigate.Status != 200 || navigate.Error != "" {
+		t.Fatalf("unexpected navigate entry %#v\n", navigate)
+	}
+
+	evaluate := &TranscriptEntry{}
+	if err := json.Unmarshal([]byte(lines[1]), evaluate); err != nil {
+		t.Fatalf("error decoding entry: %s\n", err)
+	}
+	if len(evaluate.ScriptHash) != 64 || evaluate.Error != "script failed" || strings.Contains(lines[1], "document.title") {
+		t.Fatalf("expected script to be hashed and the error recorded %s\n", lines[1])
+	}
+}
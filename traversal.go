@@ -0,0 +1,125 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+
+	"github.com/wirepair/gcd/gcdapi"
+)
+
+// Parent returns the parent element of this element.
+func (e *Element) Parent() (*Element, error) {
+	return e.relative("function() { return this.parentElement; }", "parent")
+}
+
+// NextSibling returns the next element sibling, text and comment nodes are skipped.
+func (e *Element) NextSibling() (*Element, error) {
+	return e.relative("function() { return this.nextElementSibling; }", "next sibling")
+}
+
+// PreviousSibling returns the previous element sibling, text and comment nodes are skipped.
+func (e *Element) PreviousSibling() (*Element, error) {
+	return e.relative("function() { return this.previousElementSibling; }", "previous sibling")
+}
+
+// Closest returns the closest ancestor of this element, or the element itself, matching selector.
+func (e *Element) Closest(selector string) (*Element, error) {
+	return e.relative("function(selector) { return this.closest(selector); }", "closest "+selector, selector)
+}
+
+// Children returns the child elements matching selector, or all child elements if selector is empty.
+// Only direct children are returned, see Element.QuerySelectorAll for searching the whole subtree.
+func (e *Element) Children(selector string) ([]*Element, error) {
+	return e.functionElements("function(selector) { return Array.prototype.filter.call(this.children, function(child) { return !selector || child.matches(selector); }); }", selector)
+}
+
+// QuerySelector returns the first element in this element's subtree matching selector.
+func (e *Element) QuerySelector(selector string) (*Element, error) {
+	nodeId, err := e.tab.DOM.QuerySelector(e.NodeId(), selector)
+	if err != nil {
+		return nil, err
+	}
+	if nodeId == 0 {
+		return nil, &ElementNotFoundErr{Message: fmt.Sprintf("%s in nodeId %d", selector, e.NodeId())}
+	}
+	ele, _ := e.tab.GetElementByNodeId(nodeId)
+	return ele, nil
+}
+
+// QuerySelectorAll returns all elements in this element's subtree matching selector.
+func (e *Element) QuerySelectorAll(selector string) ([]*Element, error) {
+	nodeIds, err := e.tab.DOM.QuerySelectorAll(e.NodeId(), selector)
+	if err != nil {
+		return nil, err
+	}
+
+	elements := make([]*Element, len(nodeIds))
+	for k, nodeId := range nodeIds {
+		elements[k], _ = e.tab.GetElementByNodeId(nodeId)
+	}
+	return elements, nil
+}
+
+// returns the single element functionDeclaration returns, or an ElementNotFoundErr for null.
+func (e *Element) relative(functionDeclaration, description string, args ...interface{}) (*Element, error) {
+	elements, err := e.functionElements(functionDeclaration, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) == 0 || elements[0] == nil {
+		return nil, &ElementNotFoundErr{Message: fmt.Sprintf("%s of nodeId %d", description, e.NodeId())}
+	}
+	return elements[0], nil
+}
+
+// calls functionDeclaration on this element and converts the node, or array of nodes, it returns to Elements.
+// Nodes chrome has not told us about yet are returned as not ready Elements.
+func (e *Element) functionElements(functionDeclaration string, args ...interface{}) ([]*Element, error) {
+	objectGroup := "autogcdElements"
+	e.lock.RLock()
+	id := e.id
+	e.lock.RUnlock()
+
+	obj, err := e.tab.DOM.ResolveNodeWithParams(&gcdapi.DOMResolveNodeParams{NodeId: id, ObjectGroup: objectGroup})
+	if err != nil {
+		return nil, err
+	}
+	defer e.tab.Runtime.ReleaseObjectGroup(objectGroup)
+
+	callArgs := make([]*gcdapi.RuntimeCallArgument, len(args))
+	for i, arg := range args {
+		callArgs[i] = &gcdapi.RuntimeCallArgument{Value: arg}
+	}
+
+	rro, exception, err := e.tab.Runtime.CallFunctionOn(functionDeclaration, obj.ObjectId, callArgs, true, false, false, false, false, 0, objectGroup)
+	if err != nil {
+		return nil, err
+	}
+	if exception != nil {
+		return nil, &ScriptEvaluationErr{Message: "error calling function on element: ", ExceptionText: exception.Text, ExceptionDetails: exception}
+	}
+	return e.tab.remoteObjectToElements(rro)
+}
@@ -26,6 +26,7 @@ package autogcd
 
 import (
 	"github.com/wirepair/gcd/gcdapi"
+	"time"
 )
 
 // Common node types
@@ -85,6 +86,41 @@ func (evt ChangeEventType) String() string {
 	return ""
 }
 
+// Frame event types, see Tab.ListenFrameNavigations
+type FrameEventType uint8
+
+const (
+	FrameAttachedEvent  FrameEventType = 0x0
+	FrameNavigatedEvent FrameEventType = 0x1
+	FrameDetachedEvent  FrameEventType = 0x2
+)
+
+var frameEventMap = map[FrameEventType]string{
+	FrameAttachedEvent:  "FrameAttachedEvent",
+	FrameNavigatedEvent: "FrameNavigatedEvent",
+	FrameDetachedEvent:  "FrameDetachedEvent",
+}
+
+func (evt FrameEventType) String() string {
+	if s, ok := frameEventMap[evt]; ok {
+		return s
+	}
+	return ""
+}
+
+// FrameEvent describes a frame being attached, navigated or detached. Url, Name, LoaderId and
+// SecurityOrigin are only set for FrameNavigatedEvent, ParentId is not set for FrameDetachedEvent.
+type FrameEvent struct {
+	EventType      FrameEventType // the type of frame event
+	FrameId        string         // frame the event is for
+	ParentId       string         // parent frame, empty for the top frame
+	Url            string         // url of the frame's document
+	Name           string         // name of the frame as specified in the tag
+	LoaderId       string         // loader of the frame's document
+	SecurityOrigin string         // origin of the frame's document
+	UnreachableUrl string         // url that failed to load, if any
+}
+
 // For handling DOM updating nodes
 type NodeChangeEvent struct {
 	EventType      ChangeEventType   // the type of node change event
@@ -109,19 +145,130 @@ type NetworkRequest struct {
 	DocumentURL      string                   // url of the frame
 	Request          *gcdapi.NetworkRequest   // underlying Request object
 	Timestamp        float64                  // time the request was dispatched
+	WallTime         float64                  // wall clock time the request was dispatched, seconds since the unix epoch
 	Initiator        *gcdapi.NetworkInitiator // who initiated the request
 	RedirectResponse *gcdapi.NetworkResponse  // non-nil if it was a redirect
 	Type             string                   // Document, Stylesheet, Image, Media, Font, Script, TextTrack, XHR, Fetch, EventSource, WebSocket, Other
+	HasPostData      bool                     // true if the request has a body, PostData may be empty if it was too large to send inline
+	PostData         string                   // the request body if it was sent inline, see Tab.GetRequestPostData
 }
 
 // Inbound network responses
 type NetworkResponse struct {
-	RequestId string                  // Internal chrome request id
-	FrameId   string                  // frame that the request went out on
-	LoaderId  string                  // internal chrome loader id
-	Response  *gcdapi.NetworkResponse // underlying Response object
-	Timestamp float64                 // time the request was received
-	Type      string                  // Document, Stylesheet, Image, Media, Font, Script, TextTrack, XHR, Fetch, EventSource, WebSocket, Other
+	RequestId        string                  // Internal chrome request id
+	FrameId          string                  // frame that the request went out on
+	LoaderId         string                  // internal chrome loader id
+	Response         *gcdapi.NetworkResponse // underlying Response object
+	Timestamp        float64                 // time the request was received
+	Type             string                  // Document, Stylesheet, Image, Media, Font, Script, TextTrack, XHR, Fetch, EventSource, WebSocket, Other
+	Timing           *ResponseTiming         // parsed connection timing, nil if chrome did not provide timing (cached responses)
+	Protocol         string                  // protocol used, http/1.1, h2, h3 etc
+	RemoteIPAddress  string                  // ip address of the server
+	RemotePort       int                     // port of the server
+	ConnectionReused bool                    // was an existing connection used for the request
+	FromCache        bool                    // was the response served from the disk cache or a service worker
+	Security         *SecurityInfo           // TLS details, nil for insecure responses
+}
+
+// ResponseTiming of a request, phases that did not occur (reused connections, plain http) are 0.
+type ResponseTiming struct {
+	DNS     time.Duration // resolving the host
+	Connect time.Duration // establishing the connection, including the TLS handshake
+	SSL     time.Duration // the TLS handshake
+	Send    time.Duration // sending the request
+	TTFB    time.Duration // waiting for the first byte of the response after the request was sent
+	Headers time.Duration // from the start of the request until the response headers were received
+}
+
+// SecurityInfo of the connection a response was received on.
+type SecurityInfo struct {
+	Protocol    string    // TLS 1.2, TLS 1.3, QUIC etc
+	KeyExchange string    // key exchange used, may be empty for TLS 1.3
+	Cipher      string    // cipher name
+	SubjectName string    // certificate subject
+	Issuer      string    // certificate issuer
+	SanList     []string  // subject alternative names
+	ValidFrom   time.Time // certificate valid from
+	ValidTo     time.Time // certificate expiry
+}
+
+// creates a NetworkResponse from the Network.responseReceived event, parsing the nested timing and security details.
+func newNetworkResponse(requestId, frameId, loaderId string, response *gcdapi.NetworkResponse, timestamp float64, resourceType string) *NetworkResponse {
+	r := &NetworkResponse{RequestId: requestId, FrameId: frameId, LoaderId: loaderId, Response: response, Timestamp: timestamp, Type: resourceType}
+	if response == nil {
+		return r
+	}
+
+	r.Protocol = response.Protocol
+	r.RemoteIPAddress = response.RemoteIPAddress
+	r.RemotePort = response.RemotePort
+	r.ConnectionReused = response.ConnectionReused
+	r.FromCache = response.FromDiskCache || response.FromServiceWorker
+
+	if timing := response.Timing; timing != nil {
+		r.Timing = &ResponseTiming{
+			DNS:     timingPhase(timing.DnsStart, timing.DnsEnd),
+			Connect: timingPhase(timing.ConnectStart, timing.ConnectEnd),
+			SSL:     timingPhase(timing.SslStart, timing.SslEnd),
+			Send:    timingPhase(timing.SendStart, timing.SendEnd),
+			TTFB:    timingPhase(timing.SendEnd, timing.ReceiveHeadersEnd),
+			Headers: timingPhase(0, timing.ReceiveHeadersEnd),
+		}
+	}
+
+	if details := response.SecurityDetails; details != nil {
+		r.Security = &SecurityInfo{
+			Protocol:    details.Protocol,
+			KeyExchange: details.KeyExchange,
+			Cipher:      details.Cipher,
+			SubjectName: details.SubjectName,
+			Issuer:      details.Issuer,
+			SanList:     details.SanList,
+			ValidFrom:   time.Unix(int64(details.ValidFrom), 0),
+			ValidTo:     time.Unix(int64(details.ValidTo), 0),
+		}
+	}
+	return r
+}
+
+// returns the duration between two millisecond offsets, chrome uses -1 for phases that did not occur.
+func timingPhase(start, end float64) time.Duration {
+	if start < 0 || end < 0 || end < start {
+		return 0
+	}
+	return time.Duration((end - start) * float64(time.Millisecond))
+}
+
+// Result of Tab.Navigate and Tab.NavigateWithRetry
+type NavigationResult struct {
+	FrameId    string                 // frame that was navigated
+	LoaderId   string                 // loader of the new document, identifies its main document request
+	ErrorText  string                 // friendly error text if chrome failed to navigate, Navigate also returns a NavigationFailedErr
+	Url        string                 // url of the main document response (after redirects)
+	Status     int                    // HTTP status code of the main document, 0 if no response was received
+	StatusText string                 // HTTP status text of the main document
+	Headers    map[string]interface{} // HTTP response headers of the main document
+	Response   *NetworkResponse       // the main document response, nil if no response was received
+	Attempts   int                    // number of attempts made by NavigateWithRetry
+}
+
+func (r *NavigationResult) setResponse(response *NetworkResponse) {
+	if response == nil || response.Response == nil {
+		return
+	}
+	r.Response = response
+	r.Url = response.Response.Url
+	r.Status = response.Response.Status
+	r.StatusText = response.Response.StatusText
+	r.Headers = response.Response.Headers
+}
+
+// RedirectHop is a single response in the chain returned by Tab.GetRedirectChain.
+type RedirectHop struct {
+	Url        string // url that was requested
+	Status     int    // HTTP status code it returned
+	StatusText string // HTTP status text
+	Location   string // value of the Location header for redirects, empty for the final response
 }
 
 // For storage related events.
@@ -0,0 +1,283 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+/*
+Package urlutil provides url canonicalization and deduplication helpers so that
+two urls which point at the same resource compare equal. It is used by the crawler
+frontier but has no dependency on autogcd itself.
+*/
+package urlutil
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Options controls how urls are canonicalized, the zero value (or nil) lowercases the scheme and
+// host, removes default ports, resolves dot segments, sorts the query and strips the fragment.
+type Options struct {
+	KeepFragment        bool     // do not strip #fragments
+	KeepQueryOrder      bool     // do not sort query parameters
+	RemoveParams        []string // query parameters to drop, a trailing * matches a prefix (utm_*)
+	RemoveTrailingSlash bool     // treat /path/ and /path as the same
+}
+
+// TrackingParams are common analytics parameters which rarely change page content.
+var TrackingParams = []string{"utm_*", "gclid", "fbclid", "mc_cid", "mc_eid"}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+	"ftp":   "21",
+}
+
+// Canonicalize returns the canonical form of rawurl using opts, which may be nil.
+func Canonicalize(rawurl string, opts *Options) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawurl))
+	if err != nil {
+		return "", err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	// opaque urls (about:blank, mailto:x) can't be normalized any further
+	if u.Opaque != "" {
+		if !opts.KeepFragment {
+			u.Fragment = ""
+		}
+		return u.String(), nil
+	}
+
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]" // ipv6
+	}
+	if port != "" {
+		host = host + ":" + port
+	}
+	u.Host = host
+
+	// clean the escaped path so encoded separators (%2F) are not turned into real ones
+	escaped := cleanPath(u.EscapedPath(), opts.RemoveTrailingSlash)
+	unescaped, err := url.PathUnescape(escaped)
+	if err != nil {
+		return "", err
+	}
+	u.Path = unescaped
+	u.RawPath = escaped
+	if u.Host != "" && u.Path == "" {
+		u.Path = "/"
+	}
+
+	u.RawQuery = canonicalQuery(u.RawQuery, opts)
+	u.ForceQuery = false
+
+	if !opts.KeepFragment {
+		u.Fragment = ""
+	}
+	return u.String(), nil
+}
+
+// resolves dot segments while preserving a trailing slash (unless asked to remove it).
+func cleanPath(p string, removeTrailing bool) string {
+	if p == "" || p == "/" {
+		return p
+	}
+	trailing := strings.HasSuffix(p, "/")
+	cleaned := path.Clean(p)
+	if trailing && !removeTrailing && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// filters and (optionally) sorts the query string
+func canonicalQuery(rawQuery string, opts *Options) string {
+	if rawQuery == "" {
+		return ""
+	}
+	pairs := strings.FieldsFunc(rawQuery, func(r rune) bool { return r == '&' || r == ';' })
+	kept := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		name := pair
+		if idx := strings.Index(pair, "="); idx != -1 {
+			name = pair[:idx]
+		}
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if matchesParam(name, opts.RemoveParams) {
+			continue
+		}
+		kept = append(kept, pair)
+	}
+	if !opts.KeepQueryOrder {
+		sort.Strings(kept)
+	}
+	return strings.Join(kept, "&")
+}
+
+func matchesParam(name string, params []string) bool {
+	for _, param := range params {
+		if strings.HasSuffix(param, "*") {
+			if strings.HasPrefix(name, strings.TrimSuffix(param, "*")) {
+				return true
+			}
+		} else if name == param {
+			return true
+		}
+	}
+	return false
+}
+
+// StripFragment removes the #fragment from rawurl.
+func StripFragment(rawurl string) string {
+	if idx := strings.Index(rawurl, "#"); idx != -1 {
+		return rawurl[:idx]
+	}
+	return rawurl
+}
+
+// SortQuery returns rawurl with its query parameters sorted.
+func SortQuery(rawurl string) (string, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+	u.RawQuery = canonicalQuery(u.RawQuery, &Options{})
+	return u.String(), nil
+}
+
+// FilterQuery returns rawurl with only the query parameters for which keep returns true.
+func FilterQuery(rawurl string, keep func(name string) bool) (string, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+	values := u.Query()
+	for name := range values {
+		if !keep(name) {
+			values.Del(name)
+		}
+	}
+	u.RawQuery = values.Encode()
+	return u.String(), nil
+}
+
+// Origin returns the scheme://host[:port] of rawurl, with default ports removed.
+func Origin(rawurl string) (string, error) {
+	canonical, err := Canonicalize(rawurl, nil)
+	if err != nil {
+		return "", err
+	}
+	u, _ := url.Parse(canonical)
+	return u.Scheme + "://" + u.Host, nil
+}
+
+// SameOrigin returns true if both urls share the same scheme, host and port.
+func SameOrigin(a, b string) bool {
+	originA, err := Origin(a)
+	if err != nil {
+		return false
+	}
+	originB, err := Origin(b)
+	if err != nil {
+		return false
+	}
+	return originA == originB
+}
+
+// Resolve returns ref resolved against base, useful for relative href values.
+func Resolve(base, ref string) (string, error) {
+	baseUrl, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	refUrl, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return "", err
+	}
+	return baseUrl.ResolveReference(refUrl).String(), nil
+}
+
+// Deduper tracks canonicalized urls that have already been seen. It is safe for concurrent use.
+type Deduper struct {
+	lock    *sync.RWMutex
+	options *Options
+	seen    map[string]struct{}
+}
+
+// NewDeduper creates a Deduper which canonicalizes with opts, which may be nil.
+func NewDeduper(opts *Options) *Deduper {
+	d := &Deduper{}
+	d.lock = &sync.RWMutex{}
+	d.options = opts
+	d.seen = make(map[string]struct{})
+	return d
+}
+
+// Add marks rawurl as seen and returns its canonical form, along with true if it was not seen before.
+func (d *Deduper) Add(rawurl string) (string, bool) {
+	canonical, err := Canonicalize(rawurl, d.options)
+	if err != nil {
+		return "", false
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if _, ok := d.seen[canonical]; ok {
+		return canonical, false
+	}
+	d.seen[canonical] = struct{}{}
+	return canonical, true
+}
+
+// Seen returns true if rawurl (or an equivalent url) was already added.
+func (d *Deduper) Seen(rawurl string) bool {
+	canonical, err := Canonicalize(rawurl, d.options)
+	if err != nil {
+		return false
+	}
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	_, ok := d.seen[canonical]
+	return ok
+}
+
+// Len returns the number of unique urls seen.
+func (d *Deduper) Len() int {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return len(d.seen)
+}
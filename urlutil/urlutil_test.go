@@ -0,0 +1,62 @@
+package urlutil
+
+import (
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	cases := []struct {
+		in       string
+		opts     *Options
+		expected string
+	}{
+		{"HTTP://Example.COM:80", nil, "http://example.com/"},
+		{"https://example.com:443/a/./b/../c?b=2&a=1#frag", nil, "https://example.com/a/c?a=1&b=2"},
+		{"http://example.com:8080/dir/", nil, "http://example.com:8080/dir/"},
+		{"http://example.com/dir/", &Options{RemoveTrailingSlash: true}, "http://example.com/dir"},
+		{"http://example.com/?utm_source=x&id=1&gclid=abc", &Options{RemoveParams: TrackingParams}, "http://example.com/?id=1"},
+		{"http://example.com/#top", &Options{KeepFragment: true}, "http://example.com/#top"},
+		{"http://example.com/?b=1&a=2", &Options{KeepQueryOrder: true}, "http://example.com/?b=1&a=2"},
+		{"http://example.com/a%2Fb/./c", nil, "http://example.com/a%2Fb/c"},
+		{"http://example.com/a%20b/../c%20d", nil, "http://example.com/c%20d"},
+		{"about:blank", nil, "about:blank"},
+	}
+
+	for _, c := range cases {
+		got, err := Canonicalize(c.in, c.opts)
+		if err != nil {
+			t.Fatalf("error canonicalizing %s: %s\n", c.in, err)
+		}
+		if got != c.expected {
+			t.Fatalf("canonicalizing %s expected %s got %s\n", c.in, c.expected, got)
+		}
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	if !SameOrigin("http://Example.com/a", "http://example.com:80/b?x=1") {
+		t.Fatalf("expected urls to be the same origin")
+	}
+	if SameOrigin("http://example.com/", "https://example.com/") {
+		t.Fatalf("expected different schemes to be different origins")
+	}
+	if SameOrigin("http://example.com/", "http://sub.example.com/") {
+		t.Fatalf("expected different hosts to be different origins")
+	}
+}
+
+func TestDeduper(t *testing.T) {
+	d := NewDeduper(nil)
+	if _, added := d.Add("http://example.com/?b=1&a=2#x"); !added {
+		t.Fatalf("expected first url to be added")
+	}
+	if _, added := d.Add("http://EXAMPLE.com:80/?a=2&b=1"); added {
+		t.Fatalf("expected equivalent url to be a duplicate")
+	}
+	if !d.Seen("http://example.com/?a=2&b=1#y") {
+		t.Fatalf("expected url to be seen")
+	}
+	if d.Len() != 1 {
+		t.Fatalf("expected 1 unique url got %d\n", d.Len())
+	}
+}
@@ -0,0 +1,158 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ResponseWaiter waits for a response matching a url pattern to finish loading, see ExpectResponse.
+type ResponseWaiter struct {
+	tab       *Tab
+	pattern   *regexp.Regexp
+	requestId string           // set once a matching response is received
+	response  *NetworkResponse // the matching response
+	failed    bool             // the matching request failed to load
+	doneCh    chan struct{}    // closed when the matching request finishes or fails
+}
+
+// ExpectResponse starts watching for a response whose url matches urlPattern, where * matches any sequence of
+// characters (e.g. "*/api/search?*"). Call it before the action that causes the request, then call Wait, so a
+// fast response can not be missed.
+func (t *Tab) ExpectResponse(urlPattern string) (*ResponseWaiter, error) {
+	if err := t.enableNetwork(); err != nil {
+		return nil, err
+	}
+
+	pattern, err := regexp.Compile(globToRegexp(urlPattern))
+	if err != nil {
+		return nil, err
+	}
+
+	waiter := &ResponseWaiter{tab: t, pattern: pattern, doneCh: make(chan struct{})}
+	t.networkLock.Lock()
+	t.responseWaiters = append(t.responseWaiters, waiter)
+	t.networkLock.Unlock()
+	return waiter, nil
+}
+
+// Wait returns the matching response once its body has finished loading, so GetResponseBody may be called.
+// Returns a TimeoutErr if no matching response finished loading before timeout, or an InvalidNavigationErr
+// if the matching request failed.
+func (w *ResponseWaiter) Wait(timeout time.Duration) (*NetworkResponse, error) {
+	timeoutTimer := time.NewTimer(timeout)
+	defer timeoutTimer.Stop()
+
+	select {
+	case <-w.doneCh:
+	case <-w.tab.crashedNotifyCh:
+		w.tab.removeResponseWaiter(w)
+		return nil, w.tab.CrashErr()
+	case <-timeoutTimer.C:
+		w.tab.removeResponseWaiter(w)
+		return nil, &TimeoutErr{Message: "waiting for response matching " + w.pattern.String()}
+	}
+
+	w.tab.networkLock.RLock()
+	defer w.tab.networkLock.RUnlock()
+	if w.failed {
+		return w.response, &InvalidNavigationErr{Message: "request for " + w.response.Response.Url + " failed"}
+	}
+	return w.response, nil
+}
+
+// WaitForResponse waits for a response whose url matches urlPattern (see ExpectResponse) to finish loading.
+// Only responses received after it is called are matched, use ExpectResponse if the request may be sent
+// before WaitForResponse is called.
+func (t *Tab) WaitForResponse(urlPattern string, timeout time.Duration) (*NetworkResponse, error) {
+	waiter, err := t.ExpectResponse(urlPattern)
+	if err != nil {
+		return nil, err
+	}
+	return waiter.Wait(timeout)
+}
+
+// GetResponseBody returns the body of a finished request, base64 encoded bodies are decoded.
+func (t *Tab) GetResponseBody(requestId string) ([]byte, error) {
+	body, encoded, err := t.Network.GetResponseBody(requestId)
+	if err != nil {
+		return nil, err
+	}
+	if encoded {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
+
+// caller must hold networkLock. Matches waiters that have not yet seen a response.
+func (t *Tab) matchResponseWaiters(response *NetworkResponse) {
+	if response.Response == nil {
+		return
+	}
+	for _, waiter := range t.responseWaiters {
+		if waiter.requestId == "" && waiter.pattern.MatchString(response.Response.Url) {
+			waiter.requestId = response.RequestId
+			waiter.response = response
+		}
+	}
+}
+
+// caller must hold networkLock. Notifies and removes waiters whose request has finished or failed.
+func (t *Tab) finishResponseWaiters(requestId string, failed bool) {
+	remaining := t.responseWaiters[:0]
+	for _, waiter := range t.responseWaiters {
+		if waiter.requestId == requestId {
+			waiter.failed = failed
+			close(waiter.doneCh)
+			continue
+		}
+		remaining = append(remaining, waiter)
+	}
+	t.responseWaiters = remaining
+}
+
+func (t *Tab) removeResponseWaiter(waiter *ResponseWaiter) {
+	t.networkLock.Lock()
+	defer t.networkLock.Unlock()
+
+	for i, w := range t.responseWaiters {
+		if w == waiter {
+			t.responseWaiters = append(t.responseWaiters[:i], t.responseWaiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// converts a url pattern where * matches anything to an anchored regular expression.
+func globToRegexp(pattern string) string {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return "^" + strings.Join(parts, ".*") + "$"
+}
@@ -0,0 +1,87 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+	"time"
+)
+
+// WaitForSelector waits up to timeout for selector to match an element in the top level document and
+// for that element to be ready, returning it. The TimeoutErr returned says whether nothing matched or the
+// match never became ready.
+func (t *Tab) WaitForSelector(selector string, timeout time.Duration) (*Element, error) {
+	elements, err := t.waitForSelector(selector, timeout, true)
+	if err != nil {
+		return nil, err
+	}
+	return elements[0], nil
+}
+
+// WaitForSelectorAll waits up to timeout for selector to match at least one element, then for all the
+// matching elements to be ready.
+func (t *Tab) WaitForSelectorAll(selector string, timeout time.Duration) ([]*Element, error) {
+	return t.waitForSelector(selector, timeout, false)
+}
+
+func (t *Tab) waitForSelector(selector string, timeout time.Duration, firstOnly bool) ([]*Element, error) {
+	deadline := time.Now().Add(timeout)
+	rate := 100 * time.Millisecond
+
+	var elements []*Element
+	var lastErr error
+	for {
+		elements, lastErr = t.GetElementsBySelector(selector)
+		if lastErr == nil && len(elements) > 0 {
+			break
+		}
+		if time.Now().Add(rate).After(deadline) {
+			message := fmt.Sprintf("after %s waiting for selector %s to match an element", timeout, selector)
+			if lastErr != nil {
+				message += fmt.Sprintf(", last error: %s", lastErr)
+			}
+			return nil, &TimeoutErr{Message: message}
+		}
+		select {
+		case <-time.After(rate):
+		case <-t.exitCh:
+			return nil, &InvalidTabErr{Message: "tab closed waiting for selector " + selector}
+		}
+	}
+
+	if firstOnly {
+		elements = elements[:1]
+	}
+	for i, ele := range elements {
+		remaining := time.Until(deadline)
+		if remaining < 0 {
+			remaining = 0
+		}
+		if err := ele.waitForReady(remaining); err != nil {
+			return nil, &TimeoutErr{Message: fmt.Sprintf("after %s waiting for element %d of %d matching selector %s to be ready", timeout, i+1, len(elements), selector)}
+		}
+	}
+	return elements, nil
+}
@@ -0,0 +1,106 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2017 isaac dawson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package autogcd
+
+import (
+	"fmt"
+	"time"
+)
+
+// Observes the core web vitals with buffered PerformanceObservers for waitMs milliseconds, then resolves
+// with the values in milliseconds (cls is unitless). Entry types the browser does not support are skipped.
+const webVitalsScript = `new Promise(function(resolve) {
+	var vitals = {lcp: 0, cls: 0, fid: 0, inp: 0, ttfb: 0};
+	var observers = [];
+	function observe(type, fn) {
+		try {
+			var observer = new PerformanceObserver(function(list) { list.getEntries().forEach(fn); });
+			observer.observe({type: type, buffered: true});
+			observers.push({observer: observer, fn: fn});
+		} catch (e) {}
+	}
+	observe('largest-contentful-paint', function(entry) { vitals.lcp = entry.renderTime || entry.loadTime || entry.startTime; });
+	observe('layout-shift', function(entry) { if (!entry.hadRecentInput) { vitals.cls += entry.value; } });
+	observe('first-input', function(entry) { vitals.fid = entry.processingStart - entry.startTime; });
+	observe('event', function(entry) { if (entry.interactionId && entry.duration > vitals.inp) { vitals.inp = entry.duration; } });
+
+	var nav = performance.getEntriesByType ? performance.getEntriesByType('navigation')[0] : null;
+	if (nav) {
+		vitals.ttfb = nav.responseStart;
+	} else if (performance.timing) {
+		vitals.ttfb = performance.timing.responseStart - performance.timing.navigationStart;
+	}
+
+	setTimeout(function() {
+		// entries still queued for the callbacks would be lost on disconnect
+		observers.forEach(function(o) {
+			if (o.observer.takeRecords) { o.observer.takeRecords().forEach(o.fn); }
+			o.observer.disconnect();
+		});
+		resolve(vitals);
+	}, %d);
+})`
+
+// WebVitals holds the core web vitals for the current navigation. Values that were not observed
+// (FID and INP require user input, older browsers do not report LCP or CLS) are 0.
+type WebVitals struct {
+	LCP  time.Duration // largest contentful paint
+	CLS  float64       // cumulative layout shift score, shifts after user input are excluded
+	FID  time.Duration // first input delay
+	INP  time.Duration // interaction to next paint, the longest interaction seen
+	TTFB time.Duration // time to first byte of the main document
+}
+
+// MeasureWebVitals injects PerformanceObservers into the current page and collects the core web vitals
+// for timeout before returning them. Since the observers are buffered, this can be called after Navigate
+// returns, any interactions to be included in FID/INP must happen while it is waiting.
+func (t *Tab) MeasureWebVitals(timeout time.Duration) (*WebVitals, error) {
+	rro, err := t.evaluateScript(fmt.Sprintf(webVitalsScript, int64(timeout/time.Millisecond)), true)
+	if err != nil {
+		return nil, err
+	}
+
+	vitals := &WebVitals{}
+	values, ok := rro.Value.(map[string]interface{})
+	if !ok {
+		return vitals, nil
+	}
+
+	vitals.LCP = millisToDuration(values["lcp"])
+	vitals.CLS, _ = values["cls"].(float64)
+	vitals.FID = millisToDuration(values["fid"])
+	vitals.INP = millisToDuration(values["inp"])
+	vitals.TTFB = millisToDuration(values["ttfb"])
+	return vitals, nil
+}
+
+// converts a javascript millisecond value to a duration, returns 0 if value is not a number.
+func millisToDuration(value interface{}) time.Duration {
+	millis, ok := value.(float64)
+	if !ok || millis < 0 {
+		return 0
+	}
+	return time.Duration(millis * float64(time.Millisecond))
+}